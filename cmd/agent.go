@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plan"
+	"github.com/kaiqui/commitai/internal/readonly"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+// agentPlanFile keeps the plan last shown in agent mode inside the .git
+// directory, for --approve to commit
+const agentPlanFile = "commitai/agent-plan.json"
+
+var (
+	flagAgent   bool   // Print the plan as JSON, commit only with --approve
+	flagApprove string // Approval token of the reviewed plan
+)
+
+// agentPlan is what agent mode prints: the commits and the token approving
+// them. After --approve, Head is the commit the branch ends on.
+type agentPlan struct {
+	Approval string        `json:"approval"`
+	Commits  []agentCommit `json:"commits"`
+	Head     string        `json:"head,omitempty"`
+}
+
+type agentCommit struct {
+	Name    string   `json:"name"`
+	Files   []string `json:"files"`
+	Message string   `json:"message"`
+	Fixup   string   `json:"fixup,omitempty"`
+}
+
+// agentMode reports --agent, --approve or COMMITAI_AGENT=1
+func agentMode() bool {
+	return flagAgent || flagApprove != "" || os.Getenv(config.EnvAgent) == "1"
+}
+
+// agentOutput keeps stdout for the JSON document: progress and every other
+// line go to stderr from here on, and nothing is asked. It returns the real
+// stdout.
+func agentOutput() io.Writer {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	ui, _ = render.New(uiFormat, os.Stderr)
+	flagYes = true // The approval is the answer
+	return stdout
+}
+
+// approvalToken identifies a plan together with what committing it would
+// record: HEAD, the index and the unstaged changes to its files. Any
+// change to them after review gives another token.
+func approvalToken(commits []plan.Commit) (string, error) {
+	base, _ := git.HeadCommit() // Empty in a repository without commits
+	state, err := git.IndexState(planFiles(commits))
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", base, state)
+	for _, c := range commits {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", c.Name, strings.Join(c.Files, "\x01"), c.Message, c.Fixup)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// showAgentPlan prints the plan with its approval token and remembers it
+// for --approve
+func showAgentPlan(out io.Writer, commits []plan.Commit) error {
+	if err := readonly.Check("remembering the plan for --approve"); err != nil {
+		return err
+	}
+	for i, c := range commits {
+		if c.Message == "" {
+			commits[i].Message = fmt.Sprintf("chore: update %s", c.Name)
+		}
+	}
+	if flagBisectable && len(commits) > 1 {
+		commits = bisectablePlans(commits)
+	}
+	token, err := approvalToken(commits)
+	if err != nil {
+		return err
+	}
+	doc := agentPlan{Approval: token, Commits: agentCommits(commits)}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := agentPlanPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to remember the plan: %w", err)
+	}
+	ui.Muted("🔏 Commit it with: commitai --approve %s", token)
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// approvePlan commits the plan last shown in agent mode, if token approves
+// it and nothing it would commit changed since
+func approvePlan(cfg *config.Config, out io.Writer, token string) error {
+	path, err := agentPlanPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no plan to approve; review one with: commitai --agent")
+	}
+	if err != nil {
+		return err
+	}
+	var doc agentPlan
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	commits := make([]plan.Commit, len(doc.Commits))
+	for i, c := range doc.Commits {
+		commits[i] = plan.Commit{Name: c.Name, Files: c.Files, Message: c.Message, Fixup: c.Fixup}
+	}
+
+	current, err := approvalToken(commits)
+	if err != nil {
+		return err
+	}
+	switch {
+	case token != doc.Approval:
+		return fmt.Errorf("--approve %s doesn't match the last plan shown (%s); review it again with: commitai --agent", token, doc.Approval)
+	case token != current:
+		return fmt.Errorf("HEAD, the staged changes or the plan's files changed since the plan was reviewed; review it again with: commitai --agent")
+	}
+
+	before, _ := git.HeadCommit()
+	if err := executePlans(cfg, commits, 0, false, true, nil, nil); err != nil {
+		return err
+	}
+	os.Remove(path) // An approval is used once
+	updateSquashDraft(cfg, before)
+	if err := pushCommits(before); err != nil {
+		return err
+	}
+	commentIssues(cfg, before)
+
+	doc.Head, _ = git.HeadCommit()
+	data, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func agentCommits(commits []plan.Commit) []agentCommit {
+	out := make([]agentCommit, len(commits))
+	for i, c := range commits {
+		out[i] = agentCommit{Name: c.Name, Files: c.Files, Message: c.Message, Fixup: c.Fixup}
+	}
+	return out
+}
+
+func agentPlanPath() (string, error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, agentPlanFile), nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/history"
+)
+
+var (
+	askSince  string
+	askPaths  []string
+	askLimit  int
+	askSearch bool
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Answer a question about the repository's history with AI",
+	Long: `Answer a question about the repository's history with AI.
+
+The question's key terms are searched in commit messages and, with the git
+pickaxe (git log -G), in the lines each commit added or removed; adjacent
+terms also match as one identifier (retry timeout → retry_timeout,
+retryTimeout). The best matches and the lines that mention the terms are
+sent to the AI, which answers and names the commits involved.`,
+	Args:         cobra.MinimumNArgs(1),
+	RunE:         runAsk,
+	SilenceUsage: true,
+}
+
+func init() {
+	askCmd.Flags().StringVar(&askSince, "since", "", "Only search commits since this date (any date git accepts)")
+	askCmd.Flags().StringSliceVar(&askPaths, "path", nil, "Only search commits touching these paths")
+	askCmd.Flags().IntVar(&askLimit, "limit", 20, "Candidate commits sent to the AI")
+	askCmd.Flags().BoolVar(&askSearch, "search", false, "Only list the matching commits, without an AI call")
+	askCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	question := strings.Join(args, " ")
+	terms := history.Terms(question)
+	if len(terms) == 0 {
+		return fmt.Errorf("no searchable terms in %q; mention names, identifiers or topics", question)
+	}
+
+	ui.Info("🔎 Searching history for: %s", strings.Join(terms, ", "))
+	candidates, err := history.Search(terms, askLimit, git.SearchOptions{N: 50, Since: askSince, Paths: askPaths})
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		ui.Warn("No commits mention these terms.")
+		return nil
+	}
+
+	if askSearch {
+		fmt.Println()
+		for _, c := range candidates {
+			printHistoryCommit(c.Commit)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := ensureProvider(cfg); err != nil {
+		return err
+	}
+	client := newClient(cfg)
+	ui.Info("✨ Reading %d candidate commit(s) with %s...", len(candidates), client.ProviderName())
+	answer, cited, err := client.AnswerHistory(question, candidates)
+	if err != nil {
+		return fmt.Errorf("failed to answer: %w", err)
+	}
+
+	fmt.Println()
+	ui.Document(answer)
+
+	var involved []git.CommitInfo
+	for _, h := range cited {
+		for _, c := range candidates {
+			if strings.HasPrefix(c.Commit.Hash, h) {
+				involved = append(involved, c.Commit)
+				break
+			}
+		}
+	}
+	if len(involved) > 0 {
+		fmt.Println()
+		ui.Info("📜 Commits:")
+		for _, c := range involved {
+			printHistoryCommit(c)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func printHistoryCommit(c git.CommitInfo) {
+	fmt.Printf("  %s %s %-16s %s\n", color.YellowString(shortSHA(c.Hash)), c.Date.Format("2006-01-02"), c.Author, c.Subject)
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/backup"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	rbList   bool
+	rbDelete bool
+)
+
+var restoreBackupCmd = &cobra.Command{
+	Use:   "restore-backup [name]",
+	Short: "Undo a history rewrite by restoring a backup ref",
+	Long: `Undo a history rewrite by restoring a backup ref.
+
+Every command that rewrites history (tidy, reword, release edit-notes) first
+saves what it rewrites in refs/commitai/backup/<timestamp>. restore-backup
+puts the branch or tag back where it was, the latest backup by default. The
+checked-out branch is restored with git reset --keep, so local changes are
+kept. The state being replaced is backed up too, so a restore can be undone
+the same way.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runRestoreBackup,
+	SilenceUsage: true,
+}
+
+func init() {
+	restoreBackupCmd.Flags().BoolVar(&rbList, "list", false, "List the backups")
+	restoreBackupCmd.Flags().BoolVar(&rbDelete, "delete", false, "Delete the backup instead of restoring it")
+	restoreBackupCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Restore without asking for confirmation")
+}
+
+func loadBackups() (*backup.Store, error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return backup.Load(gitDir)
+}
+
+// saveBackup keeps what ref points at in a backup ref before command
+// rewrites it, and says how to undo the rewrite
+func saveBackup(ref, command string) error {
+	hash, err := git.ResolveRef(ref)
+	if err != nil {
+		return err
+	}
+	store, err := loadBackups()
+	if err != nil {
+		return err
+	}
+	b := store.New(ref, hash, command)
+	if err := git.UpdateRef(b.BackupRef(), hash, "", "commitai "+command+": backup"); err != nil {
+		return err
+	}
+	if err := store.Add(b); err != nil {
+		return err
+	}
+	ui.Muted("💾 %s saved in %s", shortRef(ref), b.BackupRef())
+	return nil
+}
+
+// printRecovery says how to undo the rewrite of the latest backup
+func printRecovery() {
+	store, err := loadBackups()
+	if err != nil {
+		return
+	}
+	if b, ok := store.Latest(); ok {
+		ui.Muted("↩️  Undo with: commitai restore-backup %s", b.Name)
+		if branch := strings.TrimPrefix(b.Ref, "refs/heads/"); branch != b.Ref {
+			ui.Muted("   or: git reset --keep %s (git reflog %s also has it)", b.BackupRef(), branch)
+		}
+	}
+}
+
+func runRestoreBackup(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	store, err := loadBackups()
+	if err != nil {
+		return err
+	}
+
+	if rbList {
+		if len(store.Backups) == 0 {
+			ui.Warn("No backups.")
+			return nil
+		}
+		ui.Info("💾 Backups (newest first):")
+		fmt.Println()
+		for i := len(store.Backups) - 1; i >= 0; i-- {
+			b := store.Backups[i]
+			fmt.Printf("  %s  %-20s %-20s %s %s\n", color.CyanString(b.Name), b.Command, shortRef(b.Ref), shortSHA(b.Hash), git.Subject(b.Hash))
+		}
+		return nil
+	}
+
+	var b backup.Backup
+	var ok bool
+	if len(args) == 1 {
+		b, ok = store.Get(args[0])
+		if !ok {
+			return fmt.Errorf("no backup named %s (see commitai restore-backup --list)", args[0])
+		}
+	} else if b, ok = store.Latest(); !ok {
+		return fmt.Errorf("no backups to restore")
+	}
+
+	if rbDelete {
+		if err := git.DeleteRef(b.BackupRef()); err != nil {
+			return err
+		}
+		if err := store.Delete(b.Name); err != nil {
+			return err
+		}
+		ui.Success("🗑️  Backup %s deleted", b.Name)
+		return nil
+	}
+
+	current, _ := git.ResolveRef(b.Ref)
+	ui.Info("💾 Backup %s, made by %s on %s:", b.Name, b.Command, b.Created.Format("2006-01-02 15:04"))
+	fmt.Printf("  %s: %s → %s %s\n", shortRef(b.Ref), ifEmpty(shortSHA(current), "missing"), shortSHA(b.Hash), git.Subject(b.Hash))
+	if current == b.Hash {
+		ui.Warn("%s is already there; nothing to restore.", shortRef(b.Ref))
+		return nil
+	}
+
+	if !flagYes {
+		fmt.Printf("\n⚡ Restore %s? [y/N]: ", shortRef(b.Ref))
+		var input string
+		fmt.Scanln(&input)
+		if input = strings.ToLower(strings.TrimSpace(input)); input != "y" && input != "yes" {
+			ui.Warn("Nothing restored.")
+			return nil
+		}
+	}
+
+	if current != "" {
+		if err := saveBackup(b.Ref, "restore-backup"); err != nil {
+			return err
+		}
+	}
+	branch, _ := git.CurrentBranch()
+	if b.Ref == "refs/heads/"+branch {
+		err = git.ResetKeep(b.Hash)
+	} else {
+		err = git.UpdateRef(b.Ref, b.Hash, current, "commitai restore-backup "+b.Name)
+	}
+	if err != nil {
+		return err
+	}
+	ui.Success("✅ %s restored to %s", shortRef(b.Ref), shortSHA(b.Hash))
+	if strings.HasPrefix(b.Ref, "refs/tags/") {
+		ui.Muted("   If the tag was pushed since, push it again with: git push --force origin %s", b.Ref)
+	}
+	printRecovery()
+	return nil
+}
+
+// shortRef names a ref the way git prints it: main, v1.2.0
+func shortRef(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ref
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plan"
+)
+
+// checkBannedPaths refuses to go on when any of paths matches the
+// configured banned_paths. Unlike branch protection, --force doesn't
+// override it: these files are never meant to be committed.
+func checkBannedPaths(cfg *config.Config, paths []string) error {
+	banned := bannedIn(cfg, paths)
+	switch len(banned) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("refusing to commit banned path %s; unstage it with git restore --staged -- <path>, and add it to .gitignore", banned[0])
+	}
+	return fmt.Errorf("refusing to commit %d banned paths: %s; unstage them with git restore --staged -- <path>, and add them to .gitignore",
+		len(banned), strings.Join(banned, ", "))
+}
+
+// bannedIn lists the paths matching banned_paths, with their pattern
+func bannedIn(cfg *config.Config, paths []string) []string {
+	var banned []string
+	for _, p := range paths {
+		if pattern, ok := cfg.BannedPattern(p); ok {
+			banned = append(banned, fmt.Sprintf("%s (%s)", p, pattern))
+		}
+	}
+	return banned
+}
+
+// checkBannedChanges is checkBannedPaths for the changes of a commit;
+// deleting a banned file is fine
+func checkBannedChanges(cfg *config.Config, changes []git.FileChange) error {
+	var paths []string
+	for _, c := range changes {
+		if c.Status != "D" {
+			paths = append(paths, c.Path)
+		}
+	}
+	return checkBannedPaths(cfg, paths)
+}
+
+// checkBannedStaged is checkBannedPaths for what is staged in git
+func checkBannedStaged(cfg *config.Config) error {
+	if len(cfg.BannedPaths) == 0 {
+		return nil
+	}
+	paths, err := git.StagedFiles()
+	if err != nil {
+		return err
+	}
+	return checkBannedPaths(cfg, paths)
+}
+
+// checkBannedWorkingTree is checkBannedPaths for what git add -A would
+// stage: the staged files, the unstaged changes and the untracked files.
+// It is checked before staging, so nothing is left staged when it fails.
+func checkBannedWorkingTree(cfg *config.Config) error {
+	if len(cfg.BannedPaths) == 0 {
+		return nil
+	}
+	paths, err := git.StagedFiles()
+	if err != nil {
+		return err
+	}
+	unstaged, untracked, err := git.WorkingTree()
+	if err != nil {
+		return err
+	}
+	for _, c := range unstaged {
+		if c.Status != "D" {
+			paths = append(paths, c.Path)
+		}
+	}
+	// A file both staged and changed again shows up twice
+	paths = append(paths, untracked...)
+	slices.Sort(paths)
+	banned := bannedIn(cfg, slices.Compact(paths))
+	switch len(banned) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("refusing to commit banned path %s; add it to .gitignore, or stage what to commit and use --staged", banned[0])
+	}
+	return fmt.Errorf("refusing to commit %d banned paths: %s; add them to .gitignore, or stage what to commit and use --staged",
+		len(banned), strings.Join(banned, ", "))
+}
+
+// checkBannedPlans is checkBannedPaths for the files plans would commit,
+// leaving out the ones they delete
+func checkBannedPlans(cfg *config.Config, plans []plan.Commit) error {
+	var paths []string
+	for _, p := range plans {
+		for _, f := range p.Files {
+			if _, err := os.Lstat(f); err == nil {
+				paths = append(paths, f)
+			}
+		}
+	}
+	return checkBannedPaths(cfg, paths)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	batRepos  string
+	batAdd    bool
+	batReport string
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [repo...]",
+	Short: "Run the commit flow across many repositories",
+	Long: `Run the commit flow across many repositories and report the results.
+
+Repositories come from --repos (one path per line, # for comments) and/or
+the arguments. Each one is processed by a separate commitai run inside it, so
+its own .commitai.json applies.`,
+	RunE:         runBatch,
+	SilenceUsage: true, // Per-repo failures are not usage errors
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batRepos, "repos", "", "File listing repository paths, one per line")
+	batchCmd.Flags().BoolVar(&batAdd, "add", false, "Stage all changes (git add -A) in each repository first")
+	batchCmd.Flags().StringVar(&batReport, "report", "", "Write a JSON report to this file")
+	batchCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip confirmation prompts")
+	batchCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "d", false, "Preview commit messages without committing")
+	batchCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "One commit for all staged changes in each repository")
+	batchCmd.Flags().BoolVarP(&flagGranular, "granular", "g", false, "One commit per staged file")
+	batchCmd.Flags().StringVarP(&flagLanguage, "lang", "l", "", "Language for messages (en, pt-br)")
+	batchCmd.Flags().StringVar(&flagStyle, "style", "", "Commit style (conventional, simple, auto)")
+	batchCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on protected branches")
+	batchCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+// batchResult is one repository's outcome in the batch report
+type batchResult struct {
+	Repo   string `json:"repo"`
+	Status string `json:"status"` // committed, dry-run, nothing-staged, failed
+	Detail string `json:"detail,omitempty"`
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	repos := append([]string{}, args...)
+	if batRepos != "" {
+		listed, err := readRepoList(batRepos)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, listed...)
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories given; use --repos FILE or pass paths as arguments")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var results []batchResult
+	for i, repo := range repos {
+		ui.Info("\n━━━ [%d/%d] %s", i+1, len(repos), repo)
+		res := batchOne(self, repo)
+		results = append(results, res)
+		if res.Status == "failed" {
+			ui.Error("✖ %s", res.Detail)
+		}
+	}
+
+	printBatchReport("📊 Batch report:", results)
+
+	if batReport != "" {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		if err := os.WriteFile(batReport, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+		ui.Info("📄 Report written to %s", batReport)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		notifyAfter(cfg, start, fmt.Sprintf("Batch finished: %d repositories, %d failed", len(results), failed))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed", failed, len(results))
+	}
+	return nil
+}
+
+// batchOne runs commitai in a single repository
+func batchOne(self, repo string) batchResult {
+	res := batchResult{Repo: repo}
+
+	if _, err := gitIn(repo, "rev-parse", "--git-dir"); err != nil {
+		res.Status, res.Detail = "failed", "not a git repository: "+err.Error()
+		return res
+	}
+	if batAdd {
+		if _, err := gitIn(repo, "add", "-A"); err != nil {
+			res.Status, res.Detail = "failed", err.Error()
+			return res
+		}
+	}
+	if _, err := gitIn(repo, "diff", "--cached", "--quiet"); err == nil {
+		ui.Warn("Nothing staged — skipped.")
+		res.Status = "nothing-staged"
+		return res
+	}
+
+	before, _ := gitIn(repo, "rev-parse", "HEAD")
+
+	var output bytes.Buffer
+	c := exec.Command(self, batchArgs()...)
+	c.Dir = repo
+	c.Stdin = os.Stdin
+	c.Stdout = io.MultiWriter(os.Stdout, &output)
+	c.Stderr = io.MultiWriter(os.Stderr, &output)
+	if err := c.Run(); err != nil {
+		res.Status, res.Detail = "failed", lastLine(output.String())
+		return res
+	}
+
+	after, _ := gitIn(repo, "rev-parse", "HEAD")
+	switch {
+	case flagDryRun:
+		res.Status = "dry-run"
+	case after != before:
+		res.Status = "committed"
+		res.Detail, _ = gitIn(repo, "log", "-1", "--format=%s")
+	default:
+		res.Status, res.Detail = "failed", "no commit was made: "+lastLine(output.String())
+	}
+	return res
+}
+
+// batchArgs forwards the batch flags to each per-repository run
+func batchArgs() []string {
+	var args []string
+	if flagYes {
+		args = append(args, "--yes")
+	}
+	if flagDryRun {
+		args = append(args, "--dry-run")
+	}
+	if flagAll {
+		args = append(args, "--all")
+	}
+	if flagGranular {
+		args = append(args, "--granular")
+	}
+	if flagLanguage != "" {
+		args = append(args, "--lang", flagLanguage)
+	}
+	if flagStyle != "" {
+		args = append(args, "--style", flagStyle)
+	}
+	if flagMaxCost > 0 {
+		args = append(args, "--max-cost", fmt.Sprint(flagMaxCost))
+	}
+	if flagForce {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+func printBatchReport(title string, results []batchResult) {
+	fmt.Println()
+	ui.Info(title)
+	fmt.Println()
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+		var icon string
+		switch r.Status {
+		case "committed":
+			icon = color.GreenString("✔")
+		case "failed":
+			icon = color.RedString("✖")
+		default:
+			icon = color.YellowString("-")
+		}
+		fmt.Printf("  %s %-40s %-15s %s\n", icon, r.Repo, r.Status, r.Detail)
+	}
+	fmt.Println()
+	fmt.Printf("  %d committed, %d dry-run, %d nothing staged, %d failed\n",
+		counts["committed"], counts["dry-run"], counts["nothing-staged"], counts["failed"])
+	fmt.Println()
+}
+
+func readRepoList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	home, _ := os.UserHomeDir()
+	var repos []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "~/") && home != "" {
+			line = filepath.Join(home, line[2:])
+		}
+		repos = append(repos, line)
+	}
+	return repos, scanner.Err()
+}
+
+// gitIn runs git in dir, through the git package so read-only mode holds
+// there too
+func gitIn(dir string, args ...string) (string, error) {
+	out, err := git.Run(append([]string{"-C", dir}, args...)...)
+	return strings.TrimSpace(out), err
+}
+
+// lastLine picks the most useful line of a run's output: the error if there
+// is one, otherwise the last non-empty line
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for _, l := range lines {
+		if strings.HasPrefix(l, "Error: ") {
+			return strings.TrimPrefix(l, "Error: ")
+		}
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if l := strings.TrimSpace(lines[i]); l != "" {
+			return l
+		}
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/bench"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	benchModels  []string
+	benchOffline bool
+	benchRuns    int
+	benchJSON    bool
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Compare providers and models on bundled sample diffs",
+	Long: `Compare providers and models on bundled sample diffs.
+
+commitai bench generates a message for each of a fixed set of sample diffs
+(a feature, a bug fix, docs, a refactor, CI, tests) with the configured
+provider, or each --model, and reports latency, token use and the quality
+heuristics of 'commitai ci lint-commits': format, specificity, length, plus whether
+the expected commit type was picked. Only the samples are sent, nothing of
+your repositories.`,
+	Example: `  commitai bench
+  commitai bench --model gemini-2.5-flash --model gemini-2.5-pro
+  commitai bench --offline --runs 3
+  commitai bench --json > bench.json`,
+	Args:         cobra.NoArgs,
+	RunE:         runBench,
+	SilenceUsage: true,
+}
+
+func init() {
+	benchCmd.Flags().StringSliceVar(&benchModels, "model", nil, "Models to compare (repeatable; default: the configured model)")
+	benchCmd.Flags().BoolVar(&benchOffline, "offline", false, "Add the offline rule-based provider as a baseline")
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 1, "Generate each sample this many times, for steadier latencies")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "Print the results as JSON, every run included")
+}
+
+// benchTarget is one provider configuration under test
+type benchTarget struct {
+	name string
+	cfg  *config.Config
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if benchJSON {
+		// Progress goes to stderr, stdout is left for the results
+		ui, _ = render.New(uiFormat, os.Stderr)
+	}
+
+	var targets []benchTarget
+	if len(benchModels) > 0 || !benchOffline || cfg.Provider != config.ProviderOffline {
+		if err := ensureProvider(cfg); err != nil {
+			return err
+		}
+		models := benchModels
+		if len(models) == 0 {
+			models = []string{cfg.Model}
+		}
+		for _, m := range models {
+			c := *cfg
+			c.Model = m
+			targets = append(targets, benchTarget{name: benchName(&c), cfg: &c})
+		}
+	}
+	if benchOffline {
+		c := *cfg
+		c.Provider = config.ProviderOffline
+		targets = append(targets, benchTarget{name: "offline rules", cfg: &c})
+	}
+
+	total := len(targets) * len(bench.Samples) * benchRuns
+	ui.Info("🏁 %d sample(s) × %d run(s) on %d target(s): %d generation(s)", len(bench.Samples), benchRuns, len(targets), total)
+
+	var summaries []bench.Summary
+	for _, t := range targets {
+		ui.Info("\n⏱️  %s", t.name)
+		var runs []bench.Run
+		for i := 0; i < benchRuns; i++ {
+			for _, s := range bench.Samples {
+				r := benchSample(t.cfg, s)
+				ui.Muted("   %-12s %s", s.Name, benchRunLine(r))
+				runs = append(runs, r)
+			}
+		}
+		summaries = append(summaries, bench.Summarize(t.name, runs))
+	}
+
+	if benchJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	}
+	printBench(summaries)
+	return nil
+}
+
+// benchName names a target by its provider and model
+func benchName(cfg *config.Config) string {
+	provider := ai.NewGeminiClient(cfg).ProviderName()
+	if cfg.Model == "" || cfg.Provider == config.ProviderOffline {
+		return provider
+	}
+	return provider + " " + cfg.Model
+}
+
+// benchSample generates and rates one sample's message. The client is
+// bare: no budget, cost cap or anonymization, which the samples don't need.
+func benchSample(cfg *config.Config, s bench.Sample) bench.Run {
+	client := ai.NewGeminiClient(cfg)
+	r := bench.Run{Sample: s.Name}
+	client.BeforeCall = func(est ai.Estimate) error {
+		r.Tokens, r.Cost = est.InputTokens, est.Cost
+		return nil
+	}
+	client.AfterCall = func(tokens int) { r.Tokens = tokens }
+
+	start := time.Now()
+	messages, err := client.GenerateCommitMessages(s.Changes(), false, nil)
+	r.Latency = time.Since(start)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Message = messages["__all__"]
+	r.Rate(s, cfg.Policy, conventionalTypes)
+	return r
+}
+
+func benchRunLine(r bench.Run) string {
+	if r.Error != "" {
+		return "✖ " + r.Error
+	}
+	subject, _, _ := strings.Cut(r.Message, "\n")
+	return fmt.Sprintf("%8s  %3d  %s", benchDuration(r.Latency), r.Score, subject)
+}
+
+// benchDuration rounds d for display; the offline provider takes
+// microseconds
+func benchDuration(d time.Duration) string {
+	if d < 10*time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
+
+func printBench(summaries []bench.Summary) {
+	fmt.Println()
+	ui.Success("📊 Results:")
+	fmt.Printf("%-36s %9s %9s %8s %10s %7s %6s %6s\n", "target", "median", "slowest", "tokens", "cost", "score", "type", "failed")
+	for _, s := range summaries {
+		cost := "-"
+		if s.Cost > 0 {
+			cost = fmt.Sprintf("$%.4f", s.Cost)
+		}
+		ok := s.Runs - s.Failed
+		fmt.Printf("%-36s %9s %9s %8d %10s %7d %6s %6d\n",
+			s.Target, benchDuration(s.Median), benchDuration(s.Slowest),
+			s.Tokens, cost, s.Score, fmt.Sprintf("%d/%d", s.TypeHits, ok), s.Failed)
+	}
+	ui.Muted("\nTokens are per generation, cost the estimated maximum of all runs. Score is the average")
+	ui.Muted("quality heuristic (0-100); type counts the messages with the expected commit type.")
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plan"
+)
+
+// goChecks are run on every intermediate state; each must pass for the
+// commit to count as building
+var goChecks = [][]string{
+	{"go", "build", "./..."},
+	{"go", "vet", "./..."},
+}
+
+// bisectablePlans orders the commits of a granular or grouped plan so that
+// the tree builds after each of them: a commit that breaks the build is
+// moved after the ones it needs, and when no order works it is merged with
+// the commits that follow until it builds. Only Go modules are checked;
+// anything else is returned as is. Fixups stay first, unchecked.
+func bisectablePlans(plans []plan.Commit) []plan.Commit {
+	top, err := git.TopLevel()
+	if err != nil {
+		return plans
+	}
+	if !isGoModule(top) {
+		ui.Warn("⚠️  --bisectable only checks Go modules; no go.mod at the repository root")
+		return plans
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		ui.Warn("⚠️  --bisectable needs the go command on PATH")
+		return plans
+	}
+
+	var fixups, rest []plan.Commit
+	for _, p := range plans {
+		if p.Fixup != "" {
+			fixups = append(fixups, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	if len(rest) < 2 {
+		return plans // One commit: nothing in between to break
+	}
+
+	head, err := git.HeadCommit()
+	if err != nil {
+		return plans
+	}
+	dir, err := os.MkdirTemp("", "commitai-bisect-")
+	if err != nil {
+		return plans
+	}
+	defer os.RemoveAll(dir)
+	if err := git.AddDetachedWorktree(dir, head); err != nil {
+		ui.Warn("⚠️  Skipping the build check: %v", err)
+		return plans
+	}
+	defer git.RemoveWorktree(dir)
+
+	ui.Info("\n🧱 Checking that each commit builds (%s)...", goCheckNames())
+	base := planFiles(fixups)
+	if ok, out := buildsWith(dir, base, rest); !ok {
+		ui.Warn("⚠️  The staged changes don't build as a whole; keeping the planned order")
+		ui.Muted("%s", indent(out))
+		return plans
+	}
+
+	ordered := fixups
+	reordered, merged := false, 0
+	for len(rest) > 0 {
+		// The first commit, in planned order, that builds on what precedes it
+		next := -1
+		for i := range rest {
+			if ok, _ := buildsWith(dir, base, rest[i:i+1]); ok {
+				next = i
+				break
+			}
+		}
+		var p plan.Commit
+		if next >= 0 {
+			p = rest[next]
+			if next > 0 {
+				reordered = true
+				ui.Muted("   %s doesn't build yet; %s goes first", rest[0].Name, p.Name)
+			}
+			rest = append(rest[:next:next], rest[next+1:]...)
+		} else {
+			// Nothing builds alone: fold the following commits into the
+			// first until it does (all of them together do)
+			n := 2
+			for n < len(rest) {
+				if ok, _ := buildsWith(dir, base, rest[:n]); ok {
+					break
+				}
+				n++
+			}
+			p = mergePlans(rest[:n])
+			merged += n - 1
+			rest = rest[n:]
+		}
+		ordered = append(ordered, p)
+		base = append(base, p.Files...)
+	}
+
+	switch {
+	case merged > 0:
+		ui.Warn("🧱 %d commit(s) merged into others and the rest reordered so that every commit builds", merged)
+	case reordered:
+		ui.Success("🧱 Commits reordered so that every commit builds")
+	default:
+		ui.Success("🧱 Every commit builds in the planned order")
+	}
+	return ordered
+}
+
+// buildsWith reports whether the tree builds with HEAD plus the staged
+// content of base and of the plans' files, and the checks' output if not
+func buildsWith(dir string, base []string, plans []plan.Commit) (bool, string) {
+	if err := git.ResetWorktree(dir); err != nil {
+		return false, err.Error()
+	}
+	files := append(append([]string(nil), base...), planFiles(plans)...)
+	if len(files) > 0 {
+		if err := git.CopyStaged(dir, files); err != nil {
+			return false, err.Error()
+		}
+	}
+	for _, check := range goChecks {
+		cmd := exec.Command(check[0], check[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return false, strings.TrimSpace(string(out))
+		}
+	}
+	return true, ""
+}
+
+// mergePlans makes one commit of several: the first message leads and the
+// subjects of the others are listed in its body
+func mergePlans(plans []plan.Commit) plan.Commit {
+	merged := plans[0]
+	merged.Files = planFiles(plans)
+	var names, also []string
+	for _, p := range plans {
+		names = append(names, p.Name)
+	}
+	for _, p := range plans[1:] {
+		subject, _, _ := strings.Cut(p.Message, "\n")
+		also = append(also, "- "+subject)
+	}
+	merged.Name = strings.Join(names, " + ")
+	merged.Message = strings.TrimRight(merged.Message, "\n") + "\n\n" + strings.Join(also, "\n")
+	return merged
+}
+
+// planFiles lists the files of all the plans
+func planFiles(plans []plan.Commit) []string {
+	var files []string
+	for _, p := range plans {
+		files = append(files, p.Files...)
+	}
+	return files
+}
+
+// isGoModule reports a go.mod or go.work at the repository root
+func isGoModule(top string) bool {
+	for _, name := range []string{"go.mod", "go.work"} {
+		if _, err := os.Stat(filepath.Join(top, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func goCheckNames() string {
+	var names []string
+	for _, check := range goChecks {
+		names = append(names, strings.Join(check, " "))
+	}
+	return strings.Join(names, ", ")
+}
+
+func indent(text string) string {
+	return "   " + strings.ReplaceAll(text, "\n", "\n   ")
+}
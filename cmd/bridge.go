@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var vscodeBridgeCmd = &cobra.Command{
+	Use:   "vscode-bridge",
+	Short: "Serve line-delimited JSON over stdio for the VS Code extension",
+	Long: `Serve line-delimited JSON over stdin/stdout for the VS Code extension.
+
+Each request is one line, {"id": 1, "method": "...", "params": {...}}, and
+gets one response line, {"id": 1, "result": {...}} or {"id": 1, "error":
+{"code": ..., "message": "..."}}. The "jsonrpc" member is optional. Nothing
+but responses is written to stdout; logs go to stderr.
+
+The bridge starts without loading anything, so it answers initialize right
+away. A repository's config is loaded on its first request and kept until
+the user config file, the repository's .commitai.json or its commitlint
+config changes. generateForStaged returns the messages together with their policy
+violations, the staged files and the provider, so showing a suggestion
+takes a single round trip.
+
+Methods are those of commitai rpc:
+  initialize          -> {name, version, methods}
+  generateForStaged   {cwd, granular?, language?, style?}
+                      -> {granular, messages, violations, files, provider}
+  generateForDiff     {cwd?, diff, language?, style?} -> {message}
+  checkPolicy         {cwd?, message} -> {violations}
+  shutdown            stop serving`,
+	RunE: runVSCodeBridge,
+}
+
+func runVSCodeBridge(cmd *cobra.Command, args []string) error {
+	color.Output = os.Stderr
+	ui, _ = render.New(render.Plain, os.Stderr)
+
+	srv := newRPCServer()
+	srv.Lenient = true
+	return srv.Serve(os.Stdin, os.Stdout)
+}
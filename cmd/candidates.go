@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/ai"
+)
+
+// notesSection is the text under one ## heading of release notes; the
+// summary before the first heading has an empty heading
+type notesSection struct {
+	heading string
+	text    string
+}
+
+// splitNotes cuts release notes into their ## sections
+func splitNotes(notes string) []notesSection {
+	sections := []notesSection{{}}
+	for _, line := range strings.Split(strings.TrimSpace(notes), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			sections = append(sections, notesSection{heading: strings.TrimSpace(line)})
+		}
+		cur := &sections[len(sections)-1]
+		cur.text += line + "\n"
+	}
+	for i := range sections {
+		sections[i].text = strings.TrimSpace(sections[i].text)
+	}
+	return sections
+}
+
+// releaseCandidates generates terse and detailed release notes and lets
+// the user pick, section by section, which to keep
+func releaseCandidates(client *ai.GeminiClient, commits []string, currentTag, newTag string) (string, error) {
+	var candidates [2]string
+	previous, snippets := previousNotes(currentTag), releaseSnippets(commits)
+	for i, detail := range []string{ai.DetailTerse, ai.DetailDetailed} {
+		notes, err := client.GenerateReleaseNotes(commits, currentTag, newTag, ai.ReleaseOptions{Audience: relAudience, Detail: detail, PreviousNotes: previous, Snippets: snippets})
+		if err != nil {
+			return "", fmt.Errorf("%s candidate: %w", detail, err)
+		}
+		candidates[i] = notes
+	}
+	return mergeCandidates(splitNotes(candidates[0]), splitNotes(candidates[1])), nil
+}
+
+// mergeCandidates asks, for each section the candidates write differently,
+// which version to keep
+func mergeCandidates(terse, detailed []notesSection) string {
+	find := func(sections []notesSection, heading string) string {
+		for _, s := range sections {
+			if strings.EqualFold(s.heading, heading) {
+				return s.text
+			}
+		}
+		return ""
+	}
+
+	// Sections in the order of the detailed candidate, then those only the
+	// terse one has
+	headings := []string{}
+	for _, s := range detailed {
+		headings = append(headings, s.heading)
+	}
+	for _, s := range terse {
+		if find(detailed, s.heading) == "" && s.text != "" {
+			headings = append(headings, s.heading)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var kept []string
+	for _, heading := range headings {
+		a, b := find(terse, heading), find(detailed, heading)
+		if a == b {
+			if a != "" {
+				kept = append(kept, a)
+			}
+			continue
+		}
+		fmt.Println()
+		ui.Info("📑 %s", ifEmpty(strings.TrimPrefix(heading, "## "), "Summary"))
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Println("[1] terse:")
+		fmt.Println(ifEmpty(a, "(no such section)"))
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Println("[2] detailed:")
+		fmt.Println(ifEmpty(b, "(no such section)"))
+		fmt.Println(strings.Repeat("─", 60))
+		for {
+			fmt.Print("⚡ Keep [1/2/b(oth)/s(kip)] (default 2): ")
+			input, _ := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(input)) {
+			case "1":
+				kept = append(kept, a)
+			case "", "2":
+				kept = append(kept, b)
+			case "b", "both":
+				if a == "" || b == "" {
+					kept = append(kept, a+b)
+				} else {
+					kept = append(kept, a+"\n"+strings.TrimSpace(strings.TrimPrefix(b, heading)))
+				}
+			case "s", "skip":
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	var notes []string
+	for _, text := range kept {
+		if text != "" {
+			notes = append(notes, text)
+		}
+	}
+	return strings.Join(notes, "\n\n")
+}
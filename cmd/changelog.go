@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/changelog"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	chlAll     bool
+	chlOutput  string
+	chlNoCache bool
+	chlDryRun  bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate CHANGELOG.md from tag history with AI",
+	Long: `Generate CHANGELOG.md from tag history with AI.
+
+Each tag gets its own section generated from the commits between it and
+the previous tag. Without --all, the section of the latest tag is added on
+top of the existing file (replacing an older one for the same tag); --all
+writes the whole file again from every section. Generated sections are
+cached in .git/commitai/, so an interrupted run can simply be re-run and
+resumes where it stopped.`,
+	RunE: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().BoolVar(&chlAll, "all", false, "Generate sections for every tag in history and rewrite the whole file")
+	changelogCmd.Flags().StringVarP(&chlOutput, "output", "o", "CHANGELOG.md", "Output file")
+	changelogCmd.Flags().BoolVar(&chlNoCache, "no-cache", false, "Ignore cached sections and regenerate them")
+	changelogCmd.Flags().BoolVarP(&chlDryRun, "dry-run", "d", false, "Print the changelog instead of writing it")
+	changelogCmd.Flags().StringVar(&flagTagPattern, "tag-pattern", "", "Only tags matching this glob get a section, e.g. \"app-v*\" (default from tag_pattern)")
+	changelogCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := ensureProvider(cfg); err != nil {
+		return err
+	}
+
+	tags, err := git.TagsMatching(tagPattern(cfg))
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		ui.Warn("No tags found. Create a release first with 'commitai release'.")
+		return nil
+	}
+
+	gitDir, err := git.Dir()
+	if err != nil {
+		return err
+	}
+	cache, err := changelog.LoadCache(gitDir)
+	if err != nil {
+		return err
+	}
+
+	start := len(tags) - 1
+	if chlAll {
+		start = 0
+	}
+
+	client := newClient(cfg)
+	ui.Info("📚 Building changelog for %d tag(s)...", len(tags)-start)
+
+	for i := start; i < len(tags); i++ {
+		tag := tags[i]
+		prev := ""
+		if i > 0 {
+			prev = tags[i-1]
+		}
+
+		commits, err := git.CommitsBetween(prev, tag)
+		if err != nil {
+			return err
+		}
+		key := changelog.Key(prev, tag, cfg.Model, commits)
+
+		if !chlNoCache {
+			if _, ok := cache.Lookup(tag, key); ok {
+				fmt.Printf("  %s %s (cached)\n", color.CyanString("↺"), tag)
+				continue
+			}
+		}
+
+		if len(commits) == 0 {
+			fmt.Printf("  %s %s (no commits)\n", color.YellowString("∅"), tag)
+			continue
+		}
+
+		notes, err := client.GenerateChangelogSection(commits, prev, tag)
+		if err != nil {
+			return fmt.Errorf("failed to generate section for %s: %w\nRe-run the command to resume from this tag", tag, err)
+		}
+		date, _ := git.TagDate(tag)
+
+		if err := cache.Put(changelog.Section{Tag: tag, Date: date, Key: key, Notes: notes}); err != nil {
+			return fmt.Errorf("failed to save changelog cache: %w", err)
+		}
+		ui.Success("  ✅ %s (%d commit(s))", tag, len(commits))
+	}
+
+	// With --all the file is rebuilt from every section we have, in tag
+	// order; otherwise the latest section goes on top of what it has
+	var content, done string
+	if chlAll {
+		var sections []changelog.Section
+		for _, tag := range tags {
+			if s, ok := cache.Sections[tag]; ok {
+				sections = append(sections, s)
+			}
+		}
+		content = changelog.Render(sections)
+		done = fmt.Sprintf("Changelog with %d section(s) written to %s", len(sections), chlOutput)
+	} else {
+		latest := tags[len(tags)-1]
+		s, ok := cache.Sections[latest]
+		if !ok {
+			ui.Warn("Nothing to add to %s: %s has no section.", chlOutput, latest)
+			return nil
+		}
+		existing, err := os.ReadFile(chlOutput)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		content = changelog.Add(string(existing), s)
+		done = fmt.Sprintf("Section for %s added to %s", latest, chlOutput)
+	}
+
+	if chlDryRun {
+		fmt.Println()
+		fmt.Println(strings.Repeat("─", 60))
+		ui.Document(content)
+		fmt.Println(strings.Repeat("─", 60))
+		ui.Warn("\n🔍 Dry run — %s was not written.", chlOutput)
+		return nil
+	}
+
+	if err := os.WriteFile(chlOutput, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", chlOutput, err)
+	}
+	ui.Success("\n📄 %s", done)
+	return nil
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+// checkpointPrefix marks WIP commits that 'commitai tidy' consolidates
+const checkpointPrefix = "checkpoint: "
+
+// emptyTree is git's well-known empty tree, the "parent" of a root commit
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+var (
+	chkStaged bool
+	tidyDepth int
+	tidyDry   bool
+	tidyYes   bool
+)
+
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint [note]",
+	Short: "Quickly commit everything as a timestamped WIP checkpoint (no AI call)",
+	Long: `Quickly commit everything as a timestamped WIP checkpoint, without any AI call.
+
+Checkpoints are meant to be consolidated later with 'commitai tidy'.`,
+	RunE: runCheckpoint,
+}
+
+var tidyCmd = &cobra.Command{
+	Use:   "tidy",
+	Short: "Squash runs of checkpoint commits into commits with proper messages",
+	Long: `Squash runs of checkpoint commits into commits with proper messages.
+
+Every run of consecutive checkpoints on the current branch becomes one commit
+whose message is generated from the combined diff. Other commits are kept as
+they are. Commits already pushed to the upstream branch are never rewritten.
+The working tree is not touched; the previous branch tip is saved in
+refs/commitai/backup/<timestamp> (see commitai restore-backup).`,
+	RunE: runTidy,
+}
+
+func init() {
+	checkpointCmd.Flags().BoolVar(&chkStaged, "staged", false, "Only commit what is already staged")
+	checkpointCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on a protected branch")
+
+	tidyCmd.Flags().IntVar(&tidyDepth, "depth", 200, "How many commits back to look for checkpoints")
+	tidyCmd.Flags().BoolVarP(&tidyDry, "dry-run", "d", false, "Show the plan without rewriting history")
+	tidyCmd.Flags().BoolVarP(&tidyYes, "yes", "y", false, "Skip confirmation prompt")
+	tidyCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runCheckpoint(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := checkProtectedBranch(cfg, "commit"); err != nil {
+		return err
+	}
+	if chkStaged {
+		if err := checkBannedStaged(cfg); err != nil {
+			return err
+		}
+	} else {
+		// Checked before staging, so a banned file isn't left staged
+		if err := checkBannedWorkingTree(cfg); err != nil {
+			return err
+		}
+		if _, err := git.Run("add", "-A"); err != nil {
+			return err
+		}
+	}
+	if _, err := git.Run("diff", "--cached", "--quiet"); err == nil {
+		ui.Warn("Nothing to checkpoint.")
+		return nil
+	}
+
+	msg := checkpointPrefix + time.Now().Format("2006-01-02 15:04:05")
+	if note := strings.TrimSpace(strings.Join(args, " ")); note != "" {
+		msg += " — " + note
+	}
+	if err := git.Commit(msg, commitOptions()); err != nil {
+		return err
+	}
+	ui.Success("📍 %s", msg)
+	return nil
+}
+
+// tidyGroup is a commit to keep or a run of checkpoints to squash
+type tidyGroup struct {
+	commits []git.CommitInfo // Oldest first
+	squash  bool
+	message string // New message for squashed runs
+}
+
+func (g tidyGroup) last() git.CommitInfo { return g.commits[len(g.commits)-1] }
+
+func runTidy(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	branch, _ := git.CurrentBranch()
+	if branch == "" || branch == "HEAD" {
+		return fmt.Errorf("tidy needs a checked-out branch (HEAD is detached)")
+	}
+
+	history, err := git.FirstParentHistory(tidyDepth)
+	if err != nil {
+		return err
+	}
+	groups, base := planTidy(history, git.UpstreamBase())
+	squashes := 0
+	for _, g := range groups {
+		if g.squash {
+			squashes++
+		}
+	}
+	if squashes == 0 {
+		ui.Warn("No unpushed checkpoint commits to tidy.")
+		return nil
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+	recentCommits := recentCommits(cfg, nil)
+
+	ui.Info("✨ Generating messages for %d checkpoint run(s) with %s...", squashes, client.ProviderName())
+	violating := 0
+	for i := range groups {
+		g := &groups[i]
+		if !g.squash {
+			continue
+		}
+		from := emptyTree
+		if parents := g.commits[0].Parents; len(parents) > 0 {
+			from = parents[0]
+		}
+		changes, err := git.ChangesBetween(from, g.last().Hash)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			continue // Checkpoints that cancel out are dropped
+		}
+		messages, err := client.GenerateCommitMessages(changes, false, recentCommits)
+		if err != nil {
+			return fmt.Errorf("AI generation failed: %w", err)
+		}
+		g.message = messages["__all__"]
+	}
+
+	fmt.Println()
+	ui.Success("🧹 Tidy plan for %s:", branch)
+	for _, g := range groups {
+		switch {
+		case !g.squash:
+			fmt.Printf("\n  %s keep   %s %s\n", color.HiBlackString("•"), shortSHA(g.last().Hash), g.last().Subject)
+		case g.message == "":
+			fmt.Printf("\n  %s drop   %d checkpoint(s) with no net change\n", color.YellowString("✖"), len(g.commits))
+		default:
+			fmt.Printf("\n  %s squash %d checkpoint(s) into:\n", color.GreenString("✚"), len(g.commits))
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Println(g.message)
+			fmt.Println(strings.Repeat("─", 60))
+			if violations := checkMessage(cfg, g.message); len(violations) > 0 {
+				reportViolations(violations)
+				violating++
+			}
+		}
+	}
+
+	if tidyDry {
+		ui.Warn("\n🔍 Dry run — history was not rewritten.")
+		return nil
+	}
+	if violating > 0 {
+		return fmt.Errorf("%d commit message(s) violate policy", violating)
+	}
+	if !tidyYes {
+		fmt.Print(i18n.T("\n⚡ Rewrite history as shown? [y/N]: "))
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if !i18n.Yes(input) {
+			ui.Warn("Tidy cancelled.")
+			return nil
+		}
+	}
+
+	oldHead := history[0].Hash
+	parent := base
+	for _, g := range groups {
+		var err error
+		switch {
+		case !g.squash:
+			c := g.last()
+			parent, err = git.CommitTree(c.Hash, parent, fullMessage(c), c.Hash)
+		case g.message != "":
+			msg := g.message
+			if cfg.ProvenanceTrailers && !cfg.PrivacyMode {
+				msg = message.Provenance(msg, Version, cfg.Model, false)
+			}
+			parent, err = git.CommitTree(g.last().Hash, parent, msg, "")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := saveBackup("refs/heads/"+branch, "tidy"); err != nil {
+		return err
+	}
+	if err := git.UpdateRef("refs/heads/"+branch, parent, oldHead, "commitai tidy"); err != nil {
+		return err
+	}
+	ui.Success("\n✅ Tidied %d checkpoint run(s).", squashes)
+	printRecovery()
+	return nil
+}
+
+// planTidy groups the unpushed first-parent history into commits to keep and
+// checkpoint runs to squash, oldest first. History starts at the parent of
+// the oldest checkpoint; base is that parent ("" for a root commit).
+func planTidy(history []git.CommitInfo, upstreamBase string) ([]tidyGroup, string) {
+	// Only unpushed, linear history can be rewritten
+	var window []git.CommitInfo
+	for _, c := range history {
+		if c.Hash == upstreamBase || len(c.Parents) > 1 {
+			break
+		}
+		window = append(window, c)
+	}
+
+	oldest := -1
+	for i, c := range window {
+		if strings.HasPrefix(c.Subject, checkpointPrefix) {
+			oldest = i
+		}
+	}
+	if oldest < 0 {
+		return nil, ""
+	}
+
+	base := ""
+	if parents := window[oldest].Parents; len(parents) > 0 {
+		base = parents[0]
+	}
+
+	var groups []tidyGroup
+	for i := oldest; i >= 0; i-- {
+		c := window[i]
+		isCheckpoint := strings.HasPrefix(c.Subject, checkpointPrefix)
+		if n := len(groups); isCheckpoint && n > 0 && groups[n-1].squash {
+			groups[n-1].commits = append(groups[n-1].commits, c)
+			continue
+		}
+		groups = append(groups, tidyGroup{commits: []git.CommitInfo{c}, squash: isCheckpoint})
+	}
+	return groups, base
+}
+
+func fullMessage(c git.CommitInfo) string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+func shortSHA(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/quality"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	ciRange     string
+	ciThreshold int
+	ciJSON      bool
+	ciSARIF     string
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Checks for CI pipelines",
+}
+
+var ciLintCommitsCmd = &cobra.Command{
+	Use:   "lint-commits",
+	Short: "Score the commit messages of a range and fail below a threshold",
+	Long: `Score the commit messages of a range and fail below a threshold.
+
+Each commit gets a 0-100 score from three checks against its own diff:
+format (the commit convention and policy, 40%), specificity (the message
+says what changed and names something the diff touches, 35%) and length
+(the subject width, 25%). The command exits non-zero when a commit scores
+below the threshold: --threshold, lint_threshold in the config, or 60.
+Merge commits are skipped. No API call.
+
+--sarif writes the issues as SARIF for code scanning annotations, e.g. with
+github/codeql-action/upload-sarif.`,
+	RunE:         runCILintCommits,
+	SilenceUsage: true,
+}
+
+func init() {
+	ciLintCommitsCmd.Flags().StringVar(&ciRange, "range", "", "Commits to score, e.g. origin/main..HEAD (default: since the default branch)")
+	ciLintCommitsCmd.Flags().IntVar(&ciThreshold, "threshold", 0, "Fail commits scoring below this (0-100; default: lint_threshold, or 60)")
+	ciLintCommitsCmd.Flags().BoolVar(&ciJSON, "json", false, "Print the scores as JSON")
+	ciLintCommitsCmd.Flags().StringVar(&ciSARIF, "sarif", "", "Write the issues as SARIF 2.1.0 to this file (- for stdout)")
+
+	ciCmd.AddCommand(ciLintCommitsCmd)
+}
+
+// lintReport is the --json output
+type lintReport struct {
+	Range     string           `json:"range"`
+	Threshold int              `json:"threshold"`
+	Passed    int              `json:"passed"`
+	Failed    int              `json:"failed"`
+	Commits   []quality.Commit `json:"commits"`
+}
+
+// lintThreshold is the configured score commits must reach
+func lintThreshold(cfg *config.Config) int {
+	if cfg.LintThreshold > 0 {
+		return cfg.LintThreshold
+	}
+	return quality.DefaultThreshold
+}
+
+func runCILintCommits(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if ciJSON && ciSARIF == "-" {
+		return fmt.Errorf("--json and --sarif - both print to stdout; write the SARIF to a file")
+	}
+	if ciJSON || ciSARIF == "-" {
+		ui, _ = render.New(uiFormat, os.Stderr) // Warnings go to stderr, stdout is left for the report
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := applyPolicy(cfg); err != nil {
+		return err
+	}
+	applyConventions(cfg)
+	threshold := lintThreshold(cfg)
+	if cmd.Flags().Changed("threshold") {
+		if ciThreshold < 0 || ciThreshold > 100 {
+			return fmt.Errorf("invalid --threshold %d (expected 0-100)", ciThreshold)
+		}
+		threshold = ciThreshold
+	}
+
+	from, to, rng, err := lintRange()
+	if err != nil {
+		return err
+	}
+	if git.IsShallow() && (from == "" || !git.IsAncestor(from, to)) {
+		ui.Warn("⚠️  Shallow clone: commits of %s beyond the fetched history aren't scored (fetch-depth: 0 in GitHub Actions fetches them)", rng)
+	}
+	commits, err := git.RangeCommits(from, to)
+	if err != nil {
+		return err
+	}
+
+	report := lintReport{Range: rng, Threshold: threshold, Commits: []quality.Commit{}}
+	conventional := cfg.CommitStyle == "conventional"
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			continue
+		}
+		parent := emptyTree
+		if len(c.Parents) > 0 {
+			parent = c.Parents[0]
+		}
+		changes, err := git.ChangesBetween(parent, c.Hash)
+		if err != nil {
+			return err
+		}
+		scored := quality.Commit{
+			Hash:    c.Hash,
+			Subject: c.Subject,
+			Rating:  quality.Rate(fullMessage(c), changes, cfg.Policy, conventional, conventionalTypes),
+		}
+		for _, ch := range changes {
+			scored.Files = append(scored.Files, ch.Path)
+		}
+		scored.Passed = scored.Score >= threshold
+		if scored.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Commits = append(report.Commits, scored)
+	}
+
+	if ciSARIF != "" {
+		if err := writeSARIF(ciSARIF, report.Commits); err != nil {
+			return err
+		}
+	}
+	switch {
+	case ciJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false) // Issues quote "<type>(<scope>)"
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	case ciSARIF != "-":
+		printLintReport(report)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d commit(s) scored below %d", report.Failed, len(report.Commits), threshold)
+	}
+	return nil
+}
+
+// lintRange returns the ends of --range, the default branch's merge base
+// to HEAD without one, and the range as shown in the report
+func lintRange() (from, to, rng string, err error) {
+	if ciRange == "" {
+		base := git.DefaultBranch()
+		if base == "" {
+			return "", "", "", fmt.Errorf("can't tell which branch this one started from; use --range, e.g. origin/main..HEAD")
+		}
+		if from, err = git.MergeBase(base, "HEAD"); err != nil {
+			return "", "", "", err
+		}
+		return from, "HEAD", base + "..HEAD", nil
+	}
+
+	from, to, ok := strings.Cut(ciRange, "..")
+	if ok && strings.HasPrefix(to, ".") {
+		return "", "", "", fmt.Errorf("invalid --range %q (expected <base>..<head>)", ciRange)
+	}
+	if !ok {
+		from, to = ciRange, ""
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	if from != "" {
+		if from, err = git.ResolveCommit(from); err != nil {
+			return "", "", "", err
+		}
+	}
+	if to, err = git.ResolveCommit(to); err != nil {
+		return "", "", "", err
+	}
+	return from, to, ciRange, nil
+}
+
+func writeSARIF(path string, commits []quality.Commit) error {
+	data, err := json.MarshalIndent(quality.SARIF(commits, Version, "https://github.com/kaiqui/commitai"), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	ui.Muted("📄 SARIF written to %s", path)
+	return nil
+}
+
+func printLintReport(r lintReport) {
+	fmt.Println()
+	if len(r.Commits) == 0 {
+		ui.Warn("🧪 No commits to score in %s", r.Range)
+		return
+	}
+	ui.Info("🧪 Commit message scores in %s (threshold %d):", r.Range, r.Threshold)
+	fmt.Println()
+	for _, c := range r.Commits {
+		mark, score := "✅", color.GreenString("%3d", c.Score)
+		if !c.Passed {
+			mark, score = "❌", color.RedString("%3d", c.Score)
+		}
+		fmt.Printf("  %s %s %s  %s\n", mark, shortSHA(c.Hash), score, c.Subject)
+		for _, check := range c.Checks {
+			for _, issue := range check.Issues {
+				fmt.Printf("             %s\n", color.YellowString("%s: %s", check.Name, issue))
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Printf("  Passed: %d of %d\n", r.Passed, len(r.Commits))
+	fmt.Println()
+}
@@ -1,104 +1,1126 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/announce"
+	"github.com/kaiqui/commitai/internal/budget"
 	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/hooks"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/render"
 )
 
 var (
-	cfgAPIKey   string
-	cfgLanguage string
-	cfgStyle    string
-	cfgModel    string
-	cfgShow     bool
+	cfgAPIKey     string
+	cfgAddKey     string
+	cfgRemoveKey  string
+	cfgRotation   string
+	cfgGateway    string
+	cfgHeader     string
+	cfgLanguage   string
+	cfgStyle      string
+	cfgModel      string
+	cfgProvider   string
+	cfgDictation  string
+	cfgBackend    string
+	cfgMaxCost    float64
+	cfgDailyCalls int
+	cfgDailyToks  int
+	cfgNotify     int
+	cfgCompress   int
+	cfgLintMin    int
+	cfgRecent     int
+	cfgRecentAge  string
+	cfgRecentMine string
+	cfgRecentPath string
+	cfgRecentSim  string
+	cfgFileHist   int
+	cfgCloseKw    string
+	cfgPolicyURL  string
+	cfgPolicyKey  string
+	cfgWebhook    string
+	cfgHook       string
+	cfgTypeEmoji  string
+	cfgEmojiPos   string
+	cfgConsent    string
+	cfgForge      string
+	cfgForgeAPI   string
+	cfgForgeToken string
+	cfgForgeCA    string
+	cfgForgeTLS   string
+	cfgJiraURL    string
+	cfgTagPattern string
+	cfgEditNotes  string
+	cfgRelStats   string
+	cfgEmailFrom  string
+	cfgEmailTo    []string
+	cfgProvenance string
+	cfgPrivacy    string
+	cfgBranchCtx  string
+	cfgTemplate   string
+	cfgCommitlint string
+	cfgImpact     string
+	cfgCritique   string
+	cfgDetermin   string
+	cfgSeed       int
+	cfgFormat     string
+	cfgSquash     string
+	cfgAnonymize  string
+	cfgAnonPaths  string
+	cfgLocalOnly  string
+	cfgProtect    []string
+	cfgProtectMd  string
+	cfgBanned     []string
+	cfgShow       bool
+	cfgSkipCheck  bool
 )
 
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure commitai settings",
-	Long: `Configure commitai settings.
-
-Examples:
-  commitai config --key YOUR_GEMINI_API_KEY
-  commitai config --lang pt-br
-  commitai config --style conventional
-  commitai config --model gemini-2.5-flash
-  commitai config --show`,
-	RunE: runConfig,
+	Long:  `Configure commitai settings.`,
+	RunE:  runConfig,
 }
 
 func init() {
 	configCmd.Flags().StringVar(&cfgAPIKey, "key", "", "Gemini API key")
+	configCmd.Flags().StringVar(&cfgAddKey, "add-key", "", "Add another Gemini API key used when one runs out of quota")
+	configCmd.Flags().StringVar(&cfgRemoveKey, "remove-key", "", "Remove an additional key (the full key or its last 4 characters)")
+	configCmd.Flags().StringVar(&cfgRotation, "key-rotation", "", "How to use several keys: failover (default) or round-robin")
 	configCmd.Flags().StringVar(&cfgLanguage, "lang", "", "Language (en, pt-br, es, fr, ...)")
-	configCmd.Flags().StringVar(&cfgStyle, "style", "", "Commit style (conventional, simple)")
+	configCmd.Flags().StringVar(&cfgStyle, "style", "", "Commit style (conventional, simple, auto)")
 	configCmd.Flags().StringVar(&cfgModel, "model", "", "Gemini model (gemini-2.5-flash, gemini-1.5-pro, ...)")
+	configCmd.Flags().StringVar(&cfgGateway, "gateway-url", "", "Send Gemini requests through this gateway base URL (\"off\" for the public API)")
+	configCmd.Flags().StringVar(&cfgHeader, "header", "", "Extra HTTP header for Gemini requests as Name=value, ${VAR} expanded at request time (empty value removes it)")
+	configCmd.Flags().StringVar(&cfgBackend, "provider", "", "Message provider: gemini, or offline for rule-based messages without AI")
+	configCmd.Flags().StringVar(&cfgDictation, "dictation-command", "", "Speech-to-text command for say --listen that prints what it heard (\"off\" to record and have the provider transcribe)")
+	configCmd.Flags().StringVar(&cfgProvider, "provider-command", "", "Command (or .wasm module) that reads the prompt on stdin and prints the response (\"off\" to use Gemini)")
+	configCmd.Flags().Float64Var(&cfgMaxCost, "max-cost", 0, "Abort AI calls estimated to cost more than this in USD (0 to disable)")
+	configCmd.Flags().IntVar(&cfgDailyCalls, "daily-calls", 0, "AI calls allowed per repository per day before falling back to the offline generator (0 to disable)")
+	configCmd.Flags().IntVar(&cfgDailyToks, "daily-tokens", 0, "AI tokens allowed per repository per day before falling back to the offline generator (0 to disable)")
+	configCmd.Flags().IntVar(&cfgRecent, "recent-commits", 0, "Recent commits sent as style context (0 disables, default 5)")
+	configCmd.Flags().StringVar(&cfgRecentAge, "recent-since", "", "Only use recent commits since this date, e.g. \"2 weeks ago\" (off to remove)")
+	configCmd.Flags().StringVar(&cfgRecentMine, "recent-same-author", "", "Only use your own recent commits as context (on, off)")
+	configCmd.Flags().StringVar(&cfgRecentPath, "recent-same-paths", "", "Only use recent commits touching the staged files as context (on, off)")
+	configCmd.Flags().StringVar(&cfgRecentSim, "recent-relevant", "", "Use the past commits most similar to the staged diff as context instead of the latest (on, off)")
+	configCmd.Flags().IntVar(&cfgFileHist, "file-history", 0, "Commit subjects sent per modified file for consistent scopes (0 disables)")
+	configCmd.Flags().IntVar(&cfgCompress, "compress", 0, "Prompt compression level: 0 off, 1 trim context, 2 also collapse repeated hunks and moved code, 3 no context")
+	configCmd.Flags().IntVar(&cfgLintMin, "lint-threshold", 0, "Quality score (0-100) below which ci lint-commits fails a commit (0 for the default of 60)")
+	configCmd.Flags().IntVar(&cfgNotify, "notify-after", 0, "Desktop notification when generation or batch takes at least this many seconds (0 to disable)")
+	configCmd.Flags().StringVar(&cfgCloseKw, "closing-keyword", "", "Keyword added before issues found in the branch name, e.g. Closes (\"off\" to disable)")
+	configCmd.Flags().StringVar(&cfgPolicyURL, "policy-url", "", "Organization policy bundle location (https URL, git+<repo>#<path>, file; \"off\" to disable)")
+	configCmd.Flags().StringVar(&cfgPolicyKey, "policy-public-key", "", "Base64 ed25519 public key policy bundles must be signed with")
+	configCmd.Flags().StringVar(&cfgWebhook, "webhook", "", "Release announcement webhook as platform=url (slack, discord, teams)")
+	configCmd.Flags().StringVar(&cfgHook, "hook", "", "Release hook as name=command (pre_release, post_tag, post_push)")
+	configCmd.Flags().StringVar(&cfgForge, "forge", "", "Forge type of a remote host as host=type (github, gitlab, bitbucket, bitbucket-server, gitea, forgejo, azure; empty type removes the host's settings)")
+	configCmd.Flags().StringVar(&cfgForgeAPI, "forge-api-url", "", "API base URL of a forge as host=url, e.g. ghe.example.com=https://ghe.example.com/api/v3")
+	configCmd.Flags().StringVar(&cfgForgeToken, "forge-token-env", "", "Env var holding the token of a forge as host=VAR")
+	configCmd.Flags().StringVar(&cfgForgeCA, "forge-ca-file", "", "PEM CA bundle trusted for a forge as host=path")
+	configCmd.Flags().StringVar(&cfgForgeTLS, "forge-insecure", "", "Skip TLS certificate verification for a forge as host=on|off")
+	configCmd.Flags().StringVar(&cfgJiraURL, "jira-url", "", "Jira site commented on with --comment-issues, e.g. https://example.atlassian.net (\"off\" to remove)")
+	configCmd.Flags().StringVar(&cfgTagPattern, "tag-pattern", "", "Glob release tags match, e.g. \"app-v*\", for repositories with other tags (\"off\" for every tag)")
+	configCmd.Flags().StringVar(&cfgEditNotes, "edit-release-notes", "", "Open release notes in $EDITOR before tagging (on, off)")
+	configCmd.Flags().StringVar(&cfgRelStats, "release-stats", "", "Append commit, file, line and contributor counts to release notes (on, off)")
+	configCmd.Flags().StringVar(&cfgEmailFrom, "email-from", "", "Sender address for release emails")
+	configCmd.Flags().StringSliceVar(&cfgEmailTo, "email-to", nil, "Recipients for release emails (comma-separated)")
+	configCmd.Flags().StringVar(&cfgProvenance, "provenance", "", "Add AI-Generated-By/AI-Edited trailers to commits (on, off)")
+	configCmd.Flags().StringVar(&cfgBranchCtx, "branch-context", "", "Describe the current branch and its intent in prompts (on, off)")
+	configCmd.Flags().StringVar(&cfgDetermin, "deterministic-mode", "", "Generate at temperature 0 with a fixed seed on every AI call (on, off)")
+	configCmd.Flags().IntVar(&cfgSeed, "default-seed", 0, "Seed of deterministic mode")
+	configCmd.Flags().StringVar(&cfgTypeEmoji, "type-emoji", "", "Emoji put on generated subjects of a Conventional Commits type as type=emoji, e.g. feat=✨ (empty emoji removes it)")
+	configCmd.Flags().StringVar(&cfgEmojiPos, "type-emoji-position", "", "Where the type emoji goes: start (✨ feat: x) or description (feat: ✨ x)")
+	configCmd.Flags().StringVar(&cfgImpact, "impact-line", "", "End generated messages and PR descriptions with an impact and risk line (on, off)")
+	configCmd.Flags().StringVar(&cfgCritique, "confidence-critique", "", "Rate messages with a second AI call when the provider gives no token probabilities (on, off)")
+	configCmd.Flags().StringVar(&cfgTemplate, "commit-template", "", "Fill in the repository's git commit.template in generated messages (on, off)")
+	configCmd.Flags().StringVar(&cfgCommitlint, "commitlint", "", "Check generated messages with the project's commitlint before committing (on, off)")
+	configCmd.Flags().StringVar(&cfgFormat, "default-format", "", "Default output format: rich, plain or markdown")
+	configCmd.Flags().StringVar(&cfgSquash, "squash-merge", "", "Keep the squash commit draft (commitai squash) updated after every commit (on, off)")
+	configCmd.Flags().StringVar(&cfgAnonymize, "anonymize", "", "Replace string literals, emails, hostnames and URLs in diffs with placeholders before sending (on, off)")
+	configCmd.Flags().StringVar(&cfgAnonPaths, "anonymize-paths", "", "Hide file paths from the AI provider (generalize, hash, off)")
+	configCmd.Flags().StringVar(&cfgLocalOnly, "local-only-mode", "", "Refuse providers and calls that leave this machine (on, off)")
+	configCmd.Flags().StringVar(&cfgConsent, "data-consent", "", "Ask once per repository before sending its data to the provider (ask), or don't (granted)")
+	configCmd.Flags().StringVar(&cfgPrivacy, "privacy", "", "Strict privacy mode: no AI metadata, no stray files, no extra network calls (on, off)")
+	configCmd.Flags().StringSliceVar(&cfgProtect, "protect-branches", nil, "Branch patterns where commitai won't commit or tag without --force, e.g. main,release/* (empty to disable)")
+	configCmd.Flags().StringSliceVar(&cfgBanned, "banned-paths", nil, "Path patterns commitai refuses to commit, e.g. *.pem,.env,secrets/ (empty to disable)")
+	configCmd.Flags().StringVar(&cfgProtectMd, "protected-mode", "", "What to do on a protected branch: refuse (default) or warn")
 	configCmd.Flags().BoolVar(&cfgShow, "show", false, "Show current configuration")
+	configCmd.Flags().BoolVar(&cfgSkipCheck, "skip-check", false, "Save --key without checking it against the Gemini API")
+
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the config file",
+	Long: `Print the JSON Schema of the config file (~/.config/commitai/config.json
+and the repository's .commitai.json) for editors to complete and validate it.
+
+Unknown keys are reported by the schema, so a misspelled setting shows up in
+the editor instead of being silently ignored. Point a "$schema" key in the
+file at the saved schema, or map it in VS Code's "json.schemas" setting.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := json.MarshalIndent(config.Schema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	},
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	// No settings flags given: just show the current configuration
+	if cfgShow || cmd.Flags().NFlag() == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		printConfig(cfg)
+		return nil
+	}
+
+	// Edit the user config only, so repo overrides don't leak into it
+	cfg, err := config.LoadUser()
 	if err != nil {
 		cfg = config.DefaultConfig()
 	}
 
-	if cfgShow || (!cmd.Flags().Changed("key") && !cmd.Flags().Changed("lang") &&
-		!cmd.Flags().Changed("style") && !cmd.Flags().Changed("model")) {
-		printConfig(cfg)
-		return nil
+	// Gateway settings first, so a new key is checked through the gateway
+	if cfgGateway != "" {
+		if strings.EqualFold(cfgGateway, "off") {
+			cfg.GatewayURL = ""
+			ui.Success("✅ Using the public Gemini API")
+		} else {
+			if u, err := url.Parse(cfgGateway); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid --gateway-url %q (expected an http(s) URL)", cfgGateway)
+			}
+			cfg.GatewayURL = cfgGateway
+			ui.Success("✅ Gateway set to: %s", cfgGateway)
+		}
+	}
+	if cfgHeader != "" {
+		name, value, ok := strings.Cut(cfgHeader, "=")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			return fmt.Errorf("invalid --header %q (expected Name=value)", cfgHeader)
+		}
+		if cfg.Headers == nil {
+			cfg.Headers = make(map[string]string)
+		}
+		if value == "" {
+			delete(cfg.Headers, name)
+			ui.Success("✅ Header %s removed", name)
+		} else {
+			cfg.Headers[name] = value
+			ui.Success("✅ Header %s saved", name)
+		}
 	}
-
 	if cfgAPIKey != "" {
+		if !cfgSkipCheck {
+			model := cfg.Model
+			if cfgModel != "" {
+				model = cfgModel
+			}
+			if err := checkKey(cfg, cfgAPIKey, model); err != nil {
+				return err
+			}
+		}
 		cfg.GeminiAPIKey = cfgAPIKey
-		color.Green("✅ API key saved")
+		ui.Success("✅ API key saved")
+	}
+	if cfgAddKey != "" {
+		if containsString(cfg.APIKeys(), cfgAddKey) {
+			return fmt.Errorf("that key is already configured")
+		}
+		if !cfgSkipCheck {
+			if err := checkKey(cfg, cfgAddKey, cfg.Model); err != nil {
+				return err
+			}
+		}
+		cfg.GeminiAPIKeys = append(cfg.GeminiAPIKeys, cfgAddKey)
+		ui.Success("✅ Additional API key added (%d keys in total)", len(cfg.APIKeys()))
+	}
+	if cfgRemoveKey != "" {
+		var kept []string
+		for _, k := range cfg.GeminiAPIKeys {
+			if k != cfgRemoveKey && !(len(cfgRemoveKey) == 4 && strings.HasSuffix(k, cfgRemoveKey)) {
+				kept = append(kept, k)
+			}
+		}
+		if len(kept) == len(cfg.GeminiAPIKeys) {
+			return fmt.Errorf("no additional key matches %q", cfgRemoveKey)
+		}
+		cfg.GeminiAPIKeys = kept
+		ui.Success("✅ API key removed")
+	}
+	if cfgRotation != "" {
+		if cfgRotation != config.RotationFailover && cfgRotation != config.RotationRoundRobin {
+			return fmt.Errorf("invalid --key-rotation %q (expected failover or round-robin)", cfgRotation)
+		}
+		cfg.KeyRotation = cfgRotation
+		ui.Success("✅ Key rotation set to: %s", cfgRotation)
 	}
 	if cfgLanguage != "" {
 		cfg.Language = cfgLanguage
-		color.Green("✅ Language set to: %s", cfgLanguage)
+		ui.Success("✅ Language set to: %s", cfgLanguage)
 	}
 	if cfgStyle != "" {
 		cfg.CommitStyle = cfgStyle
-		color.Green("✅ Commit style set to: %s", cfgStyle)
+		ui.Success("✅ Commit style set to: %s", cfgStyle)
 	}
 	if cfgModel != "" {
 		cfg.Model = cfgModel
-		color.Green("✅ Model set to: %s", cfgModel)
+		ui.Success("✅ Model set to: %s", cfgModel)
+	}
+	if cfgBackend != "" {
+		switch strings.ToLower(cfgBackend) {
+		case "gemini":
+			cfg.Provider = ""
+			ui.Success("✅ Provider set to: gemini")
+		case config.ProviderOffline:
+			cfg.Provider = config.ProviderOffline
+			ui.Success("✅ Provider set to: offline (rule-based, no AI calls)")
+		default:
+			return fmt.Errorf("invalid --provider %q (expected gemini or offline)", cfgBackend)
+		}
+	}
+	if cmd.Flags().Changed("provider-command") {
+		command := strings.TrimSpace(cfgProvider)
+		switch {
+		case command == "":
+			return fmt.Errorf("--provider-command is empty (use \"off\" to go back to Gemini)")
+		case strings.EqualFold(command, "off"):
+			cfg.ProviderCommand = ""
+			ui.Success("✅ Using the Gemini API")
+		default:
+			cfg.ProviderCommand = command
+			ui.Success("✅ Provider command set to: %s", command)
+		}
+	}
+	if cfgDictation != "" {
+		if strings.EqualFold(cfgDictation, "off") {
+			cfg.DictationCommand = ""
+			ui.Success("✅ say --listen records and has the provider transcribe")
+		} else {
+			cfg.DictationCommand = cfgDictation
+			ui.Success("✅ Dictation command set to: %s", cfgDictation)
+		}
+	}
+	if cmd.Flags().Changed("max-cost") {
+		cfg.MaxCost = cfgMaxCost
+		if cfgMaxCost > 0 {
+			ui.Success("✅ Max cost per AI call set to: $%.4f", cfgMaxCost)
+		} else {
+			ui.Success("✅ Max cost limit disabled")
+		}
+	}
+	if cmd.Flags().Changed("compress") {
+		if cfgCompress < ai.CompressOff || cfgCompress > ai.CompressMax {
+			return fmt.Errorf("invalid --compress %d (expected 0-%d)", cfgCompress, ai.CompressMax)
+		}
+		cfg.Compress = cfgCompress
+		if cfgCompress > 0 {
+			ui.Success("✅ Prompt compression level set to: %d", cfgCompress)
+		} else {
+			ui.Success("✅ Prompt compression disabled")
+		}
+	}
+	if cmd.Flags().Changed("lint-threshold") {
+		if cfgLintMin < 0 || cfgLintMin > 100 {
+			return fmt.Errorf("invalid --lint-threshold %d (expected 0-100)", cfgLintMin)
+		}
+		cfg.LintThreshold = cfgLintMin
+		ui.Success("✅ Commit lint threshold set to: %d", lintThreshold(cfg))
+	}
+	if cmd.Flags().Changed("daily-calls") {
+		if cfgDailyCalls < 0 {
+			return fmt.Errorf("invalid --daily-calls %d (expected 0 or more)", cfgDailyCalls)
+		}
+		cfg.DailyCalls = cfgDailyCalls
+		if cfgDailyCalls > 0 {
+			ui.Success("✅ Daily budget set to: %d AI calls per repository", cfgDailyCalls)
+		} else {
+			ui.Success("✅ Daily call budget disabled")
+		}
+	}
+	if cmd.Flags().Changed("daily-tokens") {
+		if cfgDailyToks < 0 {
+			return fmt.Errorf("invalid --daily-tokens %d (expected 0 or more)", cfgDailyToks)
+		}
+		cfg.DailyTokens = cfgDailyToks
+		if cfgDailyToks > 0 {
+			ui.Success("✅ Daily budget set to: %d AI tokens per repository", cfgDailyToks)
+		} else {
+			ui.Success("✅ Daily token budget disabled")
+		}
+	}
+	if cmd.Flags().Changed("recent-commits") {
+		if cfgRecent < 0 {
+			return fmt.Errorf("invalid --recent-commits %d (expected 0 or more)", cfgRecent)
+		}
+		cfg.RecentCommits = cfgRecent
+		if cfgRecent > 0 {
+			ui.Success("✅ Recent commits in context: %d", cfgRecent)
+		} else {
+			ui.Success("✅ Recent commit context disabled")
+		}
+	}
+	if cfgRecentAge != "" {
+		if strings.EqualFold(cfgRecentAge, "off") {
+			cfg.RecentSince = ""
+			ui.Success("✅ Recent commit date limit removed")
+		} else {
+			cfg.RecentSince = cfgRecentAge
+			ui.Success("✅ Recent commits in context since: %s", cfgRecentAge)
+		}
+	}
+	if cfgRecentMine != "" {
+		switch strings.ToLower(cfgRecentMine) {
+		case "on", "true":
+			cfg.RecentSameAuthor = true
+		case "off", "false":
+			cfg.RecentSameAuthor = false
+		default:
+			return fmt.Errorf("invalid --recent-same-author %q (expected on or off)", cfgRecentMine)
+		}
+		ui.Success("✅ Recent commits by you only: %s", onOff(cfg.RecentSameAuthor))
+	}
+	if cfgRecentPath != "" {
+		switch strings.ToLower(cfgRecentPath) {
+		case "on", "true":
+			cfg.RecentSamePaths = true
+		case "off", "false":
+			cfg.RecentSamePaths = false
+		default:
+			return fmt.Errorf("invalid --recent-same-paths %q (expected on or off)", cfgRecentPath)
+		}
+		ui.Success("✅ Recent commits touching staged files only: %s", onOff(cfg.RecentSamePaths))
+	}
+	if cfgRecentSim != "" {
+		switch strings.ToLower(cfgRecentSim) {
+		case "on", "true":
+			cfg.RecentRelevant = true
+		case "off", "false":
+			cfg.RecentRelevant = false
+		default:
+			return fmt.Errorf("invalid --recent-relevant %q (expected on or off)", cfgRecentSim)
+		}
+		ui.Success("✅ Most relevant past commits as context: %s", onOff(cfg.RecentRelevant))
+	}
+	if cmd.Flags().Changed("file-history") {
+		if cfgFileHist < 0 {
+			return fmt.Errorf("invalid --file-history %d (expected 0 or more)", cfgFileHist)
+		}
+		cfg.FileHistory = cfgFileHist
+		if cfgFileHist > 0 {
+			ui.Success("✅ File history in context: last %d commit(s) per file", cfgFileHist)
+		} else {
+			ui.Success("✅ File history context disabled")
+		}
+	}
+	if cmd.Flags().Changed("notify-after") {
+		cfg.NotifyAfter = cfgNotify
+		if cfgNotify > 0 {
+			ui.Success("✅ Desktop notifications after %ds", cfgNotify)
+		} else {
+			ui.Success("✅ Desktop notifications disabled")
+		}
+	}
+	if cfgCloseKw != "" {
+		if strings.EqualFold(cfgCloseKw, "off") {
+			cfg.ClosingKeyword = ""
+			ui.Success("✅ Issue closing keywords disabled")
+		} else {
+			cfg.ClosingKeyword = cfgCloseKw
+			ui.Success("✅ Closing keyword set to: %s", cfgCloseKw)
+		}
+	}
+	if cfgPolicyURL != "" {
+		if strings.EqualFold(cfgPolicyURL, "off") {
+			cfg.PolicyURL = ""
+			ui.Success("✅ Organization policy disabled")
+		} else {
+			cfg.PolicyURL = cfgPolicyURL
+			ui.Success("✅ Policy bundle set to: %s", cfgPolicyURL)
+		}
+	}
+	if cfgJiraURL != "" {
+		if strings.EqualFold(cfgJiraURL, "off") {
+			cfg.JiraURL = ""
+			ui.Success("✅ Jira site removed")
+		} else {
+			if u, err := url.Parse(cfgJiraURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid --jira-url %q (expected an http(s) URL)", cfgJiraURL)
+			}
+			cfg.JiraURL = strings.TrimSuffix(cfgJiraURL, "/")
+			ui.Success("✅ Jira site set to: %s", cfg.JiraURL)
+		}
+	}
+	if cfgPolicyKey != "" {
+		cfg.PolicyPublicKey = cfgPolicyKey
+		ui.Success("✅ Policy signing key saved")
+	}
+	if cfgProvenance != "" {
+		switch strings.ToLower(cfgProvenance) {
+		case "on", "true":
+			cfg.ProvenanceTrailers = true
+		case "off", "false":
+			cfg.ProvenanceTrailers = false
+		default:
+			return fmt.Errorf("invalid --provenance %q (expected on or off)", cfgProvenance)
+		}
+		ui.Success("✅ Provenance trailers: %s", onOff(cfg.ProvenanceTrailers))
+	}
+	if cfgPrivacy != "" {
+		switch strings.ToLower(cfgPrivacy) {
+		case "on", "true":
+			cfg.PrivacyMode = true
+		case "off", "false":
+			cfg.PrivacyMode = false
+		default:
+			return fmt.Errorf("invalid --privacy %q (expected on or off)", cfgPrivacy)
+		}
+		ui.Success("✅ Privacy mode: %s", onOff(cfg.PrivacyMode))
+	}
+	if cfgBranchCtx != "" {
+		switch strings.ToLower(cfgBranchCtx) {
+		case "on", "true":
+			cfg.NoBranchContext = false
+		case "off", "false":
+			cfg.NoBranchContext = true
+		default:
+			return fmt.Errorf("invalid --branch-context %q (expected on or off)", cfgBranchCtx)
+		}
+		ui.Success("✅ Branch context: %s", onOff(!cfg.NoBranchContext))
+	}
+	if cfgCommitlint != "" {
+		switch strings.ToLower(cfgCommitlint) {
+		case "on", "true":
+			cfg.Commitlint = true
+		case "off", "false":
+			cfg.Commitlint = false
+		default:
+			return fmt.Errorf("invalid --commitlint %q (expected on or off)", cfgCommitlint)
+		}
+		ui.Success("✅ commitlint check: %s", onOff(cfg.Commitlint))
+	}
+	if cfgTemplate != "" {
+		switch strings.ToLower(cfgTemplate) {
+		case "on", "true":
+			cfg.NoCommitTemplate = false
+		case "off", "false":
+			cfg.NoCommitTemplate = true
+		default:
+			return fmt.Errorf("invalid --commit-template %q (expected on or off)", cfgTemplate)
+		}
+		ui.Success("✅ Commit template: %s", onOff(!cfg.NoCommitTemplate))
+	}
+	if cfgImpact != "" {
+		switch strings.ToLower(cfgImpact) {
+		case "on", "true":
+			cfg.ImpactLine = true
+		case "off", "false":
+			cfg.ImpactLine = false
+		default:
+			return fmt.Errorf("invalid --impact-line %q (expected on or off)", cfgImpact)
+		}
+		ui.Success("✅ Impact line: %s", onOff(cfg.ImpactLine))
+	}
+	if cfgCritique != "" {
+		switch strings.ToLower(cfgCritique) {
+		case "on", "true":
+			cfg.ConfidenceCritique = true
+		case "off", "false":
+			cfg.ConfidenceCritique = false
+		default:
+			return fmt.Errorf("invalid --confidence-critique %q (expected on or off)", cfgCritique)
+		}
+		ui.Success("✅ Confidence critique: %s", onOff(cfg.ConfidenceCritique))
+	}
+	if cfgDetermin != "" {
+		switch strings.ToLower(cfgDetermin) {
+		case "on", "true":
+			cfg.Deterministic = true
+		case "off", "false":
+			cfg.Deterministic = false
+		default:
+			return fmt.Errorf("invalid --deterministic-mode %q (expected on or off)", cfgDetermin)
+		}
+		ui.Success("✅ Deterministic mode: %s", onOff(cfg.Deterministic))
+	}
+	if cmd.Flags().Changed("default-seed") {
+		cfg.Seed = cfgSeed
+		ui.Success("✅ Deterministic mode seed set to: %d", cfgSeed)
+	}
+	if cfgLocalOnly != "" {
+		switch strings.ToLower(cfgLocalOnly) {
+		case "on", "true":
+			cfg.LocalOnly = true
+		case "off", "false":
+			cfg.LocalOnly = false
+		default:
+			return fmt.Errorf("invalid --local-only-mode %q (expected on or off)", cfgLocalOnly)
+		}
+		ui.Success("✅ Local-only mode: %s", onOff(cfg.LocalOnly))
+		if cfg.LocalOnly {
+			if err := cfg.CheckLocalOnly(); err != nil {
+				ui.Warn("⚠️  %s", err)
+			}
+		}
+	}
+	if cfgAnonymize != "" {
+		switch strings.ToLower(cfgAnonymize) {
+		case "on", "true":
+			cfg.Anonymize = true
+		case "off", "false":
+			cfg.Anonymize = false
+		default:
+			return fmt.Errorf("invalid --anonymize %q (expected on or off)", cfgAnonymize)
+		}
+		ui.Success("✅ Diff anonymization: %s", onOff(cfg.Anonymize))
+	}
+	if cfgAnonPaths != "" {
+		mode := strings.ToLower(cfgAnonPaths)
+		if mode == "off" {
+			mode = ""
+		}
+		if err := checkPathMode(mode); err != nil {
+			return err
+		}
+		cfg.AnonymizePaths = mode
+		ui.Success("✅ Path anonymization: %s", pathMode(cfg.AnonymizePaths))
+	}
+	if cfgSquash != "" {
+		switch strings.ToLower(cfgSquash) {
+		case "on", "true":
+			cfg.SquashMerge = true
+		case "off", "false":
+			cfg.SquashMerge = false
+		default:
+			return fmt.Errorf("invalid --squash-merge %q (expected on or off)", cfgSquash)
+		}
+		ui.Success("✅ Squash merge drafts: %s", onOff(cfg.SquashMerge))
+	}
+	if cfgTagPattern != "" {
+		if strings.EqualFold(cfgTagPattern, "off") {
+			cfg.TagPattern = ""
+			ui.Success("✅ Releases use every tag")
+		} else {
+			cfg.TagPattern = cfgTagPattern
+			ui.Success("✅ Release tags: %s", cfgTagPattern)
+		}
+	}
+	if cfgEditNotes != "" {
+		switch strings.ToLower(cfgEditNotes) {
+		case "on", "true":
+			cfg.EditReleaseNotes = true
+		case "off", "false":
+			cfg.EditReleaseNotes = false
+		default:
+			return fmt.Errorf("invalid --edit-release-notes %q (expected on or off)", cfgEditNotes)
+		}
+		ui.Success("✅ Edit release notes before tagging: %s", onOff(cfg.EditReleaseNotes))
+	}
+	if cfgRelStats != "" {
+		switch strings.ToLower(cfgRelStats) {
+		case "on", "true":
+			cfg.ReleaseStats = true
+		case "off", "false":
+			cfg.ReleaseStats = false
+		default:
+			return fmt.Errorf("invalid --release-stats %q (expected on or off)", cfgRelStats)
+		}
+		ui.Success("✅ Release stats section: %s", onOff(cfg.ReleaseStats))
+	}
+	if cfgFormat != "" {
+		if !slices.Contains(render.Formats, cfgFormat) {
+			return fmt.Errorf("invalid --default-format %q (expected %s)", cfgFormat, strings.Join(render.Formats, ", "))
+		}
+		cfg.Format = cfgFormat
+		ui.Success("✅ Output format: %s", cfgFormat)
+	}
+	if cfgWebhook != "" {
+		platform, url, ok := strings.Cut(cfgWebhook, "=")
+		if !ok || !announce.Supported(platform) {
+			return fmt.Errorf("invalid --webhook %q (expected platform=url, platform one of: %s)", cfgWebhook, strings.Join(announce.Platforms, ", "))
+		}
+		if cfg.Webhooks == nil {
+			cfg.Webhooks = make(map[string]string)
+		}
+		if url == "" {
+			delete(cfg.Webhooks, platform)
+			ui.Success("✅ %s webhook removed", platform)
+		} else {
+			cfg.Webhooks[platform] = url
+			ui.Success("✅ %s webhook saved", platform)
+		}
+	}
+	if cfgTypeEmoji != "" {
+		typ, emoji, ok := strings.Cut(cfgTypeEmoji, "=")
+		if typ = strings.ToLower(strings.TrimSpace(typ)); !ok || typ == "" {
+			return fmt.Errorf("invalid --type-emoji %q (expected type=emoji, e.g. feat=✨)", cfgTypeEmoji)
+		}
+		if cfg.TypeEmojis == nil {
+			cfg.TypeEmojis = make(map[string]string)
+		}
+		if emoji = strings.TrimSpace(emoji); emoji == "" {
+			delete(cfg.TypeEmojis, typ)
+			ui.Success("✅ Emoji of %s removed", typ)
+		} else {
+			cfg.TypeEmojis[typ] = emoji
+			ui.Success("✅ %s commits get %s", typ, emoji)
+		}
+	}
+	if cfgConsent != "" {
+		if cfgConsent != config.ConsentAsk && cfgConsent != config.ConsentGranted {
+			return fmt.Errorf("invalid --data-consent %q (expected ask or granted)", cfgConsent)
+		}
+		cfg.DataConsent = cfgConsent
+		ui.Success("✅ Data sharing consent: %s", cfgConsent)
+	}
+	if cfgEmojiPos != "" {
+		if cfgEmojiPos != message.EmojiStart && cfgEmojiPos != message.EmojiDescription {
+			return fmt.Errorf("invalid --type-emoji-position %q (expected start or description)", cfgEmojiPos)
+		}
+		cfg.TypeEmojiPosition = cfgEmojiPos
+		ui.Success("✅ Type emojis go at the %s of the subject", cfgEmojiPos)
+	}
+	if cfgHook != "" {
+		name, command, ok := strings.Cut(cfgHook, "=")
+		if !ok || !hooks.Supported(name) {
+			return fmt.Errorf("invalid --hook %q (expected name=command, name one of: %s)", cfgHook, strings.Join(hooks.Names, ", "))
+		}
+		if cfg.Hooks == nil {
+			cfg.Hooks = make(map[string]string)
+		}
+		if command == "" {
+			delete(cfg.Hooks, name)
+			ui.Success("✅ %s hook removed", name)
+		} else {
+			cfg.Hooks[name] = command
+			ui.Success("✅ %s hook saved", name)
+		}
+	}
+	if err := configureForges(cfg); err != nil {
+		return err
+	}
+	if cfgEmailFrom != "" {
+		cfg.EmailFrom = cfgEmailFrom
+		ui.Success("✅ Email sender set to: %s", cfgEmailFrom)
+	}
+	if cmd.Flags().Changed("email-to") {
+		cfg.EmailTo = cfgEmailTo
+		ui.Success("✅ Email recipients set to: %s", strings.Join(cfgEmailTo, ", "))
+	}
+	if cmd.Flags().Changed("protect-branches") {
+		var patterns []string
+		for _, p := range cfgProtect {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid branch pattern %q: %w", p, err)
+			}
+			patterns = append(patterns, p)
+		}
+		cfg.ProtectedBranches = patterns
+		if len(patterns) > 0 {
+			ui.Success("✅ Protected branches: %s", strings.Join(patterns, ", "))
+		} else {
+			ui.Success("✅ Branch protection disabled")
+		}
+	}
+	if cfgProtectMd != "" {
+		if cfgProtectMd != config.ProtectRefuse && cfgProtectMd != config.ProtectWarn {
+			return fmt.Errorf("invalid --protected-mode %q (expected %s or %s)", cfgProtectMd, config.ProtectRefuse, config.ProtectWarn)
+		}
+		cfg.ProtectedBranchMode = cfgProtectMd
+		ui.Success("✅ Protected branch mode set to: %s", cfgProtectMd)
+	}
+	if cmd.Flags().Changed("banned-paths") {
+		var patterns []string
+		for _, p := range cfgBanned {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			if _, err := path.Match(strings.TrimSuffix(p, "/"), ""); err != nil {
+				return fmt.Errorf("invalid path pattern %q: %w", p, err)
+			}
+			patterns = append(patterns, p)
+		}
+		cfg.BannedPaths = patterns
+		if len(patterns) > 0 {
+			ui.Success("✅ Banned paths: %s", strings.Join(patterns, ", "))
+		} else {
+			ui.Success("✅ Banned paths cleared")
+		}
 	}
 
+	migrating := config.UsesLegacyConfig()
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	color.Cyan("💾 Config saved to ~/.commitai.json")
+	ui.Info("💾 Config saved to %s", tildePath(config.UserConfigPath()))
+	if migrating {
+		ui.Muted("   Moved there from ~/.commitai.json")
+	}
 	return nil
 }
 
+// tildePath shortens a path in the home directory to ~/...
+func tildePath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// configureForges applies the --forge* flags, each given as host=value
+func configureForges(cfg *config.Config) error {
+	set := func(flag, value string, apply func(f *config.Forge, v string) error) error {
+		if value == "" {
+			return nil
+		}
+		host, v, ok := strings.Cut(value, "=")
+		if !ok || host == "" {
+			return fmt.Errorf("invalid --%s %q (expected host=value)", flag, value)
+		}
+		if cfg.Forges == nil {
+			cfg.Forges = make(map[string]config.Forge)
+		}
+		f := cfg.Forges[host]
+		if err := apply(&f, v); err != nil {
+			return err
+		}
+		cfg.Forges[host] = f
+		return nil
+	}
+
+	if host, kind, ok := strings.Cut(cfgForge, "="); ok && host != "" && kind == "" {
+		delete(cfg.Forges, host)
+		ui.Success("✅ Forge settings for %s removed", host)
+	} else {
+		err := set("forge", cfgForge, func(f *config.Forge, v string) error {
+			if !forge.Supported(v) {
+				return fmt.Errorf("invalid forge type %q (supported: %s)", v, strings.Join(forge.Types, ", "))
+			}
+			f.Type = v
+			ui.Success("✅ Forge type of %s: %s", host, v)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err := set("forge-api-url", cfgForgeAPI, func(f *config.Forge, v string) error {
+		if v != "" && !strings.HasPrefix(v, "https://") && !strings.HasPrefix(v, "http://") {
+			return fmt.Errorf("invalid forge API URL %q (expected http:// or https://)", v)
+		}
+		f.APIURL = v
+		ui.Success("✅ Forge API URL: %s", ifEmpty(v, "detected"))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = set("forge-token-env", cfgForgeToken, func(f *config.Forge, v string) error {
+		f.TokenEnv = v
+		ui.Success("✅ Forge token read from: %s", ifEmpty(v, "the default variable"))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = set("forge-ca-file", cfgForgeCA, func(f *config.Forge, v string) error {
+		if v != "" {
+			abs, err := filepath.Abs(v)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return fmt.Errorf("invalid forge CA file: %w", err)
+			}
+			v = abs
+		}
+		f.CAFile = v
+		ui.Success("✅ Forge CA file: %s", ifEmpty(v, "system roots only"))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return set("forge-insecure", cfgForgeTLS, func(f *config.Forge, v string) error {
+		switch strings.ToLower(v) {
+		case "on", "true":
+			f.InsecureSkipVerify = true
+			ui.Warn("⚠️  TLS certificates of this forge will not be verified")
+		case "off", "false":
+			f.InsecureSkipVerify = false
+			ui.Success("✅ TLS verification: on")
+		default:
+			return fmt.Errorf("invalid --forge-insecure %q (expected host=on or host=off)", cfgForgeTLS)
+		}
+		return nil
+	})
+}
+
 func printConfig(cfg *config.Config) {
 	fmt.Println()
-	color.Cyan("⚙️  commitai configuration:")
+	ui.Info("⚙️  commitai configuration:")
 	fmt.Println()
 
 	apiKeyDisplay := "(not set)"
 	if cfg.GeminiAPIKey != "" {
-		k := cfg.GeminiAPIKey
-		if len(k) > 8 {
-			apiKeyDisplay = k[:4] + strings.Repeat("*", len(k)-8) + k[len(k)-4:]
-		} else {
-			apiKeyDisplay = "****"
-		}
+		apiKeyDisplay = maskKey(cfg.GeminiAPIKey)
 	}
 
 	fmt.Printf("  API Key:      %s\n", apiKeyDisplay)
+	if len(cfg.GeminiAPIKeys) > 0 {
+		var masked []string
+		for _, k := range cfg.GeminiAPIKeys {
+			masked = append(masked, maskKey(k))
+		}
+		fmt.Printf("  Extra keys:   %s (%s)\n", strings.Join(masked, ", "), ifEmpty(cfg.KeyRotation, config.RotationFailover))
+	}
 	fmt.Printf("  Language:     %s\n", cfg.Language)
 	fmt.Printf("  Style:        %s\n", cfg.CommitStyle)
 	fmt.Printf("  Model:        %s\n", cfg.Model)
 	fmt.Printf("  Max Tokens:   %d\n", cfg.MaxTokens)
+	if cfg.GatewayURL != "" {
+		fmt.Printf("  Gateway:      %s\n", cfg.GatewayURL)
+	}
+	if len(cfg.Headers) > 0 {
+		var names []string
+		for name := range cfg.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("  Headers:      %s\n", strings.Join(names, ", "))
+	}
+	switch {
+	case cfg.Provider == config.ProviderOffline:
+		fmt.Printf("  Provider:     offline (rule-based)\n")
+	case cfg.ProviderCommand != "":
+		fmt.Printf("  Provider:     %s\n", cfg.ProviderCommand)
+	}
+	if cfg.DictationCommand != "" {
+		fmt.Printf("  Dictation:    %s\n", cfg.DictationCommand)
+	}
+	if cfg.MaxCost > 0 {
+		fmt.Printf("  Max cost:     $%.4f per AI call\n", cfg.MaxCost)
+	}
+	if cfg.DailyCalls > 0 || cfg.DailyTokens > 0 {
+		fmt.Printf("  Daily budget: %s\n", budgetSummary(cfg))
+	}
+	if cfg.Compress > 0 {
+		fmt.Printf("  Compression:  level %d\n", cfg.Compress)
+	}
+	fmt.Printf("  Recent:       %s\n", recentSummary(cfg))
+	if cfg.LintThreshold > 0 {
+		fmt.Printf("  Lint min:     %d\n", cfg.LintThreshold)
+	}
+	if cfg.FileHistory > 0 {
+		fmt.Printf("  File history: %d per file\n", cfg.FileHistory)
+	}
+	if cfg.NotifyAfter > 0 {
+		fmt.Printf("  Notify after: %ds\n", cfg.NotifyAfter)
+	}
+	fmt.Printf("  Close issues: %s\n", ifEmpty(cfg.ClosingKeyword, "off"))
+	fmt.Printf("  Provenance:   %s\n", onOff(cfg.ProvenanceTrailers))
+	fmt.Printf("  Privacy mode: %s\n", onOff(cfg.PrivacyMode))
+	fmt.Printf("  Branch ctx:   %s\n", onOff(!cfg.NoBranchContext))
+	fmt.Printf("  Commit tmpl:  %s\n", onOff(!cfg.NoCommitTemplate))
+	fmt.Printf("  Impact line:  %s\n", onOff(cfg.ImpactLine))
+	fmt.Printf("  Critique:     %s\n", onOff(cfg.ConfidenceCritique))
+	if len(cfg.TypeEmojis) > 0 {
+		fmt.Printf("  Type emojis:  %s (%s)\n", typeEmojiSummary(cfg.TypeEmojis), ifEmpty(cfg.TypeEmojiPosition, message.EmojiStart))
+	}
+	if cfg.Deterministic {
+		fmt.Printf("  Determinism:  on (seed %d)\n", cfg.Seed)
+	} else {
+		fmt.Printf("  Determinism:  off\n")
+	}
+	fmt.Printf("  commitlint:   %s\n", onOff(cfg.Commitlint))
+	fmt.Printf("  Anonymize:    %s\n", onOff(cfg.Anonymize))
+	fmt.Printf("  Anon. paths:  %s\n", pathMode(cfg.AnonymizePaths))
+	if cfg.LocalOnlyLocked {
+		fmt.Printf("  Local only:   on (required by the repository's .commitai.json)\n")
+	} else {
+		fmt.Printf("  Local only:   %s\n", onOff(cfg.LocalOnly))
+	}
+	if cfg.DataConsent == config.ConsentGranted {
+		fmt.Printf("  Data consent: granted\n")
+	} else if agreed := git.LocalConfig(consentKey); agreed != "" {
+		fmt.Printf("  Data consent: ask (agreed here: %s)\n", strings.ReplaceAll(agreed, ",", ", "))
+	} else {
+		fmt.Printf("  Data consent: ask\n")
+	}
+	fmt.Printf("  Squash merge: %s\n", onOff(cfg.SquashMerge))
+	if cfg.TagPattern != "" {
+		fmt.Printf("  Release tags: %s\n", cfg.TagPattern)
+	}
+	fmt.Printf("  Edit notes:   %s\n", onOff(cfg.EditReleaseNotes))
+	fmt.Printf("  Notes stats:  %s\n", onOff(cfg.ReleaseStats))
+	fmt.Printf("  Format:       %s\n", ifEmpty(cfg.Format, render.Rich))
+	if len(cfg.ProtectedBranches) > 0 {
+		fmt.Printf("  Protected:    %s (%s)\n", strings.Join(cfg.ProtectedBranches, ", "), ifEmpty(cfg.ProtectedBranchMode, config.ProtectRefuse))
+	}
+	if len(cfg.BannedPaths) > 0 {
+		fmt.Printf("  Banned paths: %s\n", strings.Join(cfg.BannedPaths, ", "))
+	}
+	if cfg.PolicyURL != "" {
+		fmt.Printf("  Org policy:   %s\n", cfg.PolicyURL)
+	}
+	if len(cfg.Webhooks) > 0 {
+		var platforms []string
+		for p := range cfg.Webhooks {
+			platforms = append(platforms, p)
+		}
+		sort.Strings(platforms)
+		fmt.Printf("  Webhooks:     %s\n", strings.Join(platforms, ", "))
+	}
+	if cfg.JiraURL != "" {
+		fmt.Printf("  Jira:         %s\n", cfg.JiraURL)
+	}
+	var forgeHosts []string
+	for host := range cfg.Forges {
+		forgeHosts = append(forgeHosts, host)
+	}
+	sort.Strings(forgeHosts)
+	label := "Forges:"
+	for _, host := range forgeHosts {
+		f := cfg.Forges[host]
+		var parts []string
+		for _, p := range []string{f.Type, f.APIURL} {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+		if f.TokenEnv != "" {
+			parts = append(parts, "token $"+f.TokenEnv)
+		}
+		if f.CAFile != "" {
+			parts = append(parts, "CA "+f.CAFile)
+		}
+		if f.InsecureSkipVerify {
+			parts = append(parts, "TLS unverified")
+		}
+		fmt.Printf("  %-14s%s: %s\n", label, host, strings.Join(parts, ", "))
+		label = ""
+	}
+	label = "Hooks:"
+	for _, name := range hooks.Names {
+		if command := cfg.Hooks[name]; command != "" {
+			fmt.Printf("  %-14s%s: %s\n", label, name, command)
+			label = ""
+		}
+	}
+	if cfg.EmailFrom != "" || len(cfg.EmailTo) > 0 {
+		fmt.Printf("  Email:        %s → %s\n", ifEmpty(cfg.EmailFrom, "(no sender)"), strings.Join(cfg.EmailTo, ", "))
+	}
 	fmt.Println()
-	fmt.Println("  Config file:  ~/.commitai.json")
+	if config.UsesLegacyConfig() {
+		fmt.Println("  Config file:  ~/.commitai.json (moved to the config directory on the next save)")
+	} else {
+		fmt.Printf("  Config file:  %s\n", tildePath(config.UserConfigPath()))
+	}
+	if path := config.RepoConfigPath(); path != "" {
+		fmt.Printf("  Repo config:  %s\n", path)
+	}
 	fmt.Println("  Env override: GEMINI_API_KEY")
 	fmt.Println()
 }
+
+func maskKey(k string) string {
+	if len(k) > 8 {
+		return k[:4] + strings.Repeat("*", len(k)-8) + k[len(k)-4:]
+	}
+	return "****"
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// pathMode shows an anonymize_paths mode, off when empty
+func pathMode(mode string) string {
+	if mode == "" {
+		return "off"
+	}
+	return mode
+}
+
+// recentSummary describes the recent-commit context, e.g. "5 commits since 2 weeks ago, yours"
+func recentSummary(cfg *config.Config) string {
+	if cfg.RecentCommits <= 0 {
+		return "off"
+	}
+	s := fmt.Sprintf("%d commits", cfg.RecentCommits)
+	if cfg.RecentSince != "" {
+		s += " since " + cfg.RecentSince
+	}
+	if cfg.RecentSameAuthor {
+		s += ", yours"
+	}
+	if cfg.RecentSamePaths {
+		s += ", touching staged files"
+	}
+	if cfg.RecentRelevant {
+		s += ", most relevant to the diff"
+	}
+	return s
+}
+
+// budgetSummary describes the daily AI budget and, inside a repository,
+// what it used today
+func budgetSummary(cfg *config.Config) string {
+	var limits []string
+	if cfg.DailyCalls > 0 {
+		limits = append(limits, fmt.Sprintf("%d calls", cfg.DailyCalls))
+	}
+	if cfg.DailyTokens > 0 {
+		limits = append(limits, fmt.Sprintf("%d tokens", cfg.DailyTokens))
+	}
+	summary := strings.Join(limits, ", ") + " per repository"
+	if gitDir, err := git.Dir(); err == nil {
+		if usage, err := budget.Load(gitDir); err == nil {
+			summary += fmt.Sprintf(" (today: %d calls, %d tokens)", usage.Calls, usage.Tokens)
+		}
+	}
+	return summary
+}
+
+// typeEmojiSummary lists the type emojis by type, e.g. "feat ✨, fix 🐛"
+func typeEmojiSummary(emojis map[string]string) string {
+	types := make([]string, 0, len(emojis))
+	for typ := range emojis {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for i, typ := range types {
+		types[i] = typ + " " + emojis[typ]
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/policy"
+)
+
+// consentKey keeps, in the repository's .git/config, which kinds of data
+// its user agreed to send
+const consentKey = "commitai.dataConsent"
+
+// sharedData is one kind of repository data sent to the provider
+type sharedData struct {
+	kind        string // Recorded in consentKey
+	description string
+}
+
+// dataShared lists what the provider receives with cfg
+func dataShared(cfg *config.Config) []sharedData {
+	diffs := "The staged diffs: changed lines with a few lines of context"
+	if cfg.Anonymize {
+		diffs = "The staged diffs, string literals, emails, hostnames and URLs replaced by placeholders"
+	}
+	paths := "File names and paths"
+	switch cfg.AnonymizePaths {
+	case config.PathsGeneralize:
+		paths = "File paths, generalized to the top-level directory and extension"
+	case config.PathsHash:
+		paths = "Hashes of file paths, with their extension"
+	}
+	shared := []sharedData{
+		{"diffs", diffs},
+		{"paths", paths},
+	}
+	if cfg.RecentCommits > 0 || cfg.FileHistory > 0 {
+		shared = append(shared, sharedData{"subjects", "Subjects of recent commits"})
+	}
+	if !cfg.NoBranchContext && !cfg.PrivacyMode {
+		shared = append(shared, sharedData{"branch", "The branch name"})
+	}
+	return shared
+}
+
+// ensureConsent asks, the first time data of this repository would be sent
+// to a provider, whether that's fine, after listing what is sent. The
+// answer is recorded in .git/config; it is asked again when more kinds of
+// data would be sent. Nothing is asked with the offline provider, when the
+// user config grants consent, when the organization does in its signed
+// policy bundle, or without a terminal.
+func ensureConsent(cfg *config.Config) error {
+	if cfg.Provider == config.ProviderOffline || cfg.DataConsent == config.ConsentGranted || !git.IsGitRepo() {
+		return nil
+	}
+	shared := dataShared(cfg)
+	var kinds []string
+	for _, d := range shared {
+		kinds = append(kinds, d.kind)
+	}
+	agreed := strings.Split(git.LocalConfig(consentKey), ",")
+	if containsAll(agreed, kinds) || flagYes || !stdinIsTerminal() {
+		return nil
+	}
+	if policy.ConsentGranted(cfg) {
+		return nil
+	}
+
+	ui.Warn("🔐 commitai sends data from this repository to %s:", consentRecipient(cfg))
+	for _, d := range shared {
+		fmt.Println("   • " + i18n.T(d.description))
+	}
+	fmt.Println(i18n.T("  [y] Agree for this repository (asked once)"))
+	fmt.Println(i18n.T("  [o] Use the offline rule-based generator for this run"))
+	fmt.Println(i18n.T("  [q] Quit"))
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(i18n.T("Choice [y/o/q]: "))
+		input, _ := reader.ReadString('\n')
+		switch input = strings.TrimSpace(strings.ToLower(input)); {
+		case i18n.Yes(input):
+			if err := git.SetLocalConfig(consentKey, strings.Join(kinds, ",")); err != nil {
+				ui.Warn("⚠️  Consent not recorded, you will be asked again: %s", err)
+			}
+			return nil
+		case input == "o" || input == "offline":
+			cfg.Provider = config.ProviderOffline
+			return nil
+		case input == "q" || input == "quit" || input == "" || i18n.No(input):
+			return fmt.Errorf("no data was sent; agree to send it, or use commitai config --provider offline")
+		}
+	}
+}
+
+// consentRecipient names where the data goes
+func consentRecipient(cfg *config.Config) string {
+	switch {
+	case cfg.ProviderCommand != "":
+		return fmt.Sprintf("the provider command %q", cfg.ProviderCommand)
+	case cfg.GatewayURL != "":
+		return cfg.GatewayURL
+	}
+	return "Google Gemini (" + cfg.Model + ")"
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			found = found || h == w
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
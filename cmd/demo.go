@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+var (
+	demoKeep bool
+	demoYes  bool
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Try commitai in a throwaway sandbox repository, no API key needed",
+	Long: `Try commitai in a throwaway sandbox repository, no API key needed.
+
+The demo creates a temporary repository with sample changes and walks through
+a single commit, a granular commit and a release. Messages come from the
+built-in offline provider (simple rules, no AI calls), so nothing leaves your
+machine. Your own configuration and repositories are never touched.`,
+	Args:         cobra.NoArgs,
+	RunE:         runDemo,
+	SilenceUsage: true, // A failed step is not a usage error
+}
+
+func init() {
+	demoCmd.Flags().BoolVar(&demoKeep, "keep", false, "Keep the sandbox directory when the demo ends")
+	demoCmd.Flags().BoolVarP(&demoYes, "yes", "y", false, "Don't pause between steps and accept every suggestion")
+}
+
+// demoStep is one stage of the guided tour
+type demoStep struct {
+	title   string
+	explain string
+	files   map[string]string // Written and staged before the step
+	args    []string          // commitai arguments
+}
+
+var demoSteps = []demoStep{
+	{
+		title:   "One message for everything",
+		explain: "You added a greeting feature with a test. commitai reads the staged diff and\nproposes one message for all of it. Answer Y to commit, e to edit it first.",
+		files: map[string]string{
+			"greet.go":      "package main\n\nimport \"fmt\"\n\n// Greet returns a friendly greeting\nfunc Greet(name string) string {\n\treturn fmt.Sprintf(\"Hello, %s!\", name)\n}\n",
+			"greet_test.go": "package main\n\nimport \"testing\"\n\nfunc TestGreet(t *testing.T) {\n\tif Greet(\"Ada\") != \"Hello, Ada!\" {\n\t\tt.Fatal(\"unexpected greeting\")\n\t}\n}\n",
+		},
+		args: []string{"--all"},
+	},
+	{
+		title:   "One commit per file",
+		explain: "Now three unrelated changes are staged: a bug fix, new docs and a CI\nworkflow. --granular proposes a separate commit for each file.",
+		files: map[string]string{
+			"main.go":                  "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// Fix: greet the user instead of printing nothing\n\tfmt.Println(Greet(\"world\"))\n}\n",
+			"docs/usage.md":            "# Usage\n\nRun `go run .` to print a greeting.\n",
+			".github/workflows/ci.yml": "name: ci\non: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: go test ./...\n",
+		},
+		args: []string{"--granular"},
+	},
+	{
+		title:   "Release",
+		explain: "Finally, cut a release. commitai picks the next version from the commit\ntypes (a feat means a minor bump) and writes release notes.",
+		args:    []string{"release", "--auto"},
+	},
+}
+
+func runDemo(cmd *cobra.Command, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	sandbox, err := os.MkdirTemp("", "commitai-demo-")
+	if err != nil {
+		return err
+	}
+	if demoKeep {
+		defer ui.Info("\n📁 Sandbox kept at %s", sandbox)
+	} else {
+		defer os.RemoveAll(sandbox)
+	}
+
+	home := filepath.Join(sandbox, "home")
+	repo := filepath.Join(sandbox, "repo")
+	env := demoEnv(home)
+	if err := setupDemoRepo(home, repo, env); err != nil {
+		return fmt.Errorf("failed to set up the sandbox: %w", err)
+	}
+
+	fmt.Println()
+	ui.Info("🎬 Welcome to the commitai demo!")
+	fmt.Println()
+	fmt.Println("  A sandbox repository was created in", repo)
+	fmt.Println("  Messages come from the offline provider (rules, no AI calls, no API key).")
+	fmt.Println("  Nothing here touches your own repositories or configuration.")
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, step := range demoSteps {
+		fmt.Println()
+		ui.Info("━━━ Step %d/%d: %s", i+1, len(demoSteps), step.title)
+		fmt.Println(step.explain)
+
+		if len(step.files) > 0 {
+			if err := writeDemoFiles(repo, step.files); err != nil {
+				return err
+			}
+			if out, err := demoGit(env, repo, "add", "-A"); err != nil {
+				return fmt.Errorf("git add failed: %s", out)
+			}
+			status, _ := demoGit(env, repo, "status", "--short")
+			fmt.Println()
+			fmt.Println(status)
+		}
+
+		stepArgs := step.args
+		if demoYes {
+			stepArgs = append(stepArgs, "--yes")
+		}
+		fmt.Println()
+		ui.Muted("$ commitai %s", strings.Join(stepArgs, " "))
+		if !demoYes {
+			fmt.Print("Press Enter to run it (q to quit): ")
+			input, _ := reader.ReadString('\n')
+			if strings.TrimSpace(strings.ToLower(input)) == "q" {
+				ui.Warn("Demo stopped.")
+				return nil
+			}
+		}
+
+		c := exec.Command(self, stepArgs...)
+		c.Dir = repo
+		c.Env = env
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("step %d failed: %w", i+1, err)
+		}
+	}
+
+	fmt.Println()
+	ui.Info("━━━ Done! The sandbox history:")
+	if log, err := demoGit(env, repo, "log", "--oneline", "--decorate"); err == nil {
+		fmt.Println(log)
+	}
+	fmt.Println()
+	ui.Success("✅ Ready to use it for real? Set an API key in your own repository:")
+	fmt.Println("   commitai config --key YOUR_GEMINI_API_KEY")
+	return nil
+}
+
+// demoEnv isolates the demo runs from the user's commitai and git
+// configuration, and from any API key in the environment
+func demoEnv(home string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch strings.ToUpper(name) {
+		case "HOME", "USERPROFILE", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", config.EnvConfig, config.EnvAPIKey, "GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE":
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env,
+		"HOME="+home,
+		"USERPROFILE="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"XDG_CACHE_HOME="+filepath.Join(home, ".cache"),
+		"GIT_CONFIG_NOSYSTEM=1",
+	)
+}
+
+func setupDemoRepo(home, repo string, env []string) error {
+	if err := os.MkdirAll(home, 0755); err != nil {
+		return err
+	}
+	data := fmt.Sprintf(`{"provider": %q, "language": "en", "commit_style": "conventional"}`+"\n", config.ProviderOffline)
+	dir := filepath.Join(home, ".config", "commitai")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0600); err != nil {
+		return err
+	}
+
+	if err := writeDemoFiles(repo, map[string]string{
+		"README.md": "# hello\n\nA tiny program used by the commitai demo.\n",
+		"main.go":   "package main\n\nfunc main() {\n}\n",
+	}); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"symbolic-ref", "HEAD", "refs/heads/main"},
+		{"config", "user.name", "Demo User"},
+		{"config", "user.email", "demo@example.com"},
+		{"add", "-A"},
+		{"commit", "--quiet", "-m", "chore: initial commit"},
+		{"tag", "v0.1.0"},
+	} {
+		if out, err := demoGit(env, repo, args...); err != nil {
+			return fmt.Errorf("git %s: %s", args[0], out)
+		}
+	}
+	return nil
+}
+
+func writeDemoFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func demoGit(env []string, dir string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Env = env
+	out, err := c.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// maxCostPathWidth bounds the path column of the diff cost table
+const maxCostPathWidth = 48
+
+// compressLevel is the prompt compression the next AI call will use
+func compressLevel(cfg *config.Config) int {
+	if flagCompress > 0 {
+		return flagCompress
+	}
+	return cfg.Compress
+}
+
+// printDiffCosts lists the staged files with the size of their diff, the
+// tokens it adds to the prompt and whether it is cut down, numbered for
+// leaveOutDiffs
+func printDiffCosts(changes []git.FileChange, costs []ai.FileCost) {
+	total := 0
+	width := 0
+	for _, c := range costs {
+		total += c.Tokens
+		width = max(width, min(len(c.Path), maxCostPathWidth))
+	}
+
+	ui.Info("\n📂 Staged files (%d), ~%d diff tokens:", len(changes), total)
+	for i, c := range costs {
+		path := c.Path
+		if len(path) > width {
+			path = "…" + path[len(path)-width+1:]
+		}
+		tokens := "-"
+		if c.Tokens > 0 {
+			tokens = fmt.Sprintf("~%d", c.Tokens)
+		}
+		fmt.Printf("  %2d %s %-*s %10s %7s", i+1, statusToIcon(changes[i].Status), width, path, c.Size(), tokens)
+		if c.Treatment != "" {
+			fmt.Print("  " + color.YellowString(c.Treatment))
+		}
+		fmt.Println()
+	}
+}
+
+// leaveOutDiffs offers to drop the diffs of truncated files from the prompt.
+// The files are still committed; the model only sees their paths.
+func leaveOutDiffs(changes []git.FileChange, costs []ai.FileCost) {
+	if flagYes || !stdinIsTerminal() {
+		return
+	}
+	truncated := 0
+	for _, c := range costs {
+		if c.Truncated() {
+			truncated++
+		}
+	}
+	if truncated == 0 || len(changes) == 1 {
+		return // Nothing heavy, or nothing left to describe without it
+	}
+
+	fmt.Printf("\n✂️  %d diff(s) are truncated in the prompt. Leave diffs out? Numbers (e.g. 1,3), or Enter to send all: ", truncated)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(changes) {
+			ui.Warn("⚠️  Ignoring %q: not a file number", field)
+			continue
+		}
+		changes[n-1].DiffLeftOut = true
+		ui.Muted("   %s: diff left out (saves ~%d tokens)", changes[n-1].Path, costs[n-1].Tokens)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	flagDiffFile  string
+	flagDiffPaths []string
+)
+
+// runDiffFile writes the message for the diff in --diff-file without
+// looking at any repository, for GUI clients and review tools. Only the
+// message goes to stdout; progress and violations go to stderr.
+func runDiffFile() error {
+	switch {
+	case flagGranular || flagGroupBy != "":
+		return fmt.Errorf("--diff-file writes one message; run it once per file with --diff-paths instead of --granular or --group-by")
+	case flagPlanOut != "":
+		return fmt.Errorf("--diff-file can't be combined with --plan-out")
+	}
+	ui, _ = render.New(uiFormat, os.Stderr)
+
+	var data []byte
+	var err error
+	if flagDiffFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(flagDiffFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the diff: %w", err)
+	}
+
+	changes := git.ParseDiff(string(data))
+	if len(flagDiffPaths) > 0 {
+		var kept []git.FileChange
+		for _, c := range changes {
+			for _, p := range flagDiffPaths {
+				p = strings.TrimSuffix(p, "/")
+				if c.Path == p || strings.HasPrefix(c.Path, p+"/") {
+					kept = append(kept, c)
+					break
+				}
+			}
+		}
+		changes = kept
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("no file changes found in %s", flagDiffFile)
+	}
+
+	cfg, err := loadCommitConfig(flagLanguage, flagStyle)
+	if err != nil {
+		return err
+	}
+	// Nothing from the repository commitai happens to run in
+	cfg.Branch = ""
+
+	client := newClient(cfg)
+	ui.Info("✨ Generating a commit message for %d file(s) with %s...", len(changes), client.ProviderName())
+	messages, err := client.GenerateCommitMessages(changes, false, nil)
+	if err != nil {
+		return fmt.Errorf("AI generation failed: %w", err)
+	}
+	msg := messages["__all__"]
+	reportViolations(policy.Check(msg, cfg.Policy))
+	fmt.Println(msg)
+	return nil
+}
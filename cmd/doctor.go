@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/githooks"
+	"github.com/kaiqui/commitai/internal/policy"
+)
+
+var docPrivacy bool
+
+var doctorCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Check that commitai is set up correctly",
+	Long:         `Check that commitai is set up correctly.`,
+	RunE:         runDoctor,
+	SilenceUsage: true, // Failed checks are not usage errors
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&docPrivacy, "privacy", false, "Verify privacy mode guarantees (no AI metadata, no stray files, no extra network calls)")
+}
+
+// checkResult is one line of the doctor report
+type checkResult struct {
+	ok     bool
+	warn   bool // Not fatal, but worth looking at
+	title  string
+	detail string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	var results []checkResult
+	if docPrivacy {
+		results = privacyChecks(cfg)
+	} else {
+		results = setupChecks(cfg)
+	}
+
+	fmt.Println()
+	if docPrivacy {
+		ui.Info("🩺 commitai privacy audit:")
+	} else {
+		ui.Info("🩺 commitai doctor:")
+	}
+	fmt.Println()
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.ok:
+			fmt.Printf("  %s %s\n", color.GreenString("✔"), r.title)
+		case r.warn:
+			fmt.Printf("  %s %s\n", color.YellowString("!"), r.title)
+		default:
+			fmt.Printf("  %s %s\n", color.RedString("✖"), r.title)
+			failed++
+		}
+		if r.detail != "" && !r.ok {
+			fmt.Printf("      %s\n", r.detail)
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	ui.Success("✅ All checks passed")
+	return nil
+}
+
+func setupChecks(cfg *config.Config) []checkResult {
+	var results []checkResult
+
+	_, err := exec.LookPath("git")
+	results = append(results, checkResult{ok: err == nil, title: "git is installed", detail: "install git and make sure it is on PATH"})
+
+	results = append(results, checkResult{ok: git.IsGitRepo(), warn: true, title: "inside a git repository", detail: "run commitai from a git working tree"})
+
+	err = cfg.Validate()
+	results = append(results, checkResult{ok: err == nil, title: "API key configured", detail: fmt.Sprint(err)})
+
+	results = append(results, checkResult{ok: cfg.Model != "", title: "model configured", detail: "run: commitai config --model gemini-2.5-flash"})
+
+	if cfg.LocalOnly {
+		err = cfg.CheckLocalOnly()
+		results = append(results, checkResult{ok: err == nil, title: "AI provider is on this machine (local-only mode)", detail: fmt.Sprint(err)})
+	}
+
+	eff, err := policy.Resolve(cfg)
+	results = append(results, checkResult{ok: err == nil, title: "commit policy loads", detail: fmt.Sprint(err)})
+	if err == nil && eff.CommitlintErr != nil {
+		results = append(results, checkResult{warn: true, title: "commitlint config is understood", detail: eff.CommitlintErr.Error()})
+	}
+
+	// Hook frameworks the project expects to run on commits
+	if top, err := git.TopLevel(); err == nil {
+		if hooksDir, err := git.HooksDir(); err == nil {
+			for _, f := range githooks.Scan(top, hooksDir) {
+				if f.Framework == "commitlint" && cfg.Commitlint {
+					continue
+				}
+				results = append(results, checkResult{warn: true, title: f.Framework + " hooks run on commits", detail: f.Problem + "; fix: " + f.Fix})
+			}
+		}
+	}
+	if cfg.Commitlint {
+		top, _ := git.TopLevel()
+		_, err := githooks.Commitlint(top, "chore: doctor check")
+		results = append(results, checkResult{ok: err == nil, warn: true, title: "commitlint runs", detail: fmt.Sprint(err)})
+	}
+
+	// Only release --publish needs the forge, so problems are warnings
+	if remote, err := git.RemoteURL("origin"); err == nil {
+		if f, err := forge.Detect(cfg, remote); err != nil {
+			results = append(results, checkResult{warn: true, title: "forge of origin detected", detail: err.Error()})
+		} else {
+			results = append(results, checkResult{
+				ok:     f.Token != "",
+				warn:   true,
+				title:  fmt.Sprintf("%s API at %s has a token", f.Type, f.APIURL),
+				detail: fmt.Sprintf("set %s (or commitai config --forge-token-env %s=VAR) for release --publish and pr", f.TokenIn, f.Host),
+			})
+		}
+	}
+
+	return results
+}
+
+func privacyChecks(cfg *config.Config) []checkResult {
+	var results []checkResult
+
+	results = append(results, checkResult{
+		ok:     cfg.PrivacyMode,
+		title:  "privacy mode enabled",
+		detail: "run: commitai config --privacy on (or set \"privacy_mode\": true in the repo's .commitai.json)",
+	})
+
+	// Tool-identifying metadata
+	results = append(results, checkResult{
+		ok:     !cfg.ProvenanceTrailers || cfg.PrivacyMode,
+		title:  "no AI provenance trailers added to commits",
+		detail: "provenance_trailers is on; privacy mode suppresses it, or run: commitai config --provenance off",
+	})
+	if git.IsGitRepo() {
+		tagged, _ := git.GrepCommits("^AI-(Generated-By|Edited):", 500)
+		results = append(results, checkResult{
+			ok:     len(tagged) == 0,
+			warn:   true,
+			title:  "no AI trailers in recent history",
+			detail: fmt.Sprintf("%d of the last 500 commits carry AI-Generated-By/AI-Edited trailers (e.g. %s)", len(tagged), first(tagged)),
+		})
+	}
+
+	// Stray files
+	if top, err := git.TopLevel(); err == nil {
+		leftovers, _ := filepath.Glob(filepath.Join(top, "RELEASE-*.md"))
+		results = append(results, checkResult{
+			ok:     len(leftovers) == 0,
+			warn:   true,
+			title:  "no RELEASE-*.md files in the working tree",
+			detail: fmt.Sprintf("found %d file(s) from earlier releases, e.g. %s", len(leftovers), first(leftovers)),
+		})
+	}
+
+	// Network calls besides the AI provider
+	results = append(results, checkResult{
+		ok:     !policy.IsRemote(cfg.PolicyURL),
+		title:  "no remote policy bundle",
+		detail: fmt.Sprintf("policy_url %s needs network access; use a local file", cfg.PolicyURL),
+	})
+	results = append(results, checkResult{
+		ok:     len(cfg.Webhooks) == 0,
+		warn:   cfg.PrivacyMode, // Blocked at runtime in privacy mode
+		title:  "no announcement webhooks configured",
+		detail: "release announcements would contact chat services; remove with: commitai config --webhook <platform>=",
+	})
+
+	return results
+}
+
+func first(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	return list[0]
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	ednRegenerate bool
+	ednAudience   string
+	ednNoEdit     bool
+	ednPush       bool
+	ednPublish    bool
+)
+
+var releaseEditNotesCmd = &cobra.Command{
+	Use:   "edit-notes <tag>",
+	Short: "Fix the notes of an existing release tag",
+	Long: `Fix the notes of an existing release tag.
+
+The notes of the annotated tag (or, with --regenerate, new ones generated
+from the commits since the previous tag) are opened in your editor. After
+confirmation the tag is re-created on the same commit with the new notes.
+
+The tag is only changed locally unless --push is given, which force-pushes
+it to origin; clones that already fetched it keep the old notes until they
+fetch with --force. --publish also replaces the notes of the release on the
+forge hosting origin.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runReleaseEditNotes,
+	SilenceUsage: true,
+}
+
+func init() {
+	releaseEditNotesCmd.Flags().BoolVarP(&ednRegenerate, "regenerate", "r", false, "Generate new notes from the tag's commits instead of editing the current ones")
+	releaseEditNotesCmd.Flags().StringVar(&ednAudience, "audience", "", "Tailor regenerated notes for an audience (users, developers, internal)")
+	releaseEditNotesCmd.Flags().BoolVar(&ednNoEdit, "no-edit", false, "Use the regenerated notes without opening them in $EDITOR")
+	releaseEditNotesCmd.Flags().BoolVarP(&ednPush, "push", "p", false, "Force-push the re-created tag to origin")
+	releaseEditNotesCmd.Flags().BoolVar(&ednPublish, "publish", false, "Force-push the tag and update the release notes on the forge")
+	releaseEditNotesCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Re-create the tag without asking for confirmation")
+	releaseEditNotesCmd.Flags().BoolVar(&flagForce, "force", false, "Re-create the tag even on a protected branch")
+	releaseEditNotesCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+
+	releaseCmd.AddCommand(releaseEditNotesCmd)
+}
+
+func runReleaseEditNotes(cmd *cobra.Command, args []string) error {
+	tag := args[0]
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if ednNoEdit && !ednRegenerate {
+		return fmt.Errorf("--no-edit only applies to --regenerate")
+	}
+	if !ai.ValidAudience(ednAudience) {
+		return fmt.Errorf("invalid --audience %q (expected users, developers or internal)", ednAudience)
+	}
+
+	current, err := git.TagMessage(tag)
+	if err != nil {
+		return err
+	}
+	signed, err := git.TagSigned(tag)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := checkProtectedBranch(cfg, "tag"); err != nil {
+		return err
+	}
+
+	var host *forge.Forge
+	if ednPublish {
+		ednPush = true
+		if host, err = originForge(cfg); err != nil {
+			return err
+		}
+		if !host.HasReleases() {
+			return fmt.Errorf("%s has no releases to update; use --push", host.Type)
+		}
+		if err := host.CheckAllowed(cfg); err != nil {
+			return err
+		}
+	}
+
+	notes := current
+	if ednRegenerate {
+		if notes, err = regenerateNotes(cfg, tag); err != nil {
+			return err
+		}
+	}
+	if !ednNoEdit {
+		if notes, err = editText(notes, "RELEASE-"+tag+".md"); err != nil {
+			return fmt.Errorf("failed to edit the release notes: %w", err)
+		}
+	}
+	notes = strings.TrimSpace(notes)
+	switch notes {
+	case "":
+		ui.Warn("Notes left empty; tag %s was not changed.", tag)
+		return nil
+	case current:
+		ui.Warn("Notes unchanged; tag %s was not changed.", tag)
+		return nil
+	}
+
+	fmt.Println()
+	ui.Success("📋 New release notes for %s:", tag)
+	fmt.Println(strings.Repeat("─", 60))
+	ui.Document(notes)
+	fmt.Println(strings.Repeat("─", 60))
+
+	if !flagYes {
+		fmt.Printf("\n⚡ Re-create tag %s with these notes? [y/N]: ", tag)
+		var input string
+		fmt.Scanln(&input)
+		if input = strings.ToLower(strings.TrimSpace(input)); input != "y" && input != "yes" {
+			ui.Warn("Tag left unchanged.")
+			return nil
+		}
+	}
+
+	if err := saveBackup("refs/tags/"+tag, "release edit-notes"); err != nil {
+		return err
+	}
+	// A signed tag stays signed, or --push would replace it with an
+	// unsigned one
+	if signed {
+		ui.Info("🔏 %s is signed; signing it again with your key", tag)
+	}
+	if err := git.ReplaceTag(tag, notes, signed); err != nil {
+		return err
+	}
+	ui.Success("\n✅ Tag %s re-created with the new notes!", tag)
+	printRecovery()
+
+	// Keep a release file from the original release in step
+	notesFile := "RELEASE-" + tag + ".md"
+	if _, err := os.Stat(notesFile); err == nil {
+		if err := os.WriteFile(notesFile, []byte(notes), 0644); err == nil {
+			ui.Info("📄 %s updated", notesFile)
+		}
+	}
+
+	if ednPush {
+		ui.Info("\n📤 Force-pushing tag to origin...")
+		if _, err := git.Run("push", "--force", "origin", "refs/tags/"+tag); err != nil {
+			return fmt.Errorf("failed to push tag: %w", err)
+		}
+		ui.Success("✅ Tag pushed to origin!")
+	}
+
+	if host != nil {
+		ui.Info("\n🌐 Updating the %s release...", host.Type)
+		link, err := host.UpdateRelease(forge.Release{Tag: tag, Name: tag, Notes: notes})
+		if err != nil {
+			return fmt.Errorf("could not update the release: %w", err)
+		}
+		ui.Success("✅ Release updated: %s", link)
+	}
+	return nil
+}
+
+// regenerateNotes writes new notes from the commits between the tag and the
+// one before it
+func regenerateNotes(cfg *config.Config, tag string) (string, error) {
+	if err := ensureProvider(cfg); err != nil {
+		return "", err
+	}
+	previous := git.PreviousTag(tag, tagPattern(cfg))
+	commits, err := git.CommitsBetween(previous, tag)
+	if err != nil {
+		return "", err
+	}
+	client := newClient(cfg)
+	ui.Info("✨ Generating release notes for %s (%d commits since %s) with %s...", tag, len(commits), ifEmpty(previous, "the start"), client.ProviderName())
+	notes, err := client.GenerateReleaseNotes(commits, previous, tag, ai.ReleaseOptions{Audience: ednAudience, PreviousNotes: previousNotes(previous)})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	return notes, nil
+}
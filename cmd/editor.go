@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/rebase"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	seqReword      []string
+	seqRewordAll   bool
+	seqDrop        []string
+	seqCheckpoints bool
+	msgAmend       bool
+)
+
+var editorCmd = &cobra.Command{
+	Use:   "editor",
+	Short: "Act as git's editors for scripted rebases",
+	Long: `Act as git's editors for scripted rebases.
+
+Set GIT_SEQUENCE_EDITOR to "commitai editor sequence <actions>" to edit the
+todo list of git rebase -i, and GIT_EDITOR to "commitai editor message" to
+write the messages of the commits it rewords or squashes. Both never prompt:
+stdin is not read, stdout stays empty and every message goes to stderr.`,
+}
+
+var editorSequenceCmd = &cobra.Command{
+	Use:   "sequence <todo-file>",
+	Short: "Edit a rebase todo list, as GIT_SEQUENCE_EDITOR",
+	Long: `Edit a rebase todo list, as GIT_SEQUENCE_EDITOR.
+
+The pick lines of the todo list are changed as the flags say; every other
+line (exec, label, merge, comments) is kept as is. Commits are given as any
+revision git understands and must be in the todo list. When nothing can be
+done the command fails, which makes git abort the rebase before anything is
+rewritten.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runEditorSequence,
+	SilenceUsage: true,
+}
+
+var editorMessageCmd = &cobra.Command{
+	Use:   "message <message-file>",
+	Short: "Write a generated commit message, as GIT_EDITOR",
+	Long: `Write a generated commit message, as GIT_EDITOR.
+
+The message is generated from the commit's diff and written above git's
+comment lines; the previous message, or the messages a squash combines, are
+replaced. During a rebase the diff is that of the commit being reworded or
+squashed (HEAD^ to the index); otherwise it is the staged changes, or with
+--amend the whole commit being amended. Files other than COMMIT_EDITMSG
+(tag and merge messages) are left alone. If no message can be generated, a
+warning is printed and the message is kept, so the rebase goes on.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runEditorMessage,
+	SilenceUsage: true,
+}
+
+func init() {
+	editorSequenceCmd.Flags().StringSliceVar(&seqReword, "reword", nil, "Reword these commits (repeatable or comma-separated)")
+	editorSequenceCmd.Flags().BoolVar(&seqRewordAll, "reword-all", false, "Reword every picked commit")
+	editorSequenceCmd.Flags().StringSliceVar(&seqDrop, "drop", nil, "Drop these commits (repeatable or comma-separated)")
+	editorSequenceCmd.Flags().BoolVar(&seqCheckpoints, "squash-checkpoints", false, "Squash each run of commitai checkpoints into one commit to reword")
+	editorMessageCmd.Flags().BoolVar(&msgAmend, "amend", false, "Describe the whole commit being amended, outside a rebase too")
+
+	editorCmd.AddCommand(editorSequenceCmd)
+	editorCmd.AddCommand(editorMessageCmd)
+}
+
+// editorMode sets up a run from git: nothing on stdout, no prompts
+func editorMode() {
+	ui, _ = render.New(render.Plain, os.Stderr)
+	flagYes = true
+}
+
+func runEditorSequence(cmd *cobra.Command, args []string) error {
+	editorMode()
+	if len(seqReword) == 0 && !seqRewordAll && len(seqDrop) == 0 && !seqCheckpoints {
+		return fmt.Errorf("nothing to do: use --reword, --reword-all, --drop or --squash-checkpoints")
+	}
+
+	file := args[0]
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	todo := rebase.Parse(string(data), git.CommentChar())
+
+	reword, err := todoLines(todo, seqReword)
+	if err != nil {
+		return err
+	}
+	drop, err := todoLines(todo, seqDrop)
+	if err != nil {
+		return err
+	}
+	for i := range reword {
+		if drop[i] {
+			return fmt.Errorf("%s is both reworded and dropped", todo.Lines[i].Hash)
+		}
+	}
+
+	changed := 0
+	set := func(i int, command string) {
+		if todo.Lines[i].Command != command {
+			todo.Lines[i].Command = command
+			changed++
+		}
+	}
+	for i, l := range todo.Lines {
+		switch {
+		case drop[i]:
+			set(i, "drop")
+		case l.Command == "pick" && (seqRewordAll || reword[i]):
+			set(i, "reword")
+		}
+	}
+	if seqCheckpoints {
+		squashCheckpoints(todo, set)
+	}
+
+	if changed == 0 {
+		ui.Muted("commitai: todo list unchanged")
+		return nil
+	}
+	if err := writeFileAtomic(file, []byte(todo.String())); err != nil {
+		return err
+	}
+	ui.Muted("commitai: %d todo line(s) changed", changed)
+	return nil
+}
+
+// todoLines returns the indexes of the todo lines of the given revisions,
+// failing on one that isn't in the todo list
+func todoLines(todo rebase.Todo, revs []string) (map[int]bool, error) {
+	found := make(map[int]bool)
+	for _, rev := range revs {
+		rev = strings.TrimSpace(rev)
+		if rev == "" {
+			continue
+		}
+		hash, err := git.ResolveCommit(rev)
+		if err != nil {
+			return nil, err
+		}
+		match := -1
+		for i, l := range todo.Lines {
+			if l.Matches(hash) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			return nil, fmt.Errorf("%s (%s) is not in the rebase todo list", rev, shortSHA(hash))
+		}
+		found[match] = true
+	}
+	return found, nil
+}
+
+// squashCheckpoints turns each run of picked checkpoint commits into one
+// commit: a lone checkpoint is reworded, a longer run picks the first and
+// squashes the rest into it, so git asks for the combined message
+func squashCheckpoints(todo rebase.Todo, set func(int, string)) {
+	var run []int
+	flush := func() {
+		switch len(run) {
+		case 0:
+		case 1:
+			set(run[0], "reword")
+		default:
+			set(run[0], "pick")
+			for _, i := range run[1:] {
+				set(i, "squash")
+			}
+		}
+		run = nil
+	}
+	for i, l := range todo.Lines {
+		if !l.HasCommit() {
+			if strings.TrimSpace(l.Raw) != "" && !strings.HasPrefix(strings.TrimSpace(l.Raw), git.CommentChar()) {
+				flush() // exec, label, merge... break the run
+			}
+			continue
+		}
+		hash, err := git.ResolveCommit(l.Hash)
+		if (l.Command == "pick" || l.Command == "reword") && err == nil && strings.HasPrefix(git.Subject(hash), checkpointPrefix) {
+			run = append(run, i)
+			continue
+		}
+		flush()
+	}
+	flush()
+}
+
+func runEditorMessage(cmd *cobra.Command, args []string) error {
+	editorMode()
+
+	file := args[0]
+	if filepath.Base(file) != "COMMIT_EDITMSG" {
+		return nil // A tag, merge or todo file: not ours to write
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	msg, err := editorMessage()
+	if err != nil {
+		ui.Warn("commitai: message kept: %s", err)
+		return nil
+	}
+	if msg == "" {
+		return nil
+	}
+
+	// Keep git's comments, and everything below the scissors line, but not
+	// the messages being replaced
+	comment := git.CommentChar()
+	var kept []string
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, comment+" ------------------------ >8") {
+			kept = append(kept, lines[i:]...)
+			break
+		}
+		if strings.HasPrefix(line, comment) {
+			kept = append(kept, line)
+		}
+	}
+	out := msg + "\n"
+	if len(kept) > 0 {
+		out += "\n" + strings.Join(kept, "\n")
+	}
+	return writeFileAtomic(file, []byte(out))
+}
+
+// editorMessage generates the message of the commit git is about to make
+func editorMessage() (string, error) {
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return "", err
+	}
+
+	var changes []git.FileChange
+	if git.RebaseInProgress() || msgAmend {
+		base := emptyTree
+		if parent, err := git.ResolveCommit("HEAD^"); err == nil {
+			base = parent
+		}
+		changes, err = git.StagedChangesSince(base)
+	} else {
+		changes, err = git.StagedChanges()
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(changes) == 0 {
+		return "", fmt.Errorf("the commit has no changes to describe")
+	}
+
+	addFileHistory(cfg, changes)
+	messages, err := commitService(cfg, newClient(cfg)).Generate(changes, false, nil)
+	if err != nil {
+		return "", err
+	}
+	msg := messages["__all__"]
+	if violations := checkMessage(cfg, msg); len(violations) > 0 {
+		reportViolations(violations)
+		return "", fmt.Errorf("the generated message violates policy")
+	}
+	return msg, nil
+}
+
+// writeFileAtomic replaces a file in one step, so git never reads it half
+// written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".commitai-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
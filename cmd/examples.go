@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/help"
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples [topic]",
+	Short: "Print copy-pasteable recipes (CI, hooks, monorepo, ...)",
+	Long: `Print copy-pasteable recipes for a topic, or list the topics.
+
+Recipes combine several commands for a common job, like releasing from CI or
+wiring release hooks. Each command's own examples are in its --help.`,
+	Args:              cobra.MaximumNArgs(1),
+	RunE:              runExamples,
+	ValidArgsFunction: completeTopics,
+	SilenceUsage:      true,
+}
+
+func runExamples(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		ui.Info("📖 Recipes:")
+		for _, r := range help.Recipes() {
+			fmt.Printf("  %-12s %s\n", r.Topic, r.Title)
+		}
+		fmt.Println("\nPrint one with: commitai examples <topic>")
+		return nil
+	}
+	r, ok := help.Find(args[0])
+	if !ok {
+		return fmt.Errorf("unknown topic %q (expected %s)", args[0], strings.Join(topicNames(), ", "))
+	}
+	ui.Document(r.Markdown())
+	return nil
+}
+
+func completeTopics(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return topicNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func topicNames() []string {
+	var names []string
+	for _, r := range help.Recipes() {
+		names = append(names, r.Topic)
+	}
+	return names
+}
+
+// applyHelp fills every command's examples from the help package and points
+// its long help to the recipes that use it
+func applyHelp(c *cobra.Command) {
+	if examples := help.Examples(c.CommandPath()); len(examples) > 0 {
+		c.Example = help.Format(examples)
+	}
+	if topics := help.Topics(c.CommandPath()); len(topics) > 0 && c.Long != "" {
+		c.Long += "\n\nSee also: commitai examples " + strings.Join(topics, ", ")
+	}
+	for _, sub := range c.Commands() {
+		applyHelp(sub)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plan"
+)
+
+// unpushedBase returns where the branch leaves its upstream (or, without
+// one, the default branch), or "" when nothing tells which commits are the
+// branch's own
+func unpushedBase() string {
+	base := git.UpstreamBase()
+	if base == "" {
+		if def := git.DefaultBranch(); def != "" {
+			base, _ = git.MergeBase(def, "HEAD")
+		}
+	}
+	return base
+}
+
+// unpushedCommits returns the non-merge commits of the branch not on its
+// upstream (or, without one, the default branch), newest last
+func unpushedCommits() []git.CommitInfo {
+	base := unpushedBase()
+	if base == "" {
+		return nil // Nothing tells which commits are the branch's own
+	}
+	commits, err := git.RangeCommits(base, "HEAD")
+	if err != nil {
+		return nil
+	}
+	var own []git.CommitInfo
+	for _, c := range commits {
+		if len(c.Parents) <= 1 && !isAutosquashSubject(c.Subject) {
+			own = append(own, c)
+		}
+	}
+	return own
+}
+
+// isAutosquashSubject reports a commit that is itself folded into another
+func isAutosquashSubject(subject string) bool {
+	for _, prefix := range []string{"fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFixups picks out the staged files that clearly belong to an earlier
+// unpushed commit: every line they change or insert next to comes from
+// that one commit. Those become fixup! commits of it; the rest is returned
+// to get messages of their own. Manifests and lockfiles committed together
+// are never split up this way.
+func splitFixups(changes []git.FileChange, lockfiles map[string]bool) (fixups []plan.Commit, rest []git.FileChange) {
+	unpushed := make(map[string]git.CommitInfo)
+	for _, c := range unpushedCommits() {
+		unpushed[c.Hash] = c
+	}
+	for _, c := range changes {
+		if len(c.Lockfiles) > 0 || lockfiles[c.Path] {
+			rest = append(rest, c)
+			continue
+		}
+		if target, ok := fixupTarget(c, unpushed); ok {
+			fixups = append(fixups, plan.Commit{Name: c.Path, Files: []string{c.Path}, Message: "fixup! " + target.Subject, Fixup: target.Hash})
+			continue
+		}
+		rest = append(rest, c)
+	}
+	return fixups, rest
+}
+
+// fixupTarget returns the unpushed commit all the lines a modified file
+// touches come from
+func fixupTarget(c git.FileChange, unpushed map[string]git.CommitInfo) (git.CommitInfo, bool) {
+	if len(unpushed) == 0 || !strings.HasPrefix(c.Status, "M") {
+		return git.CommitInfo{}, false
+	}
+	owners, err := git.BlameCommits(c)
+	if err != nil || len(owners) != 1 {
+		return git.CommitInfo{}, false
+	}
+	for hash := range owners {
+		target, ok := unpushed[hash]
+		return target, ok
+	}
+	return git.CommitInfo{}, false
+}
+
+// offerAutosquash asks to fold the fixup! commits just created into the
+// commits they fix, with git rebase -i --autosquash
+func offerAutosquash(plans []plan.Commit, skipConfirm bool) error {
+	var targets []string
+	for _, p := range plans {
+		if p.Fixup != "" {
+			targets = append(targets, p.Fixup)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// The rebase starts below the oldest commit fixed up
+	oldest := targets[0]
+	for _, t := range targets[1:] {
+		if git.IsAncestor(t, oldest) {
+			oldest = t
+		}
+	}
+	base, err := git.ResolveCommit(oldest + "^")
+	if err != nil {
+		base = "" // A root commit: rebase the whole history
+	}
+	rebase := "git rebase -i --autosquash " + ifEmpty(shortSHA(base), "--root")
+
+	// The rebase is only offered on a branch, which can be backed up first
+	branch, _ := git.CurrentBranch()
+	if skipConfirm || !stdinIsTerminal() || branch == "" || branch == "HEAD" {
+		ui.Info("\n🧹 %d fixup commit(s) created; fold them in with: %s", len(targets), rebase)
+		return nil
+	}
+	fmt.Printf("\n🧹 Fold the %d fixup commit(s) into their commits now (%s)? [y/N]: ", len(targets), rebase)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if input = strings.TrimSpace(strings.ToLower(input)); input != "y" && input != "yes" {
+		ui.Muted("   Later, run: %s", rebase)
+		return nil
+	}
+	if err := saveBackup("refs/heads/"+branch, "autosquash"); err != nil {
+		return err
+	}
+	if err := git.Autosquash(base); err != nil {
+		if git.RebaseInProgress() {
+			return fmt.Errorf("%w\nresolve the conflicts and run git rebase --continue, or git rebase --abort", err)
+		}
+		return err
+	}
+	ui.Success("✅ Fixups folded into their commits")
+	printRecovery()
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	hoBase   string
+	hoOutput string
+)
+
+var handoffCmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "Summarize uncommitted and unpushed work for a teammate taking over the branch",
+	Long: `Summarize uncommitted and unpushed work for a teammate taking over the branch.
+
+Everything only this clone has goes into a markdown handoff note: commits
+not on the branch's upstream (or, without one, the default branch or
+--base), staged and unstaged changes, and untracked files. The AI writes
+what the work is for, what is done, what looks half done, the next steps
+and what to watch out for; an inventory of the commits and files is
+appended.
+
+Files matching banned_paths are listed but their content is never sent.`,
+	RunE:         runHandoff,
+	SilenceUsage: true,
+}
+
+func init() {
+	handoffCmd.Flags().StringVar(&hoBase, "base", "", "Count the commits since this branch as local-only (default: the upstream, else the default branch)")
+	handoffCmd.Flags().StringVarP(&hoOutput, "output", "o", "", "Write the note to this file instead of printing it")
+	handoffCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runHandoff(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+
+	w, err := collectWork(cfg)
+	if err != nil {
+		return err
+	}
+	if w.Empty() {
+		ui.Success("✅ Nothing to hand over: %s has no local-only commits and the working tree is clean.", w.Branch)
+		return nil
+	}
+
+	client := newClient(cfg)
+	ui.Info("🤝 Writing the handoff note for %s with %s...", w.Branch, client.ProviderName())
+	note, err := client.GenerateHandoff(w)
+	if err != nil {
+		return fmt.Errorf("failed to generate the handoff note: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Handoff: %s\n\n", w.Branch)
+	sb.WriteString("_")
+	if head, err := git.HeadCommit(); err == nil {
+		fmt.Fprintf(&sb, "HEAD at %s, ", shortSHA(head))
+	}
+	fmt.Fprintf(&sb, "written %s._\n\n", time.Now().Format("2006-01-02 15:04"))
+	sb.WriteString(note + "\n\n")
+	sb.WriteString(handoffInventory(cfg, w))
+
+	if hoOutput == "" {
+		fmt.Println()
+		ui.Document(sb.String())
+		return nil
+	}
+	if err := os.WriteFile(hoOutput, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	ui.Success("✅ Handoff note written to %s", hoOutput)
+	return nil
+}
+
+// collectWork gathers the work of the branch that isn't pushed. The diffs of
+// banned paths are left out.
+func collectWork(cfg *config.Config) (ai.WorkInProgress, error) {
+	w := ai.WorkInProgress{Upstream: git.Upstream()}
+	w.Branch, _ = git.CurrentBranch()
+	if w.Branch == "" || w.Branch == "HEAD" {
+		w.Branch = "detached HEAD"
+	}
+
+	base := ""
+	if hoBase != "" {
+		var err error
+		if base, err = git.MergeBase(hoBase, "HEAD"); err != nil {
+			return w, err
+		}
+	} else if _, err := git.HeadCommit(); err == nil {
+		base = unpushedBase()
+	}
+	if base != "" {
+		commits, err := git.BranchCommits(base, "HEAD")
+		if err != nil {
+			return w, err
+		}
+		w.Commits = commits
+		if len(commits) > 0 {
+			if w.Committed, err = git.ChangesBetween(base, "HEAD"); err != nil {
+				return w, err
+			}
+		}
+	}
+
+	// StagedChanges errors when nothing is staged; that's fine here
+	w.Staged, _ = git.StagedChanges()
+	unstaged, err := git.UnstagedChanges()
+	if err != nil {
+		return w, err
+	}
+	w.Unstaged = unstaged
+	_, untracked, err := git.WorkingTree()
+	if err != nil {
+		return w, err
+	}
+	if w.Untracked, err = git.UntrackedChanges(untracked); err != nil {
+		return w, err
+	}
+
+	for _, changes := range [][]git.FileChange{w.Committed, w.Staged, w.Unstaged, w.Untracked} {
+		for i := range changes {
+			if _, banned := cfg.BannedPattern(changes[i].Path); banned {
+				changes[i].Diff, changes[i].DiffLeftOut = "", true
+			}
+		}
+	}
+	return w, nil
+}
+
+// handoffInventory lists the commits and files of the note
+func handoffInventory(cfg *config.Config, w ai.WorkInProgress) string {
+	var sb strings.Builder
+	sb.WriteString("## Inventory\n")
+	if len(w.Commits) > 0 {
+		where := "never pushed"
+		if w.Upstream != "" {
+			where = "not on " + w.Upstream
+		}
+		fmt.Fprintf(&sb, "\n### Local-only commits (%d, %s)\n\n", len(w.Commits), where)
+		for _, c := range w.Commits {
+			fmt.Fprintf(&sb, "- `%s` %s\n", shortSHA(c.Hash), c.Subject)
+		}
+	}
+	for _, set := range []struct {
+		title   string
+		changes []git.FileChange
+	}{
+		{"Staged", w.Staged},
+		{"Unstaged", w.Unstaged},
+		{"Untracked", w.Untracked},
+	} {
+		if len(set.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s (%d)\n\n", set.title, len(set.changes))
+		for _, c := range set.changes {
+			fmt.Fprintf(&sb, "- `%s`", c.Path)
+			if set.title != "Untracked" {
+				sb.WriteString(" " + changeWord(c.Status))
+			}
+			if pattern, banned := cfg.BannedPattern(c.Path); banned {
+				fmt.Fprintf(&sb, " — banned path (%s), not to be committed", pattern)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// changeWord names a file status in prose
+func changeWord(status string) string {
+	switch {
+	case strings.HasPrefix(status, "A"):
+		return "added"
+	case strings.HasPrefix(status, "D"):
+		return "deleted"
+	case strings.HasPrefix(status, "R"):
+		return "renamed"
+	case strings.HasPrefix(status, "T"):
+		return "type changed"
+	default:
+		return "modified"
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var hookCheck bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Run commitai from git hooks",
+	Long: `Run commitai from git hooks.
+
+These commands are for the pre-commit framework (see .pre-commit-hooks.yaml)
+and hand-written hook scripts. They never prompt: stdin is not read, stdout
+stays empty and every message goes to stderr.`,
+}
+
+var hookPreCommitMsgCmd = &cobra.Command{
+	Use:   "pre-commit-msg <message-file> [source] [sha]",
+	Short: "Write the commit message from a prepare-commit-msg hook, or check it from commit-msg",
+	Long: `Write the commit message from a prepare-commit-msg hook, or check it from
+commit-msg.
+
+As a prepare-commit-msg hook, the message for the staged changes is generated
+and written to the message file, above git's comment lines, for the editor
+to open with. The file is left alone when the commit already has a message
+(-m, -F, -c, --amend, merges and squashes): the source is the second
+argument, or PRE_COMMIT_COMMIT_MSG_SOURCE under the pre-commit framework.
+If the message can't be generated, a warning is printed and the commit goes
+on as if the hook weren't there. Staged files matching banned_paths fail the
+hook either way, aborting the commit.
+
+With --check, as a commit-msg hook, the message in the file is checked
+against the commit policy (and commitlint, if turned on). The hook fails,
+aborting the commit, when it doesn't pass or banned_paths match a staged
+file. Merge, revert, fixup! and
+squash! messages written by git are not checked.`,
+	Args:         cobra.RangeArgs(1, 3),
+	RunE:         runHookPreCommitMsg,
+	SilenceUsage: true,
+}
+
+func init() {
+	hookPreCommitMsgCmd.Flags().BoolVar(&hookCheck, "check", false, "Check the message instead of writing it (commit-msg stage)")
+
+	hookCmd.AddCommand(hookPreCommitMsgCmd)
+}
+
+func runHookPreCommitMsg(cmd *cobra.Command, args []string) error {
+	// Hook output belongs on stderr, uncolored as it usually ends up in logs
+	ui, _ = render.New(render.Plain, os.Stderr)
+	flagYes = true // Never prompt, e.g. for an API key
+
+	file := args[0]
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if hookCheck {
+		return checkHookMessage(string(data))
+	}
+
+	source := os.Getenv("PRE_COMMIT_COMMIT_MSG_SOURCE")
+	if len(args) > 1 {
+		source = args[1]
+	}
+	if source != "merge" {
+		// The hook can't write the message if the config is broken, but
+		// that mustn't stop the commit
+		if cfg, err := config.Load(); err == nil {
+			if err := checkBannedStaged(cfg); err != nil {
+				return err
+			}
+		}
+	}
+	switch source {
+	case "message", "merge", "squash", "commit":
+		return nil
+	}
+	comment := git.CommentChar()
+	if source != "template" && message.StripComments(string(data), comment) != "" {
+		return nil
+	}
+
+	msg, err := hookMessage()
+	if err != nil {
+		ui.Warn("commitai: no message generated: %s", err)
+		return nil
+	}
+	if msg == "" {
+		return nil
+	}
+
+	// Keep git's comments (status, scissors and the verbose diff) below it
+	var kept []string
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, comment) {
+			kept = lines[i:]
+			break
+		}
+	}
+	out := msg + "\n"
+	if len(kept) > 0 {
+		out += "\n" + strings.Join(kept, "\n")
+	}
+	return os.WriteFile(file, []byte(out), 0o644)
+}
+
+// hookMessage generates the message for the staged changes, or "" when
+// nothing is staged
+func hookMessage() (string, error) {
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return "", err
+	}
+	changes, err := git.StagedChanges()
+	if err != nil || len(changes) == 0 {
+		return "", err
+	}
+	addFileHistory(cfg, changes)
+	messages, err := commitService(cfg, newClient(cfg)).Generate(changes, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return messages["__all__"], nil
+}
+
+// checkHookMessage checks the message git is about to record
+func checkHookMessage(text string) error {
+	msg := message.StripComments(text, git.CommentChar())
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	// What a merge brings in was committed on the other branch
+	if !strings.HasPrefix(msg, "Merge ") {
+		if err := checkBannedStaged(cfg); err != nil {
+			return err
+		}
+	}
+	if msg == "" || writtenByGit(msg) {
+		return nil
+	}
+	if err := applyPolicy(cfg); err != nil {
+		return err
+	}
+	violations := checkMessage(cfg, msg)
+	if len(violations) == 0 {
+		return nil
+	}
+	reportViolations(violations)
+	return fmt.Errorf("commit message does not follow the commit policy")
+}
+
+// writtenByGit reports whether git wrote the message, for a merge, revert
+// or a commit to be autosquashed
+func writtenByGit(msg string) bool {
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+// commentIssues posts the message and diffstat of every commit made since
+// before on the issues it references, with --comment-issues: #N and the
+// branch's issue numbers on the forge of origin, PROJ-123 keys on the
+// configured Jira site. Failures only warn: the commits are made at this
+// point.
+func commentIssues(cfg *config.Config, before string) {
+	if !flagComment || flagDryRun {
+		return
+	}
+	if head, _ := git.HeadCommit(); head == before {
+		return
+	}
+	commits, err := git.RangeCommits(before, "HEAD")
+	if err != nil {
+		ui.Warn("⚠️  Could not comment on issues: %s", err)
+		return
+	}
+	branch, _ := git.CurrentBranch()
+
+	var host *forge.Forge
+	var hostErr error
+	jira, jiraErr := forge.NewJira(cfg), error(nil)
+	if jira == nil {
+		jiraErr = fmt.Errorf("no Jira site. Run: commitai config --jira-url <url>")
+	} else {
+		jiraErr = jira.CheckAllowed(cfg)
+	}
+	for _, c := range commits {
+		msg := strings.TrimSpace(c.Subject + "\n\n" + c.Body)
+		issues := mergeRefs(message.IssuesFromBranch(branch), message.IssueRefs(msg))
+		keys := mergeRefs(message.JiraKeys(branch), message.JiraKeys(msg))
+		if len(issues) == 0 && len(keys) == 0 {
+			continue
+		}
+		stat, _ := git.DiffStat(c.Hash)
+
+		if len(issues) > 0 && host == nil && hostErr == nil {
+			if host, hostErr = originForge(cfg); hostErr == nil {
+				hostErr = host.CheckAllowed(cfg)
+			}
+		}
+		for _, n := range issues {
+			err := hostErr
+			if err == nil {
+				err = host.CommentOnIssue(n, issueComment(c, branch, msg, stat))
+			}
+			if err != nil {
+				ui.Warn("⚠️  Could not comment on #%s: %s", n, err)
+			} else {
+				ui.Success("💬 Commented on #%s", n)
+			}
+		}
+		for _, k := range keys {
+			err := jiraErr
+			if err == nil {
+				err = jira.Comment(k, jiraComment(c, branch, msg, stat))
+			}
+			if err != nil {
+				ui.Warn("⚠️  Could not comment on %s: %s", k, err)
+			} else {
+				ui.Success("💬 Commented on %s", k)
+			}
+		}
+	}
+}
+
+// mergeRefs appends the references of b missing from a
+func mergeRefs(a, b []string) []string {
+	for _, ref := range b {
+		if !slices.Contains(a, ref) {
+			a = append(a, ref)
+		}
+	}
+	return a
+}
+
+// issueComment is the Markdown comment on a forge issue
+func issueComment(c git.CommitInfo, branch, msg, stat string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Committed %s on `%s`:\n\n```\n%s\n```\n", shortSHA(c.Hash), branch, msg)
+	if stat != "" {
+		fmt.Fprintf(&sb, "\n```\n%s\n```\n", stat)
+	}
+	return sb.String()
+}
+
+// jiraComment is issueComment in Jira's wiki markup
+func jiraComment(c git.CommitInfo, branch, msg, stat string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Committed %s on {{%s}}:\n{noformat}\n%s\n{noformat}\n", shortSHA(c.Hash), branch, msg)
+	if stat != "" {
+		fmt.Fprintf(&sb, "{noformat}\n%s\n{noformat}\n", stat)
+	}
+	return sb.String()
+}
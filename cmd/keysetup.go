@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/i18n"
+)
+
+// ensureProvider makes sure cfg can generate messages from this
+// repository: a provider is set up, then data sharing is agreed to
+func ensureProvider(cfg *config.Config) error {
+	if err := chooseProvider(cfg); err != nil {
+		return err
+	}
+	return ensureConsent(cfg)
+}
+
+// chooseProvider makes sure cfg has a usable provider. In local-only mode
+// the provider must be local and nothing is asked. Without an API key an
+// interactive user may enter one (validated, then saved) or fall back to the
+// offline rule-based provider for this run. Scripts (--yes or no terminal)
+// get the configuration error, so they fail instead of exiting silently.
+func chooseProvider(cfg *config.Config) error {
+	if cfg.LocalOnly {
+		return cfg.CheckLocalOnly()
+	}
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	if flagYes || !stdinIsTerminal() {
+		return fmt.Errorf("%w (or set \"provider\": \"offline\" for rule-based messages without AI)", err)
+	}
+
+	ui.Warn("⚠️  No Gemini API key configured.")
+	fmt.Printf(i18n.T("  [k] Enter a key now (checked, then saved to %s)")+"\n", tildePath(config.SavePath()))
+	fmt.Println(i18n.T("  [o] Use the offline rule-based generator for this run"))
+	fmt.Println(i18n.T("  [q] Quit"))
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(i18n.T("Choice [k/o/q]: "))
+		input, _ := reader.ReadString('\n')
+		switch strings.TrimSpace(strings.ToLower(input)) {
+		case "k", "key":
+			key, err := promptForKey(cfg, reader)
+			if err != nil {
+				ui.Error("✖ %s", err)
+				continue
+			}
+			cfg.GeminiAPIKey = key
+			return nil
+		case "o", "offline":
+			cfg.Provider = config.ProviderOffline
+			ui.Info("Using offline rules. Get a key at https://aistudio.google.com/app/apikey for AI messages.")
+			return nil
+		case "q", "quit", "":
+			return err
+		}
+	}
+}
+
+// promptForKey reads a key without echo, validates it and saves it to the
+// user config
+func promptForKey(cfg *config.Config, reader *bufio.Reader) (string, error) {
+	key := strings.TrimSpace(readSecret(reader, i18n.T("Gemini API key (https://aistudio.google.com/app/apikey): ")))
+	if key == "" {
+		return "", fmt.Errorf("no key entered")
+	}
+
+	ui.Info("🔑 Checking the key...")
+	models, err := ai.ValidateKey(cfg, key)
+	if err != nil {
+		return "", err
+	}
+	ui.Success("✅ Key works (%d models available)", len(models))
+
+	user, err := config.LoadUser()
+	if err != nil {
+		user = config.DefaultConfig()
+	}
+	user.GeminiAPIKey = key
+	if err := config.Save(user); err != nil {
+		ui.Warn("⚠️  Could not save the key: %s", err)
+	} else {
+		ui.Success("✅ API key saved")
+	}
+	return key, nil
+}
+
+// checkKey validates a key and reports the models it can use. A key Gemini
+// rejects is an error; an unreachable API only warns, the key may be fine.
+func checkKey(cfg *config.Config, key, model string) error {
+	if cfg.LocalOnly {
+		ui.Warn("⚠️  Local-only mode: the key was saved without checking it")
+		return nil
+	}
+	ui.Info("🔑 Checking the key...")
+	models, err := ai.ValidateKey(cfg, key)
+	if errors.Is(err, ai.ErrKeyRejected) {
+		return fmt.Errorf("%w; nothing was saved (use --skip-check to save it anyway)", err)
+	}
+	if err != nil {
+		ui.Warn("⚠️  Could not check the key: %s", err)
+		return nil
+	}
+
+	ui.Success("✅ Key works: %d models available", len(models))
+	for _, m := range models {
+		fmt.Printf("   • %s\n", m)
+	}
+	if model != "" && !containsString(models, model) {
+		ui.Warn("⚠️  The configured model %s is not available with this key. Run: commitai config --model <one of the above>", model)
+	}
+	return nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// readSecret reads a line with terminal echo turned off where possible
+func readSecret(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	if runtime.GOOS != "windows" && stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Println()
+		}()
+	}
+	line, _ := reader.ReadString('\n')
+	return line
+}
+
+func stty(arg string) error {
+	c := exec.Command("stty", arg)
+	c.Stdin = os.Stdin
+	return c.Run()
+}
+
+// stdinIsTerminal reports whether a user can answer prompts. /dev/null is a
+// character device too, so on Unix stty has to confirm it is a terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return runtime.GOOS == "windows" || stty("-g") == nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reportFlagged warns about messages that still contain profanity,
+// placeholders or prompt text after the model was asked again. They are
+// never committed unreviewed.
+func reportFlagged(flagged map[string][]string, unattended bool) error {
+	if len(flagged) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(flagged))
+	for k := range flagged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := k
+		if k == "__all__" {
+			name = "the message"
+		}
+		ui.Warn("⚠️  Check %s: %s", name, strings.Join(flagged[k], "; "))
+	}
+	if unattended {
+		return fmt.Errorf("%d generated message(s) need review; run again without --yes", len(flagged))
+	}
+	return nil
+}
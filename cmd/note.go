@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	noteRef     string
+	noteAppend  bool
+	noteReplace bool
+	noteDryRun  bool
+	noteYes     bool
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note [commit]",
+	Short: "Attach an AI-written explanation of a commit as a git note",
+	Long: `Attach an AI-written explanation of a commit as a git note.
+
+Tricky commits deserve more context than a message should carry: why the
+change was needed, how it works, subtle points and what could break. The
+note is attached to the commit with git notes, so the message stays short
+and the commit hash doesn't change. It shows up in git log and git show.
+
+Notes are not pushed by default; share them with:
+  git push origin refs/notes/commits`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runNote,
+	SilenceUsage: true,
+}
+
+func init() {
+	noteCmd.Flags().StringVar(&noteRef, "ref", "commits", "Notes ref (refs/notes/<ref>)")
+	noteCmd.Flags().BoolVar(&noteAppend, "append", false, "Append to an existing note")
+	noteCmd.Flags().BoolVar(&noteReplace, "replace", false, "Replace an existing note")
+	noteCmd.Flags().BoolVarP(&noteDryRun, "dry-run", "d", false, "Print the note without attaching it")
+	noteCmd.Flags().BoolVarP(&noteYes, "yes", "y", false, "Skip confirmation prompt")
+	noteCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if noteAppend && noteReplace {
+		return fmt.Errorf("--append and --replace can't be combined")
+	}
+	rev := "HEAD"
+	if len(args) == 1 {
+		rev = args[0]
+	}
+	commit, err := git.ShowCommit(rev)
+	if err != nil {
+		return err
+	}
+	existing := git.Note(noteRef, commit.Hash)
+	if existing != "" && !noteAppend && !noteReplace && !noteDryRun {
+		return fmt.Errorf("%s already has a note in refs/notes/%s; use --append or --replace", shortSHA(commit.Hash), noteRef)
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	diff, err := git.ShowDiff(commit.Hash)
+	if err != nil {
+		return err
+	}
+
+	client := newClient(cfg)
+	ui.Info("📝 Explaining %s %s with %s...", shortSHA(commit.Hash), commit.Subject, client.ProviderName())
+	note, err := client.GenerateNote(commit, diff)
+	if err != nil {
+		return fmt.Errorf("failed to generate note: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 60))
+	ui.Document(note)
+	fmt.Println(strings.Repeat("─", 60))
+	if noteDryRun {
+		ui.Warn("\n🔍 Dry run — no note was attached.")
+		return nil
+	}
+
+	note, ok := confirmOrEdit(note, noteYes, nil)
+	if !ok || note == "" {
+		ui.Warn("Skipped.")
+		return nil
+	}
+	if existing != "" && noteAppend {
+		note = existing + "\n\n" + note
+	}
+	if err := git.AddNote(noteRef, commit.Hash, note, existing != ""); err != nil {
+		return err
+	}
+	ui.Success("✅ Note attached to %s (refs/notes/%s)", shortSHA(commit.Hash), noteRef)
+	fmt.Printf("   Share it with: git push origin refs/notes/%s\n", noteRef)
+	return nil
+}
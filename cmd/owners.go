@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/owners"
+)
+
+var (
+	ownJSON    bool
+	ownAuthors int
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Suggest reviewers for the staged changes",
+	Long: `Suggest reviewers for the staged changes, without any API call.
+
+Owners come from the repository's CODEOWNERS file (.github/, the root or
+docs/). The authors of the lines the changes touch come from git blame, so
+people who know the code best are suggested even where CODEOWNERS is silent.
+Your own lines are not counted.`,
+	RunE:         runOwners,
+	SilenceUsage: true,
+}
+
+func init() {
+	ownersCmd.Flags().BoolVar(&ownJSON, "json", false, "Print the owners and suggestions as JSON")
+	ownersCmd.Flags().IntVar(&ownAuthors, "authors", 3, "Most blame authors to suggest")
+}
+
+// ownersReport is the --json output
+type ownersReport struct {
+	Codeowners string              `json:"codeowners,omitempty"` // CODEOWNERS file used
+	Files      []owners.FileOwners `json:"files"`
+	Reviewers  []owners.Reviewer   `json:"reviewers"`
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	changes, err := git.StagedChanges()
+	if err != nil {
+		return err
+	}
+	root, err := git.TopLevel()
+	if err != nil {
+		return err
+	}
+	co, err := owners.Load(root)
+	if err != nil {
+		return fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	report := ownersReport{Files: []owners.FileOwners{}}
+	if co != nil {
+		report.Codeowners = co.Path
+	}
+	for _, c := range changes {
+		f := owners.FileOwners{Path: c.Path}
+		f.Owners, f.Rule, _ = co.Owners(c.Path)
+		f.Authors, _ = git.BlameChanged(c) // Files new to HEAD have no authors
+		report.Files = append(report.Files, f)
+	}
+	report.Reviewers = owners.Suggest(report.Files, git.UserEmail(), ownAuthors)
+	if report.Reviewers == nil {
+		report.Reviewers = []owners.Reviewer{}
+	}
+
+	if ownJSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println()
+	if co == nil {
+		ui.Warn("📄 No CODEOWNERS file; suggestions come from git blame only")
+	} else {
+		ui.Info("📄 Using %s", co.Path)
+	}
+	fmt.Println()
+	ui.Info("👥 Staged files (%d):", len(report.Files))
+	for _, f := range report.Files {
+		fmt.Printf("  %s\n", f.Path)
+		switch {
+		case f.Rule != "" && len(f.Owners) == 0:
+			fmt.Printf("      owners:  none (%s)\n", f.Rule)
+		case f.Rule != "":
+			fmt.Printf("      owners:  %s (%s)\n", strings.Join(f.Owners, ", "), f.Rule)
+		}
+		if len(f.Authors) > 0 {
+			var names []string
+			for _, a := range f.Authors {
+				names = append(names, fmt.Sprintf("%s (%d)", a.Name, a.Lines))
+			}
+			fmt.Printf("      written: %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	fmt.Println()
+	if len(report.Reviewers) == 0 {
+		ui.Warn("💡 No reviewers to suggest: no CODEOWNERS match and no one else wrote the changed lines")
+		return nil
+	}
+	ui.Info("💡 Suggested reviewers:")
+	for _, r := range report.Reviewers {
+		if r.Source == owners.SourceCodeowners {
+			fmt.Printf("  %-32s owns %d file(s)\n", r.Name, r.Files)
+		} else {
+			fmt.Printf("  %-32s wrote %d changed line(s) in %d file(s)\n", r.Name, r.Lines, r.Files)
+		}
+	}
+	fmt.Println()
+	return nil
+}
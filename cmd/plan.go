@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plan"
+)
+
+var (
+	planDryRun bool
+	planYes    bool
+	planResume bool
+	planAbort  bool
+)
+
+var applyPlanCmd = &cobra.Command{
+	Use:   "apply-plan [plan.yaml]",
+	Short: "Create the commits of a plan written with --plan-out",
+	Long: `Create the commits of a plan written with --plan-out.
+
+Generate a plan, review or edit it (messages, which files go in which commit,
+drop commits), then apply it. No AI call is made when applying. Files are
+committed as they are in the working tree at that point; staged files no
+commit includes stay staged as they were.
+
+If a commit fails partway (a hook rejects it, a file can't be staged), the
+commits already created are kept and the progress is recorded. Fix the
+problem (or the plan), then continue with --resume.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runApplyPlan,
+	SilenceUsage: true, // A failed commit is not a usage error
+}
+
+func init() {
+	applyPlanCmd.Flags().BoolVarP(&planDryRun, "dry-run", "d", false, "Show the commits without creating them")
+	applyPlanCmd.Flags().BoolVarP(&planYes, "yes", "y", false, "Skip confirmation prompt")
+	applyPlanCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on a protected branch")
+	applyPlanCmd.Flags().BoolVar(&planResume, "resume", false, "Continue an interrupted plan from the commit that failed")
+	applyPlanCmd.Flags().BoolVar(&planAbort, "abort", false, "Forget an interrupted plan (commits already created are kept)")
+}
+
+// writePlan saves the generated messages as a plan instead of committing
+func writePlan(path string, changes []git.FileChange, groups []ai.ChangeGroup, granular bool, messages map[string]string, fixups []plan.Commit) error {
+	commits := planCommits(changes, groups, granular, messages, fixups)
+	for i, c := range commits {
+		if c.Message == "" {
+			ui.Warn("⚠️  No AI message for %s; a generic one was written, edit it before applying", c.Name)
+			commits[i].Message = fmt.Sprintf("chore: update %s", c.Name)
+		}
+	}
+	if flagBisectable && len(commits) > 1 {
+		commits = bisectablePlans(commits)
+	}
+
+	base, _ := git.HeadCommit() // Empty in a repository without commits
+	p := &plan.Plan{Base: base, Commits: commits}
+	if err := p.Save(path); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	ui.Success("\n📝 Plan with %d commit(s) written to %s", len(commits), path)
+	fmt.Printf("   Review or edit it, then run: commitai apply-plan %s\n", path)
+	return nil
+}
+
+// planCommits lays out the commits of the main flow after the fixups: one
+// per group, one per file with its lockfiles, or one for everything
+func planCommits(changes []git.FileChange, groups []ai.ChangeGroup, granular bool, messages map[string]string, fixups []plan.Commit) []plan.Commit {
+	commits := append([]plan.Commit(nil), fixups...)
+	switch {
+	case groups != nil:
+		for _, gr := range groups {
+			c := plan.Commit{Name: gr.Name, Message: messages[gr.Name]}
+			for _, ch := range gr.Changes {
+				c.Files = append(c.Files, ch.Path)
+			}
+			commits = append(commits, c)
+		}
+	case granular:
+		for _, ch := range changes {
+			commits = append(commits, plan.Commit{Name: ch.Path, Files: append([]string{ch.Path}, ch.Lockfiles...), Message: messages[ch.Path]})
+		}
+	default:
+		c := plan.Commit{Name: "all", Message: messages["__all__"]}
+		for _, ch := range changes {
+			c.Files = append(c.Files, ch.Path)
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func runApplyPlan(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	gitDir, err := git.Dir()
+	if err != nil {
+		return err
+	}
+	progress, err := plan.LoadProgress(gitDir)
+	if err != nil {
+		return err
+	}
+
+	if planAbort {
+		if progress == nil {
+			return fmt.Errorf("no interrupted plan to abort")
+		}
+		if err := plan.ClearProgress(gitDir); err != nil {
+			return err
+		}
+		ui.Success("✅ Forgot %s; the %d commit(s) already created are kept", progress.Path, len(progress.Names))
+		return nil
+	}
+
+	done := 0
+	var path string
+	switch {
+	case planResume:
+		if progress == nil {
+			return fmt.Errorf("no interrupted plan to resume")
+		}
+		path = progress.Path
+		if len(args) == 1 {
+			if abs, _ := filepath.Abs(args[0]); abs != progress.Path {
+				return fmt.Errorf("the interrupted plan is %s, not %s", progress.Path, args[0])
+			}
+		}
+		done = len(progress.Names)
+	case progress != nil:
+		return fmt.Errorf("%s was interrupted after %d of %d commit(s); continue with --resume or forget it with --abort",
+			progress.Path, len(progress.Names), progress.Total)
+	case len(args) == 0:
+		return fmt.Errorf("which plan? Usage: commitai apply-plan <plan.yaml>")
+	default:
+		if path, err = filepath.Abs(args[0]); err != nil {
+			return err
+		}
+	}
+
+	p, err := plan.Load(path)
+	if err != nil {
+		return err
+	}
+	if planResume {
+		// The plan may be fixed before resuming, but not in the part already applied
+		if len(p.Commits) < done {
+			return fmt.Errorf("%s now has %d commit(s) but %d were already created", path, len(p.Commits), done)
+		}
+		for i, name := range progress.Names {
+			if p.Commits[i].Name != name {
+				return fmt.Errorf("commit %d of %s was already created as %q but is now %q; don't edit applied commits",
+					i+1, path, name, p.Commits[i].Name)
+			}
+		}
+		if head, _ := git.HeadCommit(); head != progress.Head {
+			ui.Warn("⚠️  HEAD moved since the interrupted run (%s → %s)", shortSHA(progress.Head), shortSHA(head))
+		}
+	}
+
+	// Applying makes no AI call, so only the policy is needed
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := applyPolicy(cfg); err != nil {
+		return err
+	}
+	if !planDryRun {
+		if err := checkProtectedBranch(cfg, "commit"); err != nil {
+			return err
+		}
+	}
+
+	if head, _ := git.HeadCommit(); !planResume && p.Base != "" && head != p.Base {
+		ui.Warn("⚠️  HEAD moved since the plan was written (%s → %s)", shortSHA(p.Base), shortSHA(head))
+	}
+	if err := checkPlanFiles(p.Commits[done:]); err != nil {
+		return err
+	}
+
+	if planResume {
+		ui.Info("📋 Resuming %s at commit %d of %d", path, done+1, len(p.Commits))
+	} else {
+		ui.Info("📋 Applying %s (%d commit(s))", path, len(p.Commits))
+	}
+
+	var names []string
+	if planResume {
+		names = append(names, progress.Names...)
+	}
+	err = executePlans(cfg, p.Commits, done, planDryRun, planYes, nil, func(i int) error {
+		head, _ := git.HeadCommit()
+		names = append(names, p.Commits[i].Name)
+		pr := &plan.Progress{Path: path, Total: len(p.Commits), Names: names, Head: head}
+		return pr.Save(gitDir)
+	})
+	if errors.Is(err, errCommitCancelled) {
+		return nil // Nothing was committed by this run; any progress is kept
+	}
+	if err != nil {
+		if pr, _ := plan.LoadProgress(gitDir); pr != nil && len(pr.Names) == pr.Total {
+			// Every commit was created; only what comes after failed
+			if err2 := plan.ClearProgress(gitDir); err2 != nil {
+				ui.Warn("⚠️  %s", err2)
+			}
+		} else if pr != nil {
+			ui.Warn("\n⏸️  Stopped after %d of %d commit(s). Fix the problem, then run: commitai apply-plan --resume", len(pr.Names), pr.Total)
+		} else {
+			ui.Warn("\n⏸️  Nothing was committed. Fix the problem, then run: commitai apply-plan %s", path)
+		}
+		return err
+	}
+	if planDryRun {
+		return nil
+	}
+	return plan.ClearProgress(gitDir)
+}
+
+// checkPlanFiles makes sure every file of the commits still to create has
+// something to commit
+func checkPlanFiles(commits []plan.Commit) error {
+	staged, err := git.StagedPaths()
+	if err != nil {
+		return err
+	}
+	unstaged, untracked, err := git.WorkingTree()
+	if err != nil {
+		return err
+	}
+	changed := make(map[string]bool)
+	for _, path := range append(staged, untracked...) {
+		changed[path] = true
+	}
+	for _, c := range unstaged {
+		changed[c.Path] = true
+	}
+
+	planned := make(map[string]bool)
+	for _, c := range commits {
+		if c.Fixup != "" {
+			hash, err := git.ResolveCommit(c.Fixup)
+			if err != nil || !git.IsAncestor(hash, "HEAD") {
+				return fmt.Errorf("%q fixes up %s, which is not a commit of this branch", c.Name, c.Fixup)
+			}
+		}
+		for _, f := range c.Files {
+			if !changed[f] {
+				return fmt.Errorf("%s (in %q) has no changes to commit", f, c.Name)
+			}
+			planned[f] = true
+		}
+	}
+	for _, path := range staged {
+		if !planned[path] {
+			ui.Warn("⚠️  %s is staged but not in the plan; it stays staged, uncommitted", path)
+		}
+	}
+	return nil
+}
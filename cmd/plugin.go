@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/plugin"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List commitai-<name> plugins found on PATH",
+	Long: `List commitai-<name> plugins found on PATH.
+
+Any executable named commitai-<name> on PATH can be run as "commitai <name>".
+It receives a JSON context on stdin (protocol version, arguments, repository,
+branch, staged changes and non-secret config) and may print a JSON result:
+
+  {"output": "text to show", "commit_message": "feat: ...", "error": "..."}
+
+A commit_message is shown, checked against the policy and committed after
+confirmation. Output that is not JSON is printed as is.`,
+	RunE: runPlugins,
+}
+
+func runPlugins(cmd *cobra.Command, args []string) error {
+	names := plugin.List()
+	if len(names) == 0 {
+		ui.Warn("No plugins found. Put an executable named %s<name> on PATH.", plugin.Prefix)
+		return nil
+	}
+	ui.Info("🔌 Plugins:")
+	for _, n := range names {
+		path, _ := plugin.Lookup(n)
+		fmt.Printf("  %-20s %s\n", n, path)
+	}
+	return nil
+}
+
+// pluginFor returns the plugin executable for the command line when its
+// first argument is not a built-in command
+func pluginFor(args []string) (string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", false
+	}
+	if c, _, err := rootCmd.Find(args); err == nil && c != rootCmd {
+		return "", false
+	}
+	return plugin.Lookup(args[0])
+}
+
+// runPlugin runs a plugin subcommand and acts on its result
+func runPlugin(path string, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx := plugin.Context{
+		Protocol: plugin.ProtocolVersion,
+		Version:  Version,
+		Args:     args,
+		Staged:   []plugin.File{},
+		Config: plugin.Config{
+			Language:    cfg.Language,
+			CommitStyle: cfg.CommitStyle,
+			Model:       cfg.Model,
+			PrivacyMode: cfg.PrivacyMode,
+			LocalOnly:   cfg.LocalOnly,
+		},
+	}
+	if pol, err := json.Marshal(cfg.Policy); err == nil {
+		ctx.Config.Policy = pol
+	}
+	if git.IsGitRepo() {
+		ctx.RepoRoot, _ = git.TopLevel()
+		ctx.Branch, _ = git.CurrentBranch()
+		changes, _ := git.StagedChanges()
+		for _, c := range changes {
+			ctx.Staged = append(ctx.Staged, plugin.File{Path: c.Path, Status: c.Status, Diff: c.Diff})
+		}
+	}
+
+	res, err := plugin.Run(path, ctx)
+	if res != nil && res.Output != "" {
+		fmt.Print(res.Output)
+		if !strings.HasSuffix(res.Output, "\n") {
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.CommitMessage == "" {
+		return nil
+	}
+	if len(ctx.Staged) == 0 {
+		ui.Warn("Plugin proposed a commit message, but there are no staged changes.")
+		return nil
+	}
+	return handleSingleCommit(cfg, res.CommitMessage, nil, false, false)
+}
+
+// executePlugin dispatches to a plugin when the command line names one.
+// Returns false when the built-in commands should handle it.
+func executePlugin() (bool, error) {
+	path, ok := pluginFor(os.Args[1:])
+	if !ok {
+		return false, nil
+	}
+	err := runPlugin(path, os.Args[2:])
+	if err != nil {
+		ui.Error("Error: %s", err)
+	}
+	return true, err
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/policy"
+)
+
+var (
+	polKeyFile string
+	polOutput  string
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect and manage commit message policies",
+	Long: `Inspect and manage commit message policies.
+
+The effective policy is the local "policy" config merged with the
+organization bundle referenced by "policy_url" (if any). Organization
+settings win for allowed types/scopes, ticket pattern and language; word
+lists are combined and length limits take the stricter value. The rules of
+the repository's commitlint config are merged over both the same way.`,
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective commit message policy",
+	RunE:  runPolicyShow,
+}
+
+var policyKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an ed25519 key pair for signing policy bundles",
+	RunE:  runPolicyKeygen,
+}
+
+var policySignCmd = &cobra.Command{
+	Use:   "sign <policy.json>",
+	Short: "Wrap a policy file into a signed bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySign,
+}
+
+func init() {
+	policySignCmd.Flags().StringVar(&polKeyFile, "key-file", "", "File with the base64 ed25519 private key")
+	policySignCmd.Flags().StringVarP(&polOutput, "output", "o", "", "Write the bundle to a file instead of stdout")
+	policySignCmd.MarkFlagRequired("key-file")
+
+	policyCmd.AddCommand(policyShowCmd)
+	policyCmd.AddCommand(policyKeygenCmd)
+	policyCmd.AddCommand(policySignCmd)
+}
+
+func runPolicyShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	eff, err := policy.Resolve(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	ui.Info("📜 Effective commit policy:")
+	fmt.Println()
+
+	source := eff.Source
+	switch {
+	case eff.Source == "local":
+	case eff.Signed:
+		source += color.GreenString(" (signature verified)")
+	default:
+		source += color.YellowString(" (unsigned — set policy_public_key to require signatures)")
+	}
+	if eff.Cached {
+		source += " [cached]"
+	}
+	fmt.Printf("  Source:            %s\n", source)
+	if eff.FetchErr != nil {
+		ui.Warn("  ⚠️  Using cached bundle, refresh failed: %s", eff.FetchErr)
+	}
+
+	switch {
+	case eff.CommitlintErr != nil:
+		ui.Warn("  ⚠️  Ignoring the commitlint config: %s", eff.CommitlintErr)
+	case eff.Commitlint != nil:
+		fmt.Printf("  commitlint:        %s\n", eff.Commitlint.File)
+		if len(eff.Commitlint.Skipped) > 0 {
+			ui.Muted("                     not understood: %s", strings.Join(eff.Commitlint.Skipped, ", "))
+		}
+	}
+
+	p := eff.Policy
+	fmt.Printf("  Allowed types:     %s\n", listOrAny(p.AllowedTypes))
+	fmt.Printf("  Allowed scopes:    %s\n", listOrAny(p.AllowedScopes))
+	fmt.Printf("  Forbidden words:   %s\n", listOrNone(p.ForbiddenWords))
+	fmt.Printf("  Required patterns: %s\n", listOrNone(p.RequiredPatterns))
+	fmt.Printf("  Ticket pattern:    %s\n", ifEmpty(p.TicketPattern, "(none)"))
+	fmt.Printf("  Max subject:       %s\n", limitOrNone(p.MaxSubjectLength))
+	fmt.Printf("  Max body:          %s\n", limitOrNone(p.MaxBodyLength))
+	fmt.Printf("  Subject style:     %s\n", subjectStyle(p))
+	fmt.Printf("  Body sections:     %s\n", listOrNone(p.BodySections))
+	fmt.Printf("  Language:          %s\n", ifEmpty(p.Language, "(from config: "+cfg.Language+")"))
+	fmt.Println()
+	return nil
+}
+
+func runPolicyKeygen(cmd *cobra.Command, args []string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Public key (distribute via: commitai config --policy-public-key ...):")
+	fmt.Println(base64.StdEncoding.EncodeToString(pub))
+	fmt.Println()
+	fmt.Println("Private key (keep secret, used by 'commitai policy sign'):")
+	fmt.Println(base64.StdEncoding.EncodeToString(priv))
+	return nil
+}
+
+func runPolicySign(cmd *cobra.Command, args []string) error {
+	policyJSON, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	key, err := os.ReadFile(polKeyFile)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := policy.Sign(policyJSON, string(key))
+	if err != nil {
+		return err
+	}
+
+	if polOutput == "" {
+		fmt.Println(string(bundle))
+		return nil
+	}
+	if err := os.WriteFile(polOutput, append(bundle, '\n'), 0644); err != nil {
+		return err
+	}
+	ui.Success("✅ Signed bundle written to %s", polOutput)
+	return nil
+}
+
+func listOrAny(l []string) string {
+	if len(l) == 0 {
+		return "(any)"
+	}
+	return strings.Join(l, ", ")
+}
+
+func listOrNone(l []string) string {
+	if len(l) == 0 {
+		return "(none)"
+	}
+	return strings.Join(l, ", ")
+}
+
+func limitOrNone(n int) string {
+	if n == 0 {
+		return "(none)"
+	}
+	return fmt.Sprintf("%d chars", n)
+}
+
+// subjectStyle lists the subject style rules of the policy
+func subjectStyle(p config.Policy) string {
+	var rules []string
+	if p.Imperative {
+		rules = append(rules, "imperative")
+	}
+	switch p.SubjectCase {
+	case config.SubjectLower:
+		rules = append(rules, "lower-case start")
+	case config.SubjectSentence:
+		rules = append(rules, "capitalized start")
+	}
+	if p.NoTrailingPeriod {
+		rules = append(rules, "no trailing period")
+	}
+	return listOrNone(rules)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+var (
+	prBase    string
+	prDraft   bool
+	prDryRun  bool
+	prNoPush  bool
+	prRefresh bool
+	prTests   bool
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Open a pull request for the current branch with an AI description",
+	Long: `Open a pull request for the current branch with an AI description.
+
+The title and description are the branch's squash commit draft (see commitai
+squash), brought up to date first. The branch is pushed to origin, then the
+pull request is opened on the forge hosting origin: GitHub, GitLab (a merge
+request), Bitbucket Cloud, Bitbucket Server, Gitea, Forgejo or Azure DevOps.
+The token is read from GITHUB_TOKEN, GH_ENTERPRISE_TOKEN, GITLAB_TOKEN,
+BITBUCKET_TOKEN, GITEA_TOKEN or AZURE_DEVOPS_EXT_PAT, or the variable set
+with commitai config --forge-token-env.
+
+Azure Boards work items in the branch name (feature/AB#1234-login) are linked
+to the pull request on Azure DevOps.
+
+With --suggest-tests, test cases for the new functions no test covers yet
+are added to the description (see commitai suggest-tests).`,
+	RunE:         runPR,
+	SilenceUsage: true,
+}
+
+func init() {
+	prCmd.Flags().StringVar(&prBase, "base", "", "Branch to merge into (default: origin's HEAD, main or master)")
+	prCmd.Flags().BoolVar(&prDraft, "draft", false, "Open the pull request as a draft")
+	prCmd.Flags().BoolVarP(&prDryRun, "dry-run", "d", false, "Show the pull request without pushing or opening it")
+	prCmd.Flags().BoolVar(&prNoPush, "no-push", false, "Don't push the branch first")
+	prCmd.Flags().BoolVar(&prRefresh, "refresh", false, "Rewrite the description from scratch instead of updating the draft")
+	prCmd.Flags().BoolVar(&prTests, "suggest-tests", false, "Add suggested test cases for untested new functions to the description")
+	prCmd.Flags().BoolVar(&flagImpact, "impact", false, "End the description with an \"Impact: <area>; <risk> risk\" line for reviewers")
+	prCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Open the pull request without asking for confirmation")
+	prCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runPR(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if branch == "HEAD" {
+		return fmt.Errorf("HEAD is detached; check out the branch to open a pull request for")
+	}
+
+	base := prBase
+	if base == "" {
+		base = strings.TrimPrefix(git.DefaultBranch(), "origin/")
+	}
+	if base == "" {
+		return fmt.Errorf("can't tell which branch %s will be merged into; use --base", branch)
+	}
+	if base == branch {
+		return fmt.Errorf("%s is the base branch; check out the branch to open a pull request for", branch)
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	host, err := originForge(cfg)
+	if err != nil {
+		return err
+	}
+	if !prDryRun {
+		if err := host.CheckAllowed(cfg); err != nil {
+			return err
+		}
+		if host.Token == "" {
+			return fmt.Errorf("opening a pull request needs a %s token: set %s", host.Type, host.TokenIn)
+		}
+	}
+
+	store, err := loadSquashDrafts()
+	if err != nil {
+		return err
+	}
+	draft, _, err := refreshSquashDraft(cfg, store, branch, prBase, prRefresh)
+	if err != nil {
+		return err
+	}
+	pr := forge.PullRequest{Title: draft.Title(), Body: draft.Body(), Head: branch, Base: base, Draft: prDraft}
+	pr.WorkItems = message.WorkItemsFromBranch(branch)
+	if prTests {
+		if pr.Body, err = withSuggestedTests(cfg, pr.Body, base); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+	ui.Success("🔀 Pull request %s → %s on %s:", branch, base, host.Type)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println(pr.Title)
+	if pr.Body != "" {
+		fmt.Println()
+		fmt.Println(pr.Body)
+	}
+	fmt.Println(strings.Repeat("─", 60))
+	if len(pr.WorkItems) > 0 {
+		ui.Muted("Work items: AB#%s", strings.Join(pr.WorkItems, ", AB#"))
+	}
+
+	if prDryRun {
+		ui.Warn("\n🔍 Dry run — nothing was pushed or opened.")
+		return nil
+	}
+	if !flagYes {
+		fmt.Print(i18n.T("\n⚡ Open this pull request? [y/N]: "))
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !i18n.Yes(strings.TrimSpace(strings.ToLower(input))) {
+			ui.Warn("Pull request cancelled.")
+			return nil
+		}
+	}
+
+	if !prNoPush {
+		ui.Info("\n📤 Pushing %s to origin...", branch)
+		if _, err := git.Run("push", "--set-upstream", "origin", branch); err != nil {
+			return fmt.Errorf("failed to push %s: %w", branch, err)
+		}
+	}
+
+	link, err := host.CreatePullRequest(pr)
+	if err != nil {
+		return fmt.Errorf("failed to open the pull request: %w", err)
+	}
+	ui.Success("✅ Pull request opened: %s", ifEmpty(link, branch+" → "+base))
+	return nil
+}
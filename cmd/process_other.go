@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// processAlive reports whether p is still running. Only unix can tell
+// without side effects; elsewhere a found process counts as running.
+func processAlive(p *os.Process) bool {
+	return p != nil
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether p is still running
+func processAlive(p *os.Process) bool {
+	return p.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// flagForce overrides the protected branch check
+var flagForce bool
+
+// checkProtectedBranch stops (or warns, in warn mode) before committing or
+// tagging directly on a protected branch, nudging towards a feature branch.
+// action completes "refusing to ...", e.g. "commit".
+func checkProtectedBranch(cfg *config.Config, action string) error {
+	branch, err := git.CurrentBranch()
+	if err != nil || branch == "" || branch == "HEAD" {
+		return nil // Detached HEAD is not a branch
+	}
+	pattern, ok := cfg.ProtectedPattern(branch)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case flagForce:
+		ui.Warn("⚠️  %s is a protected branch (%s); continuing because of --force", branch, pattern)
+		return nil
+	case cfg.ProtectedBranchMode == config.ProtectWarn:
+		ui.Warn("⚠️  %s is a protected branch (%s); consider a feature branch: git switch -c <name>", branch, pattern)
+		return nil
+	}
+	return fmt.Errorf("refusing to %s on protected branch %s (matches %q); create a feature branch with git switch -c <name>, or use --force", action, branch, pattern)
+}
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// queueFile holds the queued generation inside the .git directory; its
+// background process logs next to it
+const (
+	queueFile = "commitai/queue.json"
+	queueLog  = "commitai/queue.log"
+)
+
+// queueRetries are the waits before the background process tries a failed
+// generation again, for connections that come and go
+var queueRetries = []time.Duration{15 * time.Second, time.Minute, 5 * time.Minute}
+
+const (
+	queuePending = "pending"
+	queueReady   = "ready"
+	queueFailed  = "failed"
+)
+
+// queueJob is a message generated in the background for a staged snapshot
+type queueJob struct {
+	Tree     string    `json:"tree"` // The index when queued, see git.IndexTree
+	Head     string    `json:"head,omitempty"`
+	Status   string    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Attempts int       `json:"attempts"`
+	PID      int       `json:"pid,omitempty"`
+	Queued   time.Time `json:"queued"`
+}
+
+var (
+	queueWait bool
+	queueYes  bool
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Generate the message for the staged changes in the background",
+	Long: `Generate the message for the staged changes in the background.
+
+commitai queue records what is staged and returns at once; a background
+process generates the message, trying again when the connection drops.
+Keep working, then commit the snapshot with 'commitai queue commit' once the
+message is ready. The staged changes must still be the ones queued.`,
+	Example: `  commitai queue
+  commitai queue status
+  commitai queue commit
+  commitai queue commit --wait`,
+	Args: cobra.NoArgs,
+	RunE: runQueue,
+
+	SilenceUsage: true,
+}
+
+var queueCommitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit the staged changes with the queued message",
+	Args:  cobra.NoArgs,
+	RunE:  runQueueCommit,
+
+	SilenceUsage: true,
+}
+
+var queueStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the queued message is ready",
+	Args:  cobra.NoArgs,
+	RunE:  runQueueStatus,
+
+	SilenceUsage: true,
+}
+
+var queueDropCmd = &cobra.Command{
+	Use:   "drop",
+	Short: "Forget the queued message",
+	Args:  cobra.NoArgs,
+	RunE:  runQueueDrop,
+
+	SilenceUsage: true,
+}
+
+// queueRunCmd is the background process
+var queueRunCmd = &cobra.Command{
+	Use:    "run",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runQueueWorker,
+}
+
+func init() {
+	queueCommitCmd.Flags().BoolVarP(&queueWait, "wait", "w", false, "Wait for the message instead of failing while it is generated")
+	queueCommitCmd.Flags().BoolVarP(&queueYes, "yes", "y", false, "Commit without asking for confirmation")
+	queueCommitCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on a protected branch")
+
+	queueCmd.AddCommand(queueCommitCmd)
+	queueCmd.AddCommand(queueStatusCmd)
+	queueCmd.AddCommand(queueDropCmd)
+	queueCmd.AddCommand(queueRunCmd)
+}
+
+func runQueue(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if err := readonly.Check("queueing a message"); err != nil {
+		return err
+	}
+	// Provider choice and consent are asked here, not in the background
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	paths, err := git.StagedPaths()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		ui.Warn("No staged changes found. Use 'git add' to stage files.")
+		return nil
+	}
+	if err := checkBannedStaged(cfg); err != nil {
+		return err
+	}
+
+	job := &queueJob{Status: queuePending, Queued: time.Now()}
+	if job.Tree, err = git.IndexTree(); err != nil {
+		return err
+	}
+	job.Head, _ = git.HeadCommit() // None before the first commit
+	if err := saveQueueJob(job); err != nil {
+		return err
+	}
+
+	pid, err := startQueueWorker()
+	if err != nil {
+		os.Remove(queuePath(queueFile))
+		return err
+	}
+	ui.Success("📨 Queued %d staged file(s); the message is generated in the background (PID %d).", len(paths), pid)
+	ui.Muted("   Keep working; run 'commitai queue commit' when it's ready.")
+	return nil
+}
+
+// startQueueWorker starts 'commitai queue run' detached from this process,
+// logging to queueLog
+func startQueueWorker() (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the commitai executable: %w", err)
+	}
+	log, err := os.OpenFile(queuePath(queueLog), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open the queue log: %w", err)
+	}
+	defer log.Close()
+
+	worker := exec.Command(exe, "queue", "run")
+	worker.Stdout, worker.Stderr = log, log
+	if err := worker.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start the background generation: %w", err)
+	}
+	pid := worker.Process.Pid
+	worker.Process.Release()
+	return pid, nil
+}
+
+// runQueueWorker generates the queued message, retrying after
+// queueRetries, and records the outcome in the job
+func runQueueWorker(cmd *cobra.Command, args []string) error {
+	job, err := loadQueueJob()
+	if err != nil {
+		return err
+	}
+	job.PID = os.Getpid()
+	if err := saveQueueJob(job); err != nil {
+		return err
+	}
+
+	// Only the provider call is worth retrying
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		job.Status, job.Error, job.PID = queueFailed, err.Error(), 0
+		return saveQueueJob(job)
+	}
+	msg, err := generateQueued(cfg, job)
+	for _, wait := range queueRetries {
+		if err == nil {
+			break
+		}
+		job.Attempts++
+		job.Error = err.Error()
+		ui.Warn("⚠️  Attempt %d failed: %s; trying again in %s", job.Attempts, err, wait)
+		if saveErr := saveQueueJob(job); saveErr != nil {
+			return saveErr
+		}
+		time.Sleep(wait)
+		if !queueCurrent(job) {
+			return nil // Dropped or queued again meanwhile
+		}
+		msg, err = generateQueued(cfg, job)
+	}
+	job.Attempts++
+
+	if !queueCurrent(job) {
+		return nil
+	}
+	if err != nil {
+		job.Status, job.Error = queueFailed, err.Error()
+	} else {
+		job.Status, job.Message, job.Error = queueReady, msg, ""
+	}
+	job.PID = 0
+	if err := saveQueueJob(job); err != nil {
+		return err
+	}
+	notifyAfter(cfg, job.Queued, "Queued commit message "+job.Status)
+	return nil
+}
+
+// generateQueued generates the message of the job's snapshot
+func generateQueued(cfg *config.Config, job *queueJob) (string, error) {
+	changes, err := git.ChangesBetween(ifEmpty(job.Head, emptyTree), job.Tree)
+	if err != nil {
+		return "", err
+	}
+	if len(changes) == 0 {
+		return "", fmt.Errorf("the queued snapshot has no changes")
+	}
+	addFileHistory(cfg, changes)
+	messages, err := commitService(cfg, newClient(cfg)).Generate(changes, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return messages["__all__"], nil
+}
+
+func runQueueCommit(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	job, err := loadQueueJob()
+	if err != nil {
+		return err
+	}
+	for queueWait && job.Status == queuePending {
+		if !queueWorkerAlive(job) {
+			break
+		}
+		time.Sleep(time.Second)
+		if job, err = loadQueueJob(); err != nil {
+			return err
+		}
+	}
+
+	switch job.Status {
+	case queuePending:
+		if !queueWorkerAlive(job) {
+			return fmt.Errorf("the background generation stopped without a message (see %s); run 'commitai queue' again", queuePath(queueLog))
+		}
+		if job.Error != "" {
+			return fmt.Errorf("the message isn't ready yet (attempt %d failed: %s); try again later or pass --wait", job.Attempts, job.Error)
+		}
+		return fmt.Errorf("the message isn't ready yet (queued %s ago); try again shortly or pass --wait", time.Since(job.Queued).Round(time.Second))
+	case queueFailed:
+		return fmt.Errorf("generating the queued message failed after %d attempt(s): %s; run 'commitai queue' again", job.Attempts, job.Error)
+	}
+
+	// The message describes the snapshot; anything else staged or
+	// committed since makes it stale
+	head, _ := git.HeadCommit()
+	if head != job.Head {
+		return fmt.Errorf("HEAD moved since the message was queued; run 'commitai queue' again")
+	}
+	tree, err := git.IndexTree()
+	if err != nil {
+		return err
+	}
+	if tree != job.Tree {
+		return fmt.Errorf("the staged changes changed since they were queued; run 'commitai queue' again")
+	}
+	suggestedIndex = job.Tree // Also while the prompt is open
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	if err := checkProtectedBranch(cfg, "commit"); err != nil {
+		return err
+	}
+	if err := handleSingleCommit(cfg, job.Message, nil, false, queueYes); err != nil {
+		return err
+	}
+	if head, _ := git.HeadCommit(); head != job.Head {
+		os.Remove(queuePath(queueFile)) // Committed, not cancelled
+	}
+	return nil
+}
+
+func runQueueStatus(cmd *cobra.Command, args []string) error {
+	job, err := loadQueueJob()
+	if err != nil {
+		return err
+	}
+	switch job.Status {
+	case queueReady:
+		ui.Success("✅ Ready (queued %s ago):", time.Since(job.Queued).Round(time.Second))
+		fmt.Println(job.Message)
+	case queueFailed:
+		ui.Error("❌ Failed after %d attempt(s): %s", job.Attempts, job.Error)
+	default:
+		if !queueWorkerAlive(job) {
+			ui.Error("❌ The background generation stopped; see %s", queuePath(queueLog))
+			return nil
+		}
+		ui.Info("⏳ Generating (queued %s ago)...", time.Since(job.Queued).Round(time.Second))
+		if job.Error != "" {
+			ui.Muted("   Attempt %d failed: %s", job.Attempts, job.Error)
+		}
+	}
+	return nil
+}
+
+func runQueueDrop(cmd *cobra.Command, args []string) error {
+	if _, err := loadQueueJob(); err != nil {
+		return err
+	}
+	if err := os.Remove(queuePath(queueFile)); err != nil {
+		return err
+	}
+	ui.Success("🗑️  Dropped the queued message.")
+	return nil
+}
+
+// queueWorkerAlive reports whether the job's background process may still
+// be running. A job of a worker that was killed stays pending forever.
+func queueWorkerAlive(job *queueJob) bool {
+	if job.PID == 0 {
+		return time.Since(job.Queued) < time.Minute // Not started yet
+	}
+	p, err := os.FindProcess(job.PID)
+	if err != nil {
+		return false
+	}
+	return processAlive(p)
+}
+
+// queueCurrent reports whether job is still the queued one
+func queueCurrent(job *queueJob) bool {
+	current, err := loadQueueJob()
+	return err == nil && current.Tree == job.Tree && current.Queued.Equal(job.Queued)
+}
+
+func queuePath(name string) string {
+	gitDir, err := git.Dir()
+	if err != nil {
+		gitDir = ".git"
+	}
+	return filepath.Join(gitDir, name)
+}
+
+func loadQueueJob() (*queueJob, error) {
+	data, err := os.ReadFile(queuePath(queueFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no message is queued; run 'commitai queue' first")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job queueJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", queueFile, err)
+	}
+	return &job, nil
+}
+
+func saveQueueJob(job *queueJob) error {
+	path := queuePath(queueFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
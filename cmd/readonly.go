@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+var flagReadOnly bool // Never change the repository or write files
+
+// readOnlyRefused are the commands that only change things. Read-only mode
+// refuses them up front, naming what would still be allowed.
+var readOnlyRefused = map[string]string{
+	"commitai checkpoint":          "it commits",
+	"commitai watch":               "it stages and commits",
+	"commitai release edit-notes":  "it re-creates the tag",
+	"commitai editor sequence":     "it edits git's rebase todo list",
+	"commitai editor message":      "it writes git's commit message file",
+	"commitai demo":                "it builds a sandbox repository",
+	"commitai restore-backup":      "it moves refs; --list is allowed",
+	"commitai squash":              "it stores the draft; --show is allowed",
+	"commitai hook pre-commit-msg": "it writes the message file; --check is allowed",
+	"commitai batch":               "--add stages every repository; without it, batch is allowed",
+}
+
+// readOnlyFileFlags are the flags naming a file a command writes; "-" for
+// stdout is fine
+var readOnlyFileFlags = map[string]string{
+	"commitai":                 "plan-out",
+	"commitai batch":           "report",
+	"commitai report":          "output",
+	"commitai policy sign":     "output",
+	"commitai ci lint-commits": "sarif",
+	"commitai handoff":         "output",
+}
+
+// applyReadOnly turns read-only mode on for cmd: through the environment,
+// so git calls and child commitai runs refuse to write, and up front,
+// turning --dry-run on and refusing commands and flags that can only write
+func applyReadOnly(cmd *cobra.Command) error {
+	// In agent mode, only approved commit plans change anything
+	if flagReadOnly || os.Getenv(config.EnvAgent) == "1" && cmd.HasParent() {
+		os.Setenv(readonly.Env, "1")
+	}
+	if !readonly.Enabled() {
+		return nil
+	}
+	cmd.SilenceUsage = true // The errors below aren't usage mistakes
+	path := cmd.CommandPath()
+	if why, ok := readOnlyRefused[path]; ok && !readOnlyAllowed(cmd) {
+		return fmt.Errorf("%s can't run in read-only mode: %s", path, why)
+	}
+	if name, ok := readOnlyFileFlags[path]; ok {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed && f.Value.String() != "-" {
+			return fmt.Errorf("--%s writes %s: %w", name, f.Value, readonly.ErrReadOnly)
+		}
+	}
+	if path == "commitai config" && configChanges(cmd) {
+		return fmt.Errorf("config changes are saved to the user config: %w", readonly.ErrReadOnly)
+	}
+	if f := cmd.Flags().Lookup("dry-run"); f != nil {
+		return cmd.Flags().Set("dry-run", "true")
+	}
+	return nil
+}
+
+// configChanges reports config flags other than --show, which all change
+// the user config
+func configChanges(cmd *cobra.Command) bool {
+	changes := false
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		changes = changes || f.Changed && f.Name != "show"
+	})
+	return changes
+}
+
+// readOnlyAllowed reports the read-only uses of the refused commands
+func readOnlyAllowed(cmd *cobra.Command) bool {
+	switch cmd.CommandPath() {
+	case "commitai restore-backup":
+		return rbList
+	case "commitai squash":
+		return sqShow
+	case "commitai hook pre-commit-msg":
+		return hookCheck
+	case "commitai batch":
+		return !batAdd
+	}
+	return false
+}
@@ -4,39 +4,70 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/announce"
+	"github.com/kaiqui/commitai/internal/app"
+	"github.com/kaiqui/commitai/internal/buildmeta"
 	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/deps"
+	"github.com/kaiqui/commitai/internal/forge"
 	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/hooks"
+	"github.com/kaiqui/commitai/internal/provenance"
+	"github.com/kaiqui/commitai/internal/render"
 )
 
+const migrationFile = "MIGRATION.md"
+
 var (
-	relMajor  bool
-	relMinor  bool
-	relPatch  bool
-	relAuto   bool
-	relTag    string
-	relDryRun bool
-	relPush   bool
+	relMajor   bool
+	relMinor   bool
+	relPatch   bool
+	relAuto    bool
+	relTag     string
+	relDryRun  bool
+	relPush    bool
+	relPublish bool
+
+	relAudience   string
+	relMigration  bool
+	relAnnounce   []string
+	relEmail      string
+	relSendmail   bool
+	relVerFiles   string
+	relNoDeps     bool
+	relNoDedupe   bool
+	relStats      bool
+	relCands      bool
+	relEdit       bool
+	relNoEdit     bool
+	relJSON       bool
+	relPR         bool
+	relFinalize   bool
+	relIfChanges  bool
+	relHotfix     bool
+	relBase       string
+	relProvenance bool
+	relSnippets   bool
+	relSnipTokens int
+
+	// flagTagPattern is the glob release tags match; see tagPattern
+	flagTagPattern string
 )
 
 var releaseCmd = &cobra.Command{
 	Use:   "release",
 	Short: "Create a tagged release with AI-generated release notes",
-	Long: `Create a tagged release with AI-generated release notes.
+	Long:  `Create a tagged release with AI-generated release notes.`,
+	RunE:  runRelease,
 
-Examples:
-  commitai release --auto          # AI suggests version bump
-  commitai release --major         # Bump major version (1.0.0 -> 2.0.0)
-  commitai release --minor         # Bump minor version (1.0.0 -> 1.1.0)
-  commitai release --patch         # Bump patch version (1.0.0 -> 1.0.1)
-  commitai release --tag v1.2.3    # Use specific tag
-  commitai release --auto --push   # Auto version + push tags`,
-	RunE: runRelease,
+	SilenceUsage: true,
 }
 
 func init() {
@@ -45,8 +76,34 @@ func init() {
 	releaseCmd.Flags().BoolVar(&relPatch, "patch", false, "Bump patch version")
 	releaseCmd.Flags().BoolVarP(&relAuto, "auto", "a", false, "Let AI suggest version bump")
 	releaseCmd.Flags().StringVar(&relTag, "tag", "", "Use specific tag (e.g. v1.2.3)")
-	releaseCmd.Flags().BoolVarP(&relDryRun, "dry-run", "d", false, "Preview without creating tag")
+	releaseCmd.Flags().BoolVarP(&relDryRun, "dry-run", "d", false, "Show the release plan (bump, files, tag, push, forge release) without changing anything")
+	releaseCmd.Flags().BoolVar(&relJSON, "json", false, "With --dry-run, print the release plan as JSON")
+	releaseCmd.Flags().BoolVar(&relPR, "pr", false, "Open a pull request with the changelog and version bump instead of tagging; tag it with --finalize once merged")
+	releaseCmd.Flags().BoolVar(&relFinalize, "finalize", false, "Tag the release of the merged --pr pull request, e.g. from CI")
+	releaseCmd.Flags().BoolVar(&relIfChanges, "if-changes", false, "For scheduled jobs: exit 0 without tagging when there are no commits since the last tag, otherwise release without prompts or editor")
+	releaseCmd.Flags().BoolVar(&relHotfix, "hotfix", false, "Patch release of a maintenance branch: bump the patch of --base and label the notes as a hotfix")
+	releaseCmd.Flags().StringVar(&relBase, "base", "", "With --hotfix, the release the maintenance branch continues, e.g. v1.4.2 (default: the nearest tag)")
+	releaseCmd.Flags().StringVar(&flagTagPattern, "tag-pattern", "", "Only tags matching this glob are releases, e.g. \"app-v*\"; new tags get its start (default from tag_pattern)")
 	releaseCmd.Flags().BoolVarP(&relPush, "push", "p", false, "Push tag to origin after creation")
+	releaseCmd.Flags().BoolVar(&relPublish, "publish", false, "Push the tag and create a release with the notes on the forge (GitHub, GitLab, Bitbucket, Gitea)")
+	releaseCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Create the tag without asking for confirmation")
+	releaseCmd.Flags().BoolVar(&flagForce, "force", false, "Tag even on a protected branch")
+	releaseCmd.Flags().StringVar(&relAudience, "audience", "", "Tailor notes for an audience (users, developers, internal)")
+	releaseCmd.Flags().BoolVar(&relCands, "candidates", false, "Generate terse and detailed notes and pick, section by section, which to keep")
+	releaseCmd.Flags().BoolVarP(&relEdit, "edit", "e", false, "Open the notes in $EDITOR before the tag is created")
+	releaseCmd.Flags().BoolVar(&relNoEdit, "no-edit", false, "Don't open the notes in $EDITOR, even when edit_release_notes is on")
+	releaseCmd.Flags().BoolVar(&relMigration, "migration-guide", false, "Generate a MIGRATION.md section when the release is a major bump")
+	releaseCmd.Flags().StringSliceVar(&relAnnounce, "announce", nil, "Announce the release to chat (slack, discord, teams)")
+	releaseCmd.Flags().StringVar(&relEmail, "email", "", "Write an email (.eml, text + HTML) version of the notes to this file")
+	releaseCmd.Flags().BoolVar(&relSendmail, "sendmail", false, "Send the email version of the notes through sendmail")
+	releaseCmd.Flags().BoolVar(&relNoDeps, "no-deps", false, "Don't append the go.mod dependency changes section")
+	releaseCmd.Flags().BoolVar(&relNoDedupe, "no-dedupe", false, "Don't send the previous release's notes to keep shipped items out of the new ones")
+	releaseCmd.Flags().BoolVar(&relSnippets, "snippets", false, "Show usage examples in the notes, from what the commits added to docs, CLI flags and config keys")
+	releaseCmd.Flags().IntVar(&relSnipTokens, "snippet-tokens", ai.DefaultSnippetTokens, "With --snippets, the most tokens the snippets may add to the prompt")
+	releaseCmd.Flags().BoolVar(&relStats, "stats", false, "Append a stats section counted by git: commits, files and lines changed, contributors (default from release_stats)")
+	releaseCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+	releaseCmd.Flags().BoolVar(&relProvenance, "provenance", false, "Write an SLSA provenance attestation of the release (signed with $"+provenance.EnvKey+" when set)")
+	releaseCmd.Flags().StringVar(&relVerFiles, "version-files", "", "Write VERSION, OCI label and build-args files for container builds to this directory")
 }
 
 func runRelease(cmd *cobra.Command, args []string) error {
@@ -54,24 +111,134 @@ func runRelease(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a git repository")
 	}
 
+	if relBase != "" && !relHotfix {
+		return fmt.Errorf("--base picks the release a hotfix continues; use it with --hotfix")
+	}
+	if relHotfix && (relMajor || relMinor || relAuto) {
+		return fmt.Errorf("--hotfix bumps the patch version; it can't be combined with --major, --minor or --auto")
+	}
+	if relHotfix && (relPR || relFinalize) {
+		return fmt.Errorf("--hotfix tags the maintenance branch directly; it can't be combined with --pr or --finalize")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	if err := cfg.Validate(); err != nil {
-		color.Yellow("⚠️  %s", err)
-		return nil
+	tagPattern(cfg)
+
+	if relIfChanges {
+		if relFinalize || relCands || relEdit {
+			return fmt.Errorf("--if-changes releases without prompts; it can't be combined with --finalize, --candidates or --edit")
+		}
+		changed, err := changesSinceTag()
+		if err != nil || !changed {
+			return err
+		}
+		flagYes, relNoEdit = true, true
+	}
+	if !relFinalize {
+		if err := ensureProvider(cfg); err != nil {
+			return err
+		}
+	}
+	// The release was reviewed in a pull request when --pr is used
+	if !relDryRun && !relPR && !relFinalize {
+		if err := checkProtectedBranch(cfg, "tag"); err != nil {
+			return err
+		}
+	}
+
+	if !ai.ValidAudience(relAudience) {
+		return fmt.Errorf("invalid --audience %q (expected users, developers or internal)", relAudience)
+	}
+
+	if relSnippets && relSnipTokens <= 0 {
+		return fmt.Errorf("--snippet-tokens must be positive")
+	}
+	if relCands && flagYes {
+		return fmt.Errorf("--candidates asks which sections to keep; it can't be combined with --yes")
+	}
+	if relPR && relFinalize {
+		return fmt.Errorf("--pr opens the release pull request and --finalize tags it once merged; use one at a time")
+	}
+	if relPR && (relPush || relPublish || len(relAnnounce) > 0 || relEmail != "" || relSendmail) {
+		return fmt.Errorf("--pr tags nothing until the pull request merges; pass --push, --publish, --announce and --email to release --finalize")
+	}
+	if relFinalize && (relTag != "" || relAuto || relMajor || relMinor || relPatch || relCands || relEdit || relMigration || relSnippets || relAudience != "") {
+		return fmt.Errorf("--finalize tags the version and notes of the merged release pull request; it can't be combined with version or notes options")
+	}
+	if relJSON {
+		if !relDryRun {
+			return fmt.Errorf("--json prints the plan of a --dry-run")
+		}
+		if relCands {
+			return fmt.Errorf("--candidates asks which sections to keep; it can't be combined with --json")
+		}
+		// Progress goes to stderr, stdout is left for the plan
+		ui, _ = render.New(uiFormat, os.Stderr)
+	}
+
+	if cfg.PrivacyMode && relProvenance {
+		return fmt.Errorf("privacy mode forbids --provenance (no tool-identifying metadata)")
+	}
+	if cfg.PrivacyMode && (len(relAnnounce) > 0 || relSendmail) {
+		return fmt.Errorf("privacy mode forbids --announce and --sendmail (no network calls besides the AI provider)")
+	}
+	if cfg.LocalOnly && (len(relAnnounce) > 0 || relSendmail) {
+		return fmt.Errorf("local-only mode forbids --announce and --sendmail (nothing leaves this machine)")
+	}
+	for _, platform := range relAnnounce {
+		if !announce.Supported(platform) {
+			return fmt.Errorf("invalid --announce %q (supported: %s)", platform, strings.Join(announce.Platforms, ", "))
+		}
+		if cfg.Webhooks[platform] == "" {
+			return fmt.Errorf("no %s webhook configured. Run: commitai config --webhook %s=URL", platform, platform)
+		}
 	}
 
-	client := ai.NewGeminiClient(cfg)
+	if relSendmail && len(cfg.EmailTo) == 0 {
+		return fmt.Errorf("no email recipients configured. Run: commitai config --email-to ADDRESS")
+	}
+
+	// Find out where to publish before anything is tagged
+	var host *forge.Forge
+	if (relPublish || relPR) && !relDryRun {
+		if relPublish {
+			relPush = true
+		}
+		if host, err = originForge(cfg); err != nil {
+			return err
+		}
+		if relPublish && !host.HasReleases() {
+			return fmt.Errorf("%s has no releases to publish; use --push", host.Type)
+		}
+		if err := host.CheckAllowed(cfg); err != nil {
+			return err
+		}
+		if host.Token == "" {
+			flag := "--publish"
+			if relPR {
+				flag = "--pr"
+			}
+			return fmt.Errorf("%s needs a %s token: set %s", flag, host.Type, host.TokenIn)
+		}
+	}
+
+	if relFinalize {
+		return finalizeRelease(cfg, host)
+	}
+
+	client := newClient(cfg)
+	releases := &app.ReleaseService{Config: cfg, AI: client, Out: ui, PreviousNotes: previousNotes}
 
 	// Get current tag
-	currentTag, err := git.LatestTag()
+	currentTag, err := releaseBase()
 	if err != nil {
 		return err
 	}
 
-	color.Cyan("📦 Current version: %s", ifEmpty(currentTag, "none"))
+	ui.Info("📦 Current version: %s", ifEmpty(currentTag, "none"))
 
 	// Get commits since last tag
 	commits, err := git.CommitsSinceTag(currentTag)
@@ -80,113 +247,625 @@ func runRelease(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(commits) == 0 {
-		color.Yellow("No commits since last tag. Nothing to release.")
+		ui.Warn("No commits since last tag. Nothing to release.")
 		return nil
 	}
 
-	color.Cyan("📝 %d commit(s) since last tag", len(commits))
+	ui.Info("📝 %d commit(s) since last tag", len(commits))
 
 	// Determine new version
 	var newVersion string
 	if relTag != "" {
-		newVersion = strings.TrimPrefix(relTag, "v")
-	} else if relAuto {
-		color.Cyan("\n🤖 Asking AI to suggest version bump...")
-		newVersion, err = client.SuggestNextVersion(commits, currentTag)
-		if err != nil {
-			return fmt.Errorf("AI version suggestion failed: %w", err)
-		}
-	} else {
-		newVersion = bumpVersion(currentTag, relMajor, relMinor, relPatch)
+		newVersion = tagVersion(relTag)
+	} else if newVersion, err = releases.NextVersion(commits, currentTag, tagVersion(currentTag), versionBump()); err != nil {
+		return err
 	}
 
-	newTag := "v" + newVersion
-	color.Cyan("🏷️  New version: %s", newTag)
+	newTag := tagPrefix() + newVersion
+	ui.Info("🏷️  New version: %s", newTag)
+	if relHotfix && git.TagExists(newTag) {
+		return fmt.Errorf("%s already exists, so %s is not the latest release of its line; use --base %s", newTag, currentTag, newTag)
+	}
 
 	// Generate release notes
-	color.Cyan("\n✨ Generating release notes with Gemini...")
-	notes, err := client.GenerateReleaseNotes(commits, currentTag, newTag)
-	if err != nil {
-		return fmt.Errorf("failed to generate release notes: %w", err)
+	var notes string
+	if relCands {
+		ui.Info("\n✨ Generating release notes with %s...", client.ProviderName())
+		if notes, err = releaseCandidates(client, commits, currentTag, newTag); err != nil {
+			return fmt.Errorf("failed to generate release notes: %w", err)
+		}
+	} else if notes, err = releases.Notes(commits, currentTag, newTag, ai.ReleaseOptions{Audience: relAudience, Snippets: releaseSnippets(commits)}); err != nil {
+		return err
+	}
+	if relHotfix {
+		notes = hotfixLabel(currentTag, newVersion) + "\n\n" + strings.TrimSpace(notes)
+	}
+	if !relNoDeps {
+		if section := dependencyChanges(currentTag); section != "" {
+			notes = strings.TrimSpace(notes) + "\n\n" + section
+		}
+	}
+	if relStats || (cfg.ReleaseStats && !cmd.Flags().Changed("stats")) {
+		section, err := releaseStats(currentTag)
+		if err != nil {
+			return err
+		}
+		notes = strings.TrimSpace(notes) + "\n\n" + section
 	}
 
-	fmt.Println()
-	color.Green("📋 Release Notes:")
-	fmt.Println(strings.Repeat("─", 60))
-	fmt.Println(notes)
-	fmt.Println(strings.Repeat("─", 60))
+	if !relJSON {
+		fmt.Println()
+		ui.Success("📋 Release Notes:")
+		fmt.Println(strings.Repeat("─", 60))
+		ui.Document(notes)
+		fmt.Println(strings.Repeat("─", 60))
+	}
+
+	// Migration guide for major releases
+	var migration string
+	if relMigration {
+		if isMajorBump(currentTag, newVersion) {
+			migration, err = generateMigrationGuide(client, currentTag, newTag)
+			if err != nil {
+				return err
+			}
+		} else {
+			ui.Warn("\nℹ️  %s is not a major release — skipping migration guide.", newTag)
+		}
+	}
 
 	if relDryRun {
-		color.Yellow("\n🔍 Dry run — no tag was created.")
-		return nil
+		return printReleasePlan(planRelease(cfg, currentTag, newVersion, len(commits), notes, migration))
+	}
+
+	// Touch-ups are easier now than in an annotated tag
+	if relEdit || (cfg.EditReleaseNotes && !flagYes && !relNoEdit) {
+		edited, err := editText(notes, "RELEASE-"+newTag+".md")
+		if err != nil {
+			return fmt.Errorf("failed to edit the release notes: %w", err)
+		}
+		if edited == "" {
+			ui.Warn("Release cancelled: the notes are empty.")
+			return nil
+		}
+		if edited != strings.TrimSpace(notes) {
+			notes = edited
+			fmt.Println()
+			ui.Success("📋 Edited Release Notes:")
+			fmt.Println(strings.Repeat("─", 60))
+			ui.Document(notes)
+			fmt.Println(strings.Repeat("─", 60))
+		}
 	}
 
 	// Confirm
 	if !flagYes {
-		fmt.Printf("\n⚡ Create tag %s? [Y/n]: ", newTag)
+		if relPR {
+			fmt.Printf("\n⚡ Open a release pull request for %s? [Y/n]: ", newTag)
+		} else {
+			fmt.Printf("\n⚡ Create tag %s? [Y/n]: ", newTag)
+		}
 		var input string
 		fmt.Scanln(&input)
 		input = strings.ToLower(strings.TrimSpace(input))
 		if input == "n" || input == "no" {
-			color.Yellow("Release cancelled.")
+			ui.Warn("Release cancelled.")
 			return nil
 		}
 	}
 
+	if relPR {
+		return openReleasePR(host, currentTag, newTag, newVersion, notes, migration)
+	}
+	head, err := git.HeadCommit()
+	if err != nil {
+		return err
+	}
+	return tagRelease(cfg, host, head, currentTag, newTag, newVersion, notes, migration)
+}
+
+// tagRelease creates the tag on commit and does everything that follows:
+// hooks, release files, push, forge release and announcements
+func tagRelease(cfg *config.Config, host *forge.Forge, commit, currentTag, newTag, newVersion, notes, migration string) error {
+	// Hooks get the notes through a temporary file, even in privacy mode
+	hookEnv, cleanup, err := releaseHookEnv(cfg, currentTag, newTag, notes)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := runReleaseHook(cfg, hooks.PreRelease, hookEnv); err != nil {
+		return fmt.Errorf("%w — release aborted, no tag was created", err)
+	}
+
 	// Create annotated tag
-	if err := git.CreateTag(newTag, notes); err != nil {
+	if err := git.CreateTagAt(newTag, commit, notes); err != nil {
 		return fmt.Errorf("failed to create tag: %w", err)
 	}
-	color.Green("\n✅ Tag %s created!", newTag)
+	ui.Success("\n✅ Tag %s created!", newTag)
 
-	// Save release notes to file
-	notesFile := fmt.Sprintf("RELEASE-%s.md", newTag)
-	if err := os.WriteFile(notesFile, []byte(notes), 0644); err == nil {
-		color.Cyan("📄 Release notes saved to %s", notesFile)
+	// Save release notes to file (privacy mode leaves no stray files behind)
+	if !cfg.PrivacyMode {
+		notesFile := fmt.Sprintf("RELEASE-%s.md", newTag)
+		if err := os.WriteFile(notesFile, []byte(notes), 0644); err == nil {
+			ui.Info("📄 Release notes saved to %s", notesFile)
+		}
+	}
+
+	if relVerFiles != "" {
+		writeVersionFiles(commit, newVersion, newTag)
+	}
+	if relProvenance {
+		if err := writeProvenance(cfg, commit, currentTag, newTag, notes); err != nil {
+			return fmt.Errorf("%w — tag %s was created but not pushed", err, newTag)
+		}
+	}
+
+	if migration != "" {
+		if err := prependToFile(migrationFile, migration); err != nil {
+			ui.Warn("⚠️  Could not write %s: %s", migrationFile, err)
+		} else {
+			ui.Info("🧭 Migration guide added to %s", migrationFile)
+		}
+	}
+
+	if err := runReleaseHook(cfg, hooks.PostTag, hookEnv); err != nil {
+		return fmt.Errorf("%w — tag %s was created but not pushed", err, newTag)
 	}
 
 	// Push if requested
 	if relPush {
-		color.Cyan("\n📤 Pushing tag to origin...")
-		out, err := exec.Command("git", "push", "origin", newTag).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to push tag: %s\n%w", string(out), err)
+		ui.Info("\n📤 Pushing tag to origin...")
+		if _, err := git.Run("push", "origin", newTag); err != nil {
+			return fmt.Errorf("failed to push tag: %w", err)
 		}
-		color.Green("✅ Tag pushed to origin!")
+		ui.Success("✅ Tag pushed to origin!")
 	}
 
+	if host != nil {
+		publishRelease(host, newTag, notes)
+	}
+
+	announceRelease(cfg, newTag, notes)
+
+	if relPush {
+		return runReleaseHook(cfg, hooks.PostPush, hookEnv)
+	}
 	return nil
 }
 
-func bumpVersion(currentTag string, major, minor, patch bool) string {
-	tag := strings.TrimPrefix(currentTag, "v")
-	if tag == "" {
-		return "0.1.0"
+// editText opens text in the user's editor, in a temporary file with the
+// given name, and returns it as saved
+func editText(text, name string) (string, error) {
+	dir, err := os.MkdirTemp("", "commitai-edit-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		return "", err
+	}
+
+	// Like git, the editor is a shell command that may carry arguments
+	editor := git.Editor()
+	c := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", editor, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// releaseHookEnv builds the COMMITAI_* environment for release hooks and
+// writes the notes to a temporary file removed by the returned cleanup.
+func releaseHookEnv(cfg *config.Config, currentTag, newTag, notes string) (map[string]string, func(), error) {
+	if len(cfg.Hooks) == 0 {
+		return nil, func() {}, nil
+	}
+	f, err := os.CreateTemp("", "commitai-notes-*.md")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(notes); err != nil {
+		os.Remove(f.Name())
+		return nil, nil, err
+	}
+
+	env := map[string]string{
+		"COMMITAI_NEW_TAG":      newTag,
+		"COMMITAI_NEW_VERSION":  tagVersion(newTag),
+		"COMMITAI_PREVIOUS_TAG": currentTag,
+		"COMMITAI_NOTES_FILE":   f.Name(),
+		"COMMITAI_PUSH":         fmt.Sprint(relPush),
+	}
+	return env, func() { os.Remove(f.Name()) }, nil
+}
+
+// previousNotes returns the notes of the release tagged tag, for the new
+// notes not to repeat them; "" without --no-dedupe, an annotated tag or notes
+func previousNotes(tag string) string {
+	if relNoDedupe || tag == "" {
+		return ""
+	}
+	notes, err := git.TagMessage(tag)
+	if err != nil {
+		return ""
+	}
+	return notes
+}
+
+// releaseSnippets picks, with --snippets, the usage snippets of commits
+// (git log --oneline lines) within the --snippet-tokens budget. Diffs stop
+// being read once the budget is full.
+func releaseSnippets(commits []string) []ai.Snippet {
+	if !relSnippets {
+		return nil
+	}
+	var snippets []ai.Snippet
+	used := 0
+	for _, c := range commits {
+		if used >= relSnipTokens {
+			break
+		}
+		hash, _, _ := strings.Cut(c, " ")
+		diff, err := git.ShowDiff(hash)
+		if err != nil || strings.HasPrefix(diff, "diff --cc") {
+			continue // Merges have no diff of their own
+		}
+		for _, s := range ai.FitSnippets(ai.DiffSnippets(c, diff), relSnipTokens-used) {
+			used += s.Tokens()
+			snippets = append(snippets, s)
+		}
+	}
+	if len(snippets) > 0 {
+		ui.Muted("🧩 %d snippet(s), ~%d tokens, for usage examples", len(snippets), used)
+	}
+	return snippets
+}
+
+// runReleaseHook runs the configured command for a hook point, if any
+func runReleaseHook(cfg *config.Config, name string, env map[string]string) error {
+	command := cfg.Hooks[name]
+	if command == "" {
+		return nil
+	}
+	ui.Info("\n🪝 Running %s hook: %s", name, command)
+	return hooks.Run(name, command, env)
+}
+
+// originForge detects the forge hosting the origin remote
+func originForge(cfg *config.Config) (*forge.Forge, error) {
+	remote, err := git.RemoteURL("origin")
+	if err != nil {
+		return nil, err
+	}
+	return forge.Detect(cfg, remote)
+}
+
+// publishRelease creates the forge release for a pushed tag. Failures only
+// warn: the tag is already created and pushed at this point.
+func publishRelease(host *forge.Forge, tag, notes string) {
+	ui.Info("\n🌐 Creating the %s release...", host.Type)
+	link, err := host.CreateRelease(forge.Release{Tag: tag, Name: tag, Notes: notes})
+	if err != nil {
+		ui.Warn("⚠️  Could not create the release: %s", err)
+		return
+	}
+	ui.Success("✅ Release published: %s", link)
+}
+
+// announceRelease posts the notes to every requested chat platform and
+// mailing list. Failures only warn: the tag already exists at this point.
+func announceRelease(cfg *config.Config, tag, notes string) {
+	if len(relAnnounce) == 0 && relEmail == "" && !relSendmail {
+		return
+	}
+	project := ""
+	if top, err := git.TopLevel(); err == nil {
+		project = filepath.Base(top)
+	}
+	rel := announce.Release{Project: project, Tag: tag, Notes: notes}
+
+	if relEmail != "" || relSendmail {
+		msg := announce.Email(rel, cfg.EmailFrom, cfg.EmailTo)
+		if relEmail != "" {
+			if err := os.WriteFile(relEmail, msg, 0644); err != nil {
+				ui.Warn("⚠️  Could not write %s: %s", relEmail, err)
+			} else {
+				ui.Info("✉️  Release email saved to %s", relEmail)
+			}
+		}
+		if relSendmail {
+			if err := announce.SendMail(msg); err != nil {
+				ui.Warn("⚠️  Email failed: %s", err)
+			} else {
+				ui.Success("✉️  Release email sent to %s", strings.Join(cfg.EmailTo, ", "))
+			}
+		}
+	}
+
+	for _, platform := range relAnnounce {
+		if err := announce.Post(platform, cfg.Webhooks[platform], rel); err != nil {
+			ui.Warn("⚠️  Announcement failed: %s", err)
+			continue
+		}
+		ui.Success("📣 Release announced on %s", platform)
+	}
+}
+
+// versionBump is the version bump the flags ask for, patch by default
+func versionBump() app.Bump {
+	switch {
+	case relAuto:
+		return app.BumpAuto
+	case relMajor:
+		return app.BumpMajor
+	case relMinor:
+		return app.BumpMinor
+	}
+	return app.BumpPatch
+}
+
+// dependencyChanges diffs go.mod between the previous tag and HEAD.
+// Returns "" for non-Go repos, first releases or unchanged dependencies.
+func dependencyChanges(currentTag string) string {
+	if currentTag == "" {
+		return ""
+	}
+	newMod, err := git.ShowFile("HEAD", "go.mod")
+	if err != nil {
+		return ""
+	}
+	oldMod, _ := git.ShowFile(currentTag, "go.mod")
+	return deps.DiffGoMod(oldMod, newMod).Markdown()
+}
+
+// maxStatsContributors bounds the contributors named in the stats section
+const maxStatsContributors = 10
+
+// releaseStats counts what changed since the previous tag with git, so the
+// notes carry numbers the model can't be trusted to get right
+func releaseStats(currentTag string) (string, error) {
+	st, err := git.RangeStats(currentTag, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("## 📊 Stats\n\n")
+	sb.WriteString(fmt.Sprintf("- %s by %s\n", plural(st.Commits, "commit"), plural(len(st.Contributors), "contributor")))
+	sb.WriteString(fmt.Sprintf("- %s changed, +%d −%d lines\n", plural(st.Files, "file"), st.Insertions, st.Deletions))
+	if len(st.Contributors) > 0 {
+		var names []string
+		for i, c := range st.Contributors {
+			if i == maxStatsContributors {
+				names = append(names, fmt.Sprintf("and %d more", len(st.Contributors)-i))
+				break
+			}
+			names = append(names, fmt.Sprintf("%s (%d)", c.Name, c.Commits))
+		}
+		sb.WriteString("- Contributors: " + strings.Join(names, ", ") + "\n")
+	}
+	return sb.String(), nil
+}
+
+// plural counts n things, e.g. "1 commit", "3 commits"
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
 	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
 
-	parts := strings.Split(tag, ".")
-	for len(parts) < 3 {
-		parts = append(parts, "0")
+// versionMeta is the version metadata of a release of the given commit
+func versionMeta(commit, version, tag string) buildmeta.Meta {
+	meta := buildmeta.Meta{Version: version, Tag: tag, Revision: commit, Created: time.Now()}
+	if remote, err := git.RemoteURL("origin"); err == nil {
+		meta.Source = buildmeta.SourceURL(remote)
 	}
+	return meta
+}
 
-	var maj, min, pat int
-	fmt.Sscanf(parts[0], "%d", &maj)
-	fmt.Sscanf(parts[1], "%d", &min)
-	fmt.Sscanf(parts[2], "%d", &pat)
+// writeVersionFiles emits version metadata for container image pipelines
+func writeVersionFiles(commit, version, tag string) {
+	files, err := buildmeta.Write(relVerFiles, versionMeta(commit, version, tag))
+	if err != nil {
+		ui.Warn("⚠️  %s", err)
+		return
+	}
+	ui.Info("🐳 Version metadata written: %s", strings.Join(files, ", "))
+}
+
+// provenanceFile is where the attestation of a release is written
+func provenanceFile(tag string) string {
+	return "RELEASE-" + tag + ".intoto.json"
+}
 
+// writeProvenance records how the release and its notes were produced
+func writeProvenance(cfg *config.Config, commit, currentTag, newTag, notes string) error {
+	rel := provenance.Release{
+		Tag:         newTag,
+		PreviousTag: currentTag,
+		Commit:      commit,
+		Notes:       notes,
+		ToolVersion: Version,
+		Provider:    "gemini",
+		Model:       cfg.Model,
+		Finished:    time.Now(),
+	}
 	switch {
-	case major:
-		maj++
-		min = 0
-		pat = 0
-	case minor:
-		min++
-		pat = 0
-	default: // patch
-		pat++
-	}
-
-	return fmt.Sprintf("%d.%d.%d", maj, min, pat)
+	case cfg.Provider == config.ProviderOffline:
+		rel.Provider, rel.Model = config.ProviderOffline, ""
+	case cfg.ProviderCommand != "":
+		rel.Provider, rel.Model = "command", ""
+	}
+	if commits, err := git.CommitsBetween(currentTag, commit); err == nil {
+		rel.Commits = len(commits)
+	}
+	if remote, err := git.RemoteURL("origin"); err == nil {
+		rel.Source = buildmeta.SourceURL(remote)
+	}
+
+	path := provenanceFile(newTag)
+	signed, err := provenance.Write(path, rel, os.Getenv(provenance.EnvKey))
+	if err != nil {
+		return err
+	}
+	if signed {
+		ui.Info("🔏 Signed provenance saved to %s", path)
+	} else {
+		ui.Info("🧾 Provenance saved to %s (unsigned: set %s to sign it)", path, provenance.EnvKey)
+	}
+	return nil
+}
+
+// isMajorBump reports whether newVersion increases the major version of currentTag
+func isMajorBump(currentTag, newVersion string) bool {
+	cur := tagVersion(currentTag)
+	if cur == "" {
+		return false
+	}
+	var curMaj, newMaj int
+	fmt.Sscanf(strings.Split(cur, ".")[0], "%d", &curMaj)
+	fmt.Sscanf(strings.Split(newVersion, ".")[0], "%d", &newMaj)
+	return newMaj > curMaj
+}
+
+func generateMigrationGuide(client *ai.GeminiClient, currentTag, newTag string) (string, error) {
+	breaking, err := git.BreakingCommits(currentTag, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if len(breaking) == 0 {
+		ui.Warn("\nℹ️  No breaking commits (feat!: or BREAKING CHANGE) found — skipping migration guide.")
+		return "", nil
+	}
+
+	for i := range breaking {
+		breaking[i].Diff, _ = git.ShowDiff(breaking[i].Hash)
+	}
+
+	ui.Info("\n🧭 Generating migration guide from %d breaking commit(s)...", len(breaking))
+	guide, err := client.GenerateMigrationGuide(breaking, currentTag, newTag)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate migration guide: %w", err)
+	}
+
+	if !relJSON {
+		fmt.Println()
+		ui.Success("🧭 Migration Guide:")
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Println(guide)
+		fmt.Println(strings.Repeat("─", 60))
+	}
+	return guide, nil
+}
+
+// prependToFile inserts content at the top of path, keeping older entries below
+func prependToFile(path, content string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data := strings.TrimSpace(content) + "\n"
+	if len(existing) > 0 {
+		data += "\n" + string(existing)
+	}
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// latestTag is git.LatestTag, failing in a shallow clone that has no tag
+// rather than releasing every fetched commit as if it were the first release
+// changesSinceTag reports commits since the latest tag, saying so when
+// there are none, before anything else is checked or asked
+func changesSinceTag() (bool, error) {
+	tag, err := releaseBase()
+	if err != nil {
+		return false, err
+	}
+	commits, err := git.CommitsSinceTag(tag)
+	if err != nil {
+		return false, err
+	}
+	if len(commits) == 0 {
+		ui.Success("✅ No commits since %s; nothing to release", tag)
+		return false, nil
+	}
+	return true, nil
+}
+
+func latestTag() (string, error) {
+	tag, err := git.LatestTag(flagTagPattern)
+	if err != nil || tag != "" || !git.IsShallow() {
+		return tag, err
+	}
+	return "", fmt.Errorf("this shallow clone has no tag in its %d fetched commit(s), so the current version is unknown; fetch the full history first: git fetch --unshallow --tags (fetch-depth: 0 in GitHub Actions)", git.CountCommits("HEAD"))
+}
+
+// tagPattern settles the glob release tags match: --tag-pattern, else the
+// tag_pattern setting
+func tagPattern(cfg *config.Config) string {
+	if flagTagPattern == "" {
+		flagTagPattern = cfg.TagPattern
+	}
+	return flagTagPattern
+}
+
+// tagPrefix is what release tags start with before the version: the tag
+// pattern up to its first wildcard, v by default
+func tagPrefix() string {
+	if flagTagPattern == "" {
+		return "v"
+	}
+	if i := strings.IndexAny(flagTagPattern, "*?["); i >= 0 {
+		return flagTagPattern[:i]
+	}
+	return flagTagPattern
+}
+
+// tagVersion is the version a release tag names: app-v1.2.3 → 1.2.3
+func tagVersion(tag string) string {
+	if prefix := tagPrefix(); strings.HasPrefix(tag, prefix) {
+		return strings.TrimPrefix(tag, prefix)
+	}
+	return strings.TrimPrefix(tag, "v")
+}
+
+// releaseBase returns the tag the release continues: --base for a hotfix,
+// otherwise the latest tag
+func releaseBase() (string, error) {
+	if !relHotfix {
+		return latestTag()
+	}
+	if relBase == "" {
+		tag, err := latestTag()
+		if err == nil && tag == "" {
+			err = fmt.Errorf("--hotfix patches a release, and there is no tag yet")
+		}
+		return tag, err
+	}
+	if !git.TagExists(relBase) {
+		return "", fmt.Errorf("no tag %s", relBase)
+	}
+	if !git.IsAncestor(relBase, "HEAD") {
+		return "", fmt.Errorf("%s is not in the history of HEAD; check out its maintenance branch first", relBase)
+	}
+	return relBase, nil
+}
+
+// hotfixLabel opens the notes of a hotfix release, naming its maintenance
+// line (1.4.x) and the release it patches
+func hotfixLabel(baseTag, newVersion string) string {
+	line := newVersion
+	if i := strings.LastIndex(line, "."); i >= 0 {
+		line = line[:i]
+	}
+	return fmt.Sprintf("> 🚑 **Hotfix** for the %s.x line, on top of %s.", line, baseTag)
 }
 
 func ifEmpty(s, fallback string) string {
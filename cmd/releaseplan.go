@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/buildmeta"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/hooks"
+)
+
+// releasePlan is everything release would do, as shown by --dry-run
+type releasePlan struct {
+	CurrentTag  string        `json:"current_tag,omitempty"`
+	Tag         string        `json:"tag"`
+	Version     string        `json:"version"`
+	Bump        string        `json:"bump"` // major, minor, patch, initial, auto, tag or pr
+	Commit      string        `json:"commit"`
+	Commits     int           `json:"commits"`
+	Notes       string        `json:"notes"`
+	Files       []plannedFile `json:"files"`
+	Hooks       []plannedHook `json:"hooks"`
+	Push        *plannedPush  `json:"push,omitempty"`
+	PullRequest *plannedPR    `json:"pull_request,omitempty"` // With --pr, instead of the tag
+	Release     *plannedForge `json:"release,omitempty"`
+	Announce    []string      `json:"announce,omitempty"`
+	EmailTo     []string      `json:"email_to,omitempty"`
+	Problems    []string      `json:"problems"`
+}
+
+type plannedFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // create, overwrite or prepend
+}
+
+type plannedHook struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+type plannedPush struct {
+	Remote string `json:"remote"`
+	URL    string `json:"url,omitempty"`
+	Ref    string `json:"ref"`
+}
+
+type plannedPR struct {
+	Branch string `json:"branch"`
+	Base   string `json:"base"`
+	Title  string `json:"title"`
+}
+
+type plannedForge struct {
+	Type string      `json:"type"`
+	Call *forge.Call `json:"call,omitempty"`
+}
+
+// planRelease works out what the release would change, without touching
+// anything. Whatever would make the real run fail is listed in Problems.
+func planRelease(cfg *config.Config, currentTag, newVersion string, commits int, notes, migration string) *releasePlan {
+	newTag := tagPrefix() + newVersion
+	plan := &releasePlan{
+		CurrentTag: currentTag,
+		Tag:        newTag,
+		Version:    newVersion,
+		Bump:       releaseBump(currentTag),
+		Commits:    commits,
+		Notes:      notes,
+		Files:      []plannedFile{},
+		Hooks:      []plannedHook{},
+		Problems:   []string{},
+	}
+	problem := func(format string, a ...any) {
+		plan.Problems = append(plan.Problems, fmt.Sprintf(format, a...))
+	}
+
+	plan.Commit, _ = git.HeadCommit()
+	if git.TagExists(newTag) {
+		problem("tag %s already exists", newTag)
+	}
+	if strings.TrimSpace(notes) == "" {
+		problem("the release notes are empty")
+	}
+	if !relPR && !relFinalize {
+		if err := checkProtectedBranch(cfg, "tag"); err != nil {
+			problem("%s", err)
+		}
+	}
+
+	addFile := func(path, action string) {
+		if _, err := os.Stat(path); err != nil && action != "prepend" {
+			action = "create"
+		}
+		plan.Files = append(plan.Files, plannedFile{Path: path, Action: action})
+	}
+	if relPR {
+		return planReleasePR(cfg, plan, problem, addFile, migration)
+	}
+	if !cfg.PrivacyMode {
+		addFile("RELEASE-"+newTag+".md", "overwrite")
+	}
+	if relVerFiles != "" {
+		for _, name := range []string{buildmeta.VersionFile, buildmeta.LabelsFile, buildmeta.BuildArgsFile} {
+			addFile(filepath.Join(relVerFiles, name), "overwrite")
+		}
+	}
+	if relProvenance {
+		addFile(provenanceFile(newTag), "overwrite")
+	}
+	if migration != "" {
+		addFile(migrationFile, "prepend")
+	}
+	if relEmail != "" {
+		addFile(relEmail, "overwrite")
+	}
+
+	for _, name := range hooks.Names {
+		if name == hooks.PostPush && !relPush && !relPublish {
+			continue
+		}
+		if command := cfg.Hooks[name]; command != "" {
+			plan.Hooks = append(plan.Hooks, plannedHook{Name: name, Command: command})
+		}
+	}
+
+	if relPush || relPublish {
+		plan.Push = &plannedPush{Remote: "origin", Ref: "refs/tags/" + newTag}
+		url, err := git.PushURL("origin")
+		if err != nil {
+			problem("%s", err)
+		}
+		plan.Push.URL = url
+	}
+
+	if relPublish {
+		if host, err := originForge(cfg); err != nil {
+			problem("%s", err)
+		} else {
+			plan.Release = &plannedForge{Type: host.Type}
+			if call, err := host.ReleaseCall(forge.Release{Tag: newTag, Name: newTag, Notes: notes}); err != nil {
+				problem("%s", err)
+			} else {
+				plan.Release.Call = &call
+			}
+			if err := host.CheckAllowed(cfg); err != nil {
+				problem("%s", err)
+			}
+			if host.HasReleases() && host.Token == "" {
+				problem("--publish needs a %s token: set %s", host.Type, host.TokenIn)
+			}
+		}
+	}
+
+	plan.Announce = relAnnounce
+	if relSendmail {
+		plan.EmailTo = cfg.EmailTo
+	}
+	return plan
+}
+
+// planReleasePR fills in the plan of release --pr: the files of the release
+// commit, the branch it is pushed to and the pull request
+func planReleasePR(cfg *config.Config, plan *releasePlan, problem func(string, ...any), addFile func(string, string), migration string) *releasePlan {
+	addFile(changelogFile, "prepend")
+	if relVerFiles != "" {
+		for _, name := range []string{buildmeta.VersionFile, buildmeta.LabelsFile, buildmeta.BuildArgsFile} {
+			addFile(filepath.Join(relVerFiles, name), "overwrite")
+		}
+	}
+	if migration != "" {
+		addFile(migrationFile, "prepend")
+	}
+
+	branch := releaseBranchPrefix + plan.Tag
+	base, _ := git.CurrentBranch()
+	if base == "HEAD" {
+		problem("HEAD is detached; check out the branch to release")
+	}
+	if git.BranchExists(branch) {
+		problem("branch %s already exists", branch)
+	}
+	plan.PullRequest = &plannedPR{Branch: branch, Base: base, Title: releaseSubject(plan.Tag)}
+
+	plan.Push = &plannedPush{Remote: "origin", Ref: "refs/heads/" + branch}
+	url, err := git.PushURL("origin")
+	if err != nil {
+		problem("%s", err)
+	}
+	plan.Push.URL = url
+
+	if host, err := originForge(cfg); err != nil {
+		problem("%s", err)
+	} else {
+		if err := host.CheckAllowed(cfg); err != nil {
+			problem("%s", err)
+		}
+		if host.Token == "" {
+			problem("--pr needs a %s token: set %s", host.Type, host.TokenIn)
+		}
+	}
+	return plan
+}
+
+// releaseBump names how the new version was chosen
+func releaseBump(currentTag string) string {
+	switch {
+	case relFinalize:
+		return "pr"
+	case relTag != "":
+		return "tag"
+	case relAuto:
+		return "auto"
+	case currentTag == "":
+		return "initial"
+	case relMajor:
+		return "major"
+	case relMinor:
+		return "minor"
+	}
+	return "patch"
+}
+
+// printReleasePlan shows the plan as text or, with --json, as JSON on
+// stdout, and fails when the real run would
+func printReleasePlan(plan *releasePlan) error {
+	if relJSON {
+		data, _ := json.MarshalIndent(plan, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Println()
+		ui.Info("🧪 Release plan:")
+		fmt.Printf("  Version:   %s → %s (%s, %d commit(s))\n", ifEmpty(plan.CurrentTag, "none"), plan.Tag, plan.Bump, plan.Commits)
+		if pr := plan.PullRequest; pr != nil {
+			fmt.Printf("  Tag:       %s once the pull request is merged (release --finalize)\n", plan.Tag)
+			fmt.Printf("  PR:        %s → %s: %s\n", pr.Branch, pr.Base, pr.Title)
+		} else {
+			fmt.Printf("  Tag:       annotated %s on %s\n", plan.Tag, shortSHA(plan.Commit))
+		}
+		for _, f := range plan.Files {
+			fmt.Printf("  File:      %s (%s)\n", f.Path, f.Action)
+		}
+		for _, h := range plan.Hooks {
+			fmt.Printf("  Hook:      %s: %s\n", h.Name, h.Command)
+		}
+		if plan.Push != nil {
+			fmt.Printf("  Push:      %s %s (%s)\n", plan.Push.Remote, plan.Push.Ref, ifEmpty(plan.Push.URL, "no URL"))
+		}
+		if plan.Release != nil {
+			if call := plan.Release.Call; call != nil {
+				fmt.Printf("  Release:   %s %s %s\n", plan.Release.Type, call.Method, call.URL)
+			} else {
+				fmt.Printf("  Release:   %s\n", plan.Release.Type)
+			}
+		}
+		if len(plan.Announce) > 0 {
+			fmt.Printf("  Announce:  %s\n", strings.Join(plan.Announce, ", "))
+		}
+		if len(plan.EmailTo) > 0 {
+			fmt.Printf("  Email:     %s\n", strings.Join(plan.EmailTo, ", "))
+		}
+		for _, p := range plan.Problems {
+			ui.Error("  ❌ %s", p)
+		}
+	}
+
+	if len(plan.Problems) > 0 {
+		return fmt.Errorf("the release would fail: %d problem(s) found", len(plan.Problems))
+	}
+	ui.Warn("\n🔍 Dry run — no tag was created.")
+	return nil
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/buildmeta"
+	"github.com/kaiqui/commitai/internal/changelog"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/forge"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+const (
+	changelogFile = "CHANGELOG.md"
+
+	// releaseBranchPrefix is where release pull requests are opened from
+	releaseBranchPrefix = "release/"
+)
+
+// releaseSubjectRe matches the subject of a release commit, also with the
+// pull request number a squash merge adds
+var releaseSubjectRe = regexp.MustCompile(`^chore\(release\): (\S*\d+\.\d+\S*)(?: \(#\d+\))?$`)
+
+func releaseSubject(tag string) string {
+	return "chore(release): " + tag
+}
+
+// openReleasePR commits the changelog section and version files of the
+// release to a new release/<tag> branch, pushes it and opens a pull request
+// against the current branch. The current checkout is left alone: the
+// commit is made in a temporary worktree.
+func openReleasePR(host *forge.Forge, currentTag, newTag, newVersion, notes, migration string) error {
+	base, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if base == "HEAD" {
+		return fmt.Errorf("HEAD is detached; check out the branch to release")
+	}
+	head, err := git.HeadCommit()
+	if err != nil {
+		return err
+	}
+	branch := releaseBranchPrefix + newTag
+	if git.BranchExists(branch) {
+		return fmt.Errorf("branch %s already exists; delete it to open the release pull request again", branch)
+	}
+
+	dir, err := os.MkdirTemp("", "commitai-release-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := git.AddWorktree(dir, branch, head); err != nil {
+		return err
+	}
+	defer git.RemoveWorktree(dir)
+
+	path := filepath.Join(dir, changelogFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	section := changelog.Section{Tag: newTag, Date: time.Now().Format("2006-01-02"), Notes: notes}
+	if err := os.WriteFile(path, []byte(changelog.Prepend(string(existing), section)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", changelogFile, err)
+	}
+	files := []string{changelogFile}
+
+	if relVerFiles != "" {
+		if _, err := buildmeta.Write(filepath.Join(dir, relVerFiles), versionMeta(head, newVersion, newTag)); err != nil {
+			return err
+		}
+		for _, name := range []string{buildmeta.VersionFile, buildmeta.LabelsFile, buildmeta.BuildArgsFile} {
+			files = append(files, filepath.Join(relVerFiles, name))
+		}
+	}
+	if migration != "" {
+		if err := prependToFile(filepath.Join(dir, migrationFile), migration); err != nil {
+			return fmt.Errorf("failed to write %s: %w", migrationFile, err)
+		}
+		files = append(files, migrationFile)
+	}
+
+	if err := git.CommitAllIn(dir, releaseSubject(newTag)); err != nil {
+		return err
+	}
+	ui.Success("\n✅ Release commit on %s: %s", branch, strings.Join(files, ", "))
+
+	ui.Info("\n📤 Pushing %s to origin...", branch)
+	if _, err := git.Run("push", "--set-upstream", "origin", branch); err != nil {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+
+	pr := forge.PullRequest{Title: releaseSubject(newTag), Body: releasePRBody(currentTag, newTag, notes), Head: branch, Base: base}
+	link, err := host.CreatePullRequest(pr)
+	if err != nil {
+		return fmt.Errorf("failed to open the release pull request: %w", err)
+	}
+	ui.Success("✅ Release pull request opened: %s", ifEmpty(link, branch+" → "+base))
+	ui.Info("🏷️  Once it is merged, run commitai release --finalize on %s to tag %s", base, newTag)
+	return nil
+}
+
+// releasePRBody is the description of a release pull request: the notes,
+// and how they become the tag
+func releasePRBody(currentTag, newTag, notes string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(notes))
+	sb.WriteString("\n\n---\n\n")
+	fmt.Fprintf(&sb, "Merging this pull request releases %s", newTag)
+	if currentTag != "" {
+		fmt.Fprintf(&sb, " (previous: %s)", currentTag)
+	}
+	fmt.Fprintf(&sb, ". The notes are the %s section of %s; edit them there. ", newTag, changelogFile)
+	sb.WriteString("After the merge, `commitai release --finalize` creates the tag.\n")
+	return sb.String()
+}
+
+// finalizeRelease tags the newest merged release commit since the latest
+// tag, with its CHANGELOG.md section as the notes
+func finalizeRelease(cfg *config.Config, host *forge.Forge) error {
+	currentTag, err := latestTag()
+	if err != nil {
+		return err
+	}
+	commits, err := git.SubjectsBetween(currentTag, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	var release git.CommitInfo
+	var newTag string
+	for i, c := range commits {
+		if m := releaseSubjectRe.FindStringSubmatch(c.Subject); m != nil {
+			release, newTag = c, m[1]
+			commits = commits[i:]
+			break
+		}
+	}
+	if newTag == "" {
+		ui.Warn("No release pull request merged since %s; nothing to finalize.", ifEmpty(currentTag, "the first commit"))
+		return nil
+	}
+	if git.TagExists(newTag) {
+		ui.Success("✅ %s is already tagged; nothing to finalize.", newTag)
+		return nil
+	}
+
+	content, err := git.ShowFile(release.Hash, changelogFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s of the release commit %s: %w", changelogFile, shortSHA(release.Hash), err)
+	}
+	notes := changelog.Extract(content, newTag)
+	if notes == "" {
+		return fmt.Errorf("%s has no %s section at %s; can't tell the release notes", changelogFile, newTag, shortSHA(release.Hash))
+	}
+	newVersion := tagVersion(newTag)
+
+	ui.Info("📦 Current version: %s", ifEmpty(currentTag, "none"))
+	ui.Info("🔀 Merged release: %s (%s)", newTag, shortSHA(release.Hash))
+	if !relJSON {
+		fmt.Println()
+		ui.Success("📋 Release Notes:")
+		fmt.Println(strings.Repeat("─", 60))
+		ui.Document(notes)
+		fmt.Println(strings.Repeat("─", 60))
+	}
+
+	if relDryRun {
+		plan := planRelease(cfg, currentTag, newVersion, len(commits), notes, "")
+		plan.Commit = release.Hash
+		return printReleasePlan(plan)
+	}
+
+	if !flagYes {
+		fmt.Printf("\n⚡ Create tag %s on %s? [Y/n]: ", newTag, shortSHA(release.Hash))
+		var input string
+		fmt.Scanln(&input)
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input == "n" || input == "no" {
+			ui.Warn("Release cancelled.")
+			return nil
+		}
+	}
+	return tagRelease(cfg, host, release.Hash, currentTag, newTag, newVersion, notes, "")
+}
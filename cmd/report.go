@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/report"
+)
+
+var (
+	repSince  string
+	repUntil  string
+	repOutput string
+	repTop    int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a repository health report for a period with AI",
+	Long: `Write a repository health report for a period with AI.
+
+The history of the period is analyzed locally (commits, contributors, churn
+per file and directory), then the AI writes the narrative: summary, themes,
+hotspots, contributors and risky areas (high churn by many authors). The
+figures are appended as tables. Merge commits are skipped.`,
+	RunE:         runReport,
+	SilenceUsage: true,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&repSince, "since", "1 month ago", "Start of the period (any date git accepts; \"all\" for the whole history)")
+	reportCmd.Flags().StringVar(&repUntil, "until", "", "End of the period (default: now)")
+	reportCmd.Flags().StringVarP(&repOutput, "output", "o", "", "Write the report to this file instead of printing it")
+	reportCmd.Flags().IntVar(&repTop, "top", 10, "Rows per table")
+	reportCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := ensureProvider(cfg); err != nil {
+		return err
+	}
+
+	since := repSince
+	if since == "all" {
+		since = ""
+	}
+	commits, err := git.CommitStats(since, repUntil)
+	if err != nil {
+		return err
+	}
+	activity := report.Analyze(since, repUntil, commits)
+	if activity.Commits == 0 {
+		ui.Warn("No commits %s.", activity.Period())
+		return nil
+	}
+
+	client := newClient(cfg)
+	ui.Info("📈 Writing report on %d commit(s) %s with %s...", activity.Commits, activity.Period(), client.ProviderName())
+	narrative, err := client.GenerateReport(activity)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Repository report\n\n")
+	fmt.Fprintf(&sb, "_%d commit(s) by %d contributor(s) %s, generated %s._\n\n", activity.Commits, len(activity.Authors), activity.Period(), time.Now().Format("2006-01-02"))
+	sb.WriteString(narrative + "\n\n")
+	sb.WriteString("## Figures\n\n")
+	sb.WriteString(activity.Tables(repTop))
+
+	if repOutput == "" {
+		fmt.Println()
+		ui.Document(sb.String())
+		return nil
+	}
+	if err := os.WriteFile(repOutput, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	ui.Success("✅ Report written to %s", repOutput)
+	return nil
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+var (
+	rewRange  string
+	rewDryRun bool
+	rewPushed bool
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword",
+	Short: "Regenerate the messages of a branch's commits from their diffs",
+	Long: `Regenerate the messages of a branch's commits from their diffs.
+
+Each commit in the range gets a new message generated from its own diff,
+shown as a word diff against the current one to accept, edit or skip. The
+accepted messages are then applied in one pass, as an interactive rebase
+that only rewords would: trees, authors and dates are kept and the working
+tree is not touched. The previous branch tip is saved in
+refs/commitai/backup/<timestamp> (see commitai restore-backup).
+
+The range defaults to the commits since the branch left the default branch
+(origin's HEAD, main or master) and must end at HEAD. Merge commits can't be
+reworded, and commits already pushed upstream are only reworded with --pushed.`,
+	RunE:         runReword,
+	SilenceUsage: true,
+}
+
+func init() {
+	rewordCmd.Flags().StringVar(&rewRange, "range", "", "Commits to reword, e.g. main..HEAD (default: since the default branch)")
+	rewordCmd.Flags().BoolVarP(&rewDryRun, "dry-run", "d", false, "Show the new messages without rewriting history")
+	rewordCmd.Flags().BoolVar(&rewPushed, "pushed", false, "Also reword commits already pushed upstream (they will need a force push)")
+	rewordCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Accept every new message without asking")
+	rewordCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runReword(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	branch, _ := git.CurrentBranch()
+	if branch == "" || branch == "HEAD" {
+		return fmt.Errorf("reword needs a checked-out branch (HEAD is detached)")
+	}
+
+	from, err := rewordBase()
+	if err != nil {
+		return err
+	}
+	head, err := git.HeadCommit()
+	if err != nil {
+		return err
+	}
+	commits, err := git.RangeCommits(from, head)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		ui.Warn("No commits to reword.")
+		return nil
+	}
+	upstream := git.UpstreamBase()
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return fmt.Errorf("%s is a merge commit, which reword can't rewrite; narrow the --range", shortSHA(c.Hash))
+		}
+		if upstream != "" && !rewPushed && git.IsAncestor(c.Hash, upstream) {
+			return fmt.Errorf("%s is already pushed upstream; narrow the --range, or use --pushed and force push afterwards", shortSHA(c.Hash))
+		}
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+	recentCommits := recentCommits(cfg, nil)
+
+	ui.Info("✨ Rewording %d commit(s) of %s with %s...", len(commits), branch, client.ProviderName())
+	messages := make([]string, len(commits)) // "" keeps the current message
+	reworded := 0
+	for i, c := range commits {
+		old := fullMessage(c)
+		fmt.Println()
+		ui.Info("[%d/%d] %s %s", i+1, len(commits), shortSHA(c.Hash), c.Subject)
+
+		parent := emptyTree
+		if len(c.Parents) > 0 {
+			parent = c.Parents[0]
+		}
+		changes, err := git.ChangesBetween(parent, c.Hash)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			ui.Muted("No changes — kept as is.")
+			continue
+		}
+		generated, err := client.GenerateCommitMessages(changes, false, recentCommits)
+		if err != nil {
+			return fmt.Errorf("AI generation failed: %w\nNothing was rewritten", err)
+		}
+		msg := strings.TrimSpace(generated["__all__"])
+		if msg == "" || msg == old {
+			ui.Muted("Same message — kept as is.")
+			continue
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Println(formatWordDiff(message.WordDiff(old, msg)))
+		fmt.Println(strings.Repeat("─", 60))
+		violations := checkMessage(cfg, msg)
+		reportViolations(violations)
+		if rewDryRun {
+			continue
+		}
+		if flagYes && len(violations) > 0 {
+			ui.Warn("Kept the current message: the new one violates policy.")
+			continue
+		}
+
+		chosen, ok := confirmOrEdit(msg, flagYes, nil)
+		if !ok || chosen == "" || chosen == old {
+			ui.Muted("Kept the current message.")
+			continue
+		}
+		if chosen != msg {
+			if violations := checkMessage(cfg, chosen); len(violations) > 0 {
+				reportViolations(violations)
+				ui.Warn("Kept the current message: the edited one violates policy.")
+				continue
+			}
+		}
+		if cfg.ProvenanceTrailers && !cfg.PrivacyMode {
+			chosen = message.Provenance(chosen, Version, cfg.Model, chosen != msg)
+		}
+		messages[i] = chosen
+		reworded++
+	}
+
+	if rewDryRun {
+		ui.Warn("\n🔍 Dry run — history was not rewritten.")
+		return nil
+	}
+	if reworded == 0 {
+		ui.Warn("\nNo message changed; history was not rewritten.")
+		return nil
+	}
+	if !flagYes {
+		fmt.Printf(i18n.T("\n⚡ Reword %d of %d commit(s) on %s? [y/N]: "), reworded, len(commits), branch)
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !i18n.Yes(strings.TrimSpace(strings.ToLower(input))) {
+			ui.Warn("Reword cancelled.")
+			return nil
+		}
+	}
+
+	parent := ""
+	if len(commits[0].Parents) > 0 {
+		parent = commits[0].Parents[0]
+	}
+	for i, c := range commits {
+		msg := messages[i]
+		if msg == "" {
+			msg = fullMessage(c)
+		}
+		if parent, err = git.CommitTree(c.Hash, parent, msg, c.Hash); err != nil {
+			return err
+		}
+	}
+
+	if err := saveBackup("refs/heads/"+branch, "reword"); err != nil {
+		return err
+	}
+	if err := git.UpdateRef("refs/heads/"+branch, parent, head, "commitai reword"); err != nil {
+		return err
+	}
+	ui.Success("\n✅ Reworded %d commit(s).", reworded)
+	printRecovery()
+	return nil
+}
+
+// rewordBase returns the commit the range starts after ("" for the whole
+// history), checking that the range ends at HEAD
+func rewordBase() (string, error) {
+	if rewRange == "" {
+		base := git.DefaultBranch()
+		if base == "" {
+			return "", fmt.Errorf("can't tell which branch this one started from; use --range, e.g. main..HEAD")
+		}
+		return git.MergeBase(base, "HEAD")
+	}
+
+	from, to, ok := strings.Cut(rewRange, "..")
+	if !ok || strings.HasPrefix(to, ".") {
+		return "", fmt.Errorf("invalid --range %q (expected <base>..HEAD)", rewRange)
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	end, err := git.ResolveCommit(to)
+	if err != nil {
+		return "", err
+	}
+	if head, _ := git.HeadCommit(); end != head {
+		return "", fmt.Errorf("--range must end at HEAD: only the checked-out branch can be reworded")
+	}
+	if from == "" {
+		return "", nil
+	}
+	return git.ResolveCommit(from)
+}
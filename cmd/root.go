@@ -2,17 +2,36 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
-	"os/exec"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/anonymize"
+	"github.com/kaiqui/commitai/internal/app"
+	"github.com/kaiqui/commitai/internal/budget"
 	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/conventions"
+	"github.com/kaiqui/commitai/internal/deps"
+	"github.com/kaiqui/commitai/internal/embedding"
 	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/githooks"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/notify"
+	"github.com/kaiqui/commitai/internal/plan"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/readonly"
+	"github.com/kaiqui/commitai/internal/repolock"
+	"github.com/kaiqui/commitai/internal/vcs"
 )
 
 var (
@@ -20,108 +39,706 @@ var (
 	flagAll      bool
 	flagAutoMode bool
 	flagDryRun   bool
+	flagPush     bool
+	flagComment  bool
 	flagYes      bool
 	flagLanguage string
 	flagStyle    string
+
+	flagNoCloseIssues bool
+	flagAuthor        string
+	flagDate          string
+	flagMaxCost       float64
+	flagCompress      int
+	flagGroupBy       string
+	flagPlanOut       string
+
+	flagNoBranchContext bool
+	flagAnonymize       bool
+	flagAnonymizePaths  string
+	flagFileHistory     int
+	flagImpact          bool
+	flagFixups          bool
+	flagBisectable      bool
 )
 
+// repoVCS commits the changes of the main flow: git, or jj, Sapling or
+// Mercurial when the repository uses them
+var repoVCS vcs.VCS = vcs.Git{}
+
+var authorRe = regexp.MustCompile(`^[^<>]+ <[^<>]+>$`)
+
 var rootCmd = &cobra.Command{
 	Use:   "commitai",
 	Short: "🤖 AI-powered git commit messages using Google Gemini",
 	Long: `commitai generates intelligent git commit messages using Google Gemini AI.
 
-It analyzes your staged changes and suggests meaningful commit messages.
-
-Examples:
-  commitai              # Auto-detect: single message or granular based on file count
-  commitai --all        # One message for all staged changes
-  commitai --granular   # Separate message per file
-  commitai --dry-run    # Preview messages without committing
-  commitai config       # Configure API key and preferences
-  commitai release      # Create a tagged release with AI-generated notes`,
-	RunE: runCommit,
+It analyzes your staged changes and suggests meaningful commit messages.`,
+	RunE:              runCommit,
+	PersistentPreRunE: setupUI,
 }
 
 func Execute() error {
+	// commitai <name> runs a commitai-<name> plugin from PATH
+	if handled, err := executePlugin(); handled {
+		return err
+	}
+	applyHelp(rootCmd)
 	return rootCmd.Execute()
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", "", "Output format: rich, plain or markdown")
+	rootCmd.PersistentFlags().BoolVar(&flagLocalOnly, "local-only", false, "Fail unless the AI provider is on this machine (offline or a localhost gateway)")
+	rootCmd.PersistentFlags().BoolVar(&flagDeterministic, "deterministic", false, "Generate at temperature 0 with a fixed seed, so reruns on the same input give the same text")
+	rootCmd.PersistentFlags().IntVar(&flagSeed, "seed", 0, "Seed of deterministic mode (implies --deterministic)")
+	rootCmd.PersistentFlags().BoolVar(&flagReadOnly, "read-only", false, "Never commit, tag, stage or write files: commands only preview what they would do")
+	rootCmd.PersistentFlags().CountVar(&flagVerbose, "verbose", "Show the git commands run on stderr; repeat for what git prints on stderr (2) and stdout (3)")
 	rootCmd.Flags().BoolVarP(&flagGranular, "granular", "g", false, "Generate separate commit per staged file")
+	rootCmd.Flags().StringVar(&flagGroupBy, "group-by", "", "One commit per group instead of per file: dir, package, type or owner (CODEOWNERS)")
+	rootCmd.Flags().BoolVar(&flagFixups, "fixups", false, "Commit files that only change lines of one unpushed commit as fixup! commits of it (implies --granular)")
+	rootCmd.Flags().BoolVar(&flagBisectable, "bisectable", false, "In Go modules, reorder or merge per-file and per-group commits so that each one builds (go build, go vet)")
+	rootCmd.Flags().StringVar(&flagPlanOut, "plan-out", "", "Write the commit plan to this YAML file instead of committing (see apply-plan)")
 	rootCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "Generate one commit message for all staged changes")
 	rootCmd.Flags().BoolVar(&flagAutoMode, "auto", true, "Auto-detect commit mode based on staged files (default)")
 	rootCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "d", false, "Preview commit messages without committing")
+	rootCmd.Flags().BoolVar(&flagPush, "push", false, "Push the branch after committing, setting origin as upstream when it has none")
+	rootCmd.Flags().BoolVar(&flagComment, "comment-issues", false, "Post each commit's message and diffstat on the issues it references (GitHub, GitLab, Gitea, Jira)")
 	rootCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip confirmation prompts")
 	rootCmd.Flags().StringVarP(&flagLanguage, "lang", "l", "", "Language for messages (en, pt-br)")
-	rootCmd.Flags().StringVar(&flagStyle, "style", "", "Commit style (conventional, simple)")
+	rootCmd.Flags().StringVar(&flagStyle, "style", "", "Commit style (conventional, simple, auto)")
+	rootCmd.Flags().StringVar(&flagAuthor, "author", "", "Override the commit author (\"Name <email>\")")
+	rootCmd.Flags().StringVar(&flagDate, "date", "", "Override the author and committer date of created commits")
+	rootCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+	rootCmd.Flags().IntVar(&flagFileHistory, "file-history", 0, "Send the last N commit subjects of each modified file")
+	rootCmd.Flags().BoolVar(&flagNoBranchContext, "no-branch-context", false, "Don't send the branch name to the AI")
+	rootCmd.Flags().BoolVar(&flagAnonymize, "anonymize", false, "Replace string literals, emails, hostnames and URLs in diffs with placeholders before sending them")
+	rootCmd.Flags().StringVar(&flagAnonymizePaths, "anonymize-paths", "", "Hide file paths from the AI provider (generalize, hash)")
+	rootCmd.Flags().IntVar(&flagCompress, "compress", 0, "Compress diffs in the prompt to save tokens: 1 trims context, 2 also collapses repeats and moves, 3 drops all context")
+	rootCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on a protected branch")
+	rootCmd.Flags().StringVar(&flagDiffFile, "diff-file", "", "Describe the changes in this patch file (- for stdin) instead of the staged ones, and only print the message")
+	rootCmd.Flags().StringSliceVar(&flagDiffPaths, "diff-paths", nil, "With --diff-file, describe only these files or directories")
+	rootCmd.Flags().BoolVar(&flagImpact, "impact", false, "End messages with an \"Impact: <area>; <risk> risk\" line for reviewers")
+	rootCmd.Flags().BoolVar(&flagAgent, "agent", false, "For AI agents and automation: print the commit plan as JSON with an approval token; nothing is committed without --approve")
+	rootCmd.Flags().StringVar(&flagApprove, "approve", "", "Commit the plan last printed by --agent, if this is its approval token and nothing changed since")
+	rootCmd.Flags().BoolVar(&flagNoCloseIssues, "no-close-issues", false, "Don't add issue closing keywords or work item links (e.g. Closes #123, AB#45)")
 
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(releaseCmd)
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(sayCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(ownersCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(suggestTestsCmd)
+	rootCmd.AddCommand(squashCmd)
+	rootCmd.AddCommand(handoffCmd)
+	rootCmd.AddCommand(prCmd)
+	rootCmd.AddCommand(seriesCmd)
+	rootCmd.AddCommand(askCmd)
+	rootCmd.AddCommand(applyPlanCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(worktreesCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(checkpointCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(tidyCmd)
+	rootCmd.AddCommand(rewordCmd)
+	rootCmd.AddCommand(restoreBackupCmd)
+	rootCmd.AddCommand(rpcCmd)
+	rootCmd.AddCommand(vscodeBridgeCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(editorCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(policyCmd)
+	rootCmd.AddCommand(ciCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	rootCmd.AddCommand(demoCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(examplesCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
-	// Validate git repo
-	if !git.IsGitRepo() {
-		return fmt.Errorf("not a git repository")
+	if flagDiffFile != "" {
+		return runDiffFile()
 	}
 
-	// Load config
-	cfg, err := config.Load()
+	repoVCS = vcs.Detect()
+	if repoVCS == nil {
+		return fmt.Errorf("not a git, jj, Sapling or Mercurial repository")
+	}
+	_, isGit := repoVCS.(vcs.Git)
+	if !isGit && (flagGranular || flagGroupBy != "" || flagPlanOut != "" || flagFixups || flagBisectable || flagPush || flagComment) {
+		return fmt.Errorf("%s commits the whole working-copy change: --granular, --group-by, --plan-out, --fixups, --bisectable, --push and --comment-issues need git", repoVCS.Name())
+	}
+	if flagBisectable && flagAll {
+		return fmt.Errorf("--bisectable orders several commits; it can't be combined with --all")
+	}
+	if flagFixups && (flagAll || flagGroupBy != "") {
+		return fmt.Errorf("--fixups commits file by file; it can't be combined with --all or --group-by")
+	}
+	if _, isJJ := repoVCS.(vcs.Jujutsu); isJJ && (flagAuthor != "" || flagDate != "") {
+		return fmt.Errorf("--author and --date are not supported with jj; use jj describe --author")
+	}
+
+	// Agent mode: stdout carries the plan, and only an approved one is
+	// committed
+	agent := agentMode()
+	var agentOut io.Writer
+	if agent {
+		if !isGit {
+			return fmt.Errorf("agent mode needs git")
+		}
+		if flagPlanOut != "" {
+			return fmt.Errorf("agent mode prints the plan; it can't be combined with --plan-out")
+		}
+		agentOut = agentOutput()
+		flagDryRun = flagApprove == ""
+	}
+
+	cfg, err := loadCommitConfig(flagLanguage, flagStyle)
 	if err != nil {
 		return err
 	}
-	if err := cfg.Validate(); err != nil {
-		color.Yellow("⚠️  %s", err)
-		return nil
+	if !flagDryRun && flagPlanOut == "" {
+		if err := checkProtectedBranch(cfg, "commit"); err != nil {
+			return err
+		}
+		warnSkippedHooks(cfg)
 	}
-
-	// Override config with flags
-	if flagLanguage != "" {
-		cfg.Language = flagLanguage
+	if isGit {
+		warnCheckoutState()
 	}
-	if flagStyle != "" {
-		cfg.CommitStyle = flagStyle
+	if flagApprove != "" {
+		return approvePlan(cfg, agentOut, flagApprove)
+	}
+
+	if flagAuthor != "" && !authorRe.MatchString(flagAuthor) {
+		return fmt.Errorf("invalid --author %q (expected \"Name <email>\")", flagAuthor)
 	}
 
 	// Get staged changes
-	color.Cyan("🔍 Analyzing staged changes...")
-	changes, err := git.StagedChanges()
+	ui.Info("🔍 Analyzing staged changes...")
+	changes, err := repoVCS.Changes()
 	if err != nil {
 		return err
 	}
+	if isGit {
+		suggestedIndex, _ = git.IndexTree()
+	}
 
 	if len(changes) == 0 {
-		color.Yellow("No staged changes found. Use 'git add' to stage files.")
+		if !isGit {
+			ui.Warn("No changes in the %s working copy.", repoVCS.Name())
+			return nil
+		}
+		ui.Warn("No staged changes found. Use 'git add' to stage files.")
 		return nil
 	}
+	if err := checkBannedChanges(cfg, changes); err != nil {
+		return err
+	}
 
 	// Determine mode
-	granular := determineMode(changes)
+	granular := isGit && (flagFixups || determineMode(changes))
+	lockfiles := deps.PairLockfiles(changes)
 
-	// Print what we found
-	color.Cyan("\n📂 Staged files (%d):", len(changes))
-	for _, c := range changes {
-		statusIcon := statusToIcon(c.Status)
-		fmt.Printf("  %s %s\n", statusIcon, c.Path)
+	// Show what each file costs in the prompt, and let heavy diffs be left out
+	costs := ai.FileCosts(changes, granular, compressLevel(cfg))
+	printDiffCosts(changes, costs)
+	leaveOutDiffs(changes, costs)
+
+	var groups []ai.ChangeGroup
+	if flagGroupBy != "" {
+		if flagAll || flagGranular {
+			return fmt.Errorf("--group-by can't be combined with --all or --granular")
+		}
+		if groups, err = ai.GroupChanges(changes, flagGroupBy); err != nil {
+			return err
+		}
+	}
+
+	// Files that only touch lines of one unpushed commit fix that commit up
+	// and need no message
+	var fixups []plan.Commit
+	if flagFixups {
+		fixups, changes = splitFixups(changes, lockfiles)
+		if len(fixups) > 0 {
+			ui.Info("\n🩹 Fixups of unpushed commits (%d):", len(fixups))
+			for _, f := range fixups {
+				fmt.Printf("  %s → %s %s\n", f.Name, shortSHA(f.Fixup), strings.TrimPrefix(f.Message, "fixup! "))
+			}
+		}
+	}
+
+	// Per file, a lockfile is committed with its manifest, not on its own
+	if granular && groups == nil {
+		changes = withoutLockfiles(changes, lockfiles)
 	}
 
 	// Get recent commits for context
-	recentCommits, _ := git.RecentCommits(5)
+	recentCommits := recentCommits(cfg, changes)
+	addFileHistory(cfg, changes)
+
+	// Generate messages (ONE request to the provider for all files)
+	client := newClient(cfg)
+	service := commitService(cfg, client)
+	messages := make(map[string]string)
+	if len(changes) > 0 {
+		ui.Info("\n✨ Generating commit message(s) with %s...", client.ProviderName())
+		start := time.Now()
+		if groups != nil {
+			messages, err = client.GenerateGroupMessages(groups, recentCommits)
+			for k, msg := range messages {
+				messages[k] = finishMessage(cfg, msg)
+			}
+		} else {
+			messages, err = service.Generate(changes, granular, recentCommits)
+		}
+		if err != nil {
+			notifyAfter(cfg, start, "Commit message generation failed")
+			return fmt.Errorf("AI generation failed: %w", err)
+		}
+		suggestedConfidence = client.Confidence()
+		notifyAfter(cfg, start, fmt.Sprintf("%d commit message(s) ready for review", len(messages)))
+		if err := reportFlagged(client.Flagged(), flagYes && !flagDryRun && flagPlanOut == ""); err != nil {
+			return err
+		}
+	}
+
+	if agent {
+		return showAgentPlan(agentOut, planCommits(changes, groups, granular, messages, fixups))
+	}
+	if flagPlanOut != "" {
+		return writePlan(flagPlanOut, changes, groups, granular, messages, fixups)
+	}
+	compareWithLastSuggestion(cfg, changes, messages)
+
+	// Display and confirm
+	before, _ := git.HeadCommit()
+	switch {
+	case groups != nil:
+		err = handleGroupCommits(cfg, groups, messages, flagDryRun, flagYes)
+	case granular:
+		err = handleGranularCommits(cfg, changes, fixups, messages, flagDryRun, flagYes)
+	default:
+		suggestion := messages["__all__"]
+		err = handleSingleCommit(cfg, suggestion, client.NewChat(changes, recentCommits, suggestion), flagDryRun, flagYes)
+	}
+	if err == nil {
+		updateSquashDraft(cfg, before)
+		if err = pushCommits(before); err == nil {
+			commentIssues(cfg, before)
+		}
+	}
+	return err
+}
+
+// pushCommits pushes the branch with --push once commits were made since
+// before; nothing is pushed when the commit was cancelled
+func pushCommits(before string) error {
+	if !flagPush || flagDryRun {
+		return nil
+	}
+	if head, _ := git.HeadCommit(); head == before {
+		return nil
+	}
+	target := git.Upstream()
+	if target == "" {
+		target = "origin (new upstream)"
+	}
+	ui.Info("\n📤 Pushing to %s...", target)
+	if err := git.PushBranch(); err != nil {
+		return err
+	}
+	ui.Success("✅ Pushed")
+	return nil
+}
+
+// checkPathMode validates an anonymize_paths mode; empty is off
+func checkPathMode(mode string) error {
+	switch mode {
+	case "", config.PathsGeneralize, config.PathsHash:
+		return nil
+	}
+	return fmt.Errorf("invalid anonymize paths mode %q (expected %s or %s)", mode, config.PathsGeneralize, config.PathsHash)
+}
+
+// loadCommitConfig loads the config with the effective policy applied and
+// the given language/style overrides, making sure a provider is usable.
+func loadCommitConfig(lang, style string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	// Before the consent prompt, which lists what is sent
+	if flagAnonymize {
+		cfg.Anonymize = true
+	}
+	if flagAnonymizePaths != "" {
+		cfg.AnonymizePaths = flagAnonymizePaths
+	}
+	if err := checkPathMode(cfg.AnonymizePaths); err != nil {
+		return nil, err
+	}
+	if err := ensureProvider(cfg); err != nil {
+		return nil, err
+	}
+	if err := applyPolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	if lang != "" {
+		cfg.Language = lang
+	}
+	if style != "" {
+		cfg.CommitStyle = style
+	}
+	applyConventions(cfg)
+	if flagImpact {
+		cfg.ImpactLine = true
+	}
+	if !flagNoBranchContext && !cfg.NoBranchContext && !cfg.PrivacyMode {
+		cfg.Branch, _ = git.CurrentBranch()
+	}
+	if !cfg.NoCommitTemplate {
+		tmpl, err := git.CommitTemplate()
+		if err != nil {
+			ui.Warn("⚠️  Ignoring the commit template: %s", err)
+		}
+		cfg.CommitTemplate, cfg.CommentChar = tmpl, git.CommentChar()
+	}
+	return cfg, nil
+}
+
+// warnCheckoutState says what a detached HEAD or a shallow clone (the usual
+// CI checkout) limits, rather than leaving later git calls to fail obscurely
+func warnCheckoutState() {
+	if branch, err := git.CurrentBranch(); err == nil && branch == "HEAD" {
+		ui.Warn("⚠️  HEAD is detached: the commit won't be on any branch and the message gets no branch context (git switch -c <name> keeps it)")
+	}
+	if git.IsShallow() {
+		ui.Warn("⚠️  Shallow clone: only %d commit(s) of history were fetched, so history context is limited (git fetch --unshallow fetches the rest)", git.CountCommits("HEAD"))
+	}
+}
+
+// repoConventions returns the conventions analysis of the repository,
+// cached under .git/commitai (in memory only in privacy mode and shallow
+// clones)
+func repoConventions(cfg *config.Config) (*conventions.Analysis, error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return nil, err
+	}
+	top, err := git.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	// A shallow clone's history is partial: analyze it without caching
+	return conventions.Load(gitDir, top, !cfg.PrivacyMode && !git.IsShallow())
+}
+
+// applyConventions resolves the auto commit style and the scopes suggested
+// in prompts from the repository's history. Conventional Commits is the
+// fallback style when the history says nothing.
+func applyConventions(cfg *config.Config) {
+	auto := cfg.CommitStyle == config.StyleAuto
+	if !auto && (cfg.CommitStyle != "conventional" || len(cfg.Policy.AllowedScopes) > 0) {
+		return
+	}
+	if _, ok := repoVCS.(vcs.Git); !ok {
+		if auto {
+			cfg.CommitStyle = "conventional"
+		}
+		return
+	}
+	a, err := repoConventions(cfg)
+	if err != nil {
+		ui.Warn("⚠️  Couldn't analyze the repository's conventions: %s", err)
+		a = &conventions.Analysis{}
+	}
+	if auto {
+		cfg.CommitStyle = ifEmpty(a.Style, "conventional")
+	}
+	if cfg.CommitStyle == "conventional" && len(cfg.Policy.AllowedScopes) == 0 {
+		cfg.RepoScopes = a.ScopeList()
+	}
+}
+
+// recentCommits returns the history sent as style context, narrowed as
+// configured; changes are the staged files for recent_same_paths
+func recentCommits(cfg *config.Config, changes []git.FileChange) []string {
+	if cfg.RecentCommits <= 0 {
+		return nil
+	}
+	if _, ok := repoVCS.(vcs.Git); !ok {
+		commits, _ := repoVCS.RecentCommits(cfg.RecentCommits)
+		return commits
+	}
+	if cfg.RecentRelevant && len(changes) > 0 {
+		commits, err := relevantCommits(cfg, changes)
+		if err == nil {
+			return commits
+		}
+		ui.Warn("⚠️  Using the latest commits as context: %s", err)
+	}
+	opts := git.LogOptions{N: cfg.RecentCommits, Since: cfg.RecentSince}
+	if cfg.RecentSameAuthor {
+		if email := git.UserEmail(); email != "" {
+			opts.Author = "<" + email + ">"
+		}
+	}
+	if cfg.RecentSamePaths {
+		for _, c := range changes {
+			opts.Paths = append(opts.Paths, c.Path)
+		}
+	}
+	commits, _ := git.RecentCommits(opts) // No history yet is fine
+	return commits
+}
+
+// relevantCommits returns the past commits most similar to changes, as
+// "<hash> <subject>" lines like git log --oneline, updating the local index
+// first. Privacy mode and shallow clones keep the index in memory.
+func relevantCommits(cfg *config.Config, changes []git.FileChange) ([]string, error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return nil, err
+	}
+	head, err := git.HeadCommit()
+	if err != nil {
+		return nil, nil // No history yet
+	}
+	ix, err := embedding.Load(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	indexed := ix.Head
+	if _, err := ix.Update(head); err != nil {
+		return nil, err
+	}
+	if ix.Head != indexed && !cfg.PrivacyMode && !git.IsShallow() {
+		if err := ix.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	var since time.Time
+	if cfg.RecentSince != "" {
+		if since, err = git.ParseDate(cfg.RecentSince); err != nil {
+			return nil, err
+		}
+	}
+	email := ""
+	if cfg.RecentSameAuthor {
+		email = git.UserEmail()
+	}
+	keep := func(e embedding.Entry) bool {
+		return !e.Date.Before(since) && (email == "" || strings.EqualFold(e.Email, email))
+	}
+
+	var commits []string
+	for _, e := range ix.Nearest(embedding.EmbedChanges(changes), cfg.RecentCommits, keep) {
+		commits = append(commits, shortSHA(e.Hash)+" "+e.Subject)
+	}
+	return commits, nil
+}
+
+// maxHistoryFiles bounds the git log calls made for file history context
+const maxHistoryFiles = 50
+
+// addFileHistory attaches the last subjects of each modified file (up to
+// maxHistoryFiles files) when file history context is enabled
+func addFileHistory(cfg *config.Config, changes []git.FileChange) {
+	n := cfg.FileHistory
+	if flagFileHistory > 0 {
+		n = flagFileHistory
+	}
+	if n <= 0 {
+		return
+	}
+	looked := 0
+	for i := range changes {
+		if strings.HasPrefix(changes[i].Status, "A") || strings.HasPrefix(changes[i].Status, "D") {
+			continue // New files have no history; deleted ones get their last subject already
+		}
+		if looked == maxHistoryFiles {
+			break
+		}
+		looked++
+		changes[i].History, _ = git.FileSubjects(changes[i].Path, n)
+	}
+}
+
+// applyPolicy resolves the effective policy into cfg
+func applyPolicy(cfg *config.Config) error {
+	// Organization policy may pin the language; explicit flags still win
+	pol, err := policy.Resolve(cfg)
+	if err != nil {
+		return err
+	}
+	if pol.FetchErr != nil {
+		ui.Warn("⚠️  Using cached policy bundle: %s", pol.FetchErr)
+	}
+	if pol.CommitlintErr != nil {
+		ui.Warn("⚠️  Ignoring the commitlint config: %s", pol.CommitlintErr)
+	}
+	cfg.Policy = pol.Policy
+	if pol.Policy.Language != "" {
+		cfg.Language = pol.Policy.Language
+	}
+	return nil
+}
+
+// commitService runs the commit flow against this repository: the
+// configured history as context, messages finished and checked like the
+// ones the CLI shows, and the repository locked while committing. client
+// may be nil when only committing.
+func commitService(cfg *config.Config, client *ai.GeminiClient) *app.CommitService {
+	s := &app.CommitService{
+		Config: cfg,
+		Repo:   repoVCS,
+		Out:    ui,
+		Context: func(changes []git.FileChange) []string {
+			return recentCommits(cfg, changes)
+		},
+		// Fix the subject style, add type emojis, fill in the commit
+		// template and close referenced issues
+		Finish: func(msg string) string {
+			return finishMessage(cfg, msg)
+		},
+		Check: func(msg string) []policy.Violation {
+			return checkMessage(cfg, msg)
+		},
+		Lock: func() (func(), error) {
+			lock, err := lockRepo()
+			if err != nil {
+				return nil, err
+			}
+			return lock.Release, nil
+		},
+		Version: Version,
+	}
+	if client != nil {
+		s.AI = client
+	}
+	return s
+}
+
+// newClient creates the AI client with a token/cost preview before every
+// call, enforcing --max-cost (or max_cost from the config). With anonymize
+// on, the placeholder mapping is saved in .git before each call.
+func newClient(cfg *config.Config) *ai.GeminiClient {
+	maxCost := cfg.MaxCost
+	if flagMaxCost > 0 {
+		maxCost = flagMaxCost
+	}
+	if flagCompress > 0 {
+		cfg.Compress = flagCompress
+	}
+	usage := dailyUsage(cfg)
 
-	// Generate messages (ONE request to Gemini for all files)
-	color.Cyan("\n✨ Generating commit message(s) with Gemini...")
 	client := ai.NewGeminiClient(cfg)
-	messages, err := client.GenerateCommitMessages(changes, granular, recentCommits)
+	if cfg.Anonymize || cfg.AnonymizePaths != "" {
+		client.Anon = anonymize.New()
+		if gitDir, err := git.Dir(); err == nil {
+			if m, err := anonymize.Load(gitDir); err == nil {
+				client.Anon = m
+			} else {
+				ui.Warn("⚠️  %s; starting a new mapping", err)
+			}
+		}
+		client.Anon.PathMode = cfg.AnonymizePaths
+		client.Anon.PathsOnly = !cfg.Anonymize
+	}
+	client.BeforeCall = func(est ai.Estimate) error {
+		if err := client.Anon.Save(); err != nil {
+			return fmt.Errorf("failed to save the anonymization mapping: %w", err)
+		}
+		if est.SavedTokens > 0 {
+			ui.Muted("🗜️  Compression saved ~%d input tokens (%d%%)", est.SavedTokens, 100*est.SavedTokens/(est.InputTokens+est.SavedTokens))
+		}
+		if !est.Priced {
+			ui.Muted("🧮 ~%d input tokens, up to %d output tokens", est.InputTokens, est.MaxOutputTokens)
+			return nil
+		}
+		ui.Muted("🧮 ~%d input tokens, up to %d output tokens, est. max $%.4f (%s)", est.InputTokens, est.MaxOutputTokens, est.Cost, est.Model)
+		if maxCost > 0 && est.Cost > maxCost {
+			return fmt.Errorf("estimated cost $%.4f exceeds the $%.4f limit; unstage large files (e.g. vendored code) or raise --max-cost", est.Cost, maxCost)
+		}
+		return nil
+	}
+	if usage != nil {
+		client.AfterCall = func(tokens int) {
+			if err := usage.Add(tokens); err != nil {
+				ui.Warn("⚠️  Failed to update the AI usage counter: %s", err)
+			}
+		}
+	}
+	return client
+}
+
+// dailyUsage returns the repository's AI usage today when a daily budget is
+// set, switching to the offline generator once the budget is used up
+func dailyUsage(cfg *config.Config) *budget.Usage {
+	if cfg.DailyCalls == 0 && cfg.DailyTokens == 0 || cfg.Provider == config.ProviderOffline {
+		return nil
+	}
+	gitDir, err := git.Dir()
 	if err != nil {
-		return fmt.Errorf("AI generation failed: %w", err)
+		return nil
 	}
+	usage, err := budget.Load(gitDir)
+	if err != nil {
+		ui.Warn("⚠️  %s; starting a new count", err)
+		usage = budget.New(gitDir)
+	}
+	if used := usage.Exceeded(cfg.DailyCalls, cfg.DailyTokens); used != "" {
+		ui.Warn("📉 Today's AI budget for this repository is used up (%s); falling back to the offline generator", used)
+		cfg.Provider = config.ProviderOffline
+		return nil
+	}
+	return usage
+}
 
-	// Display and confirm
-	if granular {
-		return handleGranularCommits(changes, messages, flagDryRun, flagYes)
+// finishMessage fixes the subject style the policy asks for, adds the
+// type's emoji, fits a generated message to the repository's commit
+// template and, unless --no-close-issues, closes the branch's issues
+func finishMessage(cfg *config.Config, msg string) string {
+	msg = policy.FixStyle(msg, cfg.Policy)
+	msg = policy.FixSections(msg, cfg.Policy)
+	msg = message.TypeEmoji(msg, cfg.TypeEmojis, cfg.TypeEmojiPosition)
+	msg = message.ParseTemplate(cfg.CommitTemplate, cfg.CommentChar).Fill(msg)
+	if flagNoCloseIssues {
+		return msg
+	}
+	return closeBranchIssues(cfg, msg)
+}
+
+// closeBranchIssues adds closing keywords for issues referenced by the branch
+// name, and links the Azure Boards work items (AB#N) it references
+func closeBranchIssues(cfg *config.Config, msg string) string {
+	branch, _ := git.CurrentBranch()
+	msg = message.EnsureWorkItems(msg, message.WorkItemsFromBranch(branch), cfg.ClosingKeyword)
+	if cfg.ClosingKeyword == "" {
+		return msg
 	}
-	return handleSingleCommit(messages["__all__"], flagDryRun, flagYes)
+	return message.EnsureClosingKeywords(msg, message.IssuesFromBranch(branch), cfg.ClosingKeyword)
+}
+
+// notifyAfter sends a desktop notification if the operation started at
+// start took longer than the configured threshold
+func notifyAfter(cfg *config.Config, start time.Time, body string) {
+	notify.After(time.Duration(cfg.NotifyAfter)*time.Second, start, "commitai", body)
+}
+
+// commitOptions carries --author/--date into every commit of the run
+func commitOptions() git.CommitOptions {
+	return git.CommitOptions{Author: flagAuthor, Date: flagDate}
 }
 
 func determineMode(changes []git.FileChange) bool {
@@ -132,126 +749,609 @@ func determineMode(changes []git.FileChange) bool {
 		return false
 	}
 	// Auto: granular if multiple files with different concerns
-	if len(changes) <= 1 {
-		return false
-	}
-	// Heuristic: granular if files are in different directories or different types
-	dirs := make(map[string]bool)
+	return app.AutoGranular(changes)
+}
+
+// withoutLockfiles leaves out the lockfiles committed with their manifest
+func withoutLockfiles(changes []git.FileChange, lockfiles map[string]bool) []git.FileChange {
+	var rest []git.FileChange
 	for _, c := range changes {
-		parts := strings.Split(c.Path, "/")
-		if len(parts) > 1 {
-			dirs[parts[0]] = true
+		if !lockfiles[c.Path] {
+			rest = append(rest, c)
 		}
 	}
-	return len(dirs) > 1 || len(changes) >= 3
+	return rest
 }
 
-func handleSingleCommit(message string, dryRun, skipConfirm bool) error {
+// handleSingleCommit shows the suggestion and commits it once confirmed.
+// With a chat, the user can refine the suggestion with instructions first.
+func handleSingleCommit(cfg *config.Config, suggestion string, chat *ai.Chat, dryRun, skipConfirm bool) error {
 	fmt.Println()
-	color.Green("💬 Suggested commit message:")
-	fmt.Println(strings.Repeat("─", 60))
-	fmt.Println(message)
-	fmt.Println(strings.Repeat("─", 60))
+	ui.Success("💬 Suggested commit message:")
+	showSuggestion(cfg, suggestion)
+	showConfidence("__all__")
 
 	if dryRun {
-		color.Yellow("\n🔍 Dry run — no commit was made.")
+		ui.Warn("\n🔍 Dry run — no commit was made.")
 		return nil
 	}
 
-	msg, confirmed := confirmOrEdit(message, skipConfirm)
+	var refine func(string) (string, error)
+	if chat != nil {
+		refine = func(instruction string) (string, error) {
+			ui.Info("✨ Refining...")
+			refined, err := chat.Refine(instruction)
+			if err != nil {
+				return "", err
+			}
+			refined = finishMessage(cfg, refined)
+			// A refined message is still AI-generated, not a manual edit
+			suggestion = refined
+			showSuggestion(cfg, refined)
+			return refined, nil
+		}
+	}
+
+	msg, confirmed := confirmOrEdit(suggestion, skipConfirm, refine)
 	if !confirmed {
-		color.Yellow("Commit cancelled.")
+		ui.Warn("Commit cancelled.")
 		return nil
 	}
 
-	if err := git.Commit(msg); err != nil {
+	// Locked before comparing the index, so no other run can change it in
+	// between
+	lock, err := lockRepo()
+	if err != nil {
 		return err
 	}
-	color.Green("\n✅ Committed successfully!")
+	defer lock.Release()
+	if err := checkStale(); err != nil {
+		return err
+	}
+	if _, isGit := repoVCS.(vcs.Git); isGit {
+		if err := checkBannedStaged(cfg); err != nil {
+			return err
+		}
+	}
+	svc := commitService(cfg, nil)
+	svc.Lock = nil // Already held
+	if err := svc.Commit(msg, msg != suggestion, commitOptions()); err != nil {
+		var violated *app.PolicyError
+		if errors.As(err, &violated) && msg != suggestion {
+			reportViolations(violated.Violations)
+		}
+		return err
+	}
+	ui.Success("\n✅ Committed successfully!")
 	return nil
 }
 
-func handleGranularCommits(changes []git.FileChange, messages map[string]string, dryRun, skipConfirm bool) error {
+func handleGranularCommits(cfg *config.Config, changes []git.FileChange, fixups []plan.Commit, messages map[string]string, dryRun, skipConfirm bool) error {
 	fmt.Println()
-	color.Green("💬 Suggested commit messages (per file):")
+	ui.Success("💬 Suggested commit messages (per file):")
 
-	type plan struct {
-		file    string
-		message string
+	// Every staged file must be covered by the AI answer before we commit anything
+	proceed, err := checkCoverage(changes, messages, dryRun, skipConfirm)
+	if err != nil || !proceed {
+		return err
 	}
-	var plans []plan
 
+	plans := fixups
 	for _, c := range changes {
 		msg, ok := messages[c.Path]
 		if !ok {
-			// Fallback: use generic message
+			// Only reached when the user accepted generic messages
 			msg = fmt.Sprintf("chore: update %s", c.Path)
 		}
-		plans = append(plans, plan{c.Path, msg})
+		plans = append(plans, plan.Commit{Name: c.Path, Files: append([]string{c.Path}, c.Lockfiles...), Message: msg})
+	}
+	if flagBisectable {
+		plans = bisectablePlans(plans)
+	}
+	if err := executePlans(cfg, plans, 0, dryRun, skipConfirm, planGenerator(cfg), nil); !errors.Is(err, errCommitCancelled) {
+		return err
+	}
+	return nil
+}
+
+// handleGroupCommits commits each --group-by group with its own message
+func handleGroupCommits(cfg *config.Config, groups []ai.ChangeGroup, messages map[string]string, dryRun, skipConfirm bool) error {
+	fmt.Println()
+	ui.Success("💬 Suggested commit messages (per group):")
+
+	var plans []plan.Commit
+	var missing []string
+	for _, gr := range groups {
+		p := plan.Commit{Name: gr.Name, Message: messages[gr.Name]}
+		for _, c := range gr.Changes {
+			p.Files = append(p.Files, c.Path)
+		}
+		if p.Message == "" {
+			missing = append(missing, gr.Name)
+			p.Message = fmt.Sprintf("chore: update %s", gr.Name)
+		}
+		plans = append(plans, p)
+	}
+	if len(missing) > 0 {
+		ui.Warn("\n⚠️  No AI message for %d of %d group(s): %s", len(missing), len(groups), strings.Join(missing, ", "))
+		switch {
+		case dryRun:
+		case skipConfirm:
+			return fmt.Errorf("incomplete AI coverage: %d group(s) without a message; re-run or use --all", len(missing))
+		case !confirmGeneric():
+			ui.Warn("Commit cancelled.")
+			return nil
+		}
 	}
+	if flagBisectable {
+		plans = bisectablePlans(plans)
+	}
+	if err := executePlans(cfg, plans, 0, dryRun, skipConfirm, planGenerator(cfg), nil); !errors.Is(err, errCommitCancelled) {
+		return err
+	}
+	return nil
+}
 
+// suggestedIndex is the index (see git.IndexTree) the messages of this run
+// were generated for; "" when they weren't generated from the index
+var suggestedIndex string
+
+// checkStale refuses to commit messages written for other staged changes,
+// e.g. when a file was staged while the prompt was open
+func checkStale() error {
+	if suggestedIndex == "" {
+		return nil
+	}
+	tree, err := git.IndexTree()
+	if err != nil || tree == suggestedIndex {
+		return err
+	}
+	return fmt.Errorf("the staged changes changed after the message was generated, so it no longer describes them; run commitai again for a new one")
+}
+
+// lockTimeout is how long a run waits for another one to finish committing
+const lockTimeout = 2 * time.Minute
+
+// lockRepo keeps other commitai runs in this repository from touching the
+// index until the returned lock is released. Outside git there is nothing
+// to lock and the lock is nil, which is safe to release.
+func lockRepo() (*repolock.Lock, error) {
+	dir, err := git.Dir()
+	if err != nil {
+		return nil, nil
+	}
+	return repolock.Acquire(dir, lockTimeout, func(pid int) {
+		if pid > 0 {
+			ui.Info("⏳ Waiting for another commitai run (PID %d) to finish committing...", pid)
+		} else {
+			ui.Info("⏳ Waiting for another commitai run to finish committing...")
+		}
+	})
+}
+
+// errCommitCancelled is returned by executePlans when the user declines
+var errCommitCancelled = errors.New("commit cancelled")
+
+// executePlans shows the planned commits, checks them against the policy
+// and, once confirmed, commits each plan's files separately. The first done
+// plans were committed by an earlier run and are skipped; committed, if set,
+// is called after each new commit. Messages of files that hooks change in
+// between are written again with regenerate. When the user declines to
+// commit, it returns errCommitCancelled.
+func executePlans(cfg *config.Config, plans []plan.Commit, done int, dryRun, skipConfirm bool, regenerate messageGenerator, committed func(i int) error) error {
+	violating := 0
 	for i, p := range plans {
-		fmt.Printf("\n[%d/%d] %s\n", i+1, len(plans), p.file)
+		if i < done {
+			ui.Muted("\n[%d/%d] %s — already committed", i+1, len(plans), p.Name)
+			continue
+		}
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(plans), p.Name)
+		if len(p.Files) > 1 || p.Files[0] != p.Name {
+			ui.Muted("  %s", strings.Join(p.Files, ", "))
+		}
+		if p.Fixup != "" {
+			ui.Muted("  🩹 %s (%s), folded into it by git rebase --autosquash", p.Message, shortSHA(p.Fixup))
+			continue // git writes the message; the policy applies to the commit it fixes
+		}
 		fmt.Println(strings.Repeat("─", 60))
-		fmt.Println(p.message)
+		fmt.Println(p.Message)
 		fmt.Println(strings.Repeat("─", 60))
+		showConfidence(p.Name)
+		if violations := checkMessage(cfg, p.Message); len(violations) > 0 {
+			reportViolations(violations)
+			violating++
+		}
 	}
 
 	if dryRun {
-		color.Yellow("\n🔍 Dry run — no commits were made.")
+		ui.Warn("\n🔍 Dry run — no commits were made.")
 		return nil
 	}
 
+	if violating > 0 {
+		return fmt.Errorf("%d commit message(s) violate policy", violating)
+	}
+
 	if !skipConfirm {
-		fmt.Print("\n⚡ Commit all with these messages? [Y/n/e(dit)]: ")
+		fmt.Print(i18n.T("\n⚡ Commit all with these messages? [Y/n/e(dit)]: "))
 		reader := bufio.NewReader(os.Stdin)
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(strings.ToLower(input))
 
-		if input == "n" || input == "no" {
-			color.Yellow("Commit cancelled.")
-			return nil
+		if i18n.No(input) {
+			ui.Warn("Commit cancelled.")
+			return errCommitCancelled
 		}
 	}
 
-	// Unstage all, then stage+commit one file at a time
-	exec.Command("git", "restore", "--staged", ".").Run()
+	// Checked before unstaging, which the commits would have to undo
+	if err := readonly.Check("committing"); err != nil {
+		return err
+	}
+	if err := checkBannedPlans(cfg, plans[done:]); err != nil {
+		return err
+	}
+	lock, err := lockRepo()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	if err := checkStale(); err != nil {
+		return err
+	}
+	// Unstage all, then stage+commit one plan at a time. What no plan
+	// commits is staged again afterwards exactly as it was.
+	staged, err := git.IndexTree()
+	if err != nil {
+		return err
+	}
+	committedFiles := make(map[string]bool)
+	defer func() {
+		if err := git.RestoreStaged(staged, committedFiles); err != nil {
+			ui.Warn("⚠️  %s", err)
+		}
+	}()
+	git.Run("restore", "--staged", ".")
+
+	// The messages describe the files as they are now. Hooks may format
+	// files of later commits, or stage them into an earlier one.
+	plans = append([]plan.Commit(nil), plans...)
+	var planned []string
+	for _, p := range plans[done:] {
+		planned = append(planned, p.Files...)
+	}
+	described, err := git.WorktreeHashes(planned)
+	if err != nil {
+		return err
+	}
 
-	for i, p := range plans {
-		// Re-stage just this file
-		if out, err2 := exec.Command("git", "add", p.file).CombinedOutput(); err2 != nil {
-			return fmt.Errorf("failed to stage %s: %s\n%w", p.file, string(out), err2)
+	absorbed := 0 // Plans whose files a hook already committed
+	for i := done; i < len(plans); i++ {
+		p := &plans[i]
+		p.Files = uncommitted(p.Files, committedFiles)
+		if len(p.Files) == 0 {
+			ui.Warn("  ↪️  [%d/%d] %s — already committed by a hook of an earlier commit", i+1, len(plans), p.Name)
+			absorbed++
+			if committed != nil {
+				if err := committed(i); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := unstageStray(p.Name); err != nil {
+			return err
+		}
+		current, err := git.WorktreeHashes(p.Files)
+		if err != nil {
+			return err
 		}
-		if err2 := git.Commit(p.message); err2 != nil {
-			return fmt.Errorf("failed to commit %s: %w", p.file, err2)
+		var changed []string
+		for _, f := range p.Files {
+			if current[f] != described[f] {
+				changed = append(changed, f)
+			}
+		}
+
+		// Re-stage just this plan's files
+		args := append([]string{"add", "--"}, p.Files...)
+		if _, err2 := git.Run(args...); err2 != nil {
+			return fmt.Errorf("failed to stage %s: %w", p.Name, err2)
 		}
-		color.Green("  ✅ [%d/%d] %s", i+1, len(plans), p.file)
+		if len(changed) > 0 && p.Fixup == "" {
+			if err := regeneratePlan(cfg, p, changed, regenerate); err != nil {
+				return err
+			}
+		}
+		msg := p.Message
+		if cfg.ProvenanceTrailers && !cfg.PrivacyMode {
+			msg = message.Provenance(msg, Version, cfg.Model, false)
+		}
+		opts := commitOptions()
+		opts.Fixup = p.Fixup
+		if err2 := git.Commit(msg, opts); err2 != nil {
+			return fmt.Errorf("failed to commit %s: %w", p.Name, err2)
+		}
+		for _, f := range p.Files {
+			committedFiles[f] = true
+		}
+		// A pre-commit hook may have staged more than the plan's files
+		if paths, err := git.CommitPaths("HEAD"); err == nil {
+			extra := uncommitted(paths, committedFiles)
+			for _, f := range extra {
+				committedFiles[f] = true
+			}
+			if len(extra) > 0 {
+				ui.Warn("  ⚠️  A hook added %s to this commit", strings.Join(extra, ", "))
+			}
+		}
+		ui.Success("  ✅ [%d/%d] %s", i+1, len(plans), p.Name)
+		if committed != nil {
+			if err := committed(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	ui.Success("\n🎉 All %d commits created!", len(plans)-absorbed)
+	return offerAutosquash(plans[done:], skipConfirm)
+}
+
+// messageGenerator writes the message of staged changes. executePlans
+// uses it when hooks changed a commit's files after its message was
+// written; plans without one (hand-edited, approved) stop there instead.
+type messageGenerator func(changes []git.FileChange) (string, error)
+
+// planGenerator generates plan messages like the main flow, creating the
+// client only if a hook makes it needed
+func planGenerator(cfg *config.Config) messageGenerator {
+	return func(changes []git.FileChange) (string, error) {
+		messages, err := commitService(cfg, newClient(cfg)).Generate(changes, false, nil)
+		if err != nil {
+			return "", err
+		}
+		return messages["__all__"], nil
 	}
+}
 
-	color.Green("\n🎉 All %d files committed!", len(plans))
+// regeneratePlan replaces the message of a staged plan whose files hooks
+// changed since it was written
+func regeneratePlan(cfg *config.Config, p *plan.Commit, changed []string, regenerate messageGenerator) error {
+	if regenerate == nil {
+		return fmt.Errorf("a hook changed %s after the message of %s was written; check the message, then commit again", strings.Join(changed, ", "), p.Name)
+	}
+	ui.Warn("  🪝 A hook changed %s; regenerating the message of %s...", strings.Join(changed, ", "), p.Name)
+	changes, err := git.StagedChanges()
+	if err != nil {
+		return err
+	}
+	msg, err := regenerate(changes)
+	if err != nil {
+		return fmt.Errorf("failed to regenerate the message of %s: %w", p.Name, err)
+	}
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println(msg)
+	fmt.Println(strings.Repeat("─", 60))
+	if violations := checkMessage(cfg, msg); len(violations) > 0 {
+		reportViolations(violations)
+		return fmt.Errorf("the regenerated message of %s violates policy", p.Name)
+	}
+	p.Message = msg
 	return nil
 }
 
-func confirmOrEdit(message string, skip bool) (string, bool) {
-	if skip {
-		return message, true
+// unstageStray unstages what hooks of earlier commits left staged, which
+// would otherwise be committed with the next plan
+func unstageStray(next string) error {
+	stray, err := git.StagedPaths()
+	if err != nil || len(stray) == 0 {
+		return err
+	}
+	ui.Warn("  ⚠️  A hook staged %s; unstaged so it isn't committed with %s", strings.Join(stray, ", "), next)
+	_, err = git.Run(append([]string{"restore", "--staged", "--"}, stray...)...)
+	return err
+}
+
+// uncommitted returns the paths not in committed
+func uncommitted(paths []string, committed map[string]bool) []string {
+	var left []string
+	for _, p := range paths {
+		if !committed[p] {
+			left = append(left, p)
+		}
+	}
+	return left
+}
+
+// checkCoverage cross-checks the files in the AI answer against the staged
+// files. Unknown (hallucinated) paths are reported and ignored; missing files
+// need explicit consent to fall back to generic messages.
+func checkCoverage(changes []git.FileChange, messages map[string]string, dryRun, skipConfirm bool) (bool, error) {
+	missing := ai.MissingFiles(changes, messages)
+	unknown := ai.UnknownFiles(changes, messages)
+
+	for _, path := range unknown {
+		ui.Error("✖ AI returned a message for %s, which is not staged — ignored", path)
+	}
+	if len(missing) == 0 {
+		return true, nil
+	}
+
+	ui.Warn("\n⚠️  No AI message for %d of %d staged file(s):", len(missing), len(changes))
+	for _, c := range missing {
+		fmt.Printf("  %s %s\n", statusToIcon(c.Status), c.Path)
 	}
 
-	fmt.Print("\n⚡ Use this message? [Y/n/e(dit)]: ")
+	if dryRun {
+		return true, nil
+	}
+	if skipConfirm {
+		return false, fmt.Errorf("incomplete AI coverage: %d file(s) without a message; re-run or use --all", len(missing))
+	}
+	if !confirmGeneric() {
+		ui.Warn("Commit cancelled.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// confirmGeneric asks whether to fall back to generic "chore: update"
+// messages for files or groups without an AI message
+func confirmGeneric() bool {
+	fmt.Print("\n⚡ Use generic \"chore: update <name>\" messages for them? [y/N]: ")
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes"
+}
 
-	switch input {
-	case "n", "no":
-		return "", false
-	case "e", "edit":
-		fmt.Print("Enter your message: ")
-		newMsg, _ := reader.ReadString('\n')
-		return strings.TrimSpace(newMsg), true
+// commitlintWarned keeps a missing commitlint from being reported per message
+var commitlintWarned bool
+
+// checkMessage validates a message against the commit policy and, with
+// commitlint on, the project's commitlint rules
+func checkMessage(cfg *config.Config, msg string) []policy.Violation {
+	violations := policy.Check(msg, cfg.Policy)
+	if !cfg.Commitlint {
+		return violations
+	}
+	top, err := git.TopLevel()
+	if err != nil {
+		return violations
+	}
+	problems, err := githooks.Commitlint(top, msg)
+	if err != nil {
+		if !commitlintWarned {
+			ui.Warn("⚠️  Message not checked with commitlint: %s", err)
+			commitlintWarned = true
+		}
+		return violations
+	}
+	for _, p := range problems {
+		violations = append(violations, policy.Violation{Rule: "commitlint", Detail: p})
+	}
+	return violations
+}
+
+// warnSkippedHooks points out hook frameworks the project set up that
+// won't check commitai's commits, and hooks that expect a terminal
+func warnSkippedHooks(cfg *config.Config) {
+	top, err := git.TopLevel()
+	if err != nil {
+		return
+	}
+	hooksDir, err := git.HooksDir()
+	if err != nil {
+		return
+	}
+	for _, f := range githooks.Scan(top, hooksDir) {
+		if f.Framework == "commitlint" && cfg.Commitlint {
+			continue // commitai runs it itself
+		}
+		ui.Warn("⚠️  %s", f)
+		ui.Muted("   Fix: %s", f.Fix)
+	}
+}
+
+// reportViolations lists policy violations below a suggested message
+func reportViolations(violations []policy.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+	ui.Error("🚫 Policy violations:")
+	for _, v := range violations {
+		ui.Error("  • %s", v)
+	}
+}
+
+// showSuggestion prints a message in a box followed by its policy violations
+func showSuggestion(cfg *config.Config, msg string) {
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println(msg)
+	fmt.Println(strings.Repeat("─", 60))
+	violations := checkMessage(cfg, msg)
+	reportViolations(violations)
+
+	// Soft guidance when no policy limit is configured
+	subject, _ := policy.Split(msg)
+	if width := message.DisplayWidth(subject); cfg.Policy.MaxSubjectLength == 0 && width > message.SubjectWidth {
+		ui.Warn("⚠️  Subject is %d columns wide (recommended max %d)", width, message.SubjectWidth)
+	}
+}
+
+// suggestedConfidence rates the messages of this run by their keys, see
+// ai.GeminiClient.Confidence
+var suggestedConfidence map[string]ai.Confidence
+
+// showConfidence prints how sure the model was of the message of key, and
+// flags a low confidence so the diff gets a closer look
+func showConfidence(key string) {
+	c, ok := suggestedConfidence[key]
+	if !ok {
+		return
+	}
+	detail := ""
+	if c.Source == ai.SourceCritique {
+		detail = ", self-critique"
+	}
+	if c.Reason != "" {
+		detail += " — " + c.Reason
+	}
+	percent := int(math.Round(c.Score * 100))
+	switch c.Level {
+	case ai.ConfidenceLow:
+		ui.Warn("⚠️  Low confidence (%d%%%s): read the diff before accepting", percent, detail)
+	case ai.ConfidenceMedium:
+		ui.Info("🤔 Medium confidence (%d%%%s)", percent, detail)
 	default:
+		ui.Muted("🎯 High confidence (%d%%%s)", percent, detail)
+	}
+}
+
+// confirmOrEdit asks whether to use the message. With a refine function the
+// user can also give instructions ("shorter", "use scope 'auth'") until the
+// result is accepted.
+func confirmOrEdit(message string, skip bool, refine func(string) (string, error)) (string, bool) {
+	if skip {
 		return message, true
 	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if refine != nil {
+			fmt.Print(i18n.T("\n⚡ Use this message? [Y/n/e(dit)/r(efine)]: "))
+		} else {
+			fmt.Print(i18n.T("\n⚡ Use this message? [Y/n/e(dit)]: "))
+		}
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+
+		switch {
+		case i18n.No(input):
+			return "", false
+		case input == "e", input == "edit", input == "editar":
+			fmt.Print(i18n.T("Enter your message: "))
+			newMsg, _ := reader.ReadString('\n')
+			return strings.TrimSpace(newMsg), true
+		case input == "r", input == "refine", input == "refinar":
+			if refine == nil {
+				return message, true
+			}
+			fmt.Print(i18n.T("How should it change? (e.g. shorter, mention the config change): "))
+			instruction, _ := reader.ReadString('\n')
+			if instruction = strings.TrimSpace(instruction); instruction == "" {
+				continue
+			}
+			refined, err := refine(instruction)
+			if err != nil {
+				ui.Error("✖ Refinement failed: %s", err)
+				continue
+			}
+			message = refined
+		default:
+			return message, true
+		}
+	}
 }
 
 func statusToIcon(s string) string {
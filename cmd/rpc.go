@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/commitlint"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/render"
+	"github.com/kaiqui/commitai/internal/rpc"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Serve JSON-RPC over stdio for editor integrations",
+	Long: `Serve JSON-RPC 2.0 over stdin/stdout for editor integrations.
+
+Messages may use LSP-style Content-Length framing or one JSON object per line.
+Configuration is loaded once per repository and reused across requests
+until one of its files changes.
+Logs go to stderr.
+
+Methods:
+  initialize          -> {name, version, methods}
+  generateForStaged   {cwd, granular?, language?, style?}
+                      -> {granular, messages, violations, files, provider}
+  generateForDiff     {cwd?, diff, language?, style?} -> {message}
+  checkPolicy         {cwd?, message} -> {violations}
+  shutdown            stop serving`,
+	RunE: runRPC,
+}
+
+type rpcGenerateParams struct {
+	Cwd      string `json:"cwd"`
+	Granular *bool  `json:"granular"` // nil lets commitai decide, like auto mode
+	Diff     string `json:"diff"`
+	Language string `json:"language"`
+	Style    string `json:"style"`
+	Message  string `json:"message"`
+}
+
+func runRPC(cmd *cobra.Command, args []string) error {
+	// Stdout carries the protocol; everything else is a log
+	color.Output = os.Stderr
+	ui, _ = render.New(render.Plain, os.Stderr)
+
+	srv := newRPCServer()
+	ui.Info("commitai %s: serving JSON-RPC on stdio", Version)
+	return srv.Serve(os.Stdin, os.Stdout)
+}
+
+// rpcViolation is a policy violation as sent to clients
+type rpcViolation struct {
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func rpcViolations(violations []policy.Violation) []rpcViolation {
+	out := []rpcViolation{}
+	for _, v := range violations {
+		out = append(out, rpcViolation{v.Rule, v.Detail})
+	}
+	return out
+}
+
+// rpcConfig is a repository's cached config and the state of the files it
+// was loaded from
+type rpcConfig struct {
+	cfg   *config.Config
+	stamp string
+}
+
+// configStamp describes the files a repository's config is loaded from, so
+// a long-running server notices when they are edited
+func configStamp(root string) string {
+	var files []string
+	if path := config.UserConfigPath(); path != "" {
+		files = append(files, path)
+	}
+	if root != "" {
+		files = append(files, filepath.Join(root, config.ConfigFileName))
+		if name := commitlint.Find(root); name != "" {
+			files = append(files, filepath.Join(root, name))
+		}
+	}
+	var sb strings.Builder
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			fmt.Fprintf(&sb, "%s:%d:%d;", f, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return sb.String()
+}
+
+// newRPCServer returns the server behind commitai rpc and vscode-bridge
+func newRPCServer() *rpc.Server {
+	srv := rpc.NewServer()
+	configs := make(map[string]rpcConfig) // By repository root
+
+	// repoConfig switches to the request's directory and returns its config
+	repoConfig := func(p rpcGenerateParams) (*config.Config, error) {
+		if p.Cwd != "" {
+			if err := os.Chdir(p.Cwd); err != nil {
+				return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: err.Error()}
+			}
+		}
+		root, _ := git.TopLevel()
+		stamp := configStamp(root)
+		cached, ok := configs[root]
+		if !ok || cached.stamp != stamp {
+			cfg, err := config.Load()
+			if err != nil {
+				return nil, err
+			}
+			if err := cfg.Validate(); err != nil {
+				return nil, err
+			}
+			if err := applyPolicy(cfg); err != nil {
+				return nil, err
+			}
+			cached = rpcConfig{cfg: cfg, stamp: stamp}
+			configs[root] = cached
+		}
+
+		cfg := *cached.cfg
+		if p.Language != "" {
+			cfg.Language = p.Language
+		}
+		if p.Style != "" {
+			cfg.CommitStyle = p.Style
+		}
+		return &cfg, nil
+	}
+	decode := func(raw json.RawMessage) (rpcGenerateParams, error) {
+		var p rpcGenerateParams
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &p); err != nil {
+				return p, rpc.InvalidParams(err)
+			}
+		}
+		return p, nil
+	}
+
+	srv.Handle("initialize", func(json.RawMessage) (any, error) {
+		methods := srv.Methods()
+		sort.Strings(methods)
+		return map[string]any{"name": "commitai", "version": Version, "methods": methods}, nil
+	})
+
+	srv.Handle("generateForStaged", func(raw json.RawMessage) (any, error) {
+		p, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := repoConfig(p)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := git.StagedChanges()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBannedChanges(cfg, changes); err != nil {
+			return nil, err
+		}
+		granular := determineMode(changes)
+		if p.Granular != nil {
+			granular = *p.Granular
+		}
+		addFileHistory(cfg, changes)
+		// Everything an editor shows next to the message, so one call is enough
+		service := commitService(cfg, newClient(cfg))
+		service.Check = nil // The policy alone, like checkPolicy
+		suggestion, err := service.Suggest(changes, granular)
+		if err != nil {
+			return nil, err
+		}
+		violations := make(map[string][]rpcViolation)
+		for k, v := range suggestion.Violations {
+			violations[k] = rpcViolations(v)
+		}
+		files := make([]map[string]string, 0, len(changes))
+		for _, c := range changes {
+			files = append(files, map[string]string{"path": c.Path, "status": c.Status})
+		}
+		result := map[string]any{
+			"granular":   granular,
+			"messages":   suggestion.Messages,
+			"violations": violations,
+			"files":      files,
+			"provider":   suggestion.Provider,
+		}
+		if len(suggestion.Confidence) > 0 {
+			result["confidence"] = suggestion.Confidence
+		}
+		return result, nil
+	})
+
+	srv.Handle("generateForDiff", func(raw json.RawMessage) (any, error) {
+		p, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		changes := git.ParseDiff(p.Diff)
+		if len(changes) == 0 {
+			return nil, rpc.InvalidParams(fmt.Errorf("diff contains no file changes"))
+		}
+		cfg, err := repoConfig(p)
+		if err != nil {
+			return nil, err
+		}
+		messages, err := newClient(cfg).GenerateCommitMessages(changes, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": messages["__all__"]}, nil
+	})
+
+	srv.Handle("checkPolicy", func(raw json.RawMessage) (any, error) {
+		p, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := repoConfig(p)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"violations": rpcViolations(policy.Check(p.Message, cfg.Policy))}, nil
+	})
+
+	srv.Handle("shutdown", func(json.RawMessage) (any, error) {
+		srv.Stop()
+		return nil, nil
+	})
+
+	return srv
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/dictation"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	sayDryRun   bool
+	sayYes      bool
+	sayLanguage string
+	sayStyle    string
+	sayListen   bool
+	sayAudio    string
+)
+
+var sayCmd = &cobra.Command{
+	Use:   "say [description]",
+	Short: "Turn your own description of the staged changes into a commit message",
+	Long: `Turn your own description of the staged changes into a commit message.
+
+The description is combined with the staged diff: it is rewritten to follow
+the configured style and policy, claims the diff does not support are
+dropped, and significant changes it leaves out are mentioned.
+
+Instead of typing it, the description can be dictated: --listen runs the
+configured dictation_command (any speech-to-text tool that prints what it
+heard), or records the microphone with sox or arecord until Enter is
+pressed and has the provider transcribe it. --audio transcribes a recording
+made beforehand (wav, mp3, aiff, aac, ogg or flac). Transcribing needs the
+Gemini API; typed text is kept in front of the dictated one.`,
+	Example: `  commitai say "fix login redirect loop"
+  commitai say --listen
+  commitai say --audio ~/Recordings/change.mp3`,
+	RunE: runSay,
+}
+
+func init() {
+	sayCmd.Flags().BoolVarP(&sayDryRun, "dry-run", "d", false, "Preview the commit message without committing")
+	sayCmd.Flags().BoolVarP(&sayYes, "yes", "y", false, "Skip confirmation prompt")
+	sayCmd.Flags().StringVarP(&sayLanguage, "lang", "l", "", "Language for the message (en, pt-br)")
+	sayCmd.Flags().StringVar(&sayStyle, "style", "", "Commit style (conventional, simple, auto)")
+	sayCmd.Flags().BoolVar(&sayListen, "listen", false, "Dictate the description (dictation_command, or a recording the provider transcribes)")
+	sayCmd.Flags().StringVar(&sayAudio, "audio", "", "Take the description from this recording, transcribed by the provider")
+	sayCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on a protected branch")
+	sayCmd.Flags().IntVar(&flagFileHistory, "file-history", 0, "Send the last N commit subjects of each modified file")
+	sayCmd.Flags().BoolVar(&flagNoBranchContext, "no-branch-context", false, "Don't send the branch name to the AI")
+	sayCmd.Flags().BoolVar(&flagAnonymize, "anonymize", false, "Replace string literals, emails, hostnames and URLs in diffs with placeholders before sending them")
+	sayCmd.Flags().StringVar(&flagAnonymizePaths, "anonymize-paths", "", "Hide file paths from the AI provider (generalize, hash)")
+	sayCmd.Flags().IntVar(&flagCompress, "compress", 0, "Compress diffs in the prompt to save tokens (1-3)")
+	sayCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runSay(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	intent := strings.TrimSpace(strings.Join(args, " "))
+	dictating := sayListen || sayAudio != ""
+	if sayListen && sayAudio != "" {
+		return fmt.Errorf("--listen can't be combined with --audio")
+	}
+	if intent == "" && !dictating {
+		return fmt.Errorf("describe the change, e.g. commitai say \"fix login redirect loop\", or dictate it with --listen")
+	}
+
+	cfg, err := loadCommitConfig(sayLanguage, sayStyle)
+	if err != nil {
+		return err
+	}
+	if !sayDryRun {
+		if err := checkProtectedBranch(cfg, "commit"); err != nil {
+			return err
+		}
+		warnSkippedHooks(cfg)
+	}
+
+	changes, err := git.StagedChanges()
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		ui.Warn("No staged changes found. Use 'git add' to stage files.")
+		return nil
+	}
+	if err := checkBannedChanges(cfg, changes); err != nil {
+		return err
+	}
+
+	client := newClient(cfg)
+	if dictating {
+		spoken, err := dictate(cfg, client)
+		if err != nil {
+			return err
+		}
+		ui.Muted("🗣️  \"%s\"", spoken)
+		intent = strings.TrimSpace(intent + " " + spoken)
+	}
+
+	recentCommits := recentCommits(cfg, changes)
+	addFileHistory(cfg, changes)
+
+	ui.Info("✨ Polishing your description against %d staged file(s)...", len(changes))
+	msg, err := client.GenerateFromIntent(intent, changes, recentCommits)
+	if err != nil {
+		return fmt.Errorf("AI generation failed: %w", err)
+	}
+	msg = finishMessage(cfg, msg)
+
+	before, _ := git.HeadCommit()
+	if err := handleSingleCommit(cfg, msg, client.NewChat(changes, recentCommits, msg), sayDryRun, sayYes); err != nil {
+		return err
+	}
+	updateSquashDraft(cfg, before)
+	return nil
+}
+
+// dictate returns the spoken description of the change: from the
+// dictation command, or from a recording the provider transcribes
+func dictate(cfg *config.Config, client *ai.GeminiClient) (string, error) {
+	if sayAudio != "" {
+		return transcribeFile(client, sayAudio)
+	}
+	if cfg.DictationCommand != "" {
+		ui.Info("🎙️  Listening with %s...", cfg.DictationCommand)
+		return dictation.Listen(cfg.DictationCommand)
+	}
+
+	recorder, err := dictation.FindRecorder()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "commitai-say-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "intent.wav")
+
+	stop := make(chan struct{})
+	go func() {
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		close(stop)
+	}()
+	ui.Info("🎙️  Listening... describe your change, then press Enter.")
+	if err := recorder.Record(file, stop); err != nil {
+		return "", err
+	}
+	return transcribeFile(client, file)
+}
+
+// transcribeFile has the provider write down what is said in a recording
+func transcribeFile(client *ai.GeminiClient, file string) (string, error) {
+	mimeType, ok := ai.AudioType(file)
+	if !ok {
+		return "", fmt.Errorf("can't transcribe %s: use a wav, mp3, aiff, aac, ogg or flac recording", file)
+	}
+	audio, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	ui.Info("📝 Transcribing with %s...", client.ProviderName())
+	spoken, err := client.TranscribeAudio(audio, mimeType)
+	if err != nil {
+		return "", fmt.Errorf("transcription failed: %w", err)
+	}
+	return spoken, nil
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	seriesRange   string
+	seriesOutput  string
+	seriesReroll  int
+	seriesPrefix  string
+	seriesDryRun  bool
+	seriesNoNotes bool
+)
+
+var seriesCmd = &cobra.Command{
+	Use:   "series",
+	Short: "Write a patch series with an AI cover letter for git send-email",
+	Long: `Write a patch series with an AI cover letter for git send-email.
+
+The commits of the range are written with git format-patch --cover-letter,
+for projects that review patches on a mailing list. The cover letter's
+subject and blurb are filled in, and each patch gets a note below its ---
+line: context for reviewers that doesn't belong in the commit message and
+that git am drops when applying.
+
+The range defaults to the commits of the current branch that are not on
+the default branch (origin's HEAD, main or master). Review the files, then
+send them with git send-email.`,
+	Args:         cobra.NoArgs,
+	RunE:         runSeries,
+	SilenceUsage: true,
+}
+
+func init() {
+	seriesCmd.Flags().StringVar(&seriesRange, "range", "", "Commits to send, e.g. main..HEAD (a single revision means rev..HEAD)")
+	seriesCmd.Flags().StringVarP(&seriesOutput, "output-directory", "o", ".", "Directory to write the patches to")
+	seriesCmd.Flags().IntVarP(&seriesReroll, "reroll-count", "v", 0, "Mark the series as the n-th iteration ([PATCH v2 0/3])")
+	seriesCmd.Flags().StringVar(&seriesPrefix, "subject-prefix", "", "Subject prefix instead of PATCH, e.g. \"PATCH net-next\"")
+	seriesCmd.Flags().BoolVarP(&seriesDryRun, "dry-run", "d", false, "Print the cover letter and notes without writing patches")
+	seriesCmd.Flags().BoolVar(&seriesNoNotes, "no-notes", false, "Only write the cover letter, no notes below the --- lines")
+	seriesCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runSeries(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	rangeSpec := seriesRange
+	switch {
+	case rangeSpec == "":
+		base := git.DefaultBranch()
+		if base == "" {
+			return fmt.Errorf("can't tell which commits to send; use --range")
+		}
+		rangeSpec = base + "..HEAD"
+	case !strings.Contains(rangeSpec, ".."):
+		rangeSpec += "..HEAD"
+	}
+	from, to, _ := strings.Cut(rangeSpec, "..")
+	to = strings.TrimPrefix(to, ".") // A...B names the same commits for a branch on top of A
+	if to == "" {
+		to = "HEAD"
+	}
+
+	commits, err := git.BranchCommits(from, to)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", rangeSpec)
+	}
+	diffs := make([]string, len(commits))
+	for i, c := range commits {
+		if diffs[i], err = git.ShowDiff(c.Hash); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	client := newClient(cfg)
+	ui.Info("📨 Writing the cover letter for %d patch(es) with %s...", len(commits), client.ProviderName())
+	series, err := client.GenerateSeries(commits, diffs)
+	if err != nil {
+		return fmt.Errorf("failed to write the cover letter: %w", err)
+	}
+	if series.Subject == "" {
+		return fmt.Errorf("the AI answer had no cover letter; try again")
+	}
+
+	if seriesDryRun {
+		fmt.Println()
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Printf("[PATCH 0/%d] %s\n\n%s\n", len(commits), series.Subject, series.Blurb)
+		fmt.Println(strings.Repeat("─", 60))
+		if !seriesNoNotes {
+			for i, c := range commits {
+				if series.Notes[i] == "" {
+					continue
+				}
+				ui.Info("\n[PATCH %d/%d] %s", i+1, len(commits), c.Subject)
+				fmt.Println(series.Notes[i])
+			}
+		}
+		ui.Warn("\n🔍 Dry run — no patches were written.")
+		return nil
+	}
+
+	var extra []string
+	if seriesReroll > 0 {
+		extra = append(extra, "--reroll-count="+strconv.Itoa(seriesReroll))
+	}
+	if seriesPrefix != "" {
+		extra = append(extra, "--subject-prefix="+seriesPrefix)
+	}
+	files, err := git.FormatPatch(from+".."+to, seriesOutput, extra...)
+	if err != nil {
+		return err
+	}
+	if len(files) != len(commits)+1 {
+		return fmt.Errorf("git format-patch wrote %d file(s) for %d commit(s); the cover letter was not filled in", len(files), len(commits))
+	}
+
+	if err := fillCoverLetter(files[0], series.Subject, series.Blurb); err != nil {
+		return err
+	}
+	if !seriesNoNotes {
+		for i, file := range files[1:] {
+			if err := addPatchNote(file, series.Notes[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	ui.Success("✅ Wrote %d patch(es) and the cover letter:", len(commits))
+	for _, f := range files {
+		fmt.Printf("   %s\n", f)
+	}
+	fmt.Printf("   Review them, then: git send-email --to=<list> %s\n", strings.Join(files, " "))
+	return nil
+}
+
+// fillCoverLetter replaces format-patch's placeholders in the cover letter
+func fillCoverLetter(file, subject, blurb string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	text := strings.Replace(string(data), "*** SUBJECT HERE ***", subject, 1)
+	text = strings.Replace(text, "*** BLURB HERE ***", blurb, 1)
+	return os.WriteFile(file, []byte(text), 0o644)
+}
+
+// addPatchNote puts note right below the --- line ending the commit
+// message, above the diffstat, where git am ignores it
+func addPatchNote(file, note string) error {
+	if note == "" {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if line == "---" {
+			rest := append([]string{note, ""}, lines[i+1:]...)
+			lines = append(lines[:i+1], rest...)
+			return os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0o644)
+		}
+	}
+	return fmt.Errorf("%s has no --- line to put the note below", file)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/server"
+)
+
+var (
+	srvAddr        string
+	srvTLSCert     string
+	srvTLSKey      string
+	srvTokens      []string
+	srvAdminToken  string
+	srvRateLimit   int
+	srvMaxRequest  int
+	srvDailyTokens int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the generation service over HTTP (JSON) and gRPC",
+	Long: `Serve the generation service over HTTP (JSON) and gRPC.
+
+HTTP JSON endpoints (POST):
+  /v1/commit    {diff, granular?, language?, style?, recent_commits?} -> {messages}
+  /v1/release   {commits, current_tag?, new_tag, audience?} -> {notes}
+  /v1/version   {commits, current_tag?} -> {version}
+
+gRPC service commitai.v1.GenerationService (api/proto/commitai/v1/commitai.proto)
+with GenerateCommit, GenerateRelease and SuggestVersion. gRPC needs HTTP/2,
+so it is only available with --tls-cert and --tls-key.
+
+Set --token (or COMMITAI_SERVER_TOKEN) to require "Authorization: Bearer <token>".
+Give one --token name=secret per developer so limits and usage are tracked
+per person; without tokens, clients are told apart by IP address.
+
+Limits apply per client: --rate-limit requests per minute, --max-request-kb
+per request body and --daily-tokens estimated tokens per UTC day. With
+--admin-token, GET /admin/usage reports today's usage and
+POST /admin/usage/reset {"client": "<name>"} clears it (omit client for all).
+
+Prometheus metrics (request counts and latencies, estimated tokens, provider
+errors, rejected requests) are exposed without authentication on /metrics.`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&srvAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&srvTLSCert, "tls-cert", "", "TLS certificate file (enables HTTPS and gRPC)")
+	serveCmd.Flags().StringVar(&srvTLSKey, "tls-key", "", "TLS private key file")
+	serveCmd.Flags().StringArrayVar(&srvTokens, "token", nil, "Bearer token clients must send, as token or name=token; repeatable (default $COMMITAI_SERVER_TOKEN)")
+	serveCmd.Flags().StringVar(&srvAdminToken, "admin-token", "", "Bearer token for the /admin/usage endpoint (default $COMMITAI_ADMIN_TOKEN)")
+	serveCmd.Flags().IntVar(&srvRateLimit, "rate-limit", 0, "Max requests per minute per client (0 = unlimited)")
+	serveCmd.Flags().IntVar(&srvMaxRequest, "max-request-kb", 8192, "Max request body size in KiB")
+	serveCmd.Flags().IntVar(&srvDailyTokens, "daily-tokens", 0, "Daily quota of estimated AI tokens per client (0 = unlimited)")
+	serveCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Reject AI calls estimated to cost more than this (USD)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if (srvTLSCert == "") != (srvTLSKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	if len(srvTokens) == 0 && os.Getenv("COMMITAI_SERVER_TOKEN") != "" {
+		srvTokens = []string{os.Getenv("COMMITAI_SERVER_TOKEN")}
+	}
+	if srvAdminToken == "" {
+		srvAdminToken = os.Getenv("COMMITAI_ADMIN_TOKEN")
+	}
+	if srvMaxRequest <= 0 {
+		return fmt.Errorf("--max-request-kb must be positive")
+	}
+
+	tokens := make(map[string]string)
+	for _, t := range srvTokens {
+		name, token, ok := strings.Cut(t, "=")
+		if !ok || name == "" || token == "" || strings.HasPrefix(token, "=") {
+			name, token = server.ClientName(t), t // Bare token, possibly with base64 padding
+		}
+		tokens[token] = name
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	cfg.Branch = "" // Requests come from other repositories
+
+	httpServer := &http.Server{
+		Addr: srvAddr,
+		Handler: server.New(cfg, newClient, server.Options{
+			Tokens:     tokens,
+			AdminToken: srvAdminToken,
+			Limits: server.Limits{
+				RequestsPerMinute: srvRateLimit,
+				MaxRequestBytes:   int64(srvMaxRequest) << 10,
+				DailyTokens:       srvDailyTokens,
+			},
+		}).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if len(tokens) == 0 {
+		ui.Warn("⚠️  No --token set: anyone who can reach %s can use your AI provider", srvAddr)
+	}
+	if srvTLSCert != "" {
+		ui.Info("🌐 Serving HTTPS + gRPC on %s", srvAddr)
+		return httpServer.ListenAndServeTLS(srvTLSCert, srvTLSKey)
+	}
+	ui.Info("🌐 Serving HTTP on %s (gRPC needs --tls-cert/--tls-key)", srvAddr)
+	return httpServer.ListenAndServe()
+}
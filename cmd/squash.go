@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/squash"
+)
+
+var (
+	sqBase    string
+	sqShow    bool
+	sqRefresh bool
+	sqClear   bool
+)
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Draft the message of the commit the branch becomes when squash-merged",
+	Long: `Draft the message of the commit the branch becomes when squash-merged.
+
+On forges that squash-merge pull requests, the branch's commits end up as one
+commit whose message is the pull request's title and description. This
+command writes that message from the branch's commits and overall diff and
+stores it in .git, so it can be reviewed and reused when opening the pull
+request.
+
+Running it again updates the draft with the commits made since, instead of
+starting over. With "squash_merge" on (commitai config --squash-merge on),
+the draft is updated after every commit commitai makes.
+
+The branch is compared with --base, else with the default branch (origin's
+HEAD, main or master).`,
+	RunE:         runSquash,
+	SilenceUsage: true,
+}
+
+func init() {
+	squashCmd.Flags().StringVar(&sqBase, "base", "", "Branch the pull request targets (default: origin's HEAD, main or master)")
+	squashCmd.Flags().BoolVar(&sqShow, "show", false, "Print the stored draft without updating it")
+	squashCmd.Flags().BoolVar(&sqRefresh, "refresh", false, "Rewrite the draft from scratch instead of updating it")
+	squashCmd.Flags().BoolVar(&sqClear, "clear", false, "Forget the draft of the current branch")
+	squashCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runSquash(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	if branch == "HEAD" {
+		return fmt.Errorf("HEAD is detached; check out the branch to squash")
+	}
+	store, err := loadSquashDrafts()
+	if err != nil {
+		return err
+	}
+
+	if sqClear {
+		if err := store.Delete(branch); err != nil {
+			return err
+		}
+		ui.Success("✅ Squash draft of %s removed", branch)
+		return nil
+	}
+	if sqShow {
+		draft, ok := store.Get(branch)
+		if !ok {
+			return fmt.Errorf("no squash draft for %s yet; run: commitai squash", branch)
+		}
+		printSquashDraft(draft)
+		return nil
+	}
+
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	draft, updated, err := refreshSquashDraft(cfg, store, branch, sqBase, sqRefresh)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		ui.Muted("Draft is up to date with %s.", shortSHA(draft.Head))
+	}
+	printSquashDraft(draft)
+	return nil
+}
+
+// loadSquashDrafts opens the squash draft store of the repository
+func loadSquashDrafts() (*squash.Store, error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return squash.Load(gitDir)
+}
+
+// refreshSquashDraft brings the branch's draft up to date with HEAD. An
+// existing draft is updated with the new commits unless full is set; it's
+// rewritten when the branch was rebased or its base changed.
+func refreshSquashDraft(cfg *config.Config, store *squash.Store, branch, baseRef string, full bool) (squash.Draft, bool, error) {
+	head, err := git.HeadCommit()
+	if err != nil {
+		return squash.Draft{}, false, err
+	}
+	draft, ok := store.Get(branch)
+
+	var base string
+	switch {
+	case baseRef != "":
+		if base, err = git.MergeBase(baseRef, head); err != nil {
+			return squash.Draft{}, false, err
+		}
+	case ok && git.IsAncestor(draft.Base, head):
+		base = draft.Base
+	default:
+		target := git.DefaultBranch()
+		if target == "" {
+			return squash.Draft{}, false, fmt.Errorf("can't tell which branch %s will be merged into; use --base", branch)
+		}
+		if base, err = git.MergeBase(target, head); err != nil {
+			return squash.Draft{}, false, err
+		}
+	}
+	if ok && !full && draft.Base == base && draft.Head == head {
+		return draft, false, nil
+	}
+
+	commits, err := git.BranchCommits(base, head)
+	if err != nil {
+		return squash.Draft{}, false, err
+	}
+	if len(commits) == 0 {
+		return squash.Draft{}, false, fmt.Errorf("%s has no commits since %s", branch, shortSHA(base))
+	}
+	changes, err := git.ChangesBetween(base, head)
+	if err != nil {
+		return squash.Draft{}, false, err
+	}
+	previous := ""
+	if ok && !full && draft.Base == base && git.IsAncestor(draft.Head, head) {
+		previous = draft.Message
+	}
+
+	client := newClient(cfg)
+	if previous != "" {
+		ui.Info("🧩 Updating the squash draft of %s with %d new commit(s) using %s...", branch, len(commits)-draft.Commits, client.ProviderName())
+	} else {
+		ui.Info("🧩 Drafting the squash commit of %s (%d commit(s)) with %s...", branch, len(commits), client.ProviderName())
+	}
+	msg, err := client.GenerateSquash(previous, commits, changes)
+	if err != nil {
+		return squash.Draft{}, false, fmt.Errorf("failed to draft the squash message: %w", err)
+	}
+	msg = message.ParseTemplate(cfg.CommitTemplate, cfg.CommentChar).Fill(msg)
+
+	draft = squash.Draft{Branch: branch, Base: base, Head: head, Commits: len(commits), Message: msg, Updated: time.Now()}
+	if err := store.Put(draft); err != nil {
+		return squash.Draft{}, false, err
+	}
+	return draft, true, nil
+}
+
+// updateSquashDraft refreshes the squash draft after commitai committed, when
+// squash_merge is on. Failures only warn: the commits are already made.
+func updateSquashDraft(cfg *config.Config, before string) {
+	if !cfg.SquashMerge {
+		return
+	}
+	if head, _ := git.HeadCommit(); head == before {
+		return
+	}
+	branch, err := git.CurrentBranch()
+	if err != nil || branch == "HEAD" {
+		return
+	}
+	store, err := loadSquashDrafts()
+	if err == nil {
+		_, _, err = refreshSquashDraft(cfg, store, branch, "", false)
+	}
+	if err != nil {
+		ui.Warn("⚠️  Squash draft not updated: %s", err)
+		return
+	}
+	ui.Muted("🧩 Squash draft updated (commitai squash --show)")
+}
+
+func printSquashDraft(d squash.Draft) {
+	fmt.Println()
+	ui.Success("🧩 Squash commit for %s (%d commit(s), %s..%s):", d.Branch, d.Commits, shortSHA(d.Base), shortSHA(d.Head))
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println(d.Message)
+	fmt.Println(strings.Repeat("─", 60))
+}
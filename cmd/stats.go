@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	statsSince string
+	statsUntil string
+	statsJSON  bool
+)
+
+// conventionalTypes are the types accepted when the policy allows any
+var conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "test", "chore", "perf", "ci", "build", "revert"}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Statistics about the commit history",
+}
+
+var statsTypesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "Distribution of Conventional Commits types and scopes",
+	Long: `Distribution of Conventional Commits types and scopes in a range of
+history, with the commits that don't follow the convention (or the policy's
+allowed types and scopes, if set). Merge commits are skipped. No API call.`,
+	RunE:         runStatsTypes,
+	SilenceUsage: true,
+}
+
+func init() {
+	statsTypesCmd.Flags().StringVar(&statsSince, "since", "", "Start of the range, exclusive (tag or commit; default: latest tag, \"all\" for the whole history)")
+	statsTypesCmd.Flags().StringVar(&statsUntil, "until", "HEAD", "End of the range, inclusive")
+	statsTypesCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+
+	statsCmd.AddCommand(statsTypesCmd)
+}
+
+// typeCount is one row of the type or scope distribution
+type typeCount struct {
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // Fraction of all commits in the range
+}
+
+// nonConforming is a commit that doesn't follow the convention
+type nonConforming struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Reason  string `json:"reason"`
+}
+
+// typeStats is the --json output
+type typeStats struct {
+	Range         string          `json:"range"`
+	Total         int             `json:"total"`
+	Conforming    int             `json:"conforming"`
+	Breaking      int             `json:"breaking"`
+	Types         []typeCount     `json:"types"`
+	Scopes        []typeCount     `json:"scopes"`
+	NonConforming []nonConforming `json:"non_conforming"`
+}
+
+func runStatsTypes(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if statsJSON {
+		ui, _ = render.New(uiFormat, os.Stderr) // Warnings go to stderr, stdout is left for the JSON
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := applyPolicy(cfg); err != nil {
+		return err
+	}
+
+	from := statsSince
+	switch from {
+	case "":
+		from, _ = git.LatestTag(cfg.TagPattern) // No tags: the whole history
+		if from == "" && git.IsShallow() {
+			ui.Warn("⚠️  Shallow clone without tags: only the %d fetched commit(s) are counted (git fetch --unshallow --tags for all of them)", git.CountCommits(statsUntil))
+		}
+	case "all":
+		from = ""
+	}
+	commits, err := git.SubjectsBetween(from, statsUntil)
+	if err != nil {
+		return err
+	}
+	rng := statsUntil
+	if from != "" {
+		rng = from + ".." + statsUntil
+	}
+
+	stats := typeStatsOf(commits, cfg.Policy)
+	stats.Range = rng
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false) // Reasons quote "<type>(<scope>)"
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	printTypeStats(stats)
+	return nil
+}
+
+// typeStatsOf counts types and scopes; allowed types and scopes come from
+// the policy, with the standard Conventional Commits types as the default
+func typeStatsOf(commits []git.CommitInfo, pol config.Policy) typeStats {
+	rules := config.Policy{AllowedTypes: pol.AllowedTypes, AllowedScopes: pol.AllowedScopes}
+	if len(rules.AllowedTypes) == 0 {
+		rules.AllowedTypes = conventionalTypes
+	}
+
+	stats := typeStats{Total: len(commits), Types: []typeCount{}, Scopes: []typeCount{}, NonConforming: []nonConforming{}}
+	types := make(map[string]int)
+	scopes := make(map[string]int)
+	for _, c := range commits {
+		typ, scope, ok := policy.ParseHeader(c.Subject)
+		if ok {
+			types[strings.ToLower(typ)]++
+			for _, sc := range strings.Split(scope, ",") {
+				if sc = strings.TrimSpace(sc); sc != "" {
+					scopes[sc]++
+				}
+			}
+			if strings.Contains(strings.SplitN(c.Subject, ":", 2)[0], "!") {
+				stats.Breaking++
+			}
+		} else {
+			types["(none)"]++
+		}
+
+		if violations := policy.Check(c.Subject, rules); len(violations) > 0 {
+			stats.NonConforming = append(stats.NonConforming, nonConforming{Hash: c.Hash, Subject: c.Subject, Reason: violations[0].Detail})
+		} else {
+			stats.Conforming++
+		}
+	}
+	stats.Types = counts(types, len(commits))
+	stats.Scopes = counts(scopes, len(commits))
+	return stats
+}
+
+// counts turns a tally into rows, most frequent first
+func counts(tally map[string]int, total int) []typeCount {
+	rows := make([]typeCount, 0, len(tally))
+	for name, n := range tally {
+		rows = append(rows, typeCount{Name: name, Count: n, Share: float64(n) / float64(total)})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+func printTypeStats(s typeStats) {
+	fmt.Println()
+	if s.Total == 0 {
+		ui.Warn("📊 No commits in %s", s.Range)
+		return
+	}
+	ui.Info("📊 Commit types in %s (%d commit(s)):", s.Range, s.Total)
+	fmt.Println()
+	printCounts("Type", s.Types)
+
+	if len(s.Scopes) > 0 {
+		fmt.Println()
+		printCounts("Scope", s.Scopes)
+	}
+
+	fmt.Println()
+	fmt.Printf("  Conforming: %d of %d (%.0f%%)", s.Conforming, s.Total, 100*float64(s.Conforming)/float64(s.Total))
+	if s.Breaking > 0 {
+		fmt.Printf(", %d breaking", s.Breaking)
+	}
+	fmt.Println()
+
+	if len(s.NonConforming) > 0 {
+		fmt.Println()
+		ui.Warn("⚠️  Non-conforming (%d):", len(s.NonConforming))
+		for _, c := range s.NonConforming {
+			fmt.Printf("  %s %s\n", shortSHA(c.Hash), c.Subject)
+			fmt.Printf("          %s\n", color.YellowString(c.Reason))
+		}
+	}
+	fmt.Println()
+}
+
+// printCounts prints a distribution as a table with a bar per row
+func printCounts(title string, rows []typeCount) {
+	fmt.Printf("  %-12s %6s %7s\n", title, "Count", "Share")
+	for _, r := range rows {
+		bar := strings.Repeat("█", max(1, int(r.Share*30+0.5)))
+		fmt.Printf("  %-12s %6d %6.1f%%  %s\n", r.Name, r.Count, 100*r.Share, color.CyanString(bar))
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Preview what commitai would do with the working tree",
+	Long: `Preview what commitai would do with the working tree, without any API call.
+
+Shows staged, unstaged and untracked files, the commit mode commitai would
+pick, the groups --group-by dir would commit the staged files in, the size
+and estimated tokens of each diff in the prompt and which are omitted or
+truncated, and the style and scopes the history uses.`,
+	RunE: runStatus,
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	// StagedChanges errors when nothing is staged; that's fine here
+	changes, _ := git.StagedChanges()
+	unstaged, untracked, err := git.WorkingTree()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if len(changes) == 0 {
+		ui.Warn("📂 No staged changes — commitai has nothing to commit yet.")
+	} else {
+		granular := determineMode(changes)
+		mode := i18n.T("one commit for all files")
+		if granular {
+			mode = fmt.Sprintf(i18n.T("%d commits, one per file"), len(changes))
+		}
+
+		ui.Info("📂 Staged (%d) — commitai would create %s:", len(changes), mode)
+		level := ai.CompressOff
+		if cfg, err := config.Load(); err == nil {
+			level = cfg.Compress
+		}
+		for i, cost := range ai.FileCosts(changes, granular, level) {
+			c := changes[i]
+			fmt.Printf("  %s %s (%s, ~%d tokens)\n", statusToIcon(c.Status), c.Path, cost.Size(), cost.Tokens)
+			if cost.Treatment != "" {
+				fmt.Printf("      %s\n", color.YellowString(cost.Treatment))
+			}
+		}
+
+		if moves := ai.DetectMoves(changes); len(moves.Moves) > 0 {
+			fmt.Println()
+			ui.Info("↔️  Moved code (described as a move, not as added and removed code):")
+			for _, m := range moves.Moves {
+				fmt.Printf("  %s → %s: %s\n", m.From, m.To, m.What())
+			}
+		}
+
+		if groups, _ := ai.GroupChanges(changes, ai.GroupByDir); len(groups) > 1 {
+			fmt.Println()
+			ui.Info("🧩 Grouping hints (same as --group-by dir):")
+			for _, g := range groups {
+				var files []string
+				for _, c := range g.Changes {
+					files = append(files, c.Path)
+				}
+				fmt.Printf("  %-20s %s\n", g.Name, strings.Join(files, ", "))
+			}
+		}
+	}
+
+	if len(unstaged) > 0 {
+		fmt.Println()
+		ui.Info("✏️  Not staged (%d) — ignored by commitai:", len(unstaged))
+		for _, c := range unstaged {
+			fmt.Printf("  %s %s\n", statusToIcon(c.Status), c.Path)
+		}
+	}
+	if len(untracked) > 0 {
+		fmt.Println()
+		ui.Info("❔ Untracked (%d) — ignored by commitai:", len(untracked))
+		for _, path := range untracked {
+			fmt.Printf("  %s %s\n", statusToIcon("?"), path)
+		}
+	}
+
+	if cfg, err := config.Load(); err == nil {
+		if a, err := repoConventions(cfg); err == nil && a.Commits > 0 {
+			cached := ""
+			if a.Cached {
+				cached = " (cached)"
+			}
+			fmt.Println()
+			ui.Info("📐 Conventions of the last %d commit(s)%s:", a.Commits, cached)
+			fmt.Printf("  Style:  %s (%.0f%% Conventional Commits)\n", ifEmpty(a.Style, "unknown"), 100*a.Conventional)
+			fmt.Printf("  Scopes: %s\n", ifEmpty(strings.Join(a.ScopeList(), ", "), "none"))
+		}
+	}
+
+	if len(changes) == 0 && (len(unstaged) > 0 || len(untracked) > 0) {
+		fmt.Println()
+		fmt.Println(i18n.T("  Use 'git add' to stage files for commitai."))
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// suggestionFile keeps the last suggestion inside the .git directory, so a
+// new one for the same changes can be shown as a diff against it
+const suggestionFile = "commitai/suggestion.json"
+
+type lastSuggestion struct {
+	Changes  string            `json:"changes"` // Hash of the changes described
+	Messages map[string]string `json:"messages"`
+}
+
+// compareWithLastSuggestion prints how the messages differ from those
+// suggested last time for the same changes, then remembers them. Privacy
+// mode keeps no such file, so there is nothing to compare with.
+func compareWithLastSuggestion(cfg *config.Config, changes []git.FileChange, messages map[string]string) {
+	if cfg.PrivacyMode {
+		return
+	}
+	gitDir, err := git.Dir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(gitDir, suggestionFile)
+	current := lastSuggestion{Changes: changesHash(changes), Messages: messages}
+
+	var last lastSuggestion
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &last) == nil && last.Changes == current.Changes {
+		var keys []string
+		for k := range messages {
+			if old, ok := last.Messages[k]; ok && old != messages[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Println()
+			if k == "__all__" {
+				ui.Info("🔁 Changes since the last suggestion for these changes:")
+			} else {
+				ui.Info("🔁 Changes since the last suggestion for %s:", k)
+			}
+			fmt.Println(formatWordDiff(message.WordDiff(last.Messages[k], messages[k])))
+		}
+	}
+
+	if readonly.Enabled() {
+		return
+	}
+	data, err := json.Marshal(current)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		ui.Warn("⚠️  Failed to remember the suggestion: %s", err)
+	}
+}
+
+// changesHash identifies a set of changes by their content
+func changesHash(changes []git.FileChange) string {
+	h := sha256.New()
+	for _, c := range changes {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00", c.Path, c.Status, c.Diff)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// formatWordDiff marks removed words [-like this-] and added ones {+like
+// this+}, as git diff --word-diff does, in color on terminals
+func formatWordDiff(spans []message.Span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		switch s.Kind {
+		case message.SpanRemoved:
+			sb.WriteString(color.New(color.FgRed).Sprint("[-" + s.Text + "-]"))
+		case message.SpanAdded:
+			sb.WriteString(color.New(color.FgGreen).Sprint("{+" + s.Text + "+}"))
+		default:
+			sb.WriteString(s.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var stBase string
+
+var suggestTestsCmd = &cobra.Command{
+	Use:   "suggest-tests",
+	Short: "Suggest test cases for new logic the staged changes leave untested",
+	Long: `Suggest test cases for new logic the staged changes leave untested.
+
+Functions added outside test files are looked up in the staged tests and in
+the repository's test files; for those no test mentions, the AI suggests
+test cases: a test name and what it checks. No test code is written.
+
+With --base, the branch's changes since it forked from that branch are
+checked instead of the staged ones. commitai pr --suggest-tests adds the
+suggestions to the pull request description.`,
+	Args:         cobra.NoArgs,
+	RunE:         runSuggestTests,
+	SilenceUsage: true,
+}
+
+func init() {
+	suggestTestsCmd.Flags().StringVar(&stBase, "base", "", "Check the branch's changes since it forked from this branch instead of the staged ones")
+	suggestTestsCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runSuggestTests(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	var changes []git.FileChange
+	var err error
+	if stBase != "" {
+		changes, err = branchChanges(stBase)
+	} else {
+		changes, err = git.StagedChanges()
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && stBase != "" {
+		ui.Warn("⚠️  No changes since %s", stBase)
+		return nil
+	}
+	if len(changes) == 0 {
+		ui.Warn("⚠️  No staged changes. Stage files first with: git add <files>")
+		return nil
+	}
+
+	funcs := ai.UntestedFunctions(changes)
+	if len(funcs) == 0 {
+		ui.Success("✅ No untested new functions")
+		return nil
+	}
+	cfg, err := loadCommitConfig("", "")
+	if err != nil {
+		return err
+	}
+	suggestions, err := suggestTests(cfg, changes, funcs)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 60))
+	ui.Document(suggestions)
+	fmt.Println(strings.Repeat("─", 60))
+	return nil
+}
+
+// suggestTests asks for test cases for the untested functions funcs
+func suggestTests(cfg *config.Config, changes []git.FileChange, funcs []ai.NewFunction) (string, error) {
+	client := newClient(cfg)
+	ui.Info("🧪 Suggesting tests for %d untested function(s) with %s...", len(funcs), client.ProviderName())
+	suggestions, err := client.SuggestTests(changes, funcs)
+	if err != nil {
+		return "", fmt.Errorf("failed to suggest tests: %w", err)
+	}
+	return suggestions, nil
+}
+
+// withSuggestedTests ends a pull request description with test cases for
+// the functions the branch adds without tests
+func withSuggestedTests(cfg *config.Config, body, base string) (string, error) {
+	changes, err := branchChanges(base)
+	if err != nil {
+		return "", err
+	}
+	funcs := ai.UntestedFunctions(changes)
+	if len(funcs) == 0 {
+		return body, nil
+	}
+	suggestions, err := suggestTests(cfg, changes, funcs)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(body + "\n\n## Suggested tests\n\n" + suggestions), nil
+}
+
+// branchChanges returns the changes of HEAD since it forked from base
+func branchChanges(base string) ([]git.FileChange, error) {
+	fork, err := git.MergeBase(base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return git.ChangesBetween(fork, "HEAD")
+}
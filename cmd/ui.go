@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/i18n"
+	"github.com/kaiqui/commitai/internal/render"
+)
+
+var (
+	flagFormat    string // Output format of every command
+	flagLocalOnly bool   // Refuse any provider or call that leaves this machine
+
+	flagDeterministic bool // Temperature 0 and a fixed seed on every AI call
+	flagSeed          int  // Seed of deterministic mode
+
+	flagVerbose int // Trace level of the git commands run, see git.EnvVerbose
+)
+
+// ui prints every command's messages in the selected format
+var ui, _ = render.New(render.Rich, os.Stdout)
+
+// uiFormat is the format ui was set up with
+var uiFormat = render.Rich
+
+// setupUI applies the global flags and picks the UI language and the output
+// format: --format, then the config, then rich
+func setupUI(cmd *cobra.Command, args []string) error {
+	if flagLocalOnly {
+		// Through the environment, so child commitai runs (batch) inherit it
+		os.Setenv(config.EnvLocalOnly, "1")
+	}
+	if flagDeterministic {
+		os.Setenv(config.EnvDeterministic, "1")
+	}
+	if cmd.Flags().Changed("seed") {
+		os.Setenv(config.EnvSeed, strconv.Itoa(flagSeed))
+	}
+	if flagVerbose > 0 {
+		os.Setenv(git.EnvVerbose, strconv.Itoa(flagVerbose))
+	}
+	if err := applyReadOnly(cmd); err != nil {
+		return err
+	}
+	format, lang := flagFormat, ""
+	if cfg, err := config.Load(); err == nil {
+		lang = cfg.Language
+		if format == "" {
+			format = cfg.Format
+		}
+	}
+	i18n.Set(i18n.Detect(lang))
+
+	r, err := render.New(format, os.Stdout)
+	if err != nil {
+		return err
+	}
+	ui, uiFormat = r, format
+	return nil
+}
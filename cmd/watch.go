@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/watch"
+)
+
+var (
+	watInterval   time.Duration
+	watDebounce   time.Duration
+	watAutoCommit bool
+	watBranch     string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the working tree and propose commits when changes settle",
+	Long: `Watch the working tree and propose commits when changes settle.
+
+Once files stop changing for the debounce period, all changes are staged and
+commitai proposes a commit for them. With --auto-commit, commits are made
+without asking on a WIP branch (switched to when the watch starts), which is
+handy for experiment journals and checkpointing.`,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watInterval, "interval", 2*time.Second, "How often to check for changes")
+	watchCmd.Flags().DurationVar(&watDebounce, "debounce", 10*time.Second, "How long changes must settle before committing")
+	watchCmd.Flags().BoolVar(&watAutoCommit, "auto-commit", false, "Commit without confirmation on the WIP branch")
+	watchCmd.Flags().StringVar(&watBranch, "branch", "", "WIP branch for --auto-commit (default wip/<current branch>)")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	if watInterval <= 0 || watDebounce < 0 {
+		return fmt.Errorf("--interval must be positive and --debounce non-negative")
+	}
+
+	if watAutoCommit {
+		if err := switchToWIPBranch(); err != nil {
+			return err
+		}
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ui.Info("👀 Watching for changes (settle time %s). Press Ctrl+C to stop.", watDebounce)
+	w := &watch.Watcher{Interval: watInterval, Debounce: watDebounce}
+	err = w.Run(ctx, func() {
+		ui.Info("\n🕒 %s — changes settled, staging...", time.Now().Format("15:04:05"))
+		if _, err := git.Run("add", "-A"); err != nil {
+			ui.Error("✖ %s", err)
+			return
+		}
+		if _, err := gitIn(".", "diff", "--cached", "--quiet"); err == nil {
+			return // Changes were reverted before settling
+		}
+
+		args := []string{"--all"}
+		if watAutoCommit {
+			args = append(args, "--yes")
+		}
+		c := exec.Command(self, args...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			ui.Error("✖ commit failed: %s", err)
+		}
+		ui.Info("\n👀 Watching...")
+	})
+	fmt.Println()
+	ui.Info("👋 Stopped watching.")
+	return err
+}
+
+// switchToWIPBranch moves to the WIP branch, creating it from HEAD if needed.
+// Uncommitted changes come along.
+func switchToWIPBranch() error {
+	current, _ := git.CurrentBranch()
+	branch := watBranch
+	if branch == "" {
+		switch {
+		case strings.HasPrefix(current, "wip/"):
+			branch = current
+		case current == "" || current == "HEAD":
+			branch = "wip/detached"
+		default:
+			branch = "wip/" + current
+		}
+	}
+	if branch == current {
+		return nil
+	}
+
+	args := []string{"switch", branch}
+	if _, err := gitIn(".", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"switch", "-c", branch}
+	}
+	if _, err := gitIn(".", args...); err != nil {
+		return fmt.Errorf("failed to switch to %s: %w", branch, err)
+	}
+	ui.Info("🌿 Auto-committing on branch %s", branch)
+	return nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var wtList bool
+
+var worktreesCmd = &cobra.Command{
+	Use:   "worktrees",
+	Short: "Run the commit flow in every worktree of the repository with staged changes",
+	Long: `Run the commit flow in every worktree of the repository with staged changes.
+
+The main worktree and every linked one (git worktree add) are checked for
+staged changes, and commitai runs in each one that has some, one after the
+other, as commitai batch does for separate repositories.`,
+	RunE:         runWorktrees,
+	SilenceUsage: true, // Per-worktree failures are not usage errors
+}
+
+func init() {
+	worktreesCmd.Flags().BoolVar(&wtList, "list", false, "Only list the worktrees and what they have staged")
+	worktreesCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip confirmation prompts")
+	worktreesCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "d", false, "Preview commit messages without committing")
+	worktreesCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "One commit for all staged changes in each worktree")
+	worktreesCmd.Flags().BoolVarP(&flagGranular, "granular", "g", false, "One commit per staged file")
+	worktreesCmd.Flags().StringVarP(&flagLanguage, "lang", "l", "", "Language for messages (en, pt-br)")
+	worktreesCmd.Flags().StringVar(&flagStyle, "style", "", "Commit style (conventional, simple, auto)")
+	worktreesCmd.Flags().BoolVar(&flagForce, "force", false, "Commit even on protected branches")
+	worktreesCmd.Flags().Float64Var(&flagMaxCost, "max-cost", 0, "Abort if an AI call is estimated to cost more than this (USD)")
+}
+
+func runWorktrees(cmd *cobra.Command, args []string) error {
+	if !git.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	trees, err := git.Worktrees()
+	if err != nil {
+		return err
+	}
+	current, _ := git.TopLevel()
+
+	ui.Info("🌳 Worktrees:")
+	fmt.Println()
+	var staged []string
+	for _, wt := range trees {
+		marker := " "
+		if samePath(wt.Path, current) {
+			marker = color.GreenString("*")
+		}
+		branch := wt.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+
+		var state string
+		switch {
+		case wt.Bare:
+			state = color.HiBlackString("bare")
+		case wt.Prunable:
+			state = color.HiBlackString("missing")
+		default:
+			out, err := gitIn(wt.Path, "diff", "--cached", "--name-only")
+			switch n := len(strings.Fields(out)); {
+			case err != nil:
+				state = color.RedString("unreadable")
+			case n == 0:
+				state = color.HiBlackString("nothing staged")
+			default:
+				state = color.GreenString("%d staged", n)
+				staged = append(staged, wt.Path)
+			}
+		}
+		fmt.Printf("  %s %-40s %-20s %s\n", marker, wt.Path, branch, state)
+	}
+	fmt.Println()
+
+	if len(staged) == 0 {
+		ui.Warn("Nothing staged in any worktree.")
+		return nil
+	}
+	if wtList {
+		return nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var results []batchResult
+	for i, path := range staged {
+		ui.Info("\n━━━ [%d/%d] %s", i+1, len(staged), path)
+		res := batchOne(self, path)
+		results = append(results, res)
+		if res.Status == "failed" {
+			ui.Error("✖ %s", res.Detail)
+		}
+	}
+
+	printBatchReport("📊 Worktrees report:", results)
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		notifyAfter(cfg, start, fmt.Sprintf("Worktrees finished: %d worktrees, %d failed", len(results), failed))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d worktrees failed", failed, len(results))
+	}
+	return nil
+}
+
+// samePath reports whether two paths name the same directory, through
+// symlinks such as macOS's /tmp
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
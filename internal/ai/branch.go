@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// branchKinds maps common branch prefixes to the kind of work they signal
+var branchKinds = map[string]string{
+	"feature": "a new feature", "feat": "a new feature",
+	"fix": "a bug fix", "bugfix": "a bug fix", "hotfix": "an urgent bug fix",
+	"refactor": "a refactoring", "docs": "documentation", "doc": "documentation",
+	"chore": "maintenance", "test": "tests", "tests": "tests", "ci": "CI changes",
+	"perf": "a performance improvement", "release": "release preparation",
+}
+
+// ticketRe matches a leading issue key or number (PROJ-12-, 123_), which
+// carries no meaning for the message
+var ticketRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*-)?\d+([-_.]|$)`)
+
+// BranchIntent infers what a branch is for from its name, e.g.
+// "feature/PROJ-12-login-rate-limit" gives "a new feature" and "login rate
+// limit". Default branches (main, master, develop) have no intent.
+func BranchIntent(branch string) (kind, topic string) {
+	switch branch {
+	case "", "HEAD", "main", "master", "develop", "trunk":
+		return "", ""
+	}
+
+	// Leading segments are a kind (feature/) or a personal prefix (alice/)
+	segments := strings.Split(branch, "/")
+	for _, seg := range segments[:len(segments)-1] {
+		if k, known := branchKinds[strings.ToLower(seg)]; known && kind == "" {
+			kind = k
+		}
+	}
+	name := segments[len(segments)-1]
+	name = ticketRe.ReplaceAllString(name, "")
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	return kind, strings.Join(words, " ")
+}
+
+// writeBranchContext tells the model what the current branch is for so the
+// message matches the purpose of the work
+func (g *GeminiClient) writeBranchContext(sb *strings.Builder) {
+	branch := g.cfg.Branch
+	kind, topic := BranchIntent(branch)
+	if kind == "" && topic == "" {
+		return
+	}
+	sb.WriteString("Current branch: " + branch + "\n")
+	switch {
+	case kind != "" && topic != "":
+		sb.WriteString(fmt.Sprintf("The branch name suggests %s about %q.", kind, topic))
+	case kind != "":
+		sb.WriteString(fmt.Sprintf("The branch name suggests %s.", kind))
+	default:
+		sb.WriteString(fmt.Sprintf("The branch name suggests work on %q.", topic))
+	}
+	sb.WriteString(" Align the message with that purpose where the diff supports it, but describe what the diff actually does.\n\n")
+}
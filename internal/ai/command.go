@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds a single call to a custom provider command
+const commandTimeout = 5 * time.Minute
+
+// callCommand sends the conversation to the configured provider command on
+// stdin and returns its stdout. A path ending in .wasm is run as a WASI
+// module through wasmtime.
+func (g *GeminiClient) callCommand(contents []geminiContent) (string, error) {
+	command := g.cfg.ProviderCommand
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("provider_command is blank; set it with commitai config --provider-command")
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case strings.HasSuffix(fields[0], ".wasm"):
+		if _, err := exec.LookPath("wasmtime"); err != nil {
+			return "", fmt.Errorf("provider %s is a WASM module, but wasmtime is not installed", command)
+		}
+		cmd = exec.Command("wasmtime", append([]string{"run"}, fields...)...)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("cmd", "/C", command)
+	default:
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(flattenContents(contents))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	gc := g.generationConfig()
+	cmd.Env = append(os.Environ(),
+		"COMMITAI_MODEL="+g.cfg.Model,
+		fmt.Sprintf("COMMITAI_MAX_TOKENS=%d", g.cfg.MaxTokens),
+		fmt.Sprintf("COMMITAI_TEMPERATURE=%g", gc.Temperature),
+	)
+	if gc.Seed != nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("COMMITAI_SEED=%d", *gc.Seed))
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("provider command failed to start: %w", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", fmt.Errorf("provider command failed: %w\n%s", err, strings.TrimSpace(stderr.String()))
+		}
+	case <-time.After(commandTimeout):
+		cmd.Process.Kill()
+		return "", fmt.Errorf("provider command timed out after %s", commandTimeout)
+	}
+
+	out := strings.TrimSpace(stdout.String())
+	if out == "" {
+		return "", fmt.Errorf("empty response from provider command")
+	}
+	return out, nil
+}
+
+// flattenContents renders a conversation as plain text. A single prompt is
+// passed unchanged; multi-turn conversations get USER/ASSISTANT markers.
+func flattenContents(contents []geminiContent) string {
+	if len(contents) == 1 {
+		return contents[0].Parts[0].Text
+	}
+	var sb strings.Builder
+	for _, c := range contents {
+		role := "USER"
+		if c.Role == "model" {
+			role = "ASSISTANT"
+		}
+		sb.WriteString(role + ":\n")
+		for _, p := range c.Parts {
+			sb.WriteString(p.Text + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("ASSISTANT:\n")
+	return sb.String()
+}
@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Prompt compression levels for Config.Compress
+const (
+	CompressOff    = 0
+	CompressLight  = 1 // One line of context, no index lines
+	CompressMedium = 2 // Plus repeated hunks collapsed and moved blocks deduplicated
+	CompressMax    = 3 // Plus no context lines and no diff headers
+	minMovedLines  = 3 // Smaller blocks are too likely to match by accident
+)
+
+// hunk is one @@ section of a file diff
+type hunk struct {
+	header string
+	lines  []string
+}
+
+// fileDiff is a parsed unified diff of one file
+type fileDiff struct {
+	path    string
+	headers []string // diff --git, mode, ---/+++ lines
+	hunks   []hunk
+}
+
+// CompressChanges shrinks the diffs of changes for the prompt according to
+// level and returns the compressed copies. Paths, statuses and notes are kept.
+func CompressChanges(changes []git.FileChange, level int) []git.FileChange {
+	if level <= CompressOff {
+		return changes
+	}
+
+	files := make([]*fileDiff, len(changes))
+	for i, c := range changes {
+		files[i] = parseFileDiff(c.Path, c.Diff)
+	}
+
+	context := 1
+	if level >= CompressMax {
+		context = 0
+	}
+	for _, f := range files {
+		for i := range f.hunks {
+			f.hunks[i].lines = trimContext(f.hunks[i].lines, context)
+		}
+	}
+	if level >= CompressMedium {
+		dedupeMoves(files)
+		collapseRepeats(files)
+	}
+
+	out := make([]git.FileChange, len(changes))
+	for i, c := range changes {
+		out[i] = c
+		if c.Diff != "" {
+			out[i].Diff = files[i].render(level)
+		}
+	}
+	return out
+}
+
+// prompt builds a prompt from changes (anonymized when Anon is set)
+// compressed at the configured level, with the moves found in the full
+// diffs, and records how many tokens compression saved for the next estimate
+func (g *GeminiClient) prompt(changes []git.FileChange, build func([]git.FileChange, MovedCode) string) string {
+	if g.Anon != nil {
+		changes = g.Anon.Changes(changes)
+	}
+	moves := DetectMoves(changes)
+	g.savedTokens = 0
+	if g.cfg.Compress <= CompressOff {
+		return build(changes, moves)
+	}
+	prompt := build(CompressChanges(changes, g.cfg.Compress), moves)
+	if saved := len(build(changes, moves)) - len(prompt); saved > 0 {
+		g.savedTokens = saved / 4
+	}
+	return prompt
+}
+
+func parseFileDiff(path, diff string) *fileDiff {
+	f := &fileDiff{path: path}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			f.hunks = append(f.hunks, hunk{header: line})
+		case len(f.hunks) > 0:
+			f.hunks[len(f.hunks)-1].lines = append(f.hunks[len(f.hunks)-1].lines, line)
+		default:
+			f.headers = append(f.headers, line)
+		}
+	}
+	return f
+}
+
+func (f *fileDiff) render(level int) string {
+	var sb strings.Builder
+	for _, h := range f.headers {
+		if strings.HasPrefix(h, "index ") || level >= CompressMax && (strings.HasPrefix(h, "diff --git") ||
+			strings.HasPrefix(h, "--- ") || strings.HasPrefix(h, "+++ ")) {
+			continue
+		}
+		if h != "" {
+			sb.WriteString(h + "\n")
+		}
+	}
+	for _, h := range f.hunks {
+		sb.WriteString(h.header + "\n")
+		for _, l := range h.lines {
+			sb.WriteString(l + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// trimContext keeps at most n context lines around each change and marks
+// the gaps left by dropped context
+func trimContext(lines []string, n int) []string {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if isChange(l) {
+			for j := max(i-n, 0); j <= min(i+n, len(lines)-1); j++ {
+				keep[j] = true
+			}
+		}
+	}
+
+	var out []string
+	dropped := false
+	for i, l := range lines {
+		if keep[i] || l == "" || strings.HasPrefix(l, `\`) {
+			if dropped && len(out) > 0 {
+				out = append(out, " ⋯")
+			}
+			if l != "" {
+				out = append(out, l)
+			}
+			dropped = false
+			continue
+		}
+		dropped = true
+	}
+	return out
+}
+
+func isChange(l string) bool {
+	return strings.HasPrefix(l, "+") || strings.HasPrefix(l, "-")
+}
+
+// block is a run of consecutive added or removed lines
+type block struct {
+	file       *fileDiff
+	hunk, from int
+	n          int
+	key        string
+}
+
+// blocks returns the runs of lines with the given prefix ("+" or "-")
+func blocks(files []*fileDiff, prefix string) []block {
+	var out []block
+	for _, f := range files {
+		for hi, h := range f.hunks {
+			for i := 0; i < len(h.lines); {
+				if !strings.HasPrefix(h.lines[i], prefix) {
+					i++
+					continue
+				}
+				j := i
+				var text []string
+				for j < len(h.lines) && strings.HasPrefix(h.lines[j], prefix) {
+					text = append(text, strings.TrimSpace(h.lines[j][1:]))
+					j++
+				}
+				// Diffs place blank lines at either end of a moved block
+				key := strings.Trim(strings.Join(text, "\n"), "\n")
+				out = append(out, block{file: f, hunk: hi, from: i, n: j - i, key: key})
+				i = j
+			}
+		}
+	}
+	return out
+}
+
+// dedupeMoves replaces blocks removed in one place and added unchanged
+// (ignoring indentation) in another with a one-line note on each side
+func dedupeMoves(files []*fileDiff) {
+	removed := make(map[string]block)
+	for _, b := range blocks(files, "-") {
+		if b.n >= minMovedLines {
+			removed[b.key] = b
+		}
+	}
+
+	type edit struct {
+		b    block
+		note string
+	}
+	var edits []edit
+	for _, add := range blocks(files, "+") {
+		rem, ok := removed[add.key]
+		if !ok || add.n < minMovedLines {
+			continue
+		}
+		delete(removed, add.key)
+		edits = append(edits,
+			edit{rem, fmt.Sprintf("-[%d lines moved to %s]", rem.n, add.file.path)},
+			edit{add, fmt.Sprintf("+[%d lines moved from %s]", add.n, rem.file.path)})
+	}
+
+	// Apply from the end of each hunk so earlier offsets stay valid
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].b.from > edits[j].b.from })
+	for _, e := range edits {
+		h := &e.b.file.hunks[e.b.hunk]
+		lines := append([]string{}, h.lines[:e.b.from]...)
+		lines = append(lines, e.note)
+		h.lines = append(lines, h.lines[e.b.from+e.b.n:]...)
+	}
+}
+
+// collapseRepeats replaces hunks whose changes repeat an earlier hunk
+// (e.g. the same rename applied across many files) with a reference to it
+func collapseRepeats(files []*fileDiff) {
+	seen := make(map[string]string)
+	for _, f := range files {
+		for i, h := range f.hunks {
+			var sb strings.Builder
+			changes := 0
+			for _, l := range h.lines {
+				if isChange(l) {
+					sb.WriteString(l + "\n")
+					changes++
+				}
+			}
+			if changes == 0 {
+				continue
+			}
+			key := sb.String()
+			if where, ok := seen[key]; ok {
+				f.hunks[i].lines = []string{fmt.Sprintf(" [same %d changed line(s) as in %s]", changes, where)}
+				continue
+			}
+			seen[key] = f.path
+		}
+	}
+}
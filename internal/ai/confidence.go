@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Confidence is how sure the model was of a generated message
+type Confidence struct {
+	Level  string  `json:"level"`            // high, medium or low
+	Score  float64 `json:"score"`            // 0-1
+	Source string  `json:"source"`           // logprobs or critique
+	Reason string  `json:"reason,omitempty"` // What the critique doubts
+}
+
+// Confidence levels
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Confidence sources
+const (
+	SourceLogprobs = "logprobs"
+	SourceCritique = "critique"
+)
+
+// confidenceOf rates a 0-1 score. Commit messages are sampled at a low
+// temperature, so even a mean token probability of 0.8 means the model
+// hesitated over several words.
+func confidenceOf(score float64, source string) Confidence {
+	c := Confidence{Level: ConfidenceHigh, Score: score, Source: source}
+	switch {
+	case score < 0.65:
+		c.Level = ConfidenceLow
+	case score < 0.85:
+		c.Level = ConfidenceMedium
+	}
+	return c
+}
+
+// Confidence returns how sure the model was of each message of the last
+// GenerateCommitMessages or GenerateGroupMessages call, by the same keys.
+// It is empty when the provider returned no token probabilities and the
+// critique pass (Config.ConfidenceCritique) is off.
+func (g *GeminiClient) Confidence() map[string]Confidence {
+	return g.confidence
+}
+
+// rateAnswer records the confidence of the messages parsed from the last
+// answer from its mean token log probability, when the API returned one
+func (g *GeminiClient) rateAnswer(messages map[string]string) {
+	if g.lastLogprob == nil {
+		return
+	}
+	if g.confidence == nil {
+		g.confidence = make(map[string]Confidence)
+	}
+	c := confidenceOf(math.Exp(*g.lastLogprob), SourceLogprobs)
+	for k := range messages {
+		g.confidence[k] = c
+	}
+}
+
+var critiqueLineRe = regexp.MustCompile(`(?m)^RATING:\s*(\d+)\s*\|\s*(\d{1,3})\s*\|\s*(.*)$`)
+
+// critique asks the model to rate its own messages against the changes,
+// for providers without token probabilities. Messages it didn't rate
+// keep no confidence.
+func (g *GeminiClient) critique(changes []git.FileChange, messages map[string]string) error {
+	if !g.cfg.ConfidenceCritique || g.offline() || len(g.confidence) > 0 || len(messages) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	raw, err := g.callGemini(g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+		return g.buildCritiquePrompt(c, keys, messages)
+	}))
+	if err != nil {
+		return err
+	}
+	g.confidence = make(map[string]Confidence)
+	for _, m := range critiqueLineRe.FindAllStringSubmatch(raw, -1) {
+		i, _ := strconv.Atoi(m[1])
+		score, _ := strconv.Atoi(m[2])
+		if i < 1 || i > len(keys) || score > 100 {
+			continue
+		}
+		c := confidenceOf(float64(score)/100, SourceCritique)
+		if doubt := strings.TrimSpace(m[3]); !strings.EqualFold(strings.Trim(doubt, "."), "none") {
+			c.Reason = doubt
+		}
+		g.confidence[keys[i-1]] = c
+	}
+	return nil
+}
+
+func (g *GeminiClient) buildCritiquePrompt(changes []git.FileChange, keys []string, messages map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("You are reviewing commit messages written for the staged changes below. For each message, judge whether it describes its changes accurately and completely: it names what changed, claims nothing the diff doesn't show, and picks the right type and scope.\n\n")
+	sb.WriteString("Answer with exactly one line per message, in this format:\n")
+	sb.WriteString("RATING: <message number> | <confidence that the message is right, 0-100> | <the part most likely wrong or missing, in a few words, or none>\n\n")
+
+	for i, k := range keys {
+		sb.WriteString(fmt.Sprintf("MESSAGE %d", i+1))
+		if k != "__all__" {
+			sb.WriteString(" (for " + g.Anon.Hide(k) + ")")
+		}
+		sb.WriteString(":\n" + g.Anon.Hide(messages[k]) + "\n\n")
+	}
+	writeStagedChanges(&sb, changes)
+	return sb.String()
+}
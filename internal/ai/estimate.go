@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Estimate is the expected size and cost of one AI call
+type Estimate struct {
+	Model           string
+	InputTokens     int
+	SavedTokens     int // Input tokens removed by prompt compression
+	MaxOutputTokens int
+	Cost            float64 // USD upper bound (output at max_tokens); 0 when not Priced
+	Priced          bool
+}
+
+// price is USD per million tokens
+type price struct {
+	input, output float64
+}
+
+// modelPrices are list prices for paid-tier usage. Matched by longest prefix.
+var modelPrices = map[string]price{
+	"gemini-2.5-pro":        {1.25, 10.00},
+	"gemini-2.5-flash":      {0.30, 2.50},
+	"gemini-2.5-flash-lite": {0.10, 0.40},
+	"gemini-2.0-flash":      {0.10, 0.40},
+	"gemini-2.0-flash-lite": {0.075, 0.30},
+	"gemini-1.5-pro":        {1.25, 5.00},
+	"gemini-1.5-flash":      {0.075, 0.30},
+}
+
+func (g *GeminiClient) estimate(contents []geminiContent) Estimate {
+	var size, media int
+	for _, c := range contents {
+		for _, p := range c.Parts {
+			size += len(p.Text)
+			if p.InlineData != nil {
+				// Audio is 32 tokens per second, about 1 per KB of 16 kHz WAV
+				media += base64.StdEncoding.DecodedLen(len(p.InlineData.Data)) / 1000
+			}
+		}
+	}
+	est := Estimate{
+		Model:           g.cfg.Model,
+		InputTokens:     (size+3)/4 + media, // About 4 bytes per token
+		SavedTokens:     g.savedTokens,
+		MaxOutputTokens: g.cfg.MaxTokens,
+	}
+	if g.cfg.ProviderCommand != "" {
+		return est // Unknown pricing
+	}
+	if p, ok := lookupPrice(g.cfg.Model); ok {
+		est.Priced = true
+		est.Cost = (float64(est.InputTokens)*p.input + float64(est.MaxOutputTokens)*p.output) / 1e6
+	}
+	return est
+}
+
+func lookupPrice(model string) (price, bool) {
+	best := ""
+	for prefix := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return price{}, false
+	}
+	return modelPrices[best], true
+}
+
+// FileCost is what one staged file adds to the prompt
+type FileCost struct {
+	Path      string
+	Bytes     int    // Size of the full diff
+	Tokens    int    // Estimated tokens of the diff as sent
+	Treatment string // How the diff is cut down, see PromptTreatment
+}
+
+// FileCosts estimates the prompt share of each change's diff, after
+// compression at level and the per-file truncation
+func FileCosts(changes []git.FileChange, granular bool, level int) []FileCost {
+	limit := singleDiffLimit
+	if granular {
+		limit = granularDiffLimit
+	}
+	sent := CompressChanges(changes, level)
+	costs := make([]FileCost, len(changes))
+	for i, c := range changes {
+		costs[i] = FileCost{Path: c.Path, Bytes: len(c.Diff), Treatment: PromptTreatment(c, granular)}
+		if c.Diff == "" || !includeDiff(c) {
+			continue
+		}
+		costs[i].Tokens = (min(len(sent[i].Diff), limit) + 3) / 4 // About 4 bytes per token
+	}
+	return costs
+}
+
+// Truncated reports whether only part of the diff is sent
+func (f FileCost) Truncated() bool { return strings.HasPrefix(f.Treatment, "diff truncated") }
+
+// Size returns the diff size for display, e.g. "4.2 KB"
+func (f FileCost) Size() string { return humanSize(int64(f.Bytes)) }
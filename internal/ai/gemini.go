@@ -3,21 +3,58 @@ package ai
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/kaiqui/commitai/internal/anonymize"
 	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/deps"
 	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/history"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/report"
 )
 
-const geminiURL = "https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s"
+const (
+	geminiBaseURL = "https://generativelanguage.googleapis.com"
+	generatePath  = "/v1beta/models/%s:generateContent"
+)
 
 type GeminiClient struct {
 	cfg    *config.Config
 	client *http.Client
+
+	// BeforeCall, if set, sees the estimate of every AI call before it is
+	// sent. Returning an error cancels the call.
+	BeforeCall func(Estimate) error
+
+	// AfterCall, if set, sees the tokens every answered AI call used: the
+	// estimated prompt tokens plus those of the answer
+	AfterCall func(tokens int)
+
+	// savedTokens is what compression took off the current prompt
+	savedTokens int
+
+	// flagged holds what is wrong with the messages last generated, see
+	// Flagged
+	flagged map[string][]string
+
+	// lastLogprob is the mean token log probability of the last answer,
+	// nil when the API didn't return one
+	lastLogprob *float64
+	// confidence rates the messages last generated, see Confidence
+	confidence map[string]Confidence
+
+	// Anon, if set, replaces identifying values in diffs with placeholders
+	// before they are sent, and restores them in the answers
+	Anon *anonymize.Mapping
 }
 
 func NewGeminiClient(cfg *config.Config) *GeminiClient {
@@ -27,6 +64,17 @@ func NewGeminiClient(cfg *config.Config) *GeminiClient {
 	}
 }
 
+// ProviderName is the provider shown in progress messages
+func (g *GeminiClient) ProviderName() string {
+	if g.offline() {
+		return "offline rules"
+	}
+	if g.cfg.ProviderCommand != "" {
+		return "custom provider"
+	}
+	return "Gemini"
+}
+
 // --- Request/Response types ---
 
 type geminiRequest struct {
@@ -35,50 +83,358 @@ type geminiRequest struct {
 }
 
 type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model" in conversations
 	Parts []geminiPart `json:"parts"`
 }
 
 type geminiPart struct {
-	Text string `json:"text"`
+	Text       string      `json:"text,omitempty"`
+	InlineData *geminiBlob `json:"inlineData,omitempty"`
+}
+
+// geminiBlob is media sent with a prompt, e.g. a voice note
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // Base64
 }
 
 type geminiGenerationConfig struct {
 	Temperature     float64 `json:"temperature"`
 	MaxOutputTokens int     `json:"maxOutputTokens"`
+	Seed            *int    `json:"seed,omitempty"`
+}
+
+// generationConfig samples at a low temperature, or greedily with a fixed
+// seed in deterministic mode
+func (g *GeminiClient) generationConfig() geminiGenerationConfig {
+	gc := geminiGenerationConfig{Temperature: 0.3, MaxOutputTokens: g.cfg.MaxTokens}
+	if g.cfg.Deterministic {
+		seed := g.cfg.Seed
+		gc.Temperature, gc.Seed = 0, &seed
+	}
+	return gc
 }
 
 type geminiResponse struct {
 	Candidates []struct {
-		Content geminiContent `json:"content"`
+		Content     geminiContent `json:"content"`
+		AvgLogprobs *float64      `json:"avgLogprobs,omitempty"`
 	} `json:"candidates"`
 	Error *struct {
+		Code    int    `json:"code"`
 		Message string `json:"message"`
+		Status  string `json:"status"` // e.g. RESOURCE_EXHAUSTED
 	} `json:"error,omitempty"`
 }
 
+// Release notes audiences
+const (
+	AudienceUsers      = "users"
+	AudienceDevelopers = "developers"
+	AudienceInternal   = "internal"
+)
+
+// Release notes lengths
+const (
+	DetailTerse    = "terse"
+	DetailDetailed = "detailed"
+)
+
+// ReleaseOptions tunes how release notes are written
+type ReleaseOptions struct {
+	Audience string // users, developers, internal (empty = general)
+	Detail   string // terse, detailed (empty = concise)
+
+	// PreviousNotes are the notes of the previous release, whose items the
+	// new notes don't repeat (overlapping ranges, hotfix backports)
+	PreviousNotes string
+
+	// Snippets are lines the commits added that show their usage, for
+	// examples in the notes (see DiffSnippets and FitSnippets)
+	Snippets []Snippet
+}
+
+// maxPreviousNotes caps the previous release's notes sent with a prompt
+const maxPreviousNotes = 6000
+
+// ValidAudience reports whether a is a supported release notes audience
+func ValidAudience(a string) bool {
+	switch a {
+	case "", AudienceUsers, AudienceDevelopers, AudienceInternal:
+		return true
+	}
+	return false
+}
+
 // --- Public methods ---
 
 // GenerateCommitMessages makes a SINGLE request to Gemini for all staged files.
 // Returns a map of filepath -> commit message (or a single message if granular=false).
+// In granular mode, files the answer skipped are re-requested once with a
+// targeted prompt; files still missing afterwards are absent from the map.
 func (g *GeminiClient) GenerateCommitMessages(changes []git.FileChange, granular bool, recentCommits []string) (map[string]string, error) {
-	prompt := g.buildCommitPrompt(changes, granular, recentCommits)
+	g.confidence = nil
+	result, err := g.generateCommitMessages(changes, granular, recentCommits)
+	if err != nil {
+		return nil, err
+	}
+	if !g.offline() {
+		g.moderate(changes, granular, recentCommits, result)
+	}
+	g.critique(changes, result) // Without a rating, no confidence is shown
+	for key, msg := range result {
+		result[key] = g.withImpact(msg, changesFor(changes, key))
+	}
+	return result, nil
+}
+
+func (g *GeminiClient) generateCommitMessages(changes []git.FileChange, granular bool, recentCommits []string) (map[string]string, error) {
+	if g.offline() {
+		return g.offlineCommitMessages(changes, granular), nil
+	}
+	prompt := g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+		return g.buildCommitPrompt(c, moves, granular, recentCommits)
+	})
 
 	raw, err := g.callGemini(prompt)
 	if err != nil {
 		return nil, err
 	}
 
-	return g.parseCommitResponse(raw, changes, granular), nil
+	result := g.parseCommitResponse(raw, changes, granular)
+	g.rateAnswer(result)
+	if !granular {
+		return result, nil
+	}
+
+	// Keep what we got and only ask again for the files that were skipped
+	missing := MissingFiles(changes, result)
+	if len(missing) == 0 {
+		return result, nil
+	}
+	retryRaw, err := g.callGemini(g.prompt(missing, func(c []git.FileChange, moves MovedCode) string {
+		return g.buildCommitPrompt(c, moves, true, recentCommits)
+	}))
+	if err != nil {
+		return result, nil // Partial result; caller reports the missing files
+	}
+	retried := g.parseCommitResponse(retryRaw, missing, true)
+	for path := range retried {
+		if _, ok := result[path]; ok {
+			delete(retried, path)
+		}
+	}
+	g.rateAnswer(retried)
+	for path, msg := range retried {
+		result[path] = msg
+	}
+	return result, nil
+}
+
+// GenerateFromIntent polishes the author's own description of the staged
+// changes into a commit message, checked against the actual diff.
+func (g *GeminiClient) GenerateFromIntent(intent string, changes []git.FileChange, recentCommits []string) (string, error) {
+	if g.offline() {
+		return g.withImpact(g.offlineFromIntent(intent, changes), changes), nil
+	}
+	raw, err := g.callGemini(g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+		return g.buildIntentPrompt(intent, c, moves, recentCommits)
+	}))
+	if err != nil {
+		return "", err
+	}
+	return g.withImpact(strings.TrimSpace(raw), changes), nil
+}
+
+// Chat is an iterative refinement conversation about one commit message
+type Chat struct {
+	g     *GeminiClient
+	turns []geminiContent
+}
+
+// NewChat starts a refinement conversation from a suggested message for the
+// given staged changes
+func (g *GeminiClient) NewChat(changes []git.FileChange, recentCommits []string, suggestion string) *Chat {
+	return &Chat{g: g, turns: []geminiContent{
+		{Role: "user", Parts: []geminiPart{{Text: g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+			return g.buildCommitPrompt(c, moves, false, recentCommits)
+		})}}},
+		{Role: "model", Parts: []geminiPart{{Text: g.Anon.Hide(suggestion)}}},
+	}}
+}
+
+// Refine asks for a revised message following the instruction, keeping the
+// whole conversation as context
+func (c *Chat) Refine(instruction string) (string, error) {
+	if c.g.offline() {
+		return "", errors.New("the offline provider can't follow instructions; edit the message instead")
+	}
+	prompt := fmt.Sprintf("Revise the commit message: %s\nKeep every other rule. Output ONLY the revised commit message, nothing else.", c.g.Anon.Hide(instruction))
+	turns := append(c.turns, geminiContent{Role: "user", Parts: []geminiPart{{Text: prompt}}})
+
+	raw, err := c.g.callGeminiContents(turns)
+	if err != nil {
+		return "", err
+	}
+	msg := strings.TrimSpace(raw)
+	c.turns = append(turns, geminiContent{Role: "model", Parts: []geminiPart{{Text: c.g.Anon.Hide(msg)}}})
+	return msg, nil
+}
+
+// UnknownFiles returns paths in messages that are not among the staged changes
+func UnknownFiles(changes []git.FileChange, messages map[string]string) []string {
+	staged := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		staged[c.Path] = true
+	}
+	var unknown []string
+	for path := range messages {
+		if !staged[path] {
+			unknown = append(unknown, path)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// MissingFiles returns the changes that have no message in messages
+func MissingFiles(changes []git.FileChange, messages map[string]string) []git.FileChange {
+	var missing []git.FileChange
+	for _, c := range changes {
+		if _, ok := messages[c.Path]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	return missing
 }
 
 // GenerateReleaseNotes generates release notes for a new version.
-func (g *GeminiClient) GenerateReleaseNotes(commits []string, currentTag, newTag string) (string, error) {
-	prompt := buildReleasePrompt(commits, currentTag, newTag)
+func (g *GeminiClient) GenerateReleaseNotes(commits []string, currentTag, newTag string, opts ReleaseOptions) (string, error) {
+	if g.offline() {
+		return g.offlineReleaseNotes(withoutShipped(commits, opts.PreviousNotes), currentTag, newTag) + offlineSnippets(opts.Snippets), nil
+	}
+	if g.Anon != nil && len(opts.Snippets) > 0 {
+		hidden := make([]Snippet, len(opts.Snippets))
+		for i, sn := range opts.Snippets {
+			lines := strings.Split(sn.Code, "\n")
+			for j, l := range lines {
+				lines[j] = g.Anon.Line(l)
+			}
+			sn.Code, sn.File = strings.Join(lines, "\n"), g.Anon.Path(sn.File)
+			hidden[i] = sn
+		}
+		opts.Snippets = hidden
+	}
+	prompt := buildReleasePrompt(commits, currentTag, newTag, opts)
 	return g.callGemini(prompt)
 }
 
+// GenerateChangelogSection generates the body of a CHANGELOG.md entry for a tag.
+func (g *GeminiClient) GenerateChangelogSection(commits []string, previousTag, tag string) (string, error) {
+	if g.offline() {
+		return g.offlineChangelog(commits), nil
+	}
+	prompt := buildChangelogPrompt(commits, previousTag, tag)
+	raw, err := g.callGemini(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// GenerateMigrationGuide writes a migration guide section for a major release
+// from its breaking commits and their diffs.
+func (g *GeminiClient) GenerateMigrationGuide(breaking []git.CommitInfo, currentTag, newTag string) (string, error) {
+	if g.offline() {
+		return g.offlineMigrationGuide(breaking, currentTag, newTag), nil
+	}
+	prompt := buildMigrationPrompt(breaking, currentTag, newTag)
+	raw, err := g.callGemini(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// GenerateReport writes the narrative of a repository activity report:
+// summary, themes, hotspots, contributors and risky areas, as markdown.
+func (g *GeminiClient) GenerateReport(a *report.Activity) (string, error) {
+	if g.offline() {
+		return g.offlineReport(a), nil
+	}
+	raw, err := g.callGemini(buildReportPrompt(a))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// GenerateNote writes a detailed explanation of a commit, meant for git notes
+// rather than the message: motivation, approach, subtle points and risks.
+func (g *GeminiClient) GenerateNote(c git.CommitInfo, diff string) (string, error) {
+	if g.offline() {
+		return g.offlineNote(c, diff), nil
+	}
+	if g.Anon != nil {
+		diff = g.Anon.Diff(diff)
+		c.Subject, c.Body = g.Anon.Hide(c.Subject), g.Anon.Hide(c.Body)
+	}
+	raw, err := g.callGemini(buildNotePrompt(c, diff, g.cfg.Language))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// GenerateSquash writes the message of the single commit a branch becomes
+// when squash-merged, from the branch's commits and its overall changes.
+// With a previous draft, the draft is updated instead of rewritten.
+func (g *GeminiClient) GenerateSquash(draft string, commits []git.CommitInfo, changes []git.FileChange) (string, error) {
+	if g.offline() {
+		return g.withImpact(g.offlineSquash(commits, changes), changes), nil
+	}
+	raw, err := g.callGemini(g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+		return g.buildSquashPrompt(draft, commits, c, moves)
+	}))
+	if err != nil {
+		return "", err
+	}
+	return g.withImpact(strings.TrimSpace(raw), changes), nil
+}
+
+// AnswerHistory answers a question about the repository's history from the
+// candidate commits, returning the answer and the short hashes it cites
+func (g *GeminiClient) AnswerHistory(question string, candidates []history.Candidate) (answer string, cited []string, err error) {
+	if g.offline() {
+		answer, cited = g.offlineAnswer(candidates)
+		return answer, cited, nil
+	}
+	if g.Anon != nil {
+		hidden := make([]history.Candidate, len(candidates))
+		for i, c := range candidates {
+			c.Commit.Subject = g.Anon.Hide(c.Commit.Subject)
+			lines := make([]string, len(c.Lines))
+			for j, l := range c.Lines {
+				lines[j] = g.Anon.Line(l)
+			}
+			c.Lines = lines
+			hidden[i] = c
+		}
+		candidates = hidden
+	}
+	raw, err := g.callGemini(buildAskPrompt(question, candidates, g.cfg.Language))
+	if err != nil {
+		return "", nil, err
+	}
+	answer, cited = parseAnswer(raw)
+	return answer, cited, nil
+}
+
 // SuggestNextVersion suggests the next semver version based on commits.
 func (g *GeminiClient) SuggestNextVersion(commits []string, currentTag string) (string, error) {
+	if g.offline() {
+		return g.offlineVersion(commits, currentTag), nil
+	}
 	prompt := buildVersionPrompt(commits, currentTag)
 	raw, err := g.callGemini(prompt)
 	if err != nil {
@@ -98,14 +454,47 @@ func (g *GeminiClient) SuggestNextVersion(commits []string, currentTag string) (
 // --- Internal ---
 
 func (g *GeminiClient) callGemini(prompt string) (string, error) {
+	return g.callGeminiContents([]geminiContent{
+		{Parts: []geminiPart{{Text: prompt}}},
+	})
+}
+
+func (g *GeminiClient) callGeminiContents(contents []geminiContent) (string, error) {
+	text, err := g.send(contents)
+	if err != nil {
+		return "", err
+	}
+	return g.Anon.Restore(text), nil
+}
+
+func (g *GeminiClient) send(contents []geminiContent) (string, error) {
+	if g.cfg.LocalOnly {
+		if err := g.cfg.CheckLocalOnly(); err != nil {
+			return "", err
+		}
+	}
+	g.lastLogprob = nil
+	est := g.estimate(contents)
+	if g.BeforeCall != nil {
+		if err := g.BeforeCall(est); err != nil {
+			return "", err
+		}
+	}
+	text, err := g.sendContents(contents)
+	if err == nil && g.AfterCall != nil {
+		g.AfterCall(est.InputTokens + (len(text)+3)/4)
+	}
+	return text, err
+}
+
+func (g *GeminiClient) sendContents(contents []geminiContent) (string, error) {
+	if g.cfg.ProviderCommand != "" {
+		return g.callCommand(contents)
+	}
+
 	req := geminiRequest{
-		Contents: []geminiContent{
-			{Parts: []geminiPart{{Text: prompt}}},
-		},
-		GenerationConfig: geminiGenerationConfig{
-			Temperature:     0.3,
-			MaxOutputTokens: g.cfg.MaxTokens,
-		},
+		Contents:         contents,
+		GenerationConfig: g.generationConfig(),
 	}
 
 	body, err := json.Marshal(req)
@@ -113,77 +502,113 @@ func (g *GeminiClient) callGemini(prompt string) (string, error) {
 		return "", err
 	}
 
-	url := fmt.Sprintf(geminiURL, g.cfg.Model, g.cfg.GeminiAPIKey)
-	resp, err := g.client.Post(url, "application/json", bytes.NewReader(body))
+	// With several keys, a key out of quota hands over to the next one
+	keys := keyOrder(g.cfg)
+	if len(keys) == 0 {
+		keys = []string{""} // Gateway authenticating through its own headers
+	}
+	for i, key := range keys {
+		text, quota, err := g.post(body, key)
+		if quota && i < len(keys)-1 {
+			continue
+		}
+		if quota && len(keys) > 1 {
+			return "", fmt.Errorf("all %d API keys are out of quota: %w", len(keys), err)
+		}
+		if err == nil {
+			keyWorked(g.cfg, key)
+		}
+		return text, err
+	}
+	return "", errors.New("unreachable")
+}
+
+// newGeminiRequest builds a request to the Gemini API or the configured
+// gateway, with the key in a header (never in the URL, so errors can't leak
+// it) and the configured extra headers
+func newGeminiRequest(cfg *config.Config, method, path, key string, body io.Reader) (*http.Request, error) {
+	if cfg.LocalOnly {
+		if err := cfg.CheckLocalOnly(); err != nil {
+			return nil, err
+		}
+	}
+	base := geminiBaseURL
+	if cfg.GatewayURL != "" {
+		base = strings.TrimSuffix(cfg.GatewayURL, "/")
+	}
+	req, err := http.NewRequest(method, base+path, body)
 	if err != nil {
-		return "", fmt.Errorf("request to Gemini failed: %w", err)
+		return nil, err
+	}
+	if key != "" {
+		req.Header.Set("x-goog-api-key", key)
+	}
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+	return req, nil
+}
+
+// post sends one generateContent request. quota reports a rate limit or
+// exhausted quota, which another key may not have.
+func (g *GeminiClient) post(body []byte, key string) (text string, quota bool, err error) {
+	req, err := newGeminiRequest(g.cfg, http.MethodPost, fmt.Sprintf(generatePath, g.cfg.Model), key, bytes.NewReader(body))
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("request to Gemini failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	var gemResp geminiResponse
 	if err := json.Unmarshal(data, &gemResp); err != nil {
-		return "", fmt.Errorf("failed to parse Gemini response: %w\nBody: %s", err, string(data))
+		return "", false, fmt.Errorf("failed to parse Gemini response: %w\nBody: %s", err, string(data))
 	}
 
 	if gemResp.Error != nil {
-		return "", fmt.Errorf("Gemini API error: %s", gemResp.Error.Message)
+		quota = gemResp.Error.Code == http.StatusTooManyRequests || gemResp.Error.Status == "RESOURCE_EXHAUSTED"
+		return "", quota, fmt.Errorf("Gemini API error: %s", gemResp.Error.Message)
 	}
 
 	if len(gemResp.Candidates) == 0 || len(gemResp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("empty response from Gemini")
+		return "", false, fmt.Errorf("empty response from Gemini")
 	}
 
-	return gemResp.Candidates[0].Content.Parts[0].Text, nil
+	g.lastLogprob = gemResp.Candidates[0].AvgLogprobs
+	return gemResp.Candidates[0].Content.Parts[0].Text, false, nil
 }
 
-func (g *GeminiClient) buildCommitPrompt(changes []git.FileChange, granular bool, recentCommits []string) string {
+func (g *GeminiClient) buildCommitPrompt(changes []git.FileChange, moves MovedCode, granular bool, recentCommits []string) string {
 	var sb strings.Builder
-
-	style := g.cfg.CommitStyle
-	lang := g.cfg.Language
-
-	sb.WriteString("You are an expert developer writing git commit messages.\n\n")
-
-	if style == "conventional" {
-		sb.WriteString("Use Conventional Commits format: <type>(<scope>): <description>\n")
-		sb.WriteString("Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build\n\n")
-	}
-
-	if lang == "pt" || lang == "pt-br" {
-		sb.WriteString("Write commit messages in Portuguese (pt-BR).\n\n")
-	} else {
-		sb.WriteString("Write commit messages in English.\n\n")
-	}
-
-	if len(recentCommits) > 0 {
-		sb.WriteString("Recent commits for context:\n")
-		for _, c := range recentCommits {
-			sb.WriteString("  " + c + "\n")
-		}
-		sb.WriteString("\n")
-	}
+	g.writeCommitGuidelines(&sb, recentCommits)
 
 	if granular {
 		sb.WriteString(fmt.Sprintf("I have %d staged file(s). Generate ONE commit message per file.\n", len(changes)))
 		sb.WriteString("Rules:\n")
-		sb.WriteString("- Each message must be concise (max 72 chars for subject line)\n")
-		sb.WriteString("- Add a blank line then a short body if needed\n")
+		sb.WriteString("- Each message must be concise (subject line " + g.subjectLimit() + ")\n")
+		sb.WriteString("- Add a blank line then a short body if needed, saying why the change was made when the diff or its comments tell\n")
 		sb.WriteString("- Output format must be EXACTLY:\n\n")
 		sb.WriteString("FILE: <filepath>\nMESSAGE:\n<commit message>\n---\n\n")
+		writeMovedCode(&sb, moves)
+		writeMotivationHints(&sb, MotivationHints(changes, moves))
 		sb.WriteString("Now here are the diffs:\n\n")
 
 		for _, c := range changes {
 			sb.WriteString(fmt.Sprintf("FILE: %s (status: %s)\n", c.Path, c.Status))
-			if c.Diff != "" {
+			writeChangeNotes(&sb, c)
+			if c.Diff != "" && includeDiff(c) {
 				// Limit diff size per file to avoid token overflow
 				diff := c.Diff
-				if len(diff) > 3000 {
-					diff = diff[:3000] + "\n... (truncated)"
+				if len(diff) > granularDiffLimit {
+					diff = diff[:granularDiffLimit] + "\n... (truncated)"
 				}
 				sb.WriteString("DIFF:\n```\n")
 				sb.WriteString(diff)
@@ -194,29 +619,306 @@ func (g *GeminiClient) buildCommitPrompt(changes []git.FileChange, granular bool
 	} else {
 		sb.WriteString("Generate ONE single commit message that summarizes ALL the following staged changes.\n")
 		sb.WriteString("Rules:\n")
-		sb.WriteString("- Subject line: max 72 chars\n")
-		sb.WriteString("- Add a blank line then bullet points listing key changes if there are multiple files\n")
+		sb.WriteString("- Subject line: " + g.subjectLimit() + "\n")
+		sb.WriteString("- Add a blank line then a body that starts with one or two sentences on WHY the change was made (the problem it solves or what it enables), not a restatement of what changed\n")
+		sb.WriteString("- Take the why from the diff, its comments and removed TODOs; don't claim a motivation they don't support\n")
+		sb.WriteString("- Then bullet points listing key changes if there are multiple files\n")
 		sb.WriteString("- Output ONLY the commit message, nothing else.\n\n")
+		writeMovedCode(&sb, moves)
+		writeMotivationHints(&sb, MotivationHints(changes, moves))
 		sb.WriteString("Staged changes:\n\n")
+		writeStagedChanges(&sb, changes)
+	}
 
-		for _, c := range changes {
-			sb.WriteString(fmt.Sprintf("FILE: %s (status: %s)\n", c.Path, c.Status))
-			if c.Diff != "" {
-				diff := c.Diff
-				if len(diff) > 2000 {
-					diff = diff[:2000] + "\n... (truncated)"
-				}
-				sb.WriteString("```\n")
-				sb.WriteString(diff)
-				sb.WriteString("\n```\n")
+	return sb.String()
+}
+
+// subjectLimit describes the subject length limit. Wide (CJK) characters take
+// two terminal columns, so the limit is given in columns for those languages.
+func (g *GeminiClient) subjectLimit() string {
+	limit := message.SubjectWidth
+	if n := g.cfg.Policy.MaxSubjectLength; n > 0 && n < limit {
+		limit = n
+	}
+	if message.IsWideLanguage(g.cfg.Language) {
+		return fmt.Sprintf("max %d display columns; each Chinese/Japanese/Korean character counts as two, so at most %d of them", limit, limit/2)
+	}
+	return fmt.Sprintf("max %d chars", limit)
+}
+
+// languageName turns a language code into a name the model understands
+func languageName(lang string) string {
+	switch strings.ToLower(lang) {
+	case "", "en":
+		return "English"
+	case "pt", "pt-br":
+		return "Portuguese (pt-BR)"
+	case "es":
+		return "Spanish"
+	case "fr":
+		return "French"
+	case "de":
+		return "German"
+	case "ja":
+		return "Japanese"
+	case "zh", "zh-cn", "zh-hans":
+		return "Simplified Chinese"
+	case "zh-tw", "zh-hant":
+		return "Traditional Chinese"
+	case "ko":
+		return "Korean"
+	case "ar":
+		return "Arabic"
+	case "he":
+		return "Hebrew"
+	default:
+		return "the language with code " + lang
+	}
+}
+
+// writeCommitTemplate asks for messages that fill in the repository's
+// commit.template instead of ignoring it
+func (g *GeminiClient) writeCommitTemplate(sb *strings.Builder) {
+	t := message.ParseTemplate(g.cfg.CommitTemplate, g.cfg.CommentChar)
+	if t.Empty() {
+		return
+	}
+	sb.WriteString("The repository has a mandatory commit message template:\n")
+	sb.WriteString("```\n" + g.Anon.Hide(t.Text) + "\n```\n")
+	sb.WriteString("Every message must follow its structure: keep each line not starting with " + t.Comment + " exactly as written (headings, labels, boilerplate) and write the content it asks for under or after it. ")
+	sb.WriteString("Lines starting with " + t.Comment + " are instructions for you: follow them, never copy them into the message. ")
+	sb.WriteString("Where the template leaves the subject line open, the subject rules below still apply.\n\n")
+}
+
+// writeStagedChanges lists every change with its notes and a truncated diff
+func writeStagedChanges(sb *strings.Builder, changes []git.FileChange) {
+	for _, c := range changes {
+		sb.WriteString(fmt.Sprintf("FILE: %s (status: %s)\n", c.Path, c.Status))
+		writeChangeNotes(sb, c)
+		if c.Diff != "" && includeDiff(c) {
+			diff := c.Diff
+			if len(diff) > singleDiffLimit {
+				diff = diff[:singleDiffLimit] + "\n... (truncated)"
 			}
-			sb.WriteString("\n")
+			sb.WriteString("```\n")
+			sb.WriteString(diff)
+			sb.WriteString("\n```\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeCommitGuidelines writes the style, policy, language and history
+// context shared by every commit message prompt
+func (g *GeminiClient) writeCommitGuidelines(sb *strings.Builder, recentCommits []string) {
+	style := g.cfg.CommitStyle
+	lang := g.cfg.Language
+
+	sb.WriteString("You are an expert developer writing git commit messages.\n\n")
+
+	pol := g.cfg.Policy
+	if style == "conventional" || len(pol.AllowedTypes) > 0 {
+		sb.WriteString("Use Conventional Commits format: <type>(<scope>): <description>\n")
+		if len(pol.AllowedTypes) > 0 {
+			sb.WriteString("Types (ONLY these are allowed): " + strings.Join(pol.AllowedTypes, ", ") + "\n")
+		} else {
+			sb.WriteString("Types: feat, fix, docs, style, refactor, test, chore, perf, ci, build\n")
+		}
+		if len(pol.AllowedScopes) > 0 {
+			sb.WriteString("Scope, if used, must be one of: " + strings.Join(pol.AllowedScopes, ", ") + "\n")
+		} else if len(g.cfg.RepoScopes) > 0 {
+			sb.WriteString("Scopes this repository uses (with the directories they cover); prefer one of them when it fits: " + strings.Join(g.cfg.RepoScopes, ", ") + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if pol.TicketPattern != "" {
+		sb.WriteString(fmt.Sprintf("Every message must reference a ticket matching the regex %s (take it from the branch name or recent commits).\n", pol.TicketPattern))
+	}
+	if len(pol.ForbiddenWords) > 0 {
+		sb.WriteString("Never use these words: " + strings.Join(pol.ForbiddenWords, ", ") + "\n")
+	}
+	if pol.Imperative {
+		sb.WriteString("Write the subject in the imperative mood, as a command: \"add x\", never \"added x\", \"adds x\" or \"adding x\".\n")
+	}
+	switch pol.SubjectCase {
+	case config.SubjectLower:
+		sb.WriteString("Start the subject description (after any type and scope) with a lower-case letter, unless it is a name or acronym that keeps its case.\n")
+	case config.SubjectSentence:
+		sb.WriteString("Start the subject description (after any type and scope) with a capital letter.\n")
+	}
+	if pol.NoTrailingPeriod {
+		sb.WriteString("Never end the subject line with a period.\n")
+	}
+	if len(g.cfg.TypeEmojis) > 0 {
+		sb.WriteString("Don't put emojis in the subject line; they are added afterwards.\n")
+	}
+	if len(pol.BodySections) > 0 {
+		sb.WriteString(fmt.Sprintf("Write every body as these sections, in this order, each starting with a line holding only its name and a colon: %s. This structure replaces the body layout asked for below; bullet points go inside a section. ", strings.Join(pol.BodySections, ", ")))
+		sb.WriteString("Only write what the diff shows, and write " + policy.EmptySection + " under a section it says nothing about (e.g. testing when no tests changed).\n")
+	}
+	if pol.MaxBodyLength > 0 {
+		sb.WriteString(fmt.Sprintf("Keep the body (everything after the subject line) under %d characters.\n", pol.MaxBodyLength))
+	}
+	if g.cfg.ImpactLine {
+		sb.WriteString("End every message with a blank line and an impact line for reviewers: \"Impact: <what the change touches>; <low|medium|high> risk\" (e.g. \"Impact: touches auth middleware; medium risk\"). Judge the risk by how central and how tested the touched code is and how easy the change is to revert.\n")
+	}
+
+	sb.WriteString("Write commit messages in " + languageName(lang) + ".\n\n")
+	g.writeBranchContext(sb)
+	g.writeCommitTemplate(sb)
+
+	if len(recentCommits) > 0 {
+		sb.WriteString("Recent commits for context:\n")
+		for _, c := range recentCommits {
+			sb.WriteString("  " + g.Anon.Hide(c) + "\n")
 		}
+		sb.WriteString("\n")
 	}
+}
+
+func (g *GeminiClient) buildIntentPrompt(intent string, changes []git.FileChange, moves MovedCode, recentCommits []string) string {
+	var sb strings.Builder
+	g.writeCommitGuidelines(&sb, recentCommits)
+
+	sb.WriteString("The author described the change in their own words:\n")
+	sb.WriteString(fmt.Sprintf("%q\n\n", g.Anon.Hide(intent)))
+	sb.WriteString("Turn this description into ONE polished commit message for the staged changes below.\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Keep the author's intent and wording where it is accurate\n")
+	sb.WriteString("- Check the description against the diff: drop claims the diff does not support\n")
+	sb.WriteString("- Briefly mention significant changes in the diff the description leaves out\n")
+	sb.WriteString("- Subject line: " + g.subjectLimit() + "\n")
+	sb.WriteString("- Add a blank line then a short body only if it adds information\n")
+	sb.WriteString("- Output ONLY the commit message, nothing else.\n\n")
+	writeMovedCode(&sb, moves)
+	sb.WriteString("Staged changes:\n\n")
+	writeStagedChanges(&sb, changes)
 
 	return sb.String()
 }
 
+// Per-file diff size sent to the model, in bytes
+const (
+	granularDiffLimit = 3000
+	singleDiffLimit   = 2000
+)
+
+// PromptTreatment describes how a change's diff is cut down in the prompt,
+// or "" when it is sent in full
+func PromptTreatment(c git.FileChange, granular bool) string {
+	switch {
+	case c.DiffLeftOut:
+		return "diff left out (dropped from the prompt)"
+	case c.IsLFSPointer():
+		return "diff omitted (Git LFS pointer, described by size)"
+	case c.IsSymlink():
+		return "diff omitted (symlink, described by target)"
+	case c.ModeOnly():
+		return "diff omitted (mode change only)"
+	}
+	limit := singleDiffLimit
+	if granular {
+		limit = granularDiffLimit
+	}
+	if len(c.Diff) > limit {
+		return fmt.Sprintf("diff truncated to %d of %d bytes", limit, len(c.Diff))
+	}
+	return ""
+}
+
+// includeDiff reports whether a change's diff is useful to the model.
+// Mode-only changes, symlinks and LFS pointers are fully described by notes;
+// diffs the user left out aren't sent at all.
+func includeDiff(c git.FileChange) bool {
+	return !c.DiffLeftOut && !c.ModeOnly() && !c.IsSymlink() && !c.IsLFSPointer()
+}
+
+// writeChangeNotes adds hints for changes whose diff alone is misleading
+func writeChangeNotes(sb *strings.Builder, c git.FileChange) {
+	if c.DiffLeftOut {
+		sb.WriteString("NOTE: the diff of this file was left out to save tokens; describe it from its path and the other changes.\n")
+	}
+	if len(c.Lockfiles) > 0 {
+		sb.WriteString(fmt.Sprintf("NOTE: committed together with its lockfile %s; this is a dependency change, so say which dependencies changed.\n", strings.Join(c.Lockfiles, ", ")))
+	}
+	if d := deps.FromDiff(c.Path, c.Diff); !d.Empty() {
+		sb.WriteString("DEPENDENCIES: " + d.Summary() + "\n")
+	}
+	switch {
+	case c.IsLFSPointer():
+		oldSize, newSize := c.LFSSizes()
+		sb.WriteString("NOTE: Git LFS tracked file (binary content stored in LFS, pointer diff omitted)")
+		switch {
+		case oldSize < 0 && newSize >= 0:
+			sb.WriteString(fmt.Sprintf("; added, %s", humanSize(newSize)))
+		case newSize < 0 && oldSize >= 0:
+			sb.WriteString(fmt.Sprintf("; removed, was %s", humanSize(oldSize)))
+		case oldSize >= 0 && newSize >= 0:
+			sb.WriteString(fmt.Sprintf("; content replaced, %s -> %s", humanSize(oldSize), humanSize(newSize)))
+		}
+		sb.WriteString(".\n")
+	case c.IsSymlink():
+		oldTarget, newTarget := c.SymlinkTargets()
+		switch {
+		case c.OldMode != "120000":
+			sb.WriteString(fmt.Sprintf("NOTE: symbolic link created, pointing to %s.\n", newTarget))
+		case c.NewMode != "120000" && c.NewMode != "":
+			sb.WriteString(fmt.Sprintf("NOTE: symbolic link (was pointing to %s) replaced by a regular file.\n", oldTarget))
+		case c.NewMode == "":
+			sb.WriteString(fmt.Sprintf("NOTE: symbolic link removed (was pointing to %s).\n", oldTarget))
+		default:
+			sb.WriteString(fmt.Sprintf("NOTE: symbolic link retargeted from %s to %s.\n", oldTarget, newTarget))
+		}
+	case strings.HasPrefix(c.Status, "D"):
+		sb.WriteString("NOTE: this file is DELETED. Describe it as a removal of what the file provided")
+		if c.LastSubject != "" {
+			sb.WriteString(fmt.Sprintf(" (its last change was: %q)", c.LastSubject))
+		}
+		sb.WriteString(".\n")
+	case c.ModeOnly():
+		sb.WriteString(strings.TrimSpace(fmt.Sprintf("NOTE: only the file mode changed (%s -> %s), the content is unchanged. %s",
+			c.OldMode, c.NewMode, describeModeChange(c.OldMode, c.NewMode))) + "\n")
+	case c.OldMode != "" && c.NewMode != "" && c.OldMode != c.NewMode:
+		sb.WriteString(strings.TrimSpace(fmt.Sprintf("NOTE: the file mode also changed (%s -> %s). %s",
+			c.OldMode, c.NewMode, describeModeChange(c.OldMode, c.NewMode))) + "\n")
+	}
+	if len(c.History) > 0 {
+		quoted := make([]string, len(c.History))
+		for i, h := range c.History {
+			quoted[i] = fmt.Sprintf("%q", h)
+		}
+		sb.WriteString("HISTORY: recent commits to this file (keep scope and wording consistent with them): " + strings.Join(quoted, "; ") + "\n")
+	}
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+func describeModeChange(oldMode, newMode string) string {
+	switch {
+	case newMode == "100755" && oldMode == "100644":
+		return "The file was made executable."
+	case newMode == "100644" && oldMode == "100755":
+		return "The file is no longer executable."
+	case newMode == "120000":
+		return "The file was replaced by a symlink."
+	case oldMode == "120000":
+		return "A symlink was replaced by a regular file."
+	default:
+		return ""
+	}
+}
+
 func (g *GeminiClient) parseCommitResponse(raw string, changes []git.FileChange, granular bool) map[string]string {
 	result := make(map[string]string)
 
@@ -225,7 +927,13 @@ func (g *GeminiClient) parseCommitResponse(raw string, changes []git.FileChange,
 		return result
 	}
 
-	// Parse FILE: / MESSAGE: / --- blocks
+	return parseBlocks(raw, "FILE:")
+}
+
+// parseBlocks parses "<label> name / MESSAGE: / ---" blocks into a map of
+// name -> message
+func parseBlocks(raw, label string) map[string]string {
+	result := make(map[string]string)
 	blocks := strings.Split(raw, "---")
 	for _, block := range blocks {
 		block = strings.TrimSpace(block)
@@ -233,12 +941,12 @@ func (g *GeminiClient) parseCommitResponse(raw string, changes []git.FileChange,
 			continue
 		}
 		lines := strings.SplitN(block, "\n", -1)
-		var filePath, message string
+		var name, message string
 		inMessage := false
 
 		for _, line := range lines {
-			if strings.HasPrefix(line, "FILE:") {
-				filePath = strings.TrimSpace(strings.TrimPrefix(line, "FILE:"))
+			if strings.HasPrefix(line, label) {
+				name = strings.TrimSpace(strings.TrimPrefix(line, label))
 				inMessage = false
 			} else if strings.HasPrefix(line, "MESSAGE:") {
 				inMessage = true
@@ -255,22 +963,25 @@ func (g *GeminiClient) parseCommitResponse(raw string, changes []git.FileChange,
 			}
 		}
 
-		if filePath != "" && message != "" {
-			result[filePath] = strings.TrimSpace(message)
-		}
-	}
-
-	// Fallback: if parsing failed, assign same message to all files
-	if len(result) == 0 && len(changes) > 0 {
-		for _, c := range changes {
-			result[c.Path] = strings.TrimSpace(raw)
+		if name != "" && message != "" {
+			result[normalizeFilePath(name)] = strings.TrimSpace(message)
 		}
 	}
 
 	return result
 }
 
-func buildReleasePrompt(commits []string, currentTag, newTag string) string {
+// normalizeFilePath undoes decorations the model sometimes adds around paths,
+// e.g. "`./src/a.go` (status: M)".
+func normalizeFilePath(p string) string {
+	if i := strings.Index(p, " (status:"); i >= 0 {
+		p = p[:i]
+	}
+	p = strings.Trim(strings.TrimSpace(p), "`*\"'")
+	return strings.TrimPrefix(p, "./")
+}
+
+func buildReleasePrompt(commits []string, currentTag, newTag string, opts ReleaseOptions) string {
 	var sb strings.Builder
 	sb.WriteString("You are a developer writing GitHub release notes.\n\n")
 	sb.WriteString(fmt.Sprintf("Generate release notes for version %s", newTag))
@@ -278,16 +989,244 @@ func buildReleasePrompt(commits []string, currentTag, newTag string) string {
 		sb.WriteString(fmt.Sprintf(" (previous: %s)", currentTag))
 	}
 	sb.WriteString(".\n\n")
+	writeAudienceGuidance(&sb, opts.Audience)
 	sb.WriteString("Rules:\n")
 	sb.WriteString("- Use markdown\n")
-	sb.WriteString("- Group into sections: ## 🚀 Features, ## 🐛 Bug Fixes, ## 🔧 Improvements, ## 📚 Docs (omit empty sections)\n")
-	sb.WriteString("- Be concise and user-friendly\n")
+	switch opts.Audience {
+	case AudienceDevelopers:
+		sb.WriteString("- Group into sections: ## ⚠️ Breaking Changes, ## 🧭 Migration Notes, ## 🚀 Features, ## 🐛 Bug Fixes, ## 🔧 Improvements, ## 📚 Docs (omit empty sections)\n")
+	case AudienceInternal:
+		sb.WriteString("- Group into sections: ## 🚀 Features, ## 🐛 Bug Fixes, ## 🔧 Improvements, ## 🧹 Refactoring & Maintenance, ## ⚙️ CI & Tooling, ## 📚 Docs (omit empty sections)\n")
+	default:
+		sb.WriteString("- Group into sections: ## 🚀 Features, ## 🐛 Bug Fixes, ## 🔧 Improvements, ## 📚 Docs (omit empty sections)\n")
+	}
+	switch opts.Detail {
+	case DetailTerse:
+		sb.WriteString("- Be terse: one short line per change, no explanations, merge related commits into one line\n")
+	case DetailDetailed:
+		sb.WriteString("- Be detailed: explain each notable change in a sentence or two, including why it matters and how to use it\n")
+	default:
+		sb.WriteString("- Be concise and user-friendly\n")
+	}
 	sb.WriteString("- Start with a one-sentence summary\n")
 	sb.WriteString("- Output ONLY the release notes markdown\n\n")
 	sb.WriteString("Commits since last release:\n")
 	for _, c := range commits {
 		sb.WriteString("- " + c + "\n")
 	}
+	if previous := strings.TrimSpace(opts.PreviousNotes); previous != "" {
+		if len(previous) > maxPreviousNotes {
+			previous = previous[:maxPreviousNotes] + "\n[...]"
+		}
+		sb.WriteString(fmt.Sprintf("\nNotes of the previous release (%s), already shipped:\n```\n%s\n```\n", currentTag, previous))
+		sb.WriteString("Don't repeat items these notes already cover, even when a commit above seems to bring them again (backports, overlapping tag ranges). Leave out such commits.\n")
+	}
+	writeSnippets(&sb, opts.Snippets)
+	return sb.String()
+}
+
+func writeAudienceGuidance(sb *strings.Builder, audience string) {
+	switch audience {
+	case AudienceUsers:
+		sb.WriteString("Audience: end users of the product.\n")
+		sb.WriteString("- Only mention changes users can notice: new features, fixed bugs, behavior changes\n")
+		sb.WriteString("- Omit refactors, tests, CI, build, chore and dependency-only commits entirely\n")
+		sb.WriteString("- Use plain, non-technical language and describe benefits, not implementation\n\n")
+	case AudienceDevelopers:
+		sb.WriteString("Audience: developers who integrate with or build on this project.\n")
+		sb.WriteString("- Call out API, CLI, config and behavior changes precisely\n")
+		sb.WriteString("- For breaking changes, add migration notes explaining what to change and how\n")
+		sb.WriteString("- Mention deprecations and notable dependency updates\n\n")
+	case AudienceInternal:
+		sb.WriteString("Audience: the internal engineering team.\n")
+		sb.WriteString("- Include every change, including refactors, tests, CI and tooling\n")
+		sb.WriteString("- Technical tone is fine; reference commit hashes where helpful\n\n")
+	}
+}
+
+func buildMigrationPrompt(breaking []git.CommitInfo, currentTag, newTag string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a developer writing a migration guide for a major release.\n\n")
+	sb.WriteString(fmt.Sprintf("Write the guide for upgrading from %s to %s.\n\n", ifEmptyStr(currentTag, "the previous version"), newTag))
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Use markdown; start with a level-2 heading: ## Migrating from <old> to <new>\n")
+	sb.WriteString("- One level-3 subsection per breaking change\n")
+	sb.WriteString("- For each change explain what changed, why, and show **Before** and **After** usage in code blocks\n")
+	sb.WriteString("- Only describe what the commits and diffs show; do not invent APIs\n")
+	sb.WriteString("- Output ONLY the migration guide markdown\n\n")
+	sb.WriteString("Breaking commits:\n\n")
+	for _, c := range breaking {
+		sb.WriteString(fmt.Sprintf("COMMIT: %s %s\n", shortHash(c.Hash), c.Subject))
+		if c.Body != "" {
+			sb.WriteString(c.Body + "\n")
+		}
+		if c.Diff != "" {
+			diff := c.Diff
+			if len(diff) > 4000 {
+				diff = diff[:4000] + "\n... (truncated)"
+			}
+			sb.WriteString("DIFF:\n```\n")
+			sb.WriteString(diff)
+			sb.WriteString("\n```\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
+
+func ifEmptyStr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func buildChangelogPrompt(commits []string, previousTag, tag string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a developer maintaining a CHANGELOG.md file.\n\n")
+	sb.WriteString(fmt.Sprintf("Write the changelog entry for version %s", tag))
+	if previousTag != "" {
+		sb.WriteString(fmt.Sprintf(" (previous: %s)", previousTag))
+	}
+	sb.WriteString(".\n\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Use markdown\n")
+	sb.WriteString("- Group into sections: ### Features, ### Bug Fixes, ### Improvements, ### Docs (omit empty sections)\n")
+	sb.WriteString("- One concise bullet per change, merge duplicates\n")
+	sb.WriteString("- Do NOT include a version heading or a summary paragraph\n")
+	sb.WriteString("- Output ONLY the changelog entry markdown\n\n")
+	sb.WriteString("Commits in this version:\n")
+	for _, c := range commits {
+		sb.WriteString("- " + c + "\n")
+	}
+	return sb.String()
+}
+
+// noteDiffLimit bounds the diff sent to explain one commit
+const noteDiffLimit = 20000
+
+func buildNotePrompt(c git.CommitInfo, diff, lang string) string {
+	var sb strings.Builder
+	sb.WriteString("You are the author of a git commit, writing a note that will be attached to it with git notes.\n\n")
+	sb.WriteString("The commit message stays short; the note holds the context a future reader (or reviewer) needs.\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Write in " + languageName(lang) + ", plain text with short paragraphs or '-' bullets, no markdown headings\n")
+	sb.WriteString("- Cover, where the diff shows it: why the change was needed, how it works, non-obvious details or trade-offs, and what could break or deserves testing\n")
+	sb.WriteString("- Don't restate the subject line or list every file\n")
+	sb.WriteString("- Only describe what the diff and message support; don't invent motivation\n")
+	sb.WriteString("- Keep it under 250 words\n")
+	sb.WriteString("- Output ONLY the note\n\n")
+	sb.WriteString(fmt.Sprintf("COMMIT: %s %s\n", shortHash(c.Hash), c.Subject))
+	if c.Body != "" {
+		sb.WriteString(c.Body + "\n")
+	}
+	if len(diff) > noteDiffLimit {
+		diff = diff[:noteDiffLimit] + "\n... (truncated)"
+	}
+	sb.WriteString("\nDIFF:\n```\n" + diff + "\n```\n")
+	return sb.String()
+}
+
+func (g *GeminiClient) buildSquashPrompt(draft string, commits []git.CommitInfo, changes []git.FileChange, moves MovedCode) string {
+	var sb strings.Builder
+	g.writeCommitGuidelines(&sb, nil)
+	sb.WriteString("This branch will be squash-merged: all its commits become ONE commit on the target branch.\n")
+	sb.WriteString("Write the message of that commit; it doubles as the pull request title and description.\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Subject line: " + g.subjectLimit() + ", describing the branch's overall change, not its last commit\n")
+	sb.WriteString("- Add a blank line then bullet points for the notable changes; leave out fixups, reverts of the branch's own work and WIP steps\n")
+	if draft != "" {
+		sb.WriteString("- Update the current draft below for the new commits: keep what is still accurate, drop what was undone\n")
+	}
+	sb.WriteString("- Output ONLY the commit message, nothing else.\n\n")
+	if draft != "" {
+		sb.WriteString("Current draft:\n```\n" + g.Anon.Hide(draft) + "\n```\n\n")
+	}
+	sb.WriteString("Commits on the branch, oldest first:\n")
+	for _, c := range commits {
+		sb.WriteString(fmt.Sprintf("  %s %s\n", shortHash(c.Hash), g.Anon.Hide(c.Subject)))
+		if c.Body != "" {
+			sb.WriteString("    " + strings.ReplaceAll(g.Anon.Hide(c.Body), "\n", "\n    ") + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	writeMovedCode(&sb, moves)
+	sb.WriteString("Overall changes of the branch:\n\n")
+	writeStagedChanges(&sb, changes)
+	return sb.String()
+}
+
+func buildAskPrompt(question string, candidates []history.Candidate, lang string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a developer answering a question about a git repository's history.\n\n")
+	sb.WriteString(fmt.Sprintf("Question: %q\n\n", question))
+	sb.WriteString("Below are candidate commits found by searching messages and diffs, most likely first, with changed lines that mention the question's terms.\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Answer in " + languageName(lang) + " using ONLY these commits; cite them by short hash and give dates where relevant\n")
+	sb.WriteString("- If several commits are involved, tell the story in order\n")
+	sb.WriteString("- If the commits don't answer the question, say so and name the closest ones\n")
+	sb.WriteString("- Keep the answer under 150 words, plain text\n")
+	sb.WriteString("- End with one line listing the commits the answer relies on, most relevant first: COMMITS: <hash>, <hash>\n\n")
+	for _, c := range candidates {
+		sb.WriteString(fmt.Sprintf("COMMIT: %s %s %s: %s\n", shortHash(c.Commit.Hash), c.Commit.Date.Format("2006-01-02"), c.Commit.Author, c.Commit.Subject))
+		for _, l := range c.Lines {
+			sb.WriteString("  " + l + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// parseAnswer splits the COMMITS line off an AnswerHistory response
+func parseAnswer(raw string) (answer string, cited []string) {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "COMMITS:"); ok {
+			for _, h := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' }) {
+				if h = strings.Trim(h, "`"); h != "" && !strings.EqualFold(h, "none") {
+					cited = append(cited, h)
+				}
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), cited
+}
+
+// reportSubjectLimit bounds the commit subjects sent for a report
+const reportSubjectLimit = 300
+
+func buildReportPrompt(a *report.Activity) string {
+	var sb strings.Builder
+	sb.WriteString("You are a staff engineer writing a health report about a git repository for the team.\n\n")
+	sb.WriteString(fmt.Sprintf("Period: %s (%d commits, %d contributors).\n\n", a.Period(), a.Commits, len(a.Authors)))
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Use markdown with exactly these sections: ## Summary, ## Themes, ## Hotspots, ## Contributors, ## Risky areas\n")
+	sb.WriteString("- Summary: 2-4 sentences on what the period was about\n")
+	sb.WriteString("- Themes: the main streams of work, inferred from the commit subjects\n")
+	sb.WriteString("- Hotspots: where activity concentrated and what it suggests\n")
+	sb.WriteString("- Contributors: who worked on what, factually, without ranking or judging people\n")
+	sb.WriteString("- Risky areas: files or directories with high churn by many authors, and why they deserve attention (tests, review, refactoring)\n")
+	sb.WriteString("- Base every claim on the data below; don't repeat the tables, they are appended to the report\n")
+	sb.WriteString("- Do NOT include a top-level title\n")
+	sb.WriteString("- Output ONLY the markdown\n\n")
+	sb.WriteString("Figures:\n\n")
+	sb.WriteString(a.Tables(15))
+	sb.WriteString("\nCommit subjects (newest first):\n")
+	for i, c := range a.Subjects {
+		if i == reportSubjectLimit {
+			sb.WriteString(fmt.Sprintf("- ... and %d older commit(s)\n", len(a.Subjects)-i))
+			break
+		}
+		sb.WriteString("- " + c + "\n")
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/owners"
+)
+
+// Ways to group staged files into commits with --group-by
+const (
+	GroupByDir     = "dir"     // Top-level directory
+	GroupByPackage = "package" // Directory containing the file (a Go package)
+	GroupByType    = "type"    // Kind of file: code, test, docs, ci, build
+	GroupByOwner   = "owner"   // CODEOWNERS owners of the file
+)
+
+// ChangeGroup is a set of staged files committed together
+type ChangeGroup struct {
+	Name    string
+	Changes []git.FileChange
+}
+
+// GroupChanges splits changes deterministically into groups, sorted by name
+func GroupChanges(changes []git.FileChange, by string) ([]ChangeGroup, error) {
+	var key func(string) string
+	switch by {
+	case GroupByDir:
+		key = func(p string) string {
+			if i := strings.Index(p, "/"); i >= 0 {
+				return p[:i] + "/"
+			}
+			return "(root)"
+		}
+	case GroupByPackage:
+		key = func(p string) string {
+			if dir := path.Dir(p); dir != "." {
+				return dir
+			}
+			return "(root)"
+		}
+	case GroupByType:
+		key = func(p string) string {
+			if t := pathType(p); t != "" {
+				return t
+			}
+			return "code"
+		}
+	case GroupByOwner:
+		root, err := git.TopLevel()
+		if err != nil {
+			return nil, err
+		}
+		co, err := owners.Load(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+		}
+		if co == nil {
+			return nil, fmt.Errorf("--group-by %s needs a CODEOWNERS file (looked in %s)", GroupByOwner, strings.Join(owners.Locations, ", "))
+		}
+		// Files shared by several owners form their own group, so each
+		// commit maps to exactly one set of approvers
+		key = func(p string) string {
+			if o, _, _ := co.Owners(p); len(o) > 0 {
+				return strings.Join(o, " ")
+			}
+			return "(unowned)"
+		}
+	default:
+		return nil, fmt.Errorf("invalid group %q (expected %s, %s, %s or %s)", by, GroupByDir, GroupByPackage, GroupByType, GroupByOwner)
+	}
+
+	// A lockfile always goes with its manifest, wherever that is grouped
+	manifests := make(map[string]string)
+	for _, c := range changes {
+		for _, lock := range c.Lockfiles {
+			manifests[lock] = c.Path
+		}
+	}
+
+	index := make(map[string]int)
+	var groups []ChangeGroup
+	for _, c := range changes {
+		p := c.Path
+		if m, ok := manifests[p]; ok {
+			p = m
+		}
+		name := key(strings.TrimPrefix(p, "./"))
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, ChangeGroup{Name: name})
+		}
+		groups[i].Changes = append(groups[i].Changes, c)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+// GenerateGroupMessages makes a single request for one message per group,
+// keyed by group name. Groups the answer skipped are re-requested once;
+// groups still missing afterwards are absent from the map.
+func (g *GeminiClient) GenerateGroupMessages(groups []ChangeGroup, recentCommits []string) (map[string]string, error) {
+	g.confidence = nil
+	result, err := g.generateGroupMessages(groups, recentCommits)
+	if err != nil {
+		return nil, err
+	}
+	g.flag(result)
+	var changes []git.FileChange
+	for _, gr := range groups {
+		changes = append(changes, gr.Changes...)
+	}
+	g.critique(changes, result) // Without a rating, no confidence is shown
+	for _, gr := range groups {
+		if msg, ok := result[gr.Name]; ok {
+			result[gr.Name] = g.withImpact(msg, gr.Changes)
+		}
+	}
+	return result, nil
+}
+
+func (g *GeminiClient) generateGroupMessages(groups []ChangeGroup, recentCommits []string) (map[string]string, error) {
+	if g.offline() {
+		result := make(map[string]string)
+		for _, gr := range groups {
+			result[gr.Name] = g.offlineCommitMessages(gr.Changes, false)["__all__"]
+		}
+		return result, nil
+	}
+
+	request := func(groups []ChangeGroup) (map[string]string, error) {
+		var changes []git.FileChange
+		for _, gr := range groups {
+			changes = append(changes, gr.Changes...)
+		}
+		raw, err := g.callGemini(g.prompt(changes, func(c []git.FileChange, moves MovedCode) string {
+			return g.buildGroupPrompt(regroup(groups, c), moves, recentCommits)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		result := parseBlocks(raw, "GROUP:")
+		g.rateAnswer(result)
+		return result, nil
+	}
+
+	result, err := request(groups)
+	if err != nil {
+		return nil, err
+	}
+	var missing []ChangeGroup
+	for _, gr := range groups {
+		if _, ok := result[gr.Name]; !ok {
+			missing = append(missing, gr)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	retry, err := request(missing)
+	if err != nil {
+		return result, nil // Partial result; caller reports the missing groups
+	}
+	for _, gr := range missing {
+		if msg, ok := retry[gr.Name]; ok {
+			result[gr.Name] = msg
+		}
+	}
+	return result, nil
+}
+
+// regroup puts changes (e.g. compressed copies) back into the groups they
+// were flattened from, in the same order
+func regroup(groups []ChangeGroup, changes []git.FileChange) []ChangeGroup {
+	out := make([]ChangeGroup, len(groups))
+	for i, gr := range groups {
+		out[i] = ChangeGroup{Name: gr.Name, Changes: changes[:len(gr.Changes)]}
+		changes = changes[len(gr.Changes):]
+	}
+	return out
+}
+
+func (g *GeminiClient) buildGroupPrompt(groups []ChangeGroup, moves MovedCode, recentCommits []string) string {
+	var sb strings.Builder
+	g.writeCommitGuidelines(&sb, recentCommits)
+
+	sb.WriteString(fmt.Sprintf("The staged files are split into %d group(s). Generate ONE commit message per group that summarizes all of its files.\n", len(groups)))
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Each message must be concise (subject line " + g.subjectLimit() + ")\n")
+	sb.WriteString("- Add a blank line then bullet points listing key changes if the group has several files\n")
+	sb.WriteString("- Use the group name exactly as given\n")
+	sb.WriteString("- Output format must be EXACTLY:\n\n")
+	sb.WriteString("GROUP: <group name>\nMESSAGE:\n<commit message>\n---\n\n")
+	writeMovedCode(&sb, moves)
+
+	for _, gr := range groups {
+		sb.WriteString(fmt.Sprintf("GROUP: %s\n\n", gr.Name))
+		writeStagedChanges(&sb, gr.Changes)
+	}
+	return sb.String()
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// WorkInProgress is the work of a branch only this clone has, for a
+// handoff note
+type WorkInProgress struct {
+	Branch   string
+	Upstream string           // Where the branch is pushed; "" when it isn't
+	Commits  []git.CommitInfo // Local-only commits, oldest first
+	// Committed are the changes of Commits together
+	Committed []git.FileChange
+	Staged    []git.FileChange
+	Unstaged  []git.FileChange
+	Untracked []git.FileChange
+}
+
+// Empty reports whether there is nothing to hand over
+func (w WorkInProgress) Empty() bool {
+	return len(w.Commits) == 0 && len(w.Staged) == 0 && len(w.Unstaged) == 0 && len(w.Untracked) == 0
+}
+
+// GenerateHandoff writes a note for a teammate taking over the branch: what
+// the work is about, what is done, what is half done and what comes next
+func (g *GeminiClient) GenerateHandoff(w WorkInProgress) (string, error) {
+	if g.offline() {
+		return g.offlineHandoff(w), nil
+	}
+	// All the changes go through anonymization and compression at once,
+	// then are cut back into their kinds
+	sets := [][]git.FileChange{w.Committed, w.Staged, w.Unstaged, w.Untracked}
+	var all []git.FileChange
+	for _, s := range sets {
+		all = append(all, s...)
+	}
+	raw, err := g.callGemini(g.prompt(all, func(c []git.FileChange, moves MovedCode) string {
+		var split [][]git.FileChange
+		for _, s := range sets {
+			split = append(split, c[:len(s)])
+			c = c[len(s):]
+		}
+		return g.buildHandoffPrompt(w, split, moves)
+	}))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+func (g *GeminiClient) buildHandoffPrompt(w WorkInProgress, sets [][]git.FileChange, moves MovedCode) string {
+	var sb strings.Builder
+	sb.WriteString("A developer is handing a branch over to a teammate who will finish the work. Write the handoff note from the work below, which only exists in the developer's clone: ")
+	sb.WriteString("commits that were never pushed, staged and unstaged edits, and new files.\n\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Use markdown with exactly these sections: ## Summary, ## Done, ## In progress, ## Next steps, ## Watch out\n")
+	sb.WriteString("- Summary: 2-4 sentences on what the work is for and where it stands\n")
+	sb.WriteString("- Done: what the local-only commits and finished edits achieve, as bullets\n")
+	sb.WriteString("- In progress: what the uncommitted edits seem to be in the middle of, e.g. stubs, TODOs, debugging code, code that doesn't fit together yet, as bullets\n")
+	sb.WriteString("- Next steps: concrete actions to finish the work, most important first, as bullets\n")
+	sb.WriteString("- Watch out: what could surprise the teammate, e.g. unpushed commits, files that must not be committed, half-applied renames; write \"Nothing in particular.\" if there is nothing\n")
+	sb.WriteString("- Base every claim on the changes below and say when you are guessing; don't list every file, an inventory is appended to the note\n")
+	sb.WriteString("- Write in " + languageName(g.cfg.Language) + "\n")
+	sb.WriteString("- Do NOT include a top-level title\n")
+	sb.WriteString("- Output ONLY the markdown\n\n")
+
+	sb.WriteString("Branch: " + g.Anon.Hide(w.Branch))
+	if w.Upstream != "" {
+		sb.WriteString(", pushed to " + g.Anon.Hide(w.Upstream))
+	} else {
+		sb.WriteString(", never pushed")
+	}
+	sb.WriteString("\n\n")
+	if len(w.Commits) > 0 {
+		sb.WriteString("Local-only commits, oldest first:\n")
+		for _, c := range w.Commits {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", shortHash(c.Hash), g.Anon.Hide(c.Subject)))
+			if c.Body != "" {
+				sb.WriteString("    " + strings.ReplaceAll(g.Anon.Hide(c.Body), "\n", "\n    ") + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	writeMovedCode(&sb, moves)
+	for i, title := range []string{
+		"Changes of the local-only commits together",
+		"Staged changes (not committed)",
+		"Unstaged changes (not staged)",
+		"Untracked files (new, never added)",
+	} {
+		if len(sets[i]) == 0 {
+			continue
+		}
+		sb.WriteString(title + ":\n\n")
+		writeStagedChanges(&sb, sets[i])
+	}
+	return sb.String()
+}
+
+// offlineHandoff can't tell what the work is about, so it sums up its
+// parts
+func (g *GeminiClient) offlineHandoff(w WorkInProgress) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Summary\n\n%d local-only commit(s), %d staged, %d unstaged and %d untracked file(s) on %s.\n",
+		len(w.Commits), len(w.Staged), len(w.Unstaged), len(w.Untracked), w.Branch)
+	if len(w.Commits) > 0 {
+		sb.WriteString("\n## Done\n\n")
+		var subjects []string
+		for _, c := range w.Commits {
+			subjects = append(subjects, c.Subject)
+		}
+		sb.WriteString(groupCommits(subjects, "###", false) + "\n")
+	}
+	var pending []git.FileChange
+	for _, s := range [][]git.FileChange{w.Staged, w.Unstaged, w.Untracked} {
+		pending = append(pending, s...)
+	}
+	if len(pending) > 0 {
+		sb.WriteString("\n## In progress\n\n")
+		subject, _, _ := strings.Cut(g.offlineCommitMessages(pending, false)["__all__"], "\n")
+		sb.WriteString("- " + subject + "\n")
+	}
+	sb.WriteString("\n## Next steps\n\n")
+	if len(pending) > 0 {
+		sb.WriteString("- Review the uncommitted changes listed below and commit them\n")
+	}
+	if w.Upstream == "" {
+		sb.WriteString("- Push the branch, it has no upstream\n")
+	} else if len(w.Commits) > 0 {
+		sb.WriteString("- Push the local-only commits to " + w.Upstream + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	impactLineRe = regexp.MustCompile(`(?m)^Impact:\s*\S`)
+
+	// Paths where a mistake costs the most
+	riskyPathRe = regexp.MustCompile(`(?i)(auth|login|session|secur|crypt|password|secret|token|permission|acl|payment|billing|migrat|schema)`)
+	// Paths that change how everything is built, configured or deployed
+	widePathRe = regexp.MustCompile(`(?i)(^|/)(\.github|\.gitlab-ci|ci|deploy|k8s|helm|terraform|config|Dockerfile|Makefile|go\.mod|package\.json)`)
+	testPathRe = regexp.MustCompile(`(?i)(_test\.|\.test\.|\.spec\.|(^|/)tests?/)`)
+)
+
+// withImpact ends a message with an impact line when impact lines are on
+// and the model didn't write one, estimating the risk from the changes
+func (g *GeminiClient) withImpact(msg string, changes []git.FileChange) string {
+	if !g.cfg.ImpactLine || msg == "" || impactLineRe.MatchString(msg) {
+		return msg
+	}
+	return strings.TrimRight(msg, "\n") + "\n\n" + ImpactLine(changes)
+}
+
+// changesFor returns the change a granular message describes, or all of them
+func changesFor(changes []git.FileChange, key string) []git.FileChange {
+	for _, c := range changes {
+		if c.Path == key {
+			return []git.FileChange{c}
+		}
+	}
+	return changes
+}
+
+// ImpactLine rates the changes by rule: which areas they touch and how
+// risky that is, from their size and the kind of files involved
+func ImpactLine(changes []git.FileChange) string {
+	lines := make(map[string]int) // Changed lines per directory
+	total, risky, wide, tests := 0, false, false, 0
+	for _, c := range changes {
+		n := diffLines(c.Diff)
+		total += n
+		lines[path.Dir(c.Path)] += n
+		switch {
+		case testPathRe.MatchString(c.Path):
+			tests++
+		case riskyPathRe.MatchString(c.Path):
+			risky = true
+		case widePathRe.MatchString(c.Path):
+			wide = true
+		}
+	}
+
+	risk := "low"
+	switch {
+	case tests == len(changes):
+	case risky || total > 500:
+		risk = "high"
+	case wide || total > 100 || len(changes) > 5:
+		risk = "medium"
+	}
+
+	areas := make([]string, 0, len(lines))
+	for dir := range lines {
+		areas = append(areas, dir)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		if lines[areas[i]] != lines[areas[j]] {
+			return lines[areas[i]] > lines[areas[j]]
+		}
+		return areas[i] < areas[j]
+	})
+	for i, dir := range areas {
+		if dir == "." {
+			areas[i] = "the repository root"
+		}
+	}
+	what := strings.Join(areas[:min(3, len(areas))], ", ")
+	if len(areas) > 3 {
+		what += fmt.Sprintf(" and %d more", len(areas)-3)
+	}
+	return fmt.Sprintf("Impact: touches %s; %s risk", what, risk)
+}
+
+func diffLines(diff string) int {
+	n := 0
+	for _, l := range strings.Split(diff, "\n") {
+		if (strings.HasPrefix(l, "+") || strings.HasPrefix(l, "-")) && !strings.HasPrefix(l, "+++") && !strings.HasPrefix(l, "---") {
+			n++
+		}
+	}
+	return n
+}
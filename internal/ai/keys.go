@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+// keyCursor remembers where key rotation continues, shared by every client
+// in the process so long-running modes (serve, watch) keep rotating
+var keyCursor struct {
+	sync.Mutex
+	started bool
+	next    int
+}
+
+// keyOrder returns the configured keys in the order to try them.
+// Failover starts at the last key that worked; round-robin starts one past
+// the key used for the previous call (at a random key in a fresh process, so
+// short CLI runs spread across keys too).
+func keyOrder(cfg *config.Config) []string {
+	keys := cfg.APIKeys()
+	if len(keys) <= 1 {
+		return keys
+	}
+
+	keyCursor.Lock()
+	if !keyCursor.started {
+		keyCursor.started = true
+		if cfg.KeyRotation == config.RotationRoundRobin {
+			keyCursor.next = rand.Intn(len(keys))
+		}
+	}
+	start := keyCursor.next % len(keys)
+	if cfg.KeyRotation == config.RotationRoundRobin {
+		keyCursor.next = start + 1
+	}
+	keyCursor.Unlock()
+
+	return append(append([]string{}, keys[start:]...), keys[:start]...)
+}
+
+// keyWorked makes failover stick to a key that answered
+func keyWorked(cfg *config.Config, key string) {
+	if cfg.KeyRotation == config.RotationRoundRobin {
+		return
+	}
+	for i, k := range cfg.APIKeys() {
+		if k == key {
+			keyCursor.Lock()
+			keyCursor.next = i
+			keyCursor.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+const modelsPath = "/v1beta/models?pageSize=1000"
+
+// ErrKeyRejected means Gemini answered and refused the key, as opposed to
+// not being reachable at all
+var ErrKeyRejected = errors.New("key rejected")
+
+// ValidateKey checks a Gemini API key with a cheap models listing (no
+// generation, no cost) and returns the models it can generate content with.
+// The request goes through cfg's gateway and headers, if any.
+func ValidateKey(cfg *config.Config, key string) ([]string, error) {
+	req, err := newGeminiRequest(cfg, http.MethodGet, modelsPath, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach Gemini: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var listing struct {
+		Models []struct {
+			Name    string   `json:"name"`
+			Methods []string `json:"supportedGenerationMethods"`
+		} `json:"models"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(data, &listing); err != nil {
+		return nil, fmt.Errorf("unexpected response from Gemini (%s)", resp.Status)
+	}
+	if listing.Error != nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRejected, listing.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrKeyRejected, resp.Status)
+	}
+
+	var models []string
+	for _, m := range listing.Models {
+		for _, method := range m.Methods {
+			if method == "generateContent" {
+				models = append(models, strings.TrimPrefix(m.Name, "models/"))
+				break
+			}
+		}
+	}
+	return models, nil
+}
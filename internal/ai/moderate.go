@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+var (
+	profanityRe = regexp.MustCompile(`(?i)\b(fuck\w*|shit\w*|bullshit|crap|damn\w*|bitch\w*|bastard\w*|asshole\w*|wtf|piss\w*|dickhead)\b`)
+
+	// Placeholders the model was meant to fill in
+	placeholderRe = regexp.MustCompile(`(?im)^\s*(-\s*)?(TODO|TBD|FIXME)\b|` +
+		`\b(describe|summari[sz]e|explain) (the|this|your) changes?( here)?\b|` +
+		`\byour (commit )?message here\b|lorem ipsum|` +
+		`<(type|scope|description|commit message|subject|body|filepath)>|` +
+		`\[(insert|describe|add|your) [^\]]*\]`)
+
+	// Pieces of the prompts in this package
+	promptLeakRe = regexp.MustCompile(`(?m)^\s*(Rules:|Staged changes:|Now here are the diffs:|Recent commits for context:|Output format must be|MESSAGE:|DIFF:)|` +
+		`Output ONLY the commit message|You are an expert developer|\(status: [A-Z]\d*\)|` +
+		`Subject line: max \d+|Use Conventional Commits format`)
+)
+
+// Moderate checks a generated message for text that must never end up in
+// a commit: profanity, placeholders left unfilled and parts of the prompt
+// echoed back. It returns what is wrong, or nil.
+func Moderate(msg string) []string {
+	var problems []string
+	if m := profanityRe.FindString(msg); m != "" {
+		problems = append(problems, "profanity ("+m+")")
+	}
+	if m := placeholderRe.FindString(msg); m != "" {
+		problems = append(problems, "placeholder text ("+strings.TrimSpace(m)+")")
+	}
+	if m := promptLeakRe.FindString(msg); m != "" {
+		problems = append(problems, "prompt instructions echoed back ("+strings.TrimSpace(m)+")")
+	}
+	return problems
+}
+
+// Flagged returns what is still wrong with the messages of the last
+// GenerateCommitMessages or GenerateGroupMessages call, by the same keys,
+// after asking the model once more for the ones that failed Moderate
+func (g *GeminiClient) Flagged() map[string][]string {
+	return g.flagged
+}
+
+// moderate asks again, once, for the messages that fail Moderate, keeping
+// a new message only when it is clean. What is still wrong is recorded for
+// Flagged.
+func (g *GeminiClient) moderate(changes []git.FileChange, granular bool, recentCommits []string, result map[string]string) {
+	g.flagged = nil
+	keys := make([]string, 0, len(result))
+	for k := range result {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		problems := Moderate(result[key])
+		if len(problems) == 0 {
+			continue
+		}
+		subset := changesFor(changes, key)
+		prompt := g.prompt(subset, func(c []git.FileChange, moves MovedCode) string {
+			return g.buildCommitPrompt(c, moves, key != "__all__", recentCommits) +
+				"\nA previous answer was rejected for: " + strings.Join(problems, "; ") + ". Write the message again without that.\n"
+		})
+		if raw, err := g.callGemini(prompt); err == nil {
+			if msg, ok := g.parseCommitResponse(raw, subset, key != "__all__")[key]; ok && len(Moderate(msg)) == 0 {
+				result[key] = msg
+				continue
+			}
+		}
+		if g.flagged == nil {
+			g.flagged = make(map[string][]string)
+		}
+		g.flagged[key] = problems
+	}
+}
+
+// flag records the messages that fail Moderate, without asking again
+func (g *GeminiClient) flag(result map[string]string) {
+	g.flagged = nil
+	for k, msg := range result {
+		if problems := Moderate(msg); len(problems) > 0 {
+			if g.flagged == nil {
+				g.flagged = make(map[string][]string)
+			}
+			g.flagged[k] = problems
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Move is code removed in one place and added unchanged (ignoring
+// indentation) in another, possibly within the same file
+type Move struct {
+	From, To string
+	Lines    int
+	Symbols  []string // Declarations found in the moved code
+}
+
+// MovedCode is what DetectMoves found in a set of changes
+type MovedCode struct {
+	Moves     []Move
+	OnlyMoves []string // Files whose changes are all moved code
+}
+
+// minMovedChars keeps runs of short lines like "}" or "end" from counting as a move
+const minMovedChars = 30
+
+var declNameRe = regexp.MustCompile(`^(?:export\s+)?(?:pub\s+)?(?:async\s+)?(?:func|def|class|type|fn|function|interface|struct)\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)
+
+// DetectMoves matches runs of at least minMovedLines removed lines against
+// identical runs of added lines, the way git's --color-moved does, so pure
+// relocations can be told apart from real edits
+func DetectMoves(changes []git.FileChange) MovedCode {
+	files := make([]*fileDiff, len(changes))
+	for i, c := range changes {
+		files[i] = parseFileDiff(c.Path, c.Diff)
+	}
+	removed, added := blocks(files, "-"), blocks(files, "+")
+
+	type lineRef struct{ block, line int }
+	index := make(map[string][]lineRef)
+	removedLines := make([][]string, len(removed))
+	used := make([][]bool, len(removed))
+	for bi, b := range removed {
+		removedLines[bi] = strings.Split(b.key, "\n")
+		used[bi] = make([]bool, len(removedLines[bi]))
+		for li, l := range removedLines[bi] {
+			if l != "" {
+				index[l] = append(index[l], lineRef{bi, li})
+			}
+		}
+	}
+
+	var mc MovedCode
+	byPair := make(map[[2]string]int) // from, to -> index in mc.Moves
+	moved := make(map[*fileDiff]int)  // non-blank moved lines per file
+	for _, a := range added {
+		lines := strings.Split(a.key, "\n")
+		for i := 0; i < len(lines); {
+			best, bestN := lineRef{}, 0
+			for _, ref := range index[lines[i]] {
+				n := 0
+				for i+n < len(lines) && ref.line+n < len(removedLines[ref.block]) &&
+					!used[ref.block][ref.line+n] && lines[i+n] == removedLines[ref.block][ref.line+n] {
+					n++
+				}
+				if n > bestN {
+					best, bestN = ref, n
+				}
+			}
+			run := lines[i : i+max(bestN, 1)]
+			if bestN == 0 || nonBlank(run) < minMovedLines || len(strings.Join(run, "")) < minMovedChars {
+				i++
+				continue
+			}
+
+			from := removed[best.block].file
+			for k := 0; k < bestN; k++ {
+				used[best.block][best.line+k] = true
+			}
+			moved[from] += nonBlank(run)
+			moved[a.file] += nonBlank(run)
+
+			pair := [2]string{from.path, a.file.path}
+			mi, ok := byPair[pair]
+			if !ok {
+				mi = len(mc.Moves)
+				byPair[pair] = mi
+				mc.Moves = append(mc.Moves, Move{From: from.path, To: a.file.path})
+			}
+			m := &mc.Moves[mi]
+			m.Lines += bestN
+			for _, l := range run {
+				if match := declNameRe.FindStringSubmatch(l); match != nil && !contains(m.Symbols, match[1]) {
+					m.Symbols = append(m.Symbols, match[1])
+				}
+			}
+			i += bestN
+		}
+	}
+
+	// A file only moves code when every changed line was matched; a move
+	// within one file counts its lines on both sides
+	for _, f := range files {
+		if moved[f] > 0 && moved[f] >= changedLines(f) {
+			mc.OnlyMoves = append(mc.OnlyMoves, f.path)
+		}
+	}
+	return mc
+}
+
+func nonBlank(lines []string) int {
+	n := 0
+	for _, l := range lines {
+		if l != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// changedLines counts the non-blank added and removed lines of a file
+func changedLines(f *fileDiff) int {
+	n := 0
+	for _, h := range f.hunks {
+		for _, l := range h.lines {
+			if isChange(l) && strings.TrimSpace(l[1:]) != "" {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// What names the moved code: its declarations, or its size without any
+func (m Move) What() string {
+	switch {
+	case len(m.Symbols) == 0:
+		return fmt.Sprintf("%d lines", m.Lines)
+	case len(m.Symbols) > 3:
+		return strings.Join(m.Symbols[:3], ", ") + fmt.Sprintf(" and %d more", len(m.Symbols)-3)
+	default:
+		return strings.Join(m.Symbols, ", ")
+	}
+}
+
+// writeMovedCode tells the model which changes are relocations so it
+// describes them as moves rather than as added and deleted code
+func writeMovedCode(sb *strings.Builder, mc MovedCode) {
+	if len(mc.Moves) == 0 {
+		return
+	}
+	sb.WriteString("Moved code (removed lines found again unchanged elsewhere; this code was relocated, not written or deleted):\n")
+	for _, m := range mc.Moves {
+		if m.From == m.To {
+			sb.WriteString(fmt.Sprintf("- %d lines moved within %s: %s\n", m.Lines, m.From, m.What()))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %d lines moved from %s to %s: %s\n", m.Lines, m.From, m.To, m.What()))
+		}
+	}
+	for _, p := range mc.OnlyMoves {
+		sb.WriteString(fmt.Sprintf("- %s has no changes besides moved code\n", p))
+	}
+	sb.WriteString("Describe relocated code as a move (e.g. \"move parseConfig to config.go\"), never as adding it in one place and removing it in another. Mention real edits separately.\n\n")
+}
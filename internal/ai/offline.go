@@ -0,0 +1,453 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/deps"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/history"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/report"
+)
+
+// The offline provider answers with simple rules derived from file paths,
+// statuses and diffs instead of calling a model. It needs no API key or
+// network and is used by the demo and as a fallback without a key.
+// Messages are always in English.
+
+var (
+	fixWordsRe = regexp.MustCompile(`(?i)\b(fix(es|ed)?|bug|typo|crash|panic|nil check|off[- ]by[- ]one)\b`)
+	newDeclRe  = regexp.MustCompile(`(?m)^\+\s*(func|def|class|export (function|class|const)|public|fn)\s`)
+)
+
+// offline reports whether the rule-based provider is configured
+func (g *GeminiClient) offline() bool {
+	return g.cfg.Provider == config.ProviderOffline
+}
+
+// offlineCommitMessages mirrors GenerateCommitMessages
+func (g *GeminiClient) offlineCommitMessages(changes []git.FileChange, granular bool) map[string]string {
+	result := make(map[string]string)
+	moves := DetectMoves(changes)
+	if granular {
+		for _, c := range changes {
+			typ, scope, desc := classify(c)
+			if d, ok := moveDesc(moves, c.Path); ok {
+				typ, desc = "refactor", d
+			}
+			result[c.Path] = g.formatOffline(typ, scope, desc, "")
+		}
+		return result
+	}
+
+	// Nothing but relocated code: say where it went
+	if len(moves.Moves) > 0 && len(moves.OnlyMoves) == len(changes) {
+		m := moves.Moves[0]
+		desc := fmt.Sprintf("move %s to %s", m.What(), path.Base(m.To))
+		if m.From == m.To {
+			desc = fmt.Sprintf("reorder %s in %s", m.What(), path.Base(m.From))
+		}
+		var bullets []string
+		for _, m := range moves.Moves[1:] {
+			bullets = append(bullets, fmt.Sprintf("- move %s from %s to %s", m.What(), path.Base(m.From), path.Base(m.To)))
+		}
+		result["__all__"] = g.formatOffline("refactor", "", desc, strings.Join(bullets, "\n"))
+		return result
+	}
+
+	if len(changes) == 1 {
+		typ, scope, desc := classify(changes[0])
+		result["__all__"] = g.formatOffline(typ, scope, desc, "")
+		return result
+	}
+
+	// Summarize: dominant type, shared scope, one bullet per file
+	counts := make(map[string]int)
+	scopes := make(map[string]bool)
+	types := make([]string, len(changes))
+	descs := make([]string, len(changes))
+	var bullets []string
+	for i, c := range changes {
+		typ, scope, desc := classify(c)
+		counts[typ]++
+		scopes[scope] = true
+		types[i], descs[i] = typ, desc
+		bullets = append(bullets, "- "+desc)
+	}
+	typ := dominantType(counts)
+	lead := descs[0]
+	for i := range changes {
+		if types[i] == typ {
+			lead = descs[i]
+			break
+		}
+	}
+	scope := ""
+	if len(scopes) == 1 {
+		for s := range scopes {
+			scope = s
+		}
+	}
+	desc := fmt.Sprintf("%s and %d more change(s)", lead, len(changes)-1)
+	result["__all__"] = g.formatOffline(typ, scope, desc, strings.Join(bullets, "\n"))
+	return result
+}
+
+// offlineFromIntent turns the author's description into a message
+func (g *GeminiClient) offlineFromIntent(intent string, changes []git.FileChange) string {
+	intent = strings.TrimSpace(intent)
+	if typ, _, ok := policy.ParseHeader(intent); ok && typ != "" {
+		return intent // Already formatted
+	}
+	counts := make(map[string]int)
+	for _, c := range changes {
+		typ, _, _ := classify(c)
+		counts[typ]++
+	}
+	subject, body, _ := strings.Cut(intent, "\n")
+	subject = strings.TrimSuffix(strings.TrimSpace(subject), ".")
+	if subject != "" {
+		subject = strings.ToLower(subject[:1]) + subject[1:]
+	}
+	return g.formatOffline(dominantType(counts), "", subject, strings.TrimSpace(body))
+}
+
+func (g *GeminiClient) formatOffline(typ, scope, desc, body string) string {
+	var subject string
+	if g.cfg.CommitStyle == "simple" {
+		subject = strings.ToUpper(desc[:1]) + desc[1:]
+	} else if scope != "" {
+		subject = fmt.Sprintf("%s(%s): %s", typ, scope, desc)
+	} else {
+		subject = fmt.Sprintf("%s: %s", typ, desc)
+	}
+	if body == "" {
+		return subject
+	}
+	return subject + "\n\n" + body
+}
+
+// classify derives a Conventional Commits type, scope and description for one file
+func classify(c git.FileChange) (typ, scope, desc string) {
+	p := strings.TrimPrefix(c.Path, "./")
+	base := path.Base(p)
+	if dir := path.Dir(p); dir != "." {
+		scope = strings.Split(dir, "/")[0]
+		switch scope {
+		case ".github", "docs", "test", "tests":
+			scope = "" // Already said by the type
+		}
+	}
+
+	typ = pathType(p)
+	if d := deps.FromDiff(p, c.Diff); !d.Empty() && c.Status == "M" {
+		return "build", "deps", d.Headline()
+	}
+	switch c.Status {
+	case "A":
+		desc = "add " + base
+		if typ == "" {
+			typ = "feat"
+		}
+	case "D":
+		desc = "remove " + base
+		if typ == "" {
+			typ = "refactor"
+		}
+	case "R":
+		desc = "rename " + base
+		if typ == "" {
+			typ = "refactor"
+		}
+	default:
+		desc = "update " + base
+		if c.ModeOnly() {
+			desc = "change permissions of " + base
+			typ = "chore"
+		}
+		if typ == "" {
+			added := addedLines(c.Diff)
+			switch {
+			case fixWordsRe.MatchString(added):
+				typ = "fix"
+			case newDeclRe.MatchString(c.Diff):
+				typ, desc = "feat", "extend "+base
+			default:
+				typ = "refactor"
+			}
+		}
+	}
+	return typ, scope, desc
+}
+
+// moveDesc describes a file whose changes are all moved code
+func moveDesc(moves MovedCode, file string) (string, bool) {
+	if !contains(moves.OnlyMoves, file) {
+		return "", false
+	}
+	for _, m := range moves.Moves {
+		switch file {
+		case m.From:
+			if m.To == file {
+				return fmt.Sprintf("reorder %s in %s", m.What(), path.Base(file)), true
+			}
+			return fmt.Sprintf("move %s to %s", m.What(), path.Base(m.To)), true
+		case m.To:
+			return fmt.Sprintf("move %s from %s", m.What(), path.Base(m.From)), true
+		}
+	}
+	return "", false
+}
+
+// pathType is the Conventional Commits type implied by a path alone (test,
+// docs, ci, build), or "" for code
+func pathType(p string) string {
+	base := path.Base(p)
+	lower := strings.ToLower(p)
+	switch {
+	case strings.Contains(lower, "_test.") || strings.Contains(lower, ".test.") || strings.Contains(lower, ".spec.") ||
+		strings.HasPrefix(lower, "test/") || strings.HasPrefix(lower, "tests/"):
+		return "test"
+	case strings.HasSuffix(lower, ".md") || strings.HasPrefix(lower, "docs/") || base == "LICENSE":
+		return "docs"
+	case strings.HasPrefix(lower, ".github/") || base == ".gitlab-ci.yml" || base == "Jenkinsfile":
+		return "ci"
+	case base == "go.mod" || base == "go.sum" || base == "package.json" || base == "package-lock.json" ||
+		base == "Makefile" || base == "Dockerfile" || strings.HasSuffix(base, ".lock"):
+		return "build"
+	}
+	return ""
+}
+
+func addedLines(diff string) string {
+	var sb strings.Builder
+	for _, l := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "+++") {
+			sb.WriteString(l[1:] + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// typeRank orders types for the summary of mixed changes
+var typeRank = []string{"feat", "fix", "refactor", "build", "ci", "test", "docs", "chore"}
+
+func dominantType(counts map[string]int) string {
+	best, bestN := "chore", 0
+	for _, t := range typeRank {
+		if counts[t] > bestN {
+			best, bestN = t, counts[t]
+		}
+	}
+	return best
+}
+
+// offlineSections are the release note sections, matching the prompts
+var offlineSections = []struct {
+	emoji, title string
+	types        []string
+}{
+	{"🚀", "Features", []string{"feat"}},
+	{"🐛", "Bug Fixes", []string{"fix"}},
+	{"🔧", "Improvements", nil}, // Everything else
+	{"📚", "Docs", []string{"docs"}},
+}
+
+// groupCommits groups "hash subject" commits by type into markdown sections
+// with the given heading prefix ("##" or "###")
+func groupCommits(commits []string, heading string, emoji bool) string {
+	grouped := make(map[string][]string)
+	for _, c := range commits {
+		subject := commitSubject(c)
+		typ, _, _ := policy.ParseHeader(subject)
+		title := "Improvements"
+		for _, s := range offlineSections {
+			if contains(s.types, typ) {
+				title = s.title
+			}
+		}
+		grouped[title] = append(grouped[title], "- "+subject)
+	}
+
+	var sb strings.Builder
+	for _, s := range offlineSections {
+		if len(grouped[s.title]) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		title := s.title
+		if emoji {
+			title = s.emoji + " " + title
+		}
+		sb.WriteString(heading + " " + title + "\n\n")
+		sb.WriteString(strings.Join(grouped[s.title], "\n") + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func (g *GeminiClient) offlineReleaseNotes(commits []string, currentTag, newTag string) string {
+	summary := fmt.Sprintf("%s brings %d change(s)", newTag, len(commits))
+	if currentTag != "" {
+		summary += " since " + currentTag
+	}
+	return summary + ".\n\n" + groupCommits(commits, "##", true)
+}
+
+// withoutShipped leaves out the commits whose subject the previous
+// release's notes already list
+func withoutShipped(commits []string, previous string) []string {
+	if previous == "" {
+		return commits
+	}
+	var rest []string
+	for _, c := range commits {
+		if !strings.Contains(previous, "- "+commitSubject(c)) {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+func (g *GeminiClient) offlineChangelog(commits []string) string {
+	return groupCommits(commits, "###", false)
+}
+
+// offlineAnswer can't interpret the question, so it returns the best
+// search matches
+func (g *GeminiClient) offlineAnswer(candidates []history.Candidate) (string, []string) {
+	if len(candidates) == 0 {
+		return "No commits mention these terms.", nil
+	}
+	var cited []string
+	for _, c := range candidates[:min(5, len(candidates))] {
+		cited = append(cited, shortHash(c.Commit.Hash))
+	}
+	return "These commits match the question's terms best (the offline provider ranks by matches only).", cited
+}
+
+// offlineNote lists what the commit touched, as there is no model to explain it
+func (g *GeminiClient) offlineNote(c git.CommitInfo, diff string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Files changed by %s:\n", shortHash(c.Hash))
+	for _, ch := range git.ParseDiff(diff) {
+		added, removed := 0, 0
+		for _, l := range strings.Split(ch.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "+++"):
+				added++
+			case strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "---"):
+				removed++
+			}
+		}
+		fmt.Fprintf(&sb, "- %s (+%d -%d)\n", ch.Path, added, removed)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// offlineSquash summarizes the branch's changes in the subject and lists
+// its commits in the body
+func (g *GeminiClient) offlineSquash(commits []git.CommitInfo, changes []git.FileChange) string {
+	subject := ""
+	if len(changes) > 0 {
+		subject, _, _ = strings.Cut(g.offlineCommitMessages(changes, false)["__all__"], "\n")
+	} else if len(commits) > 0 {
+		subject = commits[0].Subject
+	}
+	var bullets []string
+	for _, c := range commits {
+		bullets = append(bullets, "- "+c.Subject)
+	}
+	return strings.TrimSpace(subject + "\n\n" + strings.Join(bullets, "\n"))
+}
+
+// offlineReport summarizes the figures and groups the commits by type
+func (g *GeminiClient) offlineReport(a *report.Activity) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Summary\n\n%d commit(s) by %d contributor(s) %s.", a.Commits, len(a.Authors), a.Period())
+	if len(a.Dirs) > 0 {
+		fmt.Fprintf(&sb, " Most active area: `%s` (%d commit(s)).", a.Dirs[0].Path, a.Dirs[0].Commits)
+	}
+	if risky := a.Risky(1); len(risky) > 0 {
+		fmt.Fprintf(&sb, " Riskiest file by churn and authors: `%s`.", risky[0].Path)
+	}
+	sb.WriteString("\n\n## Themes\n\n")
+	sb.WriteString(groupCommits(a.Subjects, "###", false))
+	return sb.String()
+}
+
+func (g *GeminiClient) offlineMigrationGuide(breaking []git.CommitInfo, currentTag, newTag string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Migrating from %s to %s\n", ifEmptyStr(currentTag, "the previous version"), newTag)
+	for _, c := range breaking {
+		fmt.Fprintf(&sb, "\n### %s\n\n", c.Subject)
+		if c.Body != "" {
+			sb.WriteString(c.Body + "\n")
+		} else {
+			fmt.Fprintf(&sb, "See commit %s for details.\n", shortHash(c.Hash))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+var versionRe = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?|\d+$`)
+
+// offlineVersion applies the semver rules to Conventional Commits subjects
+func (g *GeminiClient) offlineVersion(commits []string, currentTag string) string {
+	var maj, min, pat int
+	if currentTag == "" {
+		return "0.1.0"
+	}
+	// The version may follow any prefix: v1.2.3, app-v1.2.3
+	fmt.Sscanf(versionRe.FindString(currentTag), "%d.%d.%d", &maj, &min, &pat)
+
+	bump := 0 // 0 patch, 1 minor, 2 major
+	for _, c := range commits {
+		subject := commitSubject(c)
+		typ, _, _ := policy.ParseHeader(subject)
+		switch {
+		case git.IsBreaking(subject, ""):
+			bump = 2
+		case typ == "feat" && bump < 1:
+			bump = 1
+		}
+	}
+	switch bump {
+	case 2:
+		return fmt.Sprintf("%d.0.0", maj+1)
+	case 1:
+		return fmt.Sprintf("%d.%d.0", maj, min+1)
+	}
+	return fmt.Sprintf("%d.%d.%d", maj, min, pat+1)
+}
+
+// commitSubject strips the abbreviated hash from a "git log --oneline" line
+func commitSubject(line string) string {
+	if hash, subject, ok := strings.Cut(line, " "); ok && isHex(hash) {
+		return subject
+	}
+	return line
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return len(s) >= 4
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
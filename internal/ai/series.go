@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// seriesDiffLimit bounds the diff sent for each patch of a series
+const seriesDiffLimit = 8000
+
+// Series is the text a mailing-list patch series carries besides the
+// commits: the cover letter and a note on each patch
+type Series struct {
+	Subject string   // Cover letter subject, without the [PATCH 0/N] prefix
+	Blurb   string   // Cover letter body
+	Notes   []string // One per patch, in order; "" for none
+}
+
+// GenerateSeries writes the cover letter of a patch series and the notes
+// that go below the --- line of each patch (context for reviewers that
+// doesn't belong in the commit message). diffs holds each commit's patch.
+func (g *GeminiClient) GenerateSeries(commits []git.CommitInfo, diffs []string) (Series, error) {
+	if g.offline() {
+		return g.offlineSeries(commits, diffs), nil
+	}
+	if g.Anon != nil {
+		anon := make([]git.CommitInfo, len(commits))
+		for i, c := range commits {
+			c.Subject, c.Body = g.Anon.Hide(c.Subject), g.Anon.Hide(c.Body)
+			anon[i] = c
+		}
+		commits = anon
+		hidden := make([]string, len(diffs))
+		for i, d := range diffs {
+			hidden[i] = g.Anon.Diff(d)
+		}
+		diffs = hidden
+	}
+
+	raw, err := g.callGemini(buildSeriesPrompt(commits, diffs, g.cfg.Language))
+	if err != nil {
+		return Series{}, err
+	}
+	return parseSeries(raw, len(commits)), nil
+}
+
+func buildSeriesPrompt(commits []git.CommitInfo, diffs []string, lang string) string {
+	var sb strings.Builder
+	sb.WriteString("You are the author of a patch series about to be sent to a mailing list with git send-email, kernel style.\n\n")
+	sb.WriteString(fmt.Sprintf("Write the cover letter ([PATCH 0/%d]) and a short note for each patch.\n", len(commits)))
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Write in " + languageName(lang) + ", plain text wrapped at 72 columns, no markdown\n")
+	sb.WriteString("- Cover letter subject: one line under 60 chars, no [PATCH] prefix, no trailing period\n")
+	sb.WriteString("- Cover letter body: what the series does and why, how it is split up, and what reviewers should look at; don't repeat every commit message\n")
+	sb.WriteString("- Patch notes: only what helps reviewing that patch but doesn't belong in its commit message (testing done, alternatives considered, dependencies on earlier patches); leave a note empty when there is nothing to add\n")
+	sb.WriteString("- Only describe what the commits and diffs support\n")
+	sb.WriteString("- Output format must be EXACTLY (PATCH 0 is the cover letter, subject on its first line):\n\n")
+	sb.WriteString("PATCH: 0\nMESSAGE:\n<subject>\n\n<body>\n---\nPATCH: 1\nMESSAGE:\n<note>\n---\n\n")
+
+	for i, c := range commits {
+		sb.WriteString(fmt.Sprintf("PATCH %d/%d: %s %s\n", i+1, len(commits), shortHash(c.Hash), c.Subject))
+		if c.Body != "" {
+			sb.WriteString(c.Body + "\n")
+		}
+		diff := diffs[i]
+		if len(diff) > seriesDiffLimit {
+			diff = diff[:seriesDiffLimit] + "\n... (truncated)"
+		}
+		sb.WriteString("DIFF:\n```\n" + diff + "\n```\n\n")
+	}
+	return sb.String()
+}
+
+// parseSeries reads the PATCH blocks of a GenerateSeries response
+func parseSeries(raw string, n int) Series {
+	blocks := parseBlocks(raw, "PATCH:")
+	var s Series
+	cover := blocks["0"]
+	s.Subject, s.Blurb, _ = strings.Cut(cover, "\n")
+	s.Subject, s.Blurb = strings.TrimSpace(s.Subject), strings.TrimSpace(s.Blurb)
+	s.Notes = make([]string, n)
+	for key, text := range blocks {
+		if i, err := strconv.Atoi(key); err == nil && i >= 1 && i <= n {
+			s.Notes[i-1] = text
+		}
+	}
+	return s
+}
+
+// offlineSeries lists the patches in the cover letter and the files each
+// one touches in its note
+func (g *GeminiClient) offlineSeries(commits []git.CommitInfo, diffs []string) Series {
+	s := Series{Notes: make([]string, len(commits))}
+	var changes []git.FileChange
+	for i := range commits {
+		files := git.ParseDiff(diffs[i])
+		changes = append(changes, files...)
+		var paths []string
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		if len(paths) > 0 {
+			s.Notes[i] = "Touches " + strings.Join(paths, ", ") + "."
+		}
+	}
+
+	draft := g.offlineSquash(commits, changes)
+	s.Subject, s.Blurb, _ = strings.Cut(draft, "\n")
+	s.Blurb = "This series:\n\n" + strings.TrimSpace(s.Blurb)
+	return s
+}
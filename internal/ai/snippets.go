@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Snippet is a few lines a commit added that show how to use it: a
+// documented example, a new CLI flag or a new config key
+type Snippet struct {
+	Commit   string // "<hash> <subject>", as in the release's commit list
+	File     string
+	Language string // Of the code fence, going by the file or the docs' fence
+	Code     string
+	Kind     string // usage, flag or config
+}
+
+// Tokens estimates what the snippet adds to a prompt
+func (s Snippet) Tokens() int {
+	return (len(s.Commit) + len(s.File) + len(s.Code) + 3) / 4 // About 4 bytes per token
+}
+
+const (
+	// DefaultSnippetTokens is the snippet budget of a release prompt
+	DefaultSnippetTokens = 800
+	// maxSnippetLines keeps each snippet short
+	maxSnippetLines = 8
+	// maxCommitSnippets keeps one commit from taking the whole budget
+	maxCommitSnippets = 2
+)
+
+var (
+	snippetFlagRe   = regexp.MustCompile(`Flags\(\)\.\w+\(|\bflag\.\w+\(\s*"|add_argument\(\s*["']-|\.option\(\s*["']-|#\[arg\(`)
+	snippetConfigRe = regexp.MustCompile("(?:json|yaml|toml|mapstructure):\"[a-z0-9_]+")
+)
+
+// fenceLanguages maps file extensions to code fence languages
+var fenceLanguages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".ts": "typescript",
+	".rs": "rust", ".rb": "ruby", ".java": "java", ".kt": "kotlin",
+	".cs": "csharp", ".php": "php", ".sh": "bash", ".json": "json",
+	".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".ini": "ini",
+}
+
+// DiffSnippets picks the snippets of one commit from its diff. Examples
+// added to markdown docs come first: they show usage, where a flag or
+// struct tag only shows a definition.
+func DiffSnippets(commit, diff string) []Snippet {
+	var usage, defs []Snippet
+	var file, fence string
+	inFence := false
+	var run []string // Consecutive added lines
+
+	flush := func() {
+		if len(run) > 0 {
+			if s, ok := classifyRun(commit, file, fence, inFence, run); ok {
+				if s.Kind == "usage" {
+					usage = append(usage, s)
+				} else {
+					defs = append(defs, s)
+				}
+			}
+		}
+		run = nil
+	}
+	isDoc := func() bool { return strings.HasSuffix(file, ".md") }
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			flush()
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			inFence, fence = false, ""
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "diff --git"):
+			flush()
+		case strings.HasPrefix(line, "@@"):
+			// A hunk may start inside a code block; it's treated as prose
+			flush()
+			inFence, fence = false, ""
+		case strings.HasPrefix(line, "+"):
+			text := line[1:]
+			if isDoc() && strings.HasPrefix(strings.TrimSpace(text), "```") {
+				flush()
+				inFence, fence = toggleFence(inFence, text)
+				continue
+			}
+			run = append(run, text)
+		case strings.HasPrefix(line, "-"):
+			flush()
+		default: // Context, which the new file has too
+			flush()
+			text := strings.TrimPrefix(line, " ")
+			if isDoc() && strings.HasPrefix(strings.TrimSpace(text), "```") {
+				inFence, fence = toggleFence(inFence, text)
+			}
+		}
+	}
+	flush()
+
+	snippets := append(usage, defs...)
+	return snippets[:min(len(snippets), maxCommitSnippets)]
+}
+
+func toggleFence(inFence bool, line string) (bool, string) {
+	if inFence {
+		return false, ""
+	}
+	return true, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "```"))
+}
+
+// classifyRun turns a run of added lines into a snippet, if it shows usage
+func classifyRun(commit, file, fence string, inFence bool, run []string) (Snippet, bool) {
+	s := Snippet{Commit: commit, File: file}
+	match := -1
+	if strings.HasSuffix(file, ".md") {
+		if !inFence {
+			return s, false
+		}
+		s.Kind, s.Language, match = "usage", fence, 0
+	} else {
+		for i, l := range run {
+			if snippetFlagRe.MatchString(l) {
+				s.Kind, match = "flag", i
+				break
+			}
+			if snippetConfigRe.MatchString(l) {
+				s.Kind, match = "config", i
+				break
+			}
+		}
+		if match < 0 {
+			return s, false
+		}
+		s.Language = fenceLanguages[path.Ext(file)]
+	}
+
+	// The matching line and what follows it at its depth, e.g. a flag's
+	// registration and its help text, not the closing brace
+	lines := run[match:min(len(run), match+maxSnippetLines)]
+	if s.Kind != "usage" {
+		depth := indentOf(lines[0])
+		for i, l := range lines {
+			if strings.TrimSpace(l) != "" && indentOf(l) < depth {
+				lines = lines[:i]
+				break
+			}
+		}
+	}
+	s.Code = strings.TrimRight(dedent(lines), "\n")
+	return s, strings.TrimSpace(s.Code) != ""
+}
+
+// dedent removes the indentation the lines share
+func dedent(lines []string) string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := indentOf(l)
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	var sb strings.Builder
+	for _, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		sb.WriteString(l + "\n")
+	}
+	return sb.String()
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// FitSnippets keeps the snippets, in order, that fit in budget tokens. One
+// that doesn't fit is left out rather than cut.
+func FitSnippets(snippets []Snippet, budget int) []Snippet {
+	var kept []Snippet
+	used := 0
+	for _, s := range snippets {
+		if used+s.Tokens() > budget {
+			continue
+		}
+		used += s.Tokens()
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// writeSnippets adds the snippets section of a release prompt
+func writeSnippets(sb *strings.Builder, snippets []Snippet) {
+	if len(snippets) == 0 {
+		return
+	}
+	sb.WriteString("\nSnippets the commits added (documented usage, new CLI flags, new config keys):\n")
+	for _, s := range snippets {
+		sb.WriteString(fmt.Sprintf("\n%s — %s (%s):\n```%s\n%s\n```\n", s.Commit, s.File, s.Kind, s.Language, s.Code))
+	}
+	sb.WriteString("Where a snippet shows how to use a change, add a short usage example under its item, in a fenced code block with the language given. Base examples only on these snippets: don't invent flags, keys or values, and skip snippets that only show internals.\n")
+}
+
+// offlineSnippets lists the snippets under the commits that added them
+func offlineSnippets(snippets []Snippet) string {
+	if len(snippets) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n## 🧪 Examples\n")
+	last := ""
+	for _, s := range snippets {
+		if s.Commit != last {
+			sb.WriteString("\n" + commitSubject(s.Commit) + ":\n")
+			last = s.Commit
+		}
+		sb.WriteString(fmt.Sprintf("\n```%s\n%s\n```\n", s.Language, s.Code))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,234 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// maxTestFuncs caps the functions test cases are suggested for
+const maxTestFuncs = 20
+
+// funcDefRes find function definitions in added lines, by file extension;
+// the last group is the name
+var funcDefRes = map[string]*regexp.Regexp{
+	".go":   regexp.MustCompile(`^func (?:\([^)]*\) )?([A-Za-z_]\w*)\s*[\[(]`),
+	".py":   regexp.MustCompile(`^\s*(?:async )?def ([A-Za-z_]\w*)\s*\(`),
+	".rb":   regexp.MustCompile(`^\s*def (?:self\.)?([A-Za-z_]\w*[?!]?)`),
+	".rs":   regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))? )?(?:async )?fn ([A-Za-z_]\w*)`),
+	".js":   jsFuncRe,
+	".jsx":  jsFuncRe,
+	".ts":   jsFuncRe,
+	".tsx":  jsFuncRe,
+	".mjs":  jsFuncRe,
+	".java": jvmFuncRe,
+	".kt":   regexp.MustCompile(`^\s*(?:(?:public|private|internal|protected|override|suspend)\s+)*fun (?:<[^>]*> )?(?:\w+\.)?([A-Za-z_]\w*)\s*\(`),
+	".cs":   jvmFuncRe,
+}
+
+var (
+	jsFuncRe  = regexp.MustCompile(`^\s*(?:export )?(?:default )?(?:async )?(?:function\*? ([A-Za-z_$][\w$]*)|(?:const|let) ([A-Za-z_$][\w$]*) = (?:async )?(?:\([^)]*\)|[A-Za-z_$][\w$]*) =>)`)
+	jvmFuncRe = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|async|override|virtual|synchronized)\s+)+[\w<>\[\],\s]+?\s([A-Za-z_]\w*)\s*\([^;]*$`)
+)
+
+// NewFunction is a function added by the changes
+type NewFunction struct {
+	Path string
+	Name string
+	Body []string // Its added lines
+}
+
+// UntestedFunctions returns the functions added outside test files that no
+// test mentions, neither in the changes nor in the repository
+func UntestedFunctions(changes []git.FileChange) []NewFunction {
+	var testDiffs []string
+	for _, c := range changes {
+		if testPathRe.MatchString(c.Path) {
+			testDiffs = append(testDiffs, c.Diff)
+		}
+	}
+	var untested []NewFunction
+	for _, c := range changes {
+		if testPathRe.MatchString(c.Path) || strings.HasPrefix(c.Status, "D") {
+			continue
+		}
+		for _, fn := range newFunctions(c) {
+			if !mentioned(fn.Name, testDiffs) && !testedInRepo(fn.Name) {
+				untested = append(untested, fn)
+			}
+			if len(untested) == maxTestFuncs {
+				return untested
+			}
+		}
+	}
+	return untested
+}
+
+// newFunctions finds the functions a file's diff adds
+func newFunctions(c git.FileChange) []NewFunction {
+	re := funcDefRes[strings.ToLower(filepath.Ext(c.Path))]
+	if re == nil {
+		return nil
+	}
+	var funcs []NewFunction
+	inFunc := false // Collecting the added lines of the last function
+	for _, line := range strings.Split(c.Diff, "\n") {
+		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+			inFunc = false
+			continue
+		}
+		if m := re.FindStringSubmatch(line[1:]); m != nil {
+			name := m[len(m)-1]
+			if name == "" {
+				name = m[1] // jsFuncRe's function declarations
+			}
+			inFunc = !skipFunction(name)
+			if inFunc {
+				funcs = append(funcs, NewFunction{Path: c.Path, Name: name})
+			}
+		}
+		if inFunc {
+			funcs[len(funcs)-1].Body = append(funcs[len(funcs)-1].Body, line[1:])
+		}
+	}
+	return funcs
+}
+
+// skipFunction leaves out entry points and what only tests call
+func skipFunction(name string) bool {
+	switch name {
+	case "main", "init", "__init__", "constructor", "setUp", "tearDown", "if", "for", "while", "switch", "catch":
+		return true
+	}
+	return strings.HasPrefix(name, "Test") || strings.HasPrefix(name, "test_")
+}
+
+func mentioned(name string, diffs []string) bool {
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for _, d := range diffs {
+		if re.MatchString(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func testedInRepo(name string) bool {
+	for _, f := range git.FilesMentioning(name) {
+		if testPathRe.MatchString(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuggestTests suggests test cases, by name and what each checks, for the
+// untested functions the changes add. It writes no test code.
+func (g *GeminiClient) SuggestTests(changes []git.FileChange, funcs []NewFunction) (string, error) {
+	if g.offline() {
+		return offlineTests(funcs), nil
+	}
+	files := make(map[string]bool)
+	for _, fn := range funcs {
+		files[fn.Path] = true
+	}
+	var relevant []git.FileChange
+	for _, c := range changes {
+		if files[c.Path] {
+			relevant = append(relevant, c)
+		}
+	}
+	raw, err := g.callGemini(g.prompt(relevant, func(c []git.FileChange, _ MovedCode) string {
+		return g.buildTestsPrompt(c, funcs)
+	}))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+func (g *GeminiClient) buildTestsPrompt(changes []git.FileChange, funcs []NewFunction) string {
+	var sb strings.Builder
+	sb.WriteString("You are reviewing staged changes that add logic without tests.\n")
+	sb.WriteString("Suggest the test cases worth writing for the functions listed below. Don't write test code.\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Write in " + languageName(g.cfg.Language) + "\n")
+	sb.WriteString("- Group the cases by file: a line with the file path, then one '- ' bullet per case\n")
+	sb.WriteString("- Each bullet is a test name in the language's convention (TestParseEmpty, test_parse_empty), a colon and what it checks in one short sentence\n")
+	sb.WriteString("- Cover the normal case, edge cases and error paths the code actually has; don't invent behavior the diff doesn't show\n")
+	sb.WriteString("- At most 5 cases per function\n")
+	sb.WriteString("- Output ONLY the list\n\n")
+	sb.WriteString("Untested functions:\n")
+	for _, fn := range funcs {
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", g.Anon.Path(fn.Path), fn.Name))
+	}
+	sb.WriteString("\nChanges:\n")
+	for _, c := range changes {
+		sb.WriteString(fmt.Sprintf("FILE: %s (status: %s)\n```diff\n%s\n```\n\n", c.Path, c.Status, c.Diff))
+	}
+	return sb.String()
+}
+
+// offlineTests names one test per function, and one for its error path
+// when it has one
+func offlineTests(funcs []NewFunction) string {
+	var sb strings.Builder
+	last := ""
+	for _, fn := range funcs {
+		if fn.Path != last {
+			if last != "" {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fn.Path + "\n")
+			last = fn.Path
+		}
+		ext := strings.ToLower(filepath.Ext(fn.Path))
+		fmt.Fprintf(&sb, "- %s: %s returns the expected result for typical input\n", testName(ext, fn.Name, ""), fn.Name)
+		body := strings.Join(fn.Body, "\n")
+		if strings.Contains(body, "err") || strings.Contains(body, "raise ") || strings.Contains(body, "throw ") {
+			fmt.Fprintf(&sb, "- %s: %s reports invalid input as an error\n", testName(ext, fn.Name, "error"), fn.Name)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// testName names a test in the convention of the file's language
+func testName(ext, name, suffix string) string {
+	switch ext {
+	case ".py", ".rb", ".rs":
+		if suffix != "" {
+			return "test_" + snake(name) + "_" + suffix
+		}
+		return "test_" + snake(name)
+	case ".go", ".java", ".kt", ".cs":
+		r := []rune(name)
+		r[0] = unicode.ToUpper(r[0])
+		if suffix != "" {
+			return "Test" + string(r) + strings.ToUpper(suffix[:1]) + suffix[1:]
+		}
+		return "Test" + string(r)
+	}
+	if suffix != "" {
+		return fmt.Sprintf("%q", name+" "+suffix)
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// snake turns camelCase into snake_case
+func snake(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxAudioSize keeps a voice note under the 20 MB limit of an inline
+// Gemini request, base64 and prompt included
+const maxAudioSize = 14 << 20
+
+// audioTypes are the audio formats Gemini understands, by file extension
+var audioTypes = map[string]string{
+	".wav":  "audio/wav",
+	".mp3":  "audio/mp3",
+	".aiff": "audio/aiff",
+	".aif":  "audio/aiff",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".flac": "audio/flac",
+}
+
+// AudioType returns the MIME type of an audio file from its extension, or
+// false when the provider can't transcribe the format
+func AudioType(path string) (string, bool) {
+	t, ok := audioTypes[strings.ToLower(filepath.Ext(path))]
+	return t, ok
+}
+
+// TranscribeAudio writes down what is said in a voice note about a code
+// change, for say --listen and --audio. Only the Gemini API (or a gateway
+// to it) takes audio: neither the offline rules nor a provider command can.
+func (g *GeminiClient) TranscribeAudio(audio []byte, mimeType string) (string, error) {
+	switch {
+	case g.offline():
+		return "", fmt.Errorf("the offline provider can't transcribe audio; set dictation_command or type the description")
+	case g.cfg.ProviderCommand != "":
+		return "", fmt.Errorf("a provider command can't transcribe audio; set dictation_command or type the description")
+	case len(audio) == 0:
+		return "", fmt.Errorf("the recording is empty")
+	case len(audio) > maxAudioSize:
+		return "", fmt.Errorf("the recording is too long to transcribe (%s, at most %s)", humanSize(int64(len(audio))), humanSize(maxAudioSize))
+	}
+
+	prompt := "Transcribe this voice note word for word. The speaker is describing a change they made to source code, for its commit message: " +
+		"write identifiers, file names and technical terms the way they would appear in code. " +
+		"Leave out filler words and false starts. Output ONLY the transcript, or nothing if no speech can be heard."
+	raw, err := g.send([]geminiContent{{Parts: []geminiPart{
+		{Text: prompt},
+		{InlineData: &geminiBlob{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(audio)}},
+	}}})
+	if err != nil {
+		return "", err
+	}
+	transcript := strings.TrimSpace(raw)
+	if transcript == "" {
+		return "", fmt.Errorf("no speech was heard in the recording")
+	}
+	return transcript, nil
+}
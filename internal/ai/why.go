@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Kinds of motivation hints
+const (
+	HintComment  = "added comment"
+	HintTODO     = "resolved TODO"
+	HintNearby   = "comment above the change"
+	maxHints     = 12
+	maxHintChars = 200
+)
+
+// Hint is a code comment in a diff that may tell why a change was made
+type Hint struct {
+	Path string
+	Kind string
+	Text string
+}
+
+var (
+	// A comment marker, then (after a space for the markers that are also
+	// operators, like -- and *) the text, then maybe a closing marker
+	commentRe = regexp.MustCompile(`^\s*(?://+|#+|/\*+|<!--|"""|'''|(?:--|;+|\*+/?)(?:\s|$))\s*(.*?)\s*(?:\*/|-->|"""|''')?\s*$`)
+	todoRe    = regexp.MustCompile(`\b(?:TODO|FIXME|HACK|XXX)\b`)
+	// Lines starting with # that are code, not comments
+	notCommentRe = regexp.MustCompile(`^\s*#(?:!|include|define|if|ifdef|ifndef|else|elif|endif|pragma|import|undef|\[)`)
+)
+
+// proseExts are files whose lines starting with # or -- are text, not comments
+var proseExts = []string{".md", ".markdown", ".rst", ".txt", ".adoc"}
+
+// MotivationHints collects what the comments in the changes say: comments
+// and docstrings that were added, TODOs that were removed and comments right
+// above changed lines. Files that only move code are skipped.
+func MotivationHints(changes []git.FileChange, moves MovedCode) []Hint {
+	var hints []Hint
+	for _, c := range changes {
+		if slices.Contains(proseExts, strings.ToLower(filepath.Ext(c.Path))) || slices.Contains(moves.OnlyMoves, c.Path) {
+			continue
+		}
+		hints = append(hints, fileHints(c)...)
+		if len(hints) >= maxHints {
+			return hints[:maxHints]
+		}
+	}
+	return hints
+}
+
+func fileHints(c git.FileChange) []Hint {
+	var hints []Hint
+	add := func(kind string, text []string) {
+		joined := strings.Join(text, " ")
+		if joined == "" {
+			return
+		}
+		if len(joined) > maxHintChars {
+			joined = joined[:maxHintChars] + "..."
+		}
+		for _, h := range hints {
+			if h.Text == joined {
+				return
+			}
+		}
+		hints = append(hints, Hint{Path: c.Path, Kind: kind, Text: joined})
+	}
+
+	var added, above []string // Current run of added comment lines, context comments
+	for _, line := range strings.Split(c.Diff, "\n") {
+		if line == "" || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		body := line[1:]
+		text, isComment := commentText(body)
+		switch line[0] {
+		case '+':
+			if above != nil {
+				add(HintNearby, above)
+				above = nil
+			}
+			if isComment {
+				if text != "" {
+					added = append(added, text)
+				}
+				continue
+			}
+		case '-':
+			if above != nil {
+				add(HintNearby, above)
+				above = nil
+			}
+			if todoRe.MatchString(body) {
+				if isComment {
+					body = text
+				}
+				add(HintTODO, []string{strings.TrimSpace(body)})
+			}
+		case ' ':
+			if isComment {
+				if text != "" {
+					above = append(above, text)
+				}
+			} else {
+				above = nil
+			}
+		default: // Hunk headers
+			above = nil
+		}
+		add(HintComment, added)
+		added = nil
+	}
+	add(HintComment, added)
+	return hints
+}
+
+// commentText returns the text of a comment line without its markers
+func commentText(line string) (string, bool) {
+	if notCommentRe.MatchString(line) {
+		return "", false
+	}
+	m := commentRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+func writeMotivationHints(sb *strings.Builder, hints []Hint) {
+	if len(hints) == 0 {
+		return
+	}
+	sb.WriteString("Comments in the changes that may tell why they were made:\n")
+	for _, h := range hints {
+		sb.WriteString(fmt.Sprintf("- %s, %s: %q\n", h.Path, h.Kind, h.Text))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,144 @@
+package announce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Supported chat platforms
+const (
+	Slack   = "slack"
+	Discord = "discord"
+	Teams   = "teams"
+)
+
+// Platforms lists every supported announcement target
+var Platforms = []string{Slack, Discord, Teams}
+
+// maxBullets caps how many bullets per section make it into the chat message
+const maxBullets = 5
+
+// Release describes what is being announced
+type Release struct {
+	Project string
+	Tag     string
+	Notes   string
+}
+
+// Supported reports whether platform is a known announcement target
+func Supported(platform string) bool {
+	for _, p := range Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// Post sends a condensed, chat-formatted announcement to a webhook
+func Post(platform, webhookURL string, rel Release) error {
+	var payload map[string]string
+	switch platform {
+	case Slack:
+		payload = map[string]string{"text": Format(platform, rel)}
+	case Discord:
+		payload = map[string]string{"content": Format(platform, rel)}
+	case Teams:
+		payload = map[string]string{"text": Format(platform, rel)}
+	default:
+		return fmt.Errorf("unsupported announcement platform %q (supported: %s)", platform, strings.Join(Platforms, ", "))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s webhook request failed: %w", platform, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook returned %s: %s", platform, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// Format condenses markdown release notes into a short chat message:
+// headings become bold lines and each section keeps at most a few bullets.
+func Format(platform string, rel Release) string {
+	bold := func(s string) string { return "**" + s + "**" }
+	bullet := "- "
+	if platform == Slack {
+		bold = func(s string) string { return "*" + s + "*" }
+		bullet = "• "
+	}
+
+	var sb strings.Builder
+	title := "🚀 " + rel.Tag + " released"
+	if rel.Project != "" {
+		title = "🚀 " + rel.Project + " " + rel.Tag + " released"
+	}
+	sb.WriteString(bold(title) + "\n")
+
+	bullets, skipped := 0, 0
+	summaryDone := false
+	flushSkipped := func() {
+		if skipped > 0 {
+			sb.WriteString(fmt.Sprintf("_…and %d more_\n", skipped))
+		}
+		bullets, skipped = 0, 0
+	}
+
+	for _, line := range strings.Split(rel.Notes, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			flushSkipped()
+			heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			if strings.Contains(heading, rel.Tag) {
+				continue // Redundant version heading
+			}
+			sb.WriteString("\n" + bold(heading) + "\n")
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			if bullets >= maxBullets {
+				skipped++
+				continue
+			}
+			bullets++
+			sb.WriteString(bullet + chatMarkdown(platform, line[2:]) + "\n")
+		default:
+			// Keep only the one-sentence summary, drop other prose
+			if !summaryDone {
+				sb.WriteString(chatMarkdown(platform, line) + "\n")
+				summaryDone = true
+			}
+		}
+	}
+	flushSkipped()
+
+	msg := strings.TrimSpace(sb.String())
+	if platform == Discord && len(msg) > 1900 {
+		msg = strings.ToValidUTF8(msg[:1900], "") + "\n…"
+	}
+	return msg
+}
+
+// chatMarkdown converts **bold** to Slack's *bold*; other platforms render markdown as is
+func chatMarkdown(platform, s string) string {
+	if platform == Slack {
+		return strings.ReplaceAll(s, "**", "*")
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package announce
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"mime"
+	"mime/quotedprintable"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+const emailBoundary = "commitai-release-boundary"
+
+var (
+	boldRe = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	codeRe = regexp.MustCompile("`([^`]+)`")
+)
+
+// EmailSubject returns the subject line used for release emails
+func EmailSubject(rel Release) string {
+	if rel.Project != "" {
+		return fmt.Sprintf("[%s] %s released", rel.Project, rel.Tag)
+	}
+	return rel.Tag + " released"
+}
+
+// Email renders the release as a multipart/alternative RFC 5322 message
+// with a plain-text and an HTML part.
+func Email(rel Release, from string, to []string) []byte {
+	var b bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	if len(to) > 0 {
+		fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", EmailSubject(rel)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", emailBoundary)
+
+	writePart(&b, "text/plain", PlainText(rel.Notes))
+	writePart(&b, "text/html", HTML(rel))
+	fmt.Fprintf(&b, "--%s--\r\n", emailBoundary)
+	return b.Bytes()
+}
+
+// writePart adds a part of the multipart message, quoted-printable encoded
+// so non-ASCII text and long lines survive 7-bit mail relays
+func writePart(b *bytes.Buffer, contentType, text string) {
+	fmt.Fprintf(b, "--%s\r\n", emailBoundary)
+	fmt.Fprintf(b, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(b)
+	qp.Write([]byte(crlf(text)))
+	qp.Close()
+	b.WriteString("\r\n")
+}
+
+// SendMail pipes a rendered message to the local sendmail binary
+func SendMail(msg []byte) error {
+	cmd := exec.Command("sendmail", "-t", "-i")
+	cmd.Stdin = bytes.NewReader(msg)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sendmail failed: %s\n%w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// PlainText turns markdown notes into readable plain text
+func PlainText(notes string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(notes, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			sb.WriteString(strings.ToUpper(heading) + "\n")
+			sb.WriteString(strings.Repeat("=", message.DisplayWidth(heading)) + "\n")
+			continue
+		}
+		line = boldRe.ReplaceAllString(line, "$1")
+		line = codeRe.ReplaceAllString(line, "$1")
+		sb.WriteString(line + "\n")
+	}
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+// HTML renders markdown notes as a minimal standalone HTML document
+func HTML(rel Release) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html><body style=\"font-family: sans-serif\">\n")
+	sb.WriteString("<h1>" + html.EscapeString(EmailSubject(rel)) + "</h1>\n")
+
+	inList := false
+	closeList := func() {
+		if inList {
+			sb.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(rel.Notes, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			closeList()
+		case strings.HasPrefix(line, "#"):
+			closeList()
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if level < 2 {
+				level = 2
+			}
+			if level > 4 {
+				level = 4
+			}
+			fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", level, inlineHTML(strings.TrimSpace(strings.TrimLeft(line, "#"))), level)
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			if !inList {
+				sb.WriteString("<ul>\n")
+				inList = true
+			}
+			sb.WriteString("<li>" + inlineHTML(line[2:]) + "</li>\n")
+		default:
+			closeList()
+			sb.WriteString("<p>" + inlineHTML(line) + "</p>\n")
+		}
+	}
+	closeList()
+	sb.WriteString("</body></html>\n")
+	return sb.String()
+}
+
+func inlineHTML(s string) string {
+	s = html.EscapeString(s)
+	s = boldRe.ReplaceAllString(s, "<strong>$1</strong>")
+	s = codeRe.ReplaceAllString(s, "<code>$1</code>")
+	return s
+}
+
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
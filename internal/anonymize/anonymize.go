@@ -0,0 +1,342 @@
+package anonymize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the mapping file stored inside the .git directory
+const FileName = "commitai/anonymize.json"
+
+// Kinds of values replaced, in the order they are searched for
+const (
+	KindString = "STRING"
+	KindURL    = "URL"
+	KindEmail  = "EMAIL"
+	KindHost   = "HOST"
+	KindIP     = "IP"
+
+	// File paths, with a path mode
+	KindDir  = "DIR"
+	KindFile = "FILE"
+	KindPath = "PATH"
+)
+
+// Path modes: generalize keeps the top-level directory and the extension
+// (internal/<DIR_1>/<FILE_1>.go), hash replaces the rest of the path with a
+// hash of it (<PATH_3f9a1c02>.go)
+const (
+	PathsGeneralize = "generalize"
+	PathsHash       = "hash"
+)
+
+var (
+	// Double-quoted and backquoted literals, and single-quoted ones not
+	// preceded by a letter (so "don't ... it's" isn't one)
+	stringRe = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|(?:^|[^A-Za-z0-9])'(?:[^'\\\\]|\\\\.)*'")
+	urlRe    = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^\s"'<>` + "`" + `)\]]+`)
+	emailRe  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	hostRe   = regexp.MustCompile(`\b(?:[A-Za-z0-9-]+\.)+(?:internal|corp|local|lan|intra|intranet|private|example|com|net|org|io|dev|cloud|co)\b`)
+	ipRe     = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+	placeholderRe = regexp.MustCompile(`<(STRING|URL|EMAIL|HOST|IP|DIR|FILE)_(\d+)>|<PATH_[0-9a-f]{8}>`)
+)
+
+// minStringLen skips short literals (separators, "\n") that reveal nothing
+const minStringLen = 3
+
+// Mapping replaces identifying values with placeholders (<EMAIL_1>) and
+// back. The same value always gets the same placeholder, also across runs
+// when the mapping is persisted.
+type Mapping struct {
+	path   string
+	Values map[string]string `json:"values"` // Placeholder -> original value
+	byVal  map[string]string
+	byPart map[string]string // Kind and path component -> placeholder
+	dirty  bool
+
+	// PathMode also hides file paths (PathsGeneralize or PathsHash);
+	// PathsOnly leaves the changed lines alone
+	PathMode  string            `json:"-"`
+	PathsOnly bool              `json:"-"`
+	paths     map[string]string // Original path -> hidden path, for Hide
+}
+
+// New returns an empty mapping that isn't persisted
+func New() *Mapping {
+	return &Mapping{Values: make(map[string]string), byVal: make(map[string]string), byPart: make(map[string]string), paths: make(map[string]string)}
+}
+
+// Load reads the mapping from the given .git directory; Save writes it back.
+// A missing file yields an empty mapping.
+func Load(gitDir string) (*Mapping, error) {
+	m := New()
+	m.path = filepath.Join(gitDir, FileName)
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid anonymization mapping %s: %w", m.path, err)
+	}
+	if m.Values == nil {
+		m.Values = make(map[string]string)
+	}
+	for p, v := range m.Values {
+		if kind := placeholderKind(p); isPathKind(kind) {
+			m.byPart[kind+"\x00"+v] = p
+		} else {
+			m.byVal[v] = p
+		}
+	}
+	return m, nil
+}
+
+// Save writes the mapping if it changed. It holds the original values, so
+// it's only readable by the user.
+func (m *Mapping) Save() error {
+	if m == nil || m.path == "" || !m.dirty || readonly.Enabled() {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep placeholders readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	if err := os.WriteFile(m.path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
+
+// placeholder returns the placeholder of a value, creating one if needed
+func (m *Mapping) placeholder(kind, value string) string {
+	// Path components are kept apart: a directory named like a literal
+	// gets its own placeholder, and Hide doesn't replace them in text
+	byVal, key := m.byVal, value
+	if isPathKind(kind) {
+		byVal, key = m.byPart, kind+"\x00"+value
+	}
+	if p, ok := byVal[key]; ok {
+		return p
+	}
+	n := 1
+	for p := range m.Values {
+		if strings.HasPrefix(p, "<"+kind+"_") {
+			n++
+		}
+	}
+	p := fmt.Sprintf("<%s_%d>", kind, n)
+	m.Values[p] = value
+	byVal[key] = p
+	m.dirty = true
+	return p
+}
+
+// placeholderKind returns the kind of a placeholder: STRING for <STRING_1>
+func placeholderKind(p string) string {
+	kind, _, _ := strings.Cut(strings.TrimPrefix(p, "<"), "_")
+	return kind
+}
+
+func isPathKind(kind string) bool {
+	return kind == KindDir || kind == KindFile || kind == KindPath
+}
+
+// Line replaces string literals, URLs, emails, hostnames and IP addresses
+// in one line of code
+func (m *Mapping) Line(line string) string {
+	line = stringRe.ReplaceAllStringFunc(line, func(lit string) string {
+		lead := ""
+		if lit[0] != '"' && lit[0] != '`' && lit[0] != '\'' {
+			lead, lit = lit[:1], lit[1:] // The character before a single quote
+		}
+		body := lit[1 : len(lit)-1]
+		if len(body) < minStringLen || placeholderRe.MatchString(body) {
+			return lead + lit
+		}
+		return lead + lit[:1] + m.placeholder(KindString, body) + lit[len(lit)-1:]
+	})
+	for _, r := range []struct {
+		kind string
+		re   *regexp.Regexp
+	}{{KindURL, urlRe}, {KindEmail, emailRe}, {KindHost, hostRe}, {KindIP, ipRe}} {
+		line = r.re.ReplaceAllStringFunc(line, func(v string) string { return m.placeholder(r.kind, v) })
+	}
+	return line
+}
+
+// Diff anonymizes the changed and context lines of a diff; file and hunk
+// headers are kept so the diff stays readable, with their paths hidden in
+// a path mode
+func (m *Mapping) Diff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "+++ "), strings.HasPrefix(l, "--- "):
+			lines[i] = l[:4] + m.headerPath(l[4:])
+		case strings.HasPrefix(l, "@@"):
+		case strings.HasPrefix(l, "+"), strings.HasPrefix(l, "-"), strings.HasPrefix(l, " "):
+			if !m.PathsOnly {
+				lines[i] = l[:1] + m.Line(l[1:])
+			}
+		case m.PathMode != "":
+			lines[i] = m.pathHeader(l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Changes returns copies of the changes with anonymized diffs, and paths
+// in a path mode
+func (m *Mapping) Changes(changes []git.FileChange) []git.FileChange {
+	out := make([]git.FileChange, len(changes))
+	for i, c := range changes {
+		c.Diff = m.Diff(c.Diff)
+		if m.PathMode != "" {
+			c.Path = m.Path(c.Path)
+			lockfiles := make([]string, len(c.Lockfiles))
+			for j, l := range c.Lockfiles {
+				lockfiles[j] = m.Path(l)
+			}
+			c.Lockfiles = lockfiles
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// Path hides a file path in the path mode; without one it's kept
+func (m *Mapping) Path(path string) string {
+	if m == nil || m.PathMode == "" || path == "" {
+		return path
+	}
+	if hidden, ok := m.paths[path]; ok {
+		return hidden
+	}
+	dir, base := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, base = path[:i], path[i+1:]
+	}
+	ext := filepath.Ext(base)
+	if ext == base {
+		ext = "" // .gitignore
+	}
+	var hidden string
+	switch m.PathMode {
+	case PathsHash:
+		sum := sha256.Sum256([]byte(strings.TrimSuffix(path, ext)))
+		p := "<" + KindPath + "_" + hex.EncodeToString(sum[:4]) + ">"
+		if _, ok := m.Values[p]; !ok {
+			m.Values[p] = strings.TrimSuffix(path, ext)
+			m.dirty = true
+		}
+		hidden = p + ext
+	default:
+		var parts []string
+		for i, d := range strings.Split(dir, "/") {
+			switch {
+			case d == "":
+			case i == 0:
+				parts = append(parts, d)
+			default:
+				parts = append(parts, m.placeholder(KindDir, d))
+			}
+		}
+		hidden = strings.Join(append(parts, m.placeholder(KindFile, strings.TrimSuffix(base, ext))+ext), "/")
+	}
+	m.paths[path] = hidden
+	return hidden
+}
+
+// headerPath hides the path of a ---/+++ line: a/path, b/path or /dev/null
+func (m *Mapping) headerPath(s string) string {
+	if m.PathMode == "" || s == "/dev/null" {
+		return s
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[:2] + m.Path(s[2:])
+	}
+	return m.Path(s)
+}
+
+// pathHeader hides the paths of the other file header lines
+func (m *Mapping) pathHeader(l string) string {
+	if rest, ok := strings.CutPrefix(l, "diff --git "); ok {
+		if a, b, ok := strings.Cut(rest, " b/"); ok {
+			return "diff --git " + m.headerPath(a) + " b/" + m.Path(b)
+		}
+		return l
+	}
+	for _, prefix := range []string{"rename from ", "rename to ", "copy from ", "copy to "} {
+		if rest, ok := strings.CutPrefix(l, prefix); ok {
+			return prefix + m.Path(rest)
+		}
+	}
+	if rest, ok := strings.CutPrefix(l, "Binary files "); ok {
+		if a, b, ok := strings.Cut(strings.TrimSuffix(rest, " differ"), " and "); ok {
+			return "Binary files " + m.headerPath(a) + " and " + m.headerPath(b) + " differ"
+		}
+	}
+	return l
+}
+
+// Hide replaces the values and paths already in the mapping wherever they
+// appear in text (commit messages, the author's description, chat turns),
+// longest first. Unlike Line, it finds no new values.
+func (m *Mapping) Hide(text string) string {
+	if m == nil || len(m.byVal)+len(m.paths) == 0 {
+		return text
+	}
+	hidden := make(map[string]string, len(m.byVal)+len(m.paths))
+	for v, p := range m.byVal {
+		hidden[v] = p
+	}
+	for path, h := range m.paths {
+		hidden[path] = h
+	}
+	values := make([]string, 0, len(hidden))
+	for v := range hidden {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, hidden[v])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Restore puts the original values back in place of the placeholders
+func (m *Mapping) Restore(text string) string {
+	if m == nil {
+		return text
+	}
+	return placeholderRe.ReplaceAllStringFunc(text, func(p string) string {
+		if v, ok := m.Values[p]; ok {
+			return v
+		}
+		return p
+	})
+}
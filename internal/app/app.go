@@ -0,0 +1,48 @@
+// Package app holds the commit and release flows behind commitai's
+// frontends. The CLI, the editor RPC server and the HTTP server each build
+// a service with their own repository client, AI provider and output, and
+// share the steps in between; the cmd package only parses flags and talks
+// to the terminal.
+package app
+
+import (
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Generator is the AI provider the services ask; *ai.GeminiClient
+// implements it
+type Generator interface {
+	ProviderName() string
+	GenerateCommitMessages(changes []git.FileChange, granular bool, recentCommits []string) (map[string]string, error)
+	GenerateReleaseNotes(commits []string, currentTag, newTag string, opts ai.ReleaseOptions) (string, error)
+	SuggestNextVersion(commits []string, currentTag string) (string, error)
+}
+
+// confidenceRater is a Generator that can tell how sure it was of the
+// messages it generated last
+type confidenceRater interface {
+	Confidence() map[string]ai.Confidence
+}
+
+// Output reports progress; render.Renderer implements it
+type Output interface {
+	Info(format string, a ...any)
+	Warn(format string, a ...any)
+}
+
+// Discard is the Output of frontends nobody watches, like the servers
+var Discard Output = discard{}
+
+type discard struct{}
+
+func (discard) Info(string, ...any) {}
+func (discard) Warn(string, ...any) {}
+
+// out returns o, or Discard when o is nil
+func out(o Output) Output {
+	if o == nil {
+		return Discard
+	}
+	return o
+}
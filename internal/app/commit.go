@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/vcs"
+)
+
+// CommitService generates commit messages for a repository's changes and
+// records the commits
+type CommitService struct {
+	Config *config.Config
+	Repo   vcs.VCS
+	AI     Generator
+	Out    Output
+
+	// Context returns the history sent as style context for changes; the
+	// repository's last Config.RecentCommits commits when nil
+	Context func(changes []git.FileChange) []string
+	// Finish completes a generated message (subject style, emojis,
+	// template, closing keywords); messages are kept as generated when nil
+	Finish func(msg string) string
+	// Check validates a message; against Config.Policy alone when nil
+	Check func(msg string) []policy.Violation
+	// Lock, when set, keeps other runs from committing until release is
+	// called
+	Lock func() (release func(), err error)
+	// Version is recorded in provenance trailers
+	Version string
+}
+
+// Suggestion is what a frontend shows before committing: the messages
+// generated for the changes, each with its policy violations
+type Suggestion struct {
+	Changes  []git.FileChange
+	Granular bool
+	// Messages holds one message per file path when granular, or a single
+	// one under "__all__"
+	Messages   map[string]string
+	Violations map[string][]policy.Violation
+	// Confidence rates the messages by the same keys, when the provider
+	// could tell
+	Confidence map[string]ai.Confidence
+	Provider   string
+}
+
+// PolicyError is returned when a message is committed despite violating
+// the policy
+type PolicyError struct {
+	Violations []policy.Violation
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("commit message violates policy (%d violation(s))", len(e.Violations))
+}
+
+// AutoGranular reports whether changes are better committed per file: more
+// than two files, or files in more than one top-level directory
+func AutoGranular(changes []git.FileChange) bool {
+	if len(changes) <= 1 {
+		return false
+	}
+	dirs := make(map[string]bool)
+	for _, c := range changes {
+		parts := strings.Split(c.Path, "/")
+		if len(parts) > 1 {
+			dirs[parts[0]] = true
+		}
+	}
+	return len(dirs) > 1 || len(changes) >= 3
+}
+
+// Changes returns what the next commit will contain
+func (s *CommitService) Changes() ([]git.FileChange, error) {
+	return s.Repo.Changes()
+}
+
+// Generate asks the AI for messages and finishes them. recentCommits is
+// the style context; it's looked up when nil.
+func (s *CommitService) Generate(changes []git.FileChange, granular bool, recentCommits []string) (map[string]string, error) {
+	if recentCommits == nil {
+		recentCommits = s.recentCommits(changes)
+	}
+	messages, err := s.AI.GenerateCommitMessages(changes, granular, recentCommits)
+	if err != nil {
+		return nil, err
+	}
+	for k, msg := range messages {
+		messages[k] = s.finish(msg)
+	}
+	return messages, nil
+}
+
+// Suggest generates the messages for changes and checks each against the
+// policy
+func (s *CommitService) Suggest(changes []git.FileChange, granular bool) (*Suggestion, error) {
+	messages, err := s.Generate(changes, granular, nil)
+	if err != nil {
+		return nil, err
+	}
+	violations := make(map[string][]policy.Violation, len(messages))
+	for k, msg := range messages {
+		violations[k] = s.check(msg)
+	}
+	suggestion := &Suggestion{
+		Changes:    changes,
+		Granular:   granular,
+		Messages:   messages,
+		Violations: violations,
+		Provider:   s.AI.ProviderName(),
+	}
+	if r, ok := s.AI.(confidenceRater); ok {
+		suggestion.Confidence = r.Confidence()
+	}
+	return suggestion, nil
+}
+
+// Commit records msg after checking it against the policy, which fails
+// with a *PolicyError. edited tells provenance trailers whether a person
+// changed the generated message.
+func (s *CommitService) Commit(msg string, edited bool, opts git.CommitOptions) error {
+	if violations := s.check(msg); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	if s.Config.ProvenanceTrailers && !s.Config.PrivacyMode {
+		msg = message.Provenance(msg, s.Version, s.Config.Model, edited)
+	}
+	if s.Lock != nil {
+		release, err := s.Lock()
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	return s.Repo.Commit(msg, opts)
+}
+
+func (s *CommitService) recentCommits(changes []git.FileChange) []string {
+	if s.Context != nil {
+		return s.Context(changes)
+	}
+	if s.Config.RecentCommits <= 0 || s.Repo == nil {
+		return nil
+	}
+	commits, err := s.Repo.RecentCommits(s.Config.RecentCommits)
+	if err != nil {
+		out(s.Out).Warn("⚠️  No recent commits as context: %s", err)
+	}
+	return commits
+}
+
+func (s *CommitService) finish(msg string) string {
+	if s.Finish == nil {
+		return msg
+	}
+	return s.Finish(msg)
+}
+
+func (s *CommitService) check(msg string) []policy.Violation {
+	if s.Check != nil {
+		return s.Check(msg)
+	}
+	return policy.Check(msg, s.Config.Policy)
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+// Bump is how far a release moves the version
+type Bump int
+
+const (
+	BumpPatch Bump = iota
+	BumpMinor
+	BumpMajor
+	BumpAuto // Asks the AI
+)
+
+// ReleaseService works out the next version and its notes from the commits
+// since the last release
+type ReleaseService struct {
+	Config *config.Config
+	AI     Generator
+	Out    Output
+
+	// PreviousNotes returns the notes of the release at tag, which new
+	// notes shouldn't repeat; nothing is left out when nil
+	PreviousNotes func(tag string) string
+}
+
+// NextVersion returns the version after currentVersion (without the tag
+// prefix, "" before the first release). BumpAuto asks the AI, which sees
+// the commits and currentTag.
+func (s *ReleaseService) NextVersion(commits []string, currentTag, currentVersion string, bump Bump) (string, error) {
+	if bump != BumpAuto {
+		return BumpVersion(currentVersion, bump), nil
+	}
+	out(s.Out).Info("\n🤖 Asking AI to suggest version bump...")
+	version, err := s.AI.SuggestNextVersion(commits, currentTag)
+	if err != nil {
+		return "", fmt.Errorf("AI version suggestion failed: %w", err)
+	}
+	return version, nil
+}
+
+// Notes generates the notes of newTag for the commits since currentTag.
+// opts.PreviousNotes is filled in from PreviousNotes when empty.
+func (s *ReleaseService) Notes(commits []string, currentTag, newTag string, opts ai.ReleaseOptions) (string, error) {
+	if opts.PreviousNotes == "" && s.PreviousNotes != nil && currentTag != "" {
+		opts.PreviousNotes = s.PreviousNotes(currentTag)
+	}
+	out(s.Out).Info("\n✨ Generating release notes with %s...", s.AI.ProviderName())
+	notes, err := s.AI.GenerateReleaseNotes(commits, currentTag, newTag, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	return notes, nil
+}
+
+// BumpVersion bumps a MAJOR.MINOR.PATCH version, missing parts counting as
+// 0. The first version is 0.1.0.
+func BumpVersion(version string, bump Bump) string {
+	if version == "" {
+		return "0.1.0"
+	}
+
+	parts := strings.Split(version, ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+
+	var maj, min, pat int
+	fmt.Sscanf(parts[0], "%d", &maj)
+	fmt.Sscanf(parts[1], "%d", &min)
+	fmt.Sscanf(parts[2], "%d", &pat)
+
+	switch bump {
+	case BumpMajor:
+		maj++
+		min = 0
+		pat = 0
+	case BumpMinor:
+		min++
+		pat = 0
+	default:
+		pat++
+	}
+	return fmt.Sprintf("%d.%d.%d", maj, min, pat)
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the backup list inside the .git directory
+const FileName = "commitai/backups.json"
+
+// RefPrefix is where backup refs are kept, so git gc doesn't collect the
+// commits they point at
+const RefPrefix = "refs/commitai/backup/"
+
+// Backup is a ref saved before commitai rewrote it
+type Backup struct {
+	Name    string    `json:"name"`    // Timestamp; the backup ref is RefPrefix + Name
+	Ref     string    `json:"ref"`     // What was rewritten, e.g. refs/heads/main
+	Hash    string    `json:"hash"`    // What Ref pointed at
+	Command string    `json:"command"` // What rewrote it, e.g. reword
+	Created time.Time `json:"created"`
+}
+
+// BackupRef returns the ref that keeps the backup
+func (b Backup) BackupRef() string {
+	return RefPrefix + b.Name
+}
+
+// Store lists the backups of a repository, oldest first
+type Store struct {
+	path    string
+	Backups []Backup `json:"backups"`
+}
+
+// Load reads the store from the given .git directory. A missing file yields
+// an empty store.
+func Load(gitDir string) (*Store, error) {
+	s := &Store{path: filepath.Join(gitDir, FileName)}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid backup list %s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// New returns a backup of ref, named after the current time and unique in
+// the store. It isn't added until Add.
+func (s *Store) New(ref, hash, command string) Backup {
+	now := time.Now()
+	name := now.Format("20060102-150405")
+	for i := 2; s.has(name); i++ {
+		name = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), i)
+	}
+	return Backup{Name: name, Ref: ref, Hash: hash, Command: command, Created: now}
+}
+
+func (s *Store) has(name string) bool {
+	_, ok := s.Get(name)
+	return ok
+}
+
+// Get returns the backup with the given name
+func (s *Store) Get(name string) (Backup, bool) {
+	for _, b := range s.Backups {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Backup{}, false
+}
+
+// Latest returns the most recent backup
+func (s *Store) Latest() (Backup, bool) {
+	if len(s.Backups) == 0 {
+		return Backup{}, false
+	}
+	return s.Backups[len(s.Backups)-1], true
+}
+
+// Add records a backup and persists the store immediately
+func (s *Store) Add(b Backup) error {
+	s.Backups = append(s.Backups, b)
+	return s.Save()
+}
+
+// Delete forgets a backup
+func (s *Store) Delete(name string) error {
+	for i, b := range s.Backups {
+		if b.Name == name {
+			s.Backups = append(s.Backups[:i], s.Backups[i+1:]...)
+			break
+		}
+	}
+	return s.Save()
+}
+
+// Save writes the store to disk
+func (s *Store) Save() error {
+	if err := readonly.Check("saving a backup"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
@@ -0,0 +1,266 @@
+package bench
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/quality"
+)
+
+// Sample is a bundled diff with the commit type a good message gives it
+type Sample struct {
+	Name string
+	Type string
+	Diff string
+}
+
+// Changes returns the sample's diff as staged changes
+func (s Sample) Changes() []git.FileChange {
+	return git.ParseDiff(s.Diff)
+}
+
+// Run is one generation of a sample's message
+type Run struct {
+	Sample  string        `json:"sample"`
+	Message string        `json:"message,omitempty"`
+	Latency time.Duration `json:"latency_ns"`
+	Tokens  int           `json:"tokens"`         // Input and output, estimated
+	Cost    float64       `json:"cost,omitempty"` // USD upper bound, when priced
+	Score   int           `json:"score"`          // See quality.Rate
+	TypeOK  bool          `json:"type_ok"`        // The sample's type was picked
+	Error   string        `json:"error,omitempty"`
+}
+
+// Rate fills in the quality heuristics of a generated message
+func (r *Run) Rate(s Sample, pol config.Policy, types []string) {
+	r.Score = quality.Rate(r.Message, s.Changes(), pol, true, types).Score
+	subject, _ := policy.Split(r.Message)
+	typ, _, ok := policy.ParseHeader(subject)
+	r.TypeOK = ok && strings.EqualFold(typ, s.Type)
+}
+
+// Summary sums up the runs of one provider or model
+type Summary struct {
+	Target   string        `json:"target"`
+	Runs     int           `json:"runs"`
+	Failed   int           `json:"failed"`
+	Median   time.Duration `json:"median_latency_ns"`
+	Slowest  time.Duration `json:"slowest_latency_ns"`
+	Tokens   int           `json:"avg_tokens"`
+	Cost     float64       `json:"total_cost,omitempty"`
+	Score    int           `json:"avg_score"`
+	TypeHits int           `json:"type_hits"`
+	Details  []Run         `json:"details"`
+}
+
+// Summarize aggregates runs; failed runs only count as failures
+func Summarize(target string, runs []Run) Summary {
+	s := Summary{Target: target, Runs: len(runs), Details: runs}
+	var latencies []time.Duration
+	tokens, score := 0, 0
+	for _, r := range runs {
+		if r.Error != "" {
+			s.Failed++
+			continue
+		}
+		latencies = append(latencies, r.Latency)
+		tokens += r.Tokens
+		score += r.Score
+		s.Cost += r.Cost
+		if r.TypeOK {
+			s.TypeHits++
+		}
+	}
+	if ok := len(latencies); ok > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		s.Median, s.Slowest = latencies[ok/2], latencies[ok-1]
+		s.Tokens, s.Score = tokens/ok, (score+ok/2)/ok
+	}
+	return s
+}
+
+// Samples are the bundled diffs: one per common kind of change, small
+// enough for a run to stay cheap
+var Samples = []Sample{
+	{
+		Name: "new-feature",
+		Type: "feat",
+		Diff: `diff --git a/internal/export/csv.go b/internal/export/csv.go
+new file mode 100644
+index 0000000..3b18e51
+--- /dev/null
++++ b/internal/export/csv.go
+@@ -0,0 +1,24 @@
++package export
++
++import (
++	"encoding/csv"
++	"io"
++	"strconv"
++)
++
++// WriteCSV writes the orders as CSV with a header row
++func WriteCSV(w io.Writer, orders []Order) error {
++	cw := csv.NewWriter(w)
++	if err := cw.Write([]string{"id", "customer", "total"}); err != nil {
++		return err
++	}
++	for _, o := range orders {
++		row := []string{strconv.Itoa(o.ID), o.Customer, strconv.FormatFloat(o.Total, 'f', 2, 64)}
++		if err := cw.Write(row); err != nil {
++			return err
++		}
++	}
++	cw.Flush()
++	return cw.Error()
++}
+diff --git a/cmd/export.go b/cmd/export.go
+index 1a2b3c4..5d6e7f8 100644
+--- a/cmd/export.go
++++ b/cmd/export.go
+@@ -12,6 +12,11 @@ func runExport(format string, orders []Order) error {
+ 	switch format {
+ 	case "json":
+ 		return export.WriteJSON(os.Stdout, orders)
++	case "csv":
++		return export.WriteCSV(os.Stdout, orders)
+ 	}
+-	return fmt.Errorf("unknown format %q (expected json)", format)
++	return fmt.Errorf("unknown format %q (expected json or csv)", format)
+ }
+`,
+	},
+	{
+		Name: "bug-fix",
+		Type: "fix",
+		Diff: `diff --git a/pagination.py b/pagination.py
+index 4e5f6a7..8b9c0d1 100644
+--- a/pagination.py
++++ b/pagination.py
+@@ -8,9 +8,9 @@ def page_count(total, per_page):
+
+ def page_items(items, page, per_page):
+     """Return the items of a 1-based page."""
+-    start = page * per_page
++    start = (page - 1) * per_page
+     end = start + per_page
+     return items[start:end]
+
+ def page_count(total, per_page):
+-    return total // per_page
++    return (total + per_page - 1) // per_page
+`,
+	},
+	{
+		Name: "docs",
+		Type: "docs",
+		Diff: `diff --git a/README.md b/README.md
+index 2c3d4e5..6f7a8b9 100644
+--- a/README.md
++++ b/README.md
+@@ -20,6 +20,17 @@ npm install --save ledgerly
+
+ ## Usage
+
++### Configuration
++
++Ledgerly reads ` + "`ledgerly.config.json`" + ` from the project root:
++
++` + "```json" + `
++{ "currency": "EUR", "rounding": "bankers" }
++` + "```" + `
++
++Set ` + "`LEDGERLY_CONFIG`" + ` to read another file.
++
+ ` + "```js" + `
+ const ledger = require('ledgerly')
+ ` + "```" + `
+`,
+	},
+	{
+		Name: "refactor",
+		Type: "refactor",
+		Diff: `diff --git a/src/cart.ts b/src/cart.ts
+index 9a8b7c6..5d4e3f2 100644
+--- a/src/cart.ts
++++ b/src/cart.ts
+@@ -1,17 +1,17 @@
+ export class Cart {
+   private items: Item[] = []
+
+   total(): number {
+-    let sum = 0
+-    for (const item of this.items) {
+-      sum += item.price * item.quantity
+-    }
+-    return sum
++    return this.items.reduce((sum, item) => sum + lineTotal(item), 0)
+   }
+
+   count(): number {
+-    let n = 0
+-    for (const item of this.items) {
+-      n += item.quantity
+-    }
+-    return n
++    return this.items.reduce((n, item) => n + item.quantity, 0)
+   }
+ }
++
++function lineTotal(item: Item): number {
++  return item.price * item.quantity
++}
+`,
+	},
+	{
+		Name: "ci",
+		Type: "ci",
+		Diff: `diff --git a/.github/workflows/test.yml b/.github/workflows/test.yml
+index 7c6d5e4..3b2a1f0 100644
+--- a/.github/workflows/test.yml
++++ b/.github/workflows/test.yml
+@@ -8,9 +8,12 @@ jobs:
+   test:
+     runs-on: ubuntu-latest
++    strategy:
++      matrix:
++        go: ['1.22', '1.23']
+     steps:
+       - uses: actions/checkout@v4
+       - uses: actions/setup-go@v5
+         with:
+-          go-version: '1.22'
++          go-version: ${{ matrix.go }}
+       - run: go test ./...
+`,
+	},
+	{
+		Name: "tests",
+		Type: "test",
+		Diff: `diff --git a/slug_test.go b/slug_test.go
+index 0f1e2d3..4c5b6a7 100644
+--- a/slug_test.go
++++ b/slug_test.go
+@@ -10,4 +10,16 @@ func TestSlug(t *testing.T) {
+ 		t.Errorf("got %q", got)
+ 	}
+ }
++
++func TestSlugUnicode(t *testing.T) {
++	cases := map[string]string{
++		"Crème brûlée": "creme-brulee",
++		"Straße":       "strasse",
++	}
++	for in, want := range cases {
++		if got := Slug(in); got != want {
++			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
++		}
++	}
++}
+`,
+	},
+}
@@ -0,0 +1,85 @@
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the usage counter stored inside the .git directory
+const FileName = "commitai/usage.json"
+
+// Usage counts the AI calls made and tokens spent in a repository today
+type Usage struct {
+	path   string
+	Date   string `json:"date"` // YYYY-MM-DD, local time
+	Calls  int    `json:"calls"`
+	Tokens int    `json:"tokens"`
+}
+
+// New returns zero usage for today, saved to the given .git directory
+func New(gitDir string) *Usage {
+	u := &Usage{path: filepath.Join(gitDir, FileName)}
+	u.rollover()
+	return u
+}
+
+// Load reads today's usage from the given .git directory. A missing file,
+// or one from an earlier day, yields zero usage.
+func Load(gitDir string) (*Usage, error) {
+	u := New(gitDir)
+	data, err := os.ReadFile(u.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, u); err != nil {
+			return nil, fmt.Errorf("invalid usage counter %s: %w", u.path, err)
+		}
+	}
+	u.rollover()
+	return u, nil
+}
+
+// rollover starts counting again on a new day
+func (u *Usage) rollover() {
+	if today := time.Now().Format("2006-01-02"); u.Date != today {
+		u.Date, u.Calls, u.Tokens = today, 0, 0
+	}
+}
+
+// Add counts one call that used the given tokens and saves the counter
+func (u *Usage) Add(tokens int) error {
+	u.rollover()
+	u.Calls++
+	u.Tokens += tokens
+	if readonly.Enabled() {
+		return nil // Counted for this run only
+	}
+	if err := os.MkdirAll(filepath.Dir(u.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.path, data, 0644)
+}
+
+// Exceeded returns which daily limit the usage reached, e.g. "20 of 20
+// calls", or "" when there is budget left. A limit of 0 is no limit.
+func (u *Usage) Exceeded(calls, tokens int) string {
+	u.rollover()
+	switch {
+	case calls > 0 && u.Calls >= calls:
+		return fmt.Sprintf("%d of %d calls", u.Calls, calls)
+	case tokens > 0 && u.Tokens >= tokens:
+		return fmt.Sprintf("%d of %d tokens", u.Tokens, tokens)
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package buildmeta
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// File names written by Write
+const (
+	VersionFile   = "VERSION"
+	LabelsFile    = "oci-labels.dockerfile"
+	BuildArgsFile = "build-args.env"
+)
+
+// Meta is the version metadata handed to container builds
+type Meta struct {
+	Version  string // Semver without the "v" prefix
+	Tag      string
+	Revision string
+	Source   string
+	Created  time.Time
+}
+
+// Write creates the VERSION, OCI label and build-args files in dir
+// and returns the paths it wrote.
+func Write(dir string, m Meta) ([]string, error) {
+	if err := readonly.Check("writing build metadata"); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		VersionFile:   m.Version + "\n",
+		LabelsFile:    Labels(m),
+		BuildArgsFile: BuildArgs(m),
+	}
+
+	var written []string
+	for _, name := range []string{VersionFile, LabelsFile, BuildArgsFile} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// Labels returns a Dockerfile LABEL instruction with org.opencontainers.image.* keys
+func Labels(m Meta) string {
+	labels := [][2]string{
+		{"org.opencontainers.image.version", m.Version},
+		{"org.opencontainers.image.created", m.Created.UTC().Format(time.RFC3339)},
+	}
+	if m.Revision != "" {
+		labels = append(labels, [2]string{"org.opencontainers.image.revision", m.Revision})
+	}
+	if m.Source != "" {
+		labels = append(labels, [2]string{"org.opencontainers.image.source", m.Source})
+	}
+
+	var sb strings.Builder
+	sb.WriteString("LABEL")
+	for i, l := range labels {
+		sb.WriteString(fmt.Sprintf(" %s=%q", l[0], l[1]))
+		if i < len(labels)-1 {
+			sb.WriteString(" \\\n     ")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// BuildArgs returns KEY=value lines usable with --build-arg or an env file
+func BuildArgs(m Meta) string {
+	var sb strings.Builder
+	sb.WriteString("VERSION=" + m.Version + "\n")
+	sb.WriteString("GIT_TAG=" + m.Tag + "\n")
+	sb.WriteString("GIT_COMMIT=" + m.Revision + "\n")
+	sb.WriteString("BUILD_DATE=" + m.Created.UTC().Format(time.RFC3339) + "\n")
+	if m.Source != "" {
+		sb.WriteString("SOURCE_URL=" + m.Source + "\n")
+	}
+	return sb.String()
+}
+
+// SourceURL normalizes a git remote URL into a browsable https URL
+func SourceURL(remote string) string {
+	u := strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	if strings.HasPrefix(u, "git@") {
+		// git@github.com:owner/repo -> https://github.com/owner/repo
+		u = "https://" + strings.Replace(strings.TrimPrefix(u, "git@"), ":", "/", 1)
+	}
+	if strings.HasPrefix(u, "ssh://git@") {
+		u = "https://" + strings.TrimPrefix(u, "ssh://git@")
+	}
+	return u
+}
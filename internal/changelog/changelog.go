@@ -0,0 +1,195 @@
+package changelog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// CacheFileName is the cache file stored inside the .git directory
+const CacheFileName = "commitai/changelog-cache.json"
+
+// Section is one generated changelog entry for a tag
+type Section struct {
+	Tag   string `json:"tag"`
+	Date  string `json:"date"`
+	Key   string `json:"key"`
+	Notes string `json:"notes"`
+}
+
+// Cache stores generated sections so interrupted runs can resume
+// without repeating AI calls for tags that are already done.
+type Cache struct {
+	path     string
+	Sections map[string]Section `json:"sections"`
+}
+
+// LoadCache reads the cache from the given .git directory.
+// A missing cache file yields an empty cache.
+func LoadCache(gitDir string) (*Cache, error) {
+	c := &Cache{
+		path:     filepath.Join(gitDir, CacheFileName),
+		Sections: make(map[string]Section),
+	}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid changelog cache %s: %w", c.path, err)
+	}
+	if c.Sections == nil {
+		c.Sections = make(map[string]Section)
+	}
+	return c, nil
+}
+
+// Lookup returns the cached section for a tag if its key still matches
+func (c *Cache) Lookup(tag, key string) (Section, bool) {
+	s, ok := c.Sections[tag]
+	if !ok || s.Key != key {
+		return Section{}, false
+	}
+	return s, true
+}
+
+// Put stores a section and persists the cache immediately
+func (c *Cache) Put(s Section) error {
+	c.Sections[s.Tag] = s
+	return c.Save()
+}
+
+// Save writes the cache to disk
+func (c *Cache) Save() error {
+	if readonly.Enabled() {
+		return nil // Caches aren't written in read-only mode
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Key derives a cache key from everything that influences a section's content
+func Key(previousTag, tag, model string, commits []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", previousTag, tag, model)
+	for _, c := range commits {
+		fmt.Fprintln(h, c)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Render builds the full CHANGELOG.md content, newest section first
+func Render(sections []Section) string {
+	var sb strings.Builder
+	sb.WriteString("# Changelog\n\n")
+	sb.WriteString("All notable changes to this project are documented in this file.\n")
+	for i := len(sections) - 1; i >= 0; i-- {
+		s := sections[i]
+		sb.WriteString("\n## " + s.Tag)
+		if s.Date != "" {
+			sb.WriteString(" (" + s.Date + ")")
+		}
+		sb.WriteString("\n\n")
+		sb.WriteString(strings.TrimSpace(s.Notes))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Prepend adds a section on top of an existing CHANGELOG.md, below its
+// title, or starts one. Headings in the notes move one level down to stay
+// inside the section.
+func Prepend(content string, s Section) string {
+	s.Notes = shiftHeadings(strings.TrimSpace(s.Notes), 1)
+	return insert(content, s)
+}
+
+// Add puts a section generated for the changelog, whose headings already
+// sit inside it, on top of an existing CHANGELOG.md, or starts one. A
+// section the file already has for the tag is replaced.
+func Add(content string, s Section) string {
+	var kept strings.Builder
+	in := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			title := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			in = title == s.Tag || strings.HasPrefix(title, s.Tag+" ")
+		}
+		if !in {
+			kept.WriteString(line)
+		}
+	}
+	return insert(kept.String(), s)
+}
+
+func insert(content string, s Section) string {
+	if strings.TrimSpace(content) == "" {
+		return Render([]Section{s})
+	}
+	entry := Render([]Section{s})
+	entry = entry[strings.Index(entry, "\n## ")+1:]
+
+	if i := strings.Index(content, "\n## "); i >= 0 {
+		return content[:i+1] + entry + "\n" + content[i+1:]
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + entry
+}
+
+// Extract returns the notes of a tag's section, with the headings Prepend
+// moved down put back, or "" when there is no such section
+func Extract(content, tag string) string {
+	var lines []string
+	in := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			if in {
+				break
+			}
+			title := strings.TrimPrefix(line, "## ")
+			in = title == tag || strings.HasPrefix(title, tag+" ")
+			continue
+		}
+		if in {
+			lines = append(lines, line)
+		}
+	}
+	return shiftHeadings(strings.TrimSpace(strings.Join(lines, "\n")), -1)
+}
+
+// shiftHeadings moves every markdown heading outside code blocks by levels
+func shiftHeadings(text string, levels int) string {
+	lines := strings.Split(text, "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+		}
+		hashes := len(line) - len(strings.TrimLeft(line, "#"))
+		if fenced || hashes == 0 || !strings.HasPrefix(line[hashes:], " ") {
+			continue
+		}
+		switch {
+		case levels > 0:
+			lines[i] = strings.Repeat("#", levels) + line
+		case hashes+levels >= 1:
+			lines[i] = line[-levels:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
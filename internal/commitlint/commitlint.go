@@ -0,0 +1,278 @@
+package commitlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+// ConfigFiles are the files commitlint reads its rules from, in the order
+// commitlint looks for them
+var ConfigFiles = []string{
+	".commitlintrc", ".commitlintrc.json", ".commitlintrc.yaml", ".commitlintrc.yml",
+	".commitlintrc.js", ".commitlintrc.cjs", ".commitlintrc.mjs", ".commitlintrc.ts",
+	"commitlint.config.js", "commitlint.config.cjs", "commitlint.config.mjs", "commitlint.config.ts",
+}
+
+// presets are the rules of the shareable configs most projects extend,
+// limited to the rules translate understands
+var presets = map[string]map[string][]any{
+	"@commitlint/config-conventional": {
+		"type-enum":         {"2", "always", []any{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}},
+		"header-max-length": {"2", "always", "100"},
+		"subject-case":      {"2", "never", []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+		"subject-full-stop": {"2", "never", "."},
+	},
+	"@commitlint/config-angular": {
+		"type-enum":         {"2", "always", []any{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}},
+		"header-max-length": {"2", "always", "72"},
+		"subject-case":      {"2", "always", "lower-case"},
+		"subject-full-stop": {"2", "never", "."},
+	},
+}
+
+// known are the rules that have a commit policy counterpart
+var known = map[string]bool{
+	"type-enum": true, "scope-enum": true, "header-max-length": true, "body-max-length": true,
+	"subject-case": true, "subject-full-stop": true,
+}
+
+// Rules is what commitai understood of a project's commitlint config
+type Rules struct {
+	File    string        // Config file, relative to the repository root
+	Policy  config.Policy // The rules commitlint fails on, as a commit policy
+	Skipped []string      // Extends and rules that couldn't be read
+}
+
+// Find returns the commitlint config file of the project at top, relative
+// to top, or "" if commitlint isn't configured
+func Find(top string) string {
+	for _, name := range ConfigFiles {
+		if _, err := os.Stat(filepath.Join(top, name)); err == nil {
+			return name
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(top, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg map[string]json.RawMessage
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	if _, ok := pkg["commitlint"]; ok {
+		return "package.json"
+	}
+	return ""
+}
+
+// Load reads the commitlint config of the project at top. It returns nil
+// when commitlint isn't configured.
+func Load(top string) (*Rules, error) {
+	file := Find(top)
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(top, file))
+	if err != nil {
+		return nil, err
+	}
+
+	var extends []string
+	var rules map[string]any
+	var unreadable []string
+	switch {
+	case file == "package.json":
+		var pkg struct {
+			Commitlint json.RawMessage `json:"commitlint"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		extends, rules, err = parseJSON(pkg.Commitlint)
+	case strings.HasSuffix(file, ".json"):
+		extends, rules, err = parseJSON(data)
+	case strings.HasSuffix(file, ".yaml"), strings.HasSuffix(file, ".yml"):
+		extends, rules, err = parseYAML(string(data))
+	case file == ".commitlintrc":
+		// JSON or YAML
+		if extends, rules, err = parseJSON(data); err != nil {
+			extends, rules, err = parseYAML(string(data))
+		}
+	default:
+		extends, rules, unreadable = parseJS(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	r := &Rules{File: file}
+	merged := make(map[string]any)
+	for _, name := range extends {
+		preset, ok := presets[presetName(name)]
+		if !ok {
+			r.Skipped = append(r.Skipped, "extends "+name)
+			continue
+		}
+		for rule, value := range preset {
+			merged[rule] = value
+		}
+	}
+	for rule, value := range rules {
+		merged[rule] = value
+	}
+	for _, rule := range unreadable {
+		if known[rule] {
+			delete(merged, rule)
+			r.Skipped = append(r.Skipped, rule)
+		}
+	}
+	r.Policy, r.Skipped = translate(merged, r.Skipped)
+	sort.Strings(r.Skipped)
+	return r, nil
+}
+
+// presetName expands commitlint's shorthand: "conventional" and
+// "@commitlint/conventional" both mean @commitlint/config-conventional
+func presetName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "@commitlint/config-"):
+		return name
+	case strings.HasPrefix(name, "@commitlint/"):
+		return "@commitlint/config-" + strings.TrimPrefix(name, "@commitlint/")
+	case !strings.Contains(name, "/") && !strings.HasPrefix(name, "."):
+		return "@commitlint/config-" + strings.TrimPrefix(name, "commitlint-config-")
+	}
+	return name
+}
+
+// translate turns the error-level rules with a commit policy counterpart
+// into a policy. Warnings are left out as they don't fail commitlint.
+func translate(rules map[string]any, skipped []string) (config.Policy, []string) {
+	var p config.Policy
+	for name, raw := range rules {
+		if !known[name] {
+			continue
+		}
+		rule, ok := raw.([]any)
+		if !ok || len(rule) == 0 {
+			skipped = append(skipped, name)
+			continue
+		}
+		if severity(rule[0]) < 2 {
+			continue
+		}
+		when := "always"
+		if len(rule) > 1 {
+			when, _ = rule[1].(string)
+		}
+		var value any
+		if len(rule) > 2 {
+			value = rule[2]
+		}
+
+		switch name {
+		case "type-enum", "scope-enum":
+			list, ok := stringList(value)
+			if when != "always" || !ok {
+				skipped = append(skipped, name)
+				continue
+			}
+			if name == "type-enum" {
+				p.AllowedTypes = list
+			} else {
+				p.AllowedScopes = list
+			}
+		case "header-max-length", "body-max-length":
+			n, err := strconv.Atoi(fmt.Sprint(value))
+			if when != "always" || err != nil {
+				skipped = append(skipped, name)
+				continue
+			}
+			if name == "header-max-length" {
+				p.MaxSubjectLength = n
+			} else {
+				p.MaxBodyLength = n
+			}
+		case "subject-case":
+			if p.SubjectCase = subjectCase(when, value); p.SubjectCase == "" {
+				skipped = append(skipped, name)
+			}
+		case "subject-full-stop":
+			if stop, _ := value.(string); when != "never" || (value != nil && stop != ".") {
+				skipped = append(skipped, name)
+				continue
+			}
+			p.NoTrailingPeriod = true
+		}
+	}
+	return p, skipped
+}
+
+// subjectCase reads a subject-case rule as a policy subject case: always
+// lower-case, always sentence-case, or never an upper-case start. Other
+// cases (camel-case, kebab-case...) have no counterpart.
+func subjectCase(when string, value any) string {
+	cases, ok := stringList(value)
+	if !ok {
+		s, isString := value.(string)
+		if !isString {
+			return ""
+		}
+		cases = []string{s}
+	}
+	has := func(c string) bool {
+		for _, x := range cases {
+			if x == c {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case when == "always" && has("lower-case"):
+		return config.SubjectLower
+	case when == "always" && has("sentence-case"):
+		return config.SubjectSentence
+	case when == "never" && has("sentence-case") && !has("lower-case"):
+		return config.SubjectLower
+	}
+	return ""
+}
+
+// severity reads a rule level: 0, 1, 2 or RuleConfigSeverity.Disabled,
+// .Warning, .Error
+func severity(v any) int {
+	s := fmt.Sprint(v)
+	switch {
+	case strings.HasSuffix(s, ".Error"):
+		return 2
+	case strings.HasSuffix(s, ".Warning"):
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// stringList reads a list of strings
+func stringList(v any) ([]string, bool) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		list = append(list, s)
+	}
+	return list, true
+}
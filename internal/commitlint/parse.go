@@ -0,0 +1,283 @@
+package commitlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule values are read into []any lists of strings (numbers included) and
+// nested lists, whatever the config format
+
+func parseJSON(data []byte) ([]string, map[string]any, error) {
+	var doc struct {
+		Extends any            `json:"extends"`
+		Rules   map[string]any `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	return extendsList(doc.Extends), doc.Rules, nil
+}
+
+// extendsList reads extends, a single config or a list of them
+func extendsList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+var (
+	jsExtendsRe = regexp.MustCompile(`\bextends\s*:\s*`)
+	jsRulesRe   = regexp.MustCompile(`\brules\s*:\s*\{`)
+	jsRuleRe    = regexp.MustCompile(`['"]?([a-z]+(?:-[a-z]+)+)['"]?\s*:\s*`)
+)
+
+// parseJS reads the extends and rules of a JavaScript or TypeScript config
+// without running it: rules written as array literals are read, rules built
+// by code (variables, functions) are returned as unreadable
+func parseJS(text string) (extends []string, rules map[string]any, unreadable []string) {
+	if loc := jsExtendsRe.FindStringIndex(text); loc != nil {
+		if v, _, err := parseFlow(text[loc[1]:]); err == nil {
+			extends = extendsList(v)
+		}
+	}
+
+	loc := jsRulesRe.FindStringIndex(text)
+	if loc == nil {
+		return extends, nil, nil
+	}
+	rules = make(map[string]any)
+	rest := text[loc[1]:]
+	for {
+		m := jsRuleRe.FindStringSubmatchIndex(rest)
+		if m == nil {
+			break
+		}
+		name := rest[m[2]:m[3]]
+		rest = rest[m[1]:]
+		if !strings.HasPrefix(rest, "[") {
+			unreadable = append(unreadable, name)
+			continue
+		}
+		v, n, err := parseFlow(rest)
+		if err != nil {
+			unreadable = append(unreadable, name)
+			continue
+		}
+		rules[name] = v
+		rest = rest[n:]
+	}
+	return extends, rules, unreadable
+}
+
+// parseFlow reads a JavaScript array literal or YAML flow sequence from the
+// start of s, returning the value and how many bytes it took
+func parseFlow(s string) (any, int, error) {
+	i := skipSpace(s, 0)
+	if i >= len(s) {
+		return nil, i, fmt.Errorf("unexpected end of input")
+	}
+	switch c := s[i]; c {
+	case '[':
+		list := []any{}
+		i++
+		for {
+			i = skipSpace(s, i)
+			if i >= len(s) {
+				return nil, i, fmt.Errorf("unterminated list")
+			}
+			if s[i] == ']' {
+				return list, i + 1, nil
+			}
+			v, n, err := parseFlow(s[i:])
+			if err != nil {
+				return nil, i, err
+			}
+			list = append(list, v)
+			i = skipSpace(s, i+n)
+			if i < len(s) && s[i] == ',' {
+				i++
+			}
+		}
+	case '\'', '"', '`':
+		end := strings.IndexByte(s[i+1:], c)
+		if end < 0 {
+			return nil, i, fmt.Errorf("unterminated string")
+		}
+		return s[i+1 : i+1+end], i + end + 2, nil
+	case '{', '(':
+		return nil, i, fmt.Errorf("unsupported value")
+	}
+	end := i
+	for end < len(s) && !strings.ContainsRune(",]}\n", rune(s[end])) {
+		end++
+	}
+	word := strings.TrimSpace(s[i:end])
+	if word == "" || strings.Contains(word, "(") || strings.Contains(word, "=>") {
+		return nil, i, fmt.Errorf("unsupported value %q", word)
+	}
+	return word, end, nil
+}
+
+// skipSpace skips whitespace and // comments
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(s[i])):
+			i++
+		case strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// yamlLine is a significant line of a YAML document
+type yamlLine struct {
+	indent int
+	text   string
+}
+
+// parseYAML reads the extends and rules of a YAML config. It understands
+// the block mappings and sequences and flow sequences commitlint configs
+// are written with, not YAML as a whole.
+func parseYAML(text string) ([]string, map[string]any, error) {
+	var lines []yamlLine
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{indent: len(line) - len(strings.TrimLeft(line, " ")), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil, nil
+	}
+	v, _, err := parseBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a mapping")
+	}
+	rules, _ := doc["rules"].(map[string]any)
+	return extendsList(doc["extends"]), rules, nil
+}
+
+// stripComment drops a # comment that isn't inside quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseBlock reads the mapping or sequence starting at lines[i], whose
+// entries are indented by indent, returning it and the next line to read
+func parseBlock(lines []yamlLine, i, indent int) (any, int, error) {
+	if strings.HasPrefix(lines[i].text, "-") {
+		list := []any{}
+		for i < len(lines) && lines[i].indent == indent && (lines[i].text == "-" || strings.HasPrefix(lines[i].text, "- ")) {
+			item := strings.TrimSpace(strings.TrimPrefix(lines[i].text, "-"))
+			switch {
+			case item == "":
+				if i+1 >= len(lines) || lines[i+1].indent <= indent {
+					list = append(list, "")
+					i++
+					continue
+				}
+				v, next, err := parseBlock(lines, i+1, lines[i+1].indent)
+				if err != nil {
+					return nil, next, err
+				}
+				list, i = append(list, v), next
+			case item == "-" || strings.HasPrefix(item, "- "):
+				// A nested sequence starting on the same line: "- - api"
+				lines[i] = yamlLine{indent: indent + 2, text: item}
+				v, next, err := parseBlock(lines, i, indent+2)
+				if err != nil {
+					return nil, next, err
+				}
+				list, i = append(list, v), next
+			default:
+				v, err := parseInline(item)
+				if err != nil {
+					return nil, i, err
+				}
+				list = append(list, v)
+				i++
+			}
+		}
+		return list, i, nil
+	}
+
+	m := make(map[string]any)
+	for i < len(lines) && lines[i].indent == indent {
+		key, value, ok := strings.Cut(lines[i].text, ":")
+		if !ok {
+			return nil, i, fmt.Errorf("line %q: expected key: value", lines[i].text)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `'"`)
+		value = strings.TrimSpace(value)
+		if value != "" {
+			v, err := parseInline(value)
+			if err != nil {
+				return nil, i, err
+			}
+			m[key] = v
+			i++
+			continue
+		}
+		if i+1 >= len(lines) || lines[i+1].indent < indent ||
+			(lines[i+1].indent == indent && !strings.HasPrefix(lines[i+1].text, "-")) {
+			m[key] = nil
+			i++
+			continue
+		}
+		v, next, err := parseBlock(lines, i+1, lines[i+1].indent)
+		if err != nil {
+			return nil, next, err
+		}
+		m[key], i = v, next
+	}
+	return m, i, nil
+}
+
+// parseInline reads a scalar or flow sequence on a single line
+func parseInline(s string) (any, error) {
+	if strings.HasPrefix(s, "[") {
+		v, _, err := parseFlow(s)
+		return v, err
+	}
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], nil
+	}
+	return s, nil
+}
@@ -4,59 +4,454 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/readonly"
 )
 
 const (
 	ConfigFileName = ".commitai.json"
 	EnvAPIKey      = "GEMINI_API_KEY"
+
+	// EnvLocalOnly set to 1 turns local-only mode on, like --local-only
+	EnvLocalOnly = "COMMITAI_LOCAL_ONLY"
+
+	// EnvDeterministic set to 1 turns deterministic mode on, like
+	// --deterministic; EnvSeed set to a number also picks its seed, like --seed
+	EnvDeterministic = "COMMITAI_DETERMINISTIC"
+	EnvSeed          = "COMMITAI_SEED"
+
+	// EnvAgent set to 1 turns agent mode on, like --agent, so an automation
+	// can't leave it by dropping the flag
+	EnvAgent = "COMMITAI_AGENT"
+
+	// ProviderOffline selects the built-in rule-based generator (no AI calls)
+	ProviderOffline = "offline"
+
+	// StyleAuto picks the commit style the repository's history uses
+	StyleAuto = "auto"
+
+	// Key rotation strategies for GeminiAPIKeys
+	RotationFailover   = "failover"    // Stick to one key, move on when it runs out of quota
+	RotationRoundRobin = "round-robin" // Spread calls evenly across keys
+
+	// Values of DataConsent
+	ConsentAsk     = "ask"
+	ConsentGranted = "granted"
+
+	PathsGeneralize = "generalize"
+	PathsHash       = "hash"
+
+	// What to do on a protected branch
+	ProtectRefuse = "refuse" // Stop unless --force is given
+	ProtectWarn   = "warn"   // Print a warning and continue
+
+	// How a subject description starts (Policy.SubjectCase)
+	SubjectLower    = "lower"    // add x
+	SubjectSentence = "sentence" // Add x
 )
 
 type Config struct {
 	GeminiAPIKey string `json:"gemini_api_key,omitempty"`
-	Language     string `json:"language"`
-	CommitStyle  string `json:"commit_style"` // conventional, simple
-	MaxTokens    int    `json:"max_tokens"`
-	Model        string `json:"model"`
+	// GeminiAPIKeys are additional keys used when another key runs out of
+	// quota (or in turn, with KeyRotation round-robin)
+	GeminiAPIKeys []string `json:"gemini_api_keys,omitempty"`
+	KeyRotation   string   `json:"key_rotation,omitempty"` // failover (default), round-robin
+	Language      string   `json:"language"`
+	CommitStyle   string   `json:"commit_style"` // conventional, simple, auto
+	MaxTokens     int      `json:"max_tokens"`
+	Model         string   `json:"model"`
+
+	// Provider is "" for Gemini (or ProviderCommand when set) or ProviderOffline
+	Provider string `json:"provider,omitempty"`
+
+	// GatewayURL replaces https://generativelanguage.googleapis.com, e.g. to
+	// route traffic through an internal AI gateway. Headers are added to every
+	// request; values may reference environment variables as ${NAME}.
+	GatewayURL string            `json:"gateway_url,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+
+	// ProviderCommand replaces the Gemini API with an external command (or a
+	// .wasm module run through wasmtime) that reads the prompt on stdin and
+	// prints the response
+	ProviderCommand string `json:"provider_command,omitempty"`
+
+	// DictationCommand is a speech-to-text command for say --listen: it
+	// listens on the microphone and prints what it heard
+	DictationCommand string `json:"dictation_command,omitempty"`
+
+	// MaxCost aborts any AI call whose estimated cost (USD) exceeds it; 0 disables
+	MaxCost float64 `json:"max_cost,omitempty"`
+
+	// DailyCalls and DailyTokens cap the AI calls and tokens a repository
+	// uses per day; past either, the offline generator is used. 0 disables.
+	DailyCalls  int `json:"daily_calls,omitempty"`
+	DailyTokens int `json:"daily_tokens,omitempty"`
+
+	// Deterministic generates at temperature 0 with a fixed Seed (for
+	// providers that take one), so reruns on the same input, e.g. release
+	// notes in CI, give the same text
+	Deterministic bool `json:"deterministic,omitempty"`
+	Seed          int  `json:"seed,omitempty"`
+
+	// Compress shrinks diffs in prompts: 1 trims context, 2 also collapses
+	// repeated hunks and moved blocks, 3 also drops all context; 0 disables
+	Compress int `json:"compress,omitempty"`
+
+	// RecentCommits is how many recent subjects are sent as style context
+	// (0 disables), optionally limited to commits since RecentSince (any date
+	// git accepts), by the current user, or touching the staged paths.
+	// RecentRelevant picks the commits most similar to the staged diff from
+	// a local index instead of the latest ones.
+	RecentCommits    int    `json:"recent_commits"`
+	RecentSince      string `json:"recent_since,omitempty"`
+	RecentSameAuthor bool   `json:"recent_same_author,omitempty"`
+	RecentSamePaths  bool   `json:"recent_same_paths,omitempty"`
+	RecentRelevant   bool   `json:"recent_relevant,omitempty"`
+
+	// FileHistory is how many past subjects of each modified file are sent
+	// with its diff, for consistent scopes; 0 disables
+	FileHistory int `json:"file_history,omitempty"`
+
+	// Format is the output format of every command: rich (default), plain or markdown
+	Format string `json:"format,omitempty"`
+
+	// NotifyAfter sends a desktop notification when a generation or batch run
+	// takes at least this many seconds; 0 disables
+	NotifyAfter int `json:"notify_after,omitempty"`
+
+	// ClosingKeyword (e.g. "Closes", "Fixes") is added before issue numbers
+	// detected in the branch name or message. Empty disables it.
+	ClosingKeyword string `json:"closing_keyword,omitempty"`
+
+	// Policy holds content rules enforced on messages before committing
+	Policy Policy `json:"policy"`
+
+	// PolicyURL points to an organization policy bundle (https URL,
+	// git+<repo>#<path>, or local file) merged over the local policy.
+	PolicyURL string `json:"policy_url,omitempty"`
+	// PolicyPublicKey is the base64 ed25519 key bundles must be signed with
+	PolicyPublicKey string `json:"policy_public_key,omitempty"`
+
+	// Webhooks maps an announcement platform (slack, discord, teams) to its incoming webhook URL
+	Webhooks map[string]string `json:"webhooks,omitempty"`
+
+	// Forges overrides forge detection per remote host, for GitHub
+	// Enterprise Server, self-hosted GitLab and hosts behind SSH aliases
+	Forges map[string]Forge `json:"forges,omitempty"`
+
+	// JiraURL is the Jira site commitai --comment-issues comments on for
+	// issue keys (PROJ-123) in the branch name or message
+	JiraURL string `json:"jira_url,omitempty"`
+
+	// ProvenanceTrailers appends AI-Generated-By / AI-Edited trailers to commits
+	ProvenanceTrailers bool `json:"provenance_trailers,omitempty"`
+
+	// PrivacyMode guarantees no tool-identifying metadata in commits, no
+	// stray files and no network calls besides the AI provider.
+	PrivacyMode bool `json:"privacy_mode,omitempty"`
+
+	// SquashMerge keeps the squash commit draft of the branch (commitai squash)
+	// up to date after every commit, for forges that squash-merge
+	SquashMerge bool `json:"squash_merge,omitempty"`
+
+	// Anonymize replaces string literals, emails, hostnames, IP addresses and
+	// URLs in diffs with placeholders before they are sent; the mapping is
+	// kept in .git/commitai/anonymize.json and answers are restored with it
+	Anonymize bool `json:"anonymize,omitempty"`
+
+	// AnonymizePaths also hides file paths from the provider: generalize
+	// keeps the top-level directory and the extension, hash replaces the
+	// rest with a hash. Commits still use the real paths.
+	AnonymizePaths string `json:"anonymize_paths,omitempty"`
+
+	// DataConsent is how sending repository data to the AI provider is
+	// agreed to: ask, once per repository (default), or granted, when the
+	// user already approved the provider. Only read from the user config;
+	// organizations grant it with Policy.DataConsent in a signed bundle.
+	DataConsent string `json:"data_consent,omitempty"`
+
+	// LocalOnly refuses any AI provider but the offline one or a gateway on
+	// localhost, and every other network call. A repository's .commitai.json
+	// can turn it on for everyone; nothing turns it off again.
+	LocalOnly bool `json:"local_only,omitempty"`
+
+	// LocalOnlyLocked is set when the repository config turned LocalOnly on
+	LocalOnlyLocked bool `json:"-"`
+
+	// Commitlint runs the project's commitlint on generated messages before
+	// committing, so its rules are enforced like the commit policy
+	Commitlint bool `json:"commitlint,omitempty"`
+
+	// ImpactLine ends generated commit messages and pull request descriptions
+	// with an "Impact: <area>; <risk> risk" line for reviewers to triage
+	ImpactLine bool `json:"impact_line,omitempty"`
+
+	// ConfidenceCritique rates generated messages with a second AI call
+	// when the provider returns no token probabilities to rate them by
+	ConfidenceCritique bool `json:"confidence_critique,omitempty"`
+
+	// TypeEmojis maps Conventional Commits types to the emoji put on their
+	// subjects after generation (feat: ✨); TypeEmojiPosition is start (the
+	// default, before the type) or description (after the colon)
+	TypeEmojis        map[string]string `json:"type_emojis,omitempty"`
+	TypeEmojiPosition string            `json:"type_emoji_position,omitempty"`
+
+	// LintThreshold is the score (0-100) below which ci lint-commits fails
+	// a commit; 0 means the default of 60
+	LintThreshold int `json:"lint_threshold,omitempty"`
+
+	// NoCommitTemplate ignores the repository's commit.template
+	NoCommitTemplate bool `json:"no_commit_template,omitempty"`
+	// CommitTemplate is the commit.template generated messages fill in, and
+	// CommentChar starts its guidance lines; set at runtime only
+	CommitTemplate string `json:"-"`
+	CommentChar    string `json:"-"`
+
+	// RepoScopes are the scopes the history uses most, with the directories
+	// they cover, suggested in prompts; set at runtime only
+	RepoScopes []string `json:"-"`
+
+	// NoBranchContext keeps the branch name out of prompts
+	NoBranchContext bool `json:"no_branch_context,omitempty"`
+	// Branch is the current branch, described in prompts; set at runtime only
+	Branch string `json:"-"`
+
+	// ProtectedBranches are branch name patterns (e.g. main, release/*) where
+	// commitai refuses, or with ProtectedBranchMode warn only warns, to
+	// commit or tag directly
+	ProtectedBranches   []string `json:"protected_branches,omitempty"`
+	ProtectedBranchMode string   `json:"protected_branch_mode,omitempty"` // refuse (default), warn
+
+	// BannedPaths are path patterns (e.g. *.pem, .env, terraform.tfstate)
+	// commitai never commits, see BannedPattern
+	BannedPaths []string `json:"banned_paths,omitempty"`
+
+	// Hooks maps a release hook point (pre_release, post_tag, post_push) to a
+	// shell command run with COMMITAI_* environment variables
+	Hooks map[string]string `json:"hooks,omitempty"`
+
+	// TagPattern is the glob (e.g. app-v*) release tags match, for
+	// repositories that also tag deploys or infrastructure; new versions
+	// are tagged with its start up to the first wildcard
+	TagPattern string `json:"tag_pattern,omitempty"`
+
+	// EditReleaseNotes opens generated release notes in the editor before
+	// the tag is created
+	EditReleaseNotes bool `json:"edit_release_notes,omitempty"`
+
+	// ReleaseStats appends a stats section counted by git (commits, files,
+	// lines, contributors) to release notes
+	ReleaseStats bool `json:"release_stats,omitempty"`
+
+	// Release announcement email headers
+	EmailFrom string   `json:"email_from,omitempty"`
+	EmailTo   []string `json:"email_to,omitempty"`
+}
+
+// Forge configures the API of the forge hosting a remote
+type Forge struct {
+	Type               string `json:"type,omitempty"`                 // github, gitlab
+	APIURL             string `json:"api_url,omitempty"`              // e.g. https://ghe.example.com/api/v3
+	TokenEnv           string `json:"token_env,omitempty"`            // Env var holding the token
+	CAFile             string `json:"ca_file,omitempty"`              // PEM bundle trusted besides the system roots
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // Don't verify the TLS certificate
+}
+
+// Policy describes content rules a commit message must satisfy
+type Policy struct {
+	ForbiddenWords   []string `json:"forbidden_words,omitempty"`   // Case-insensitive whole words, e.g. "wip"
+	RequiredPatterns []string `json:"required_patterns,omitempty"` // Regexes that must all match the message
+	MaxSubjectLength int      `json:"max_subject_length,omitempty"`
+	MaxBodyLength    int      `json:"max_body_length,omitempty"`
+	AllowedTypes     []string `json:"allowed_types,omitempty"`  // Conventional Commits types, e.g. feat, fix
+	AllowedScopes    []string `json:"allowed_scopes,omitempty"` // Empty allows any scope
+	TicketPattern    string   `json:"ticket_pattern,omitempty"` // Regex a ticket reference must match, e.g. [A-Z]+-\d+
+	Language         string   `json:"language,omitempty"`       // Forces the message language
+
+	// Subject style, checked and fixed after generation
+	Imperative       bool   `json:"imperative,omitempty"`         // "add x", not "added x" or "adds x"
+	SubjectCase      string `json:"subject_case,omitempty"`       // lower or sentence: how the description starts
+	NoTrailingPeriod bool   `json:"no_trailing_period,omitempty"` // Subject doesn't end with a period
+
+	// BodySections are the sections every body has, in order, each under a
+	// "Name:" line, e.g. Summary, Details, Testing Done
+	BodySections []string `json:"body_sections,omitempty"`
+
+	// DataConsent set to granted in a signed organization bundle skips the
+	// data sharing question; anywhere else it is ignored
+	DataConsent string `json:"data_consent,omitempty"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		Language:    "en",
-		CommitStyle: "conventional",
-		MaxTokens:   1024,
-		Model:       "gemini-2.5-flash",
+		Language:      "en",
+		CommitStyle:   "conventional",
+		MaxTokens:     1024,
+		Model:         "gemini-2.5-flash",
+		RecentCommits: 5,
 	}
 }
 
+// Load returns the effective config: defaults, the user config in the home
+// directory, the repository's own .commitai.json, then env overrides.
 func Load() (*Config, error) {
-	cfg := DefaultConfig()
+	cfg, err := loadUser()
+	if err != nil {
+		return nil, err
+	}
+
+	// Repo config lets a project pin settings for everyone working on it
+	if path := RepoConfigPath(); path != "" {
+		provider, gateway, headers, forges, jira, localOnly := cfg.ProviderCommand, cfg.GatewayURL, cfg.Headers, cfg.Forges, cfg.JiraURL, cfg.LocalOnly
+		dictation, hooks, webhooks := cfg.DictationCommand, cfg.Hooks, cfg.Webhooks
+		policyURL, policyKey := cfg.PolicyURL, cfg.PolicyPublicKey
+		commitlint := cfg.Commitlint
+		privacy := cfg.PrivacyMode
+		consent := cfg.DataConsent
+		banned := cfg.BannedPaths
+		cfg.LocalOnly = false
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+		// A cloned repo must not be able to run arbitrary commands on every
+		// commit or release, nor send diffs, keys (or forge tokens) and
+		// release notes to a server of its choosing
+		cfg.ProviderCommand, cfg.GatewayURL, cfg.Headers, cfg.Forges, cfg.JiraURL = provider, gateway, headers, forges, jira
+		cfg.DictationCommand, cfg.Hooks, cfg.Webhooks = dictation, hooks, webhooks
+		// Nor swap the organization policy (or the key it is checked with)
+		// for one of its own
+		cfg.PolicyURL, cfg.PolicyPublicKey = policyURL, policyKey
+		// Running the project's commitlint runs its node_modules, so only the
+		// user can turn it on
+		cfg.Commitlint = commitlint
+		// Nor decide on its own that its data may be sent; an organization
+		// grants that in its signed policy bundle
+		cfg.DataConsent = consent
+		// It can enforce local-only mode, but not lift the user's
+		cfg.LocalOnlyLocked = cfg.LocalOnly
+		cfg.LocalOnly = cfg.LocalOnly || localOnly
+		// Same for privacy mode
+		cfg.PrivacyMode = cfg.PrivacyMode || privacy
+		// Nor lift the user's banned paths, only add to them
+		for _, p := range banned {
+			if !slices.Contains(cfg.BannedPaths, p) {
+				cfg.BannedPaths = append(cfg.BannedPaths, p)
+			}
+		}
+	}
 
-	// Try home dir config
-	home, err := os.UserHomeDir()
-	if err == nil {
-		path := filepath.Join(home, ConfigFileName)
-		if data, err := os.ReadFile(path); err == nil {
-			if err := json.Unmarshal(data, cfg); err != nil {
-				return nil, fmt.Errorf("invalid config file: %w", err)
+	applyEnv(cfg)
+	return cfg, nil
+}
+
+// LoadUser returns the config from the home directory only, without
+// repository overrides. Used when editing the user config.
+func LoadUser() (*Config, error) {
+	cfg, err := loadUser()
+	if err != nil {
+		return nil, err
+	}
+	applyEnv(cfg)
+	return cfg, nil
+}
+
+// RepoConfigPath returns the repository config file if the current
+// directory is inside a git (or jj, Sapling or Mercurial) repo that has
+// one, or "".
+func RepoConfigPath() string {
+	root := ""
+	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		root = strings.TrimSpace(string(out))
+	} else if root = vcsRoot(); root == "" {
+		return ""
+	}
+	path := filepath.Join(root, ConfigFileName)
+	if path == UserConfigPath() {
+		return "" // Repo rooted at $HOME, same file as the legacy user config
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// vcsRoot returns the root of the jj, Sapling or Mercurial repository the
+// current directory is in, for repositories without a git working tree, or ""
+func vcsRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, marker := range []string{".jj", ".sl", ".hg"} {
+			if info, err := os.Stat(filepath.Join(dir, marker)); err == nil && info.IsDir() {
+				return dir
 			}
 		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func loadUser() (*Config, error) {
+	cfg := DefaultConfig()
+	if path := UserConfigPath(); path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
 	}
+	return cfg, nil
+}
 
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil // Missing files are fine
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) {
 	// Env var overrides config file
 	if key := os.Getenv(EnvAPIKey); key != "" {
 		cfg.GeminiAPIKey = key
 	}
-
-	return cfg, nil
+	if os.Getenv(EnvLocalOnly) == "1" {
+		cfg.LocalOnly = true
+	}
+	if os.Getenv(EnvDeterministic) == "1" {
+		cfg.Deterministic = true
+	}
+	if seed, err := strconv.Atoi(os.Getenv(EnvSeed)); err == nil {
+		cfg.Deterministic, cfg.Seed = true, seed
+	}
 }
 
+// Save writes the user config to SavePath, moving it out of
+// ~/.commitai.json on the first save
 func Save(cfg *Config) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	if err := readonly.Check("saving the config"); err != nil {
 		return err
 	}
+	path, legacy := SavePath(), UsesLegacyConfig()
+	if path == "" {
+		return fmt.Errorf("no home directory to keep the config in; set %s", EnvConfig)
+	}
 
 	// Never save API key to disk if it came from env
 	saveCfg := *cfg
@@ -69,11 +464,108 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(home, ConfigFileName), data, 0600)
+	// The file holds the API key: keep it private even if it already existed
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return err
+	}
+	if legacy {
+		return os.Remove(LegacyConfigPath())
+	}
+	return nil
+}
+
+// ProtectedPattern returns the pattern protecting branch, if any. Patterns
+// use shell glob syntax, where * doesn't match "/".
+func (c *Config) ProtectedPattern(branch string) (string, bool) {
+	for _, p := range c.ProtectedBranches {
+		if ok, _ := path.Match(p, branch); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// BannedPattern returns the banned_paths pattern matching file, a path
+// from the repository root, if any. Patterns use shell glob syntax: one
+// without a "/" matches the file name in any directory (*.pem, .env), one
+// with a "/" the whole path (deploy/*.tfvars), and one ending in "/" a
+// directory and everything in it (secrets/).
+func (c *Config) BannedPattern(file string) (string, bool) {
+	for _, p := range c.BannedPaths {
+		var ok bool
+		switch dir := strings.TrimSuffix(p, "/"); {
+		case dir != p && strings.Contains(dir, "/"):
+			ok = strings.HasPrefix(file, p)
+		case dir != p:
+			ok = strings.HasPrefix(file, p) || strings.Contains(file, "/"+p)
+		case strings.Contains(p, "/"):
+			ok, _ = path.Match(p, file)
+		default:
+			ok, _ = path.Match(p, path.Base(file))
+		}
+		if ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// APIKeys returns every configured Gemini key, the primary one first
+func (c *Config) APIKeys() []string {
+	var keys []string
+	for _, k := range append([]string{c.GeminiAPIKey}, c.GeminiAPIKeys...) {
+		if k == "" {
+			continue
+		}
+		dup := false
+		for _, seen := range keys {
+			dup = dup || seen == k
+		}
+		if !dup {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// CheckLocalOnly returns an error unless the configured provider keeps
+// everything on this machine: the offline provider, or a gateway on
+// localhost. Provider commands can't be verified and are refused.
+func (c *Config) CheckLocalOnly() error {
+	switch {
+	case c.Provider == ProviderOffline:
+		return nil
+	case c.ProviderCommand != "":
+		return fmt.Errorf("local-only mode: the provider command %q can't be verified to stay on this machine; use a gateway on localhost (commitai config --gateway-url http://localhost:PORT) or the offline provider", c.ProviderCommand)
+	case c.GatewayURL == "":
+		return errors.New("local-only mode: the Gemini API is remote; use a gateway on localhost (commitai config --gateway-url http://localhost:PORT) or the offline provider (commitai config --provider offline)")
+	}
+	u, err := url.Parse(c.GatewayURL)
+	if err != nil || !IsLocalHost(u.Hostname()) {
+		return fmt.Errorf("local-only mode: the gateway %s is not on localhost", c.GatewayURL)
+	}
+	return nil
+}
+
+// IsLocalHost reports whether host names this machine (localhost or a
+// loopback address)
+func IsLocalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 func (c *Config) Validate() error {
-	if c.GeminiAPIKey == "" {
+	// A gateway may authenticate with its own headers instead of a key
+	if len(c.APIKeys()) == 0 && c.ProviderCommand == "" && c.Provider != ProviderOffline && c.GatewayURL == "" {
 		return errors.New("Gemini API key not set. Run: commitai config --key YOUR_KEY or set GEMINI_API_KEY env var")
 	}
 	return nil
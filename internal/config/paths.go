@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvConfig names a user config file to use instead of the default one
+const EnvConfig = "COMMITAI_CONFIG"
+
+// appDir is commitai's directory in the config and cache base directories
+const appDir = "commitai"
+
+// UserConfigPath returns the user config file: $COMMITAI_CONFIG if set,
+// otherwise commitai/config.json in the config base directory. The legacy
+// ~/.commitai.json is returned instead while it exists and the new file
+// doesn't; Save moves it.
+func UserConfigPath() string {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+	path := defaultConfigPath()
+	if legacy := LegacyConfigPath(); legacy != "" && !exists(path) && exists(legacy) {
+		return legacy
+	}
+	return path
+}
+
+// LegacyConfigPath returns ~/.commitai.json, where the user config was kept
+// before it moved to the config base directory
+func LegacyConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ConfigFileName)
+}
+
+// UsesLegacyConfig reports whether the user config is still read from
+// ~/.commitai.json
+func UsesLegacyConfig() bool {
+	path := UserConfigPath()
+	return path != "" && path == LegacyConfigPath() && os.Getenv(EnvConfig) == ""
+}
+
+// SavePath returns the file Save writes: the user config file, or its new
+// place when it is still ~/.commitai.json
+func SavePath() string {
+	if UsesLegacyConfig() {
+		return defaultConfigPath()
+	}
+	return UserConfigPath()
+}
+
+// CacheDir returns commitai's cache directory: commitai in $XDG_CACHE_HOME,
+// or in the platform's cache directory (~/.cache on Linux)
+func CacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDir), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDir), nil
+}
+
+// defaultConfigPath is commitai/config.json in $XDG_CONFIG_HOME, or in the
+// platform's config directory (~/.config on Linux), or "" without a home
+func defaultConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDir, "config.json")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, appDir, "config.json")
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaID identifies the JSON Schema of the config file
+const SchemaID = "https://github.com/kaiqui/commitai/schema/commitai.json"
+
+// schemaDocs describes each setting, by its path in the file ("policy.x"
+// for nested objects, "forges.x" for the values of maps)
+var schemaDocs = map[string]string{
+	"gemini_api_key":        "Gemini API key",
+	"gemini_api_keys":       "Additional Gemini API keys, used when another key runs out of quota or in turn",
+	"key_rotation":          "How to use several keys",
+	"language":              "Language of generated messages, e.g. en, pt-br",
+	"commit_style":          "Commit message style; auto follows the repository's history",
+	"max_tokens":            "Maximum output tokens per AI call",
+	"model":                 "Gemini model",
+	"provider":              "Message provider: empty for Gemini (or provider_command), offline for rule-based messages",
+	"gateway_url":           "Base URL replacing the public Gemini API, e.g. an internal AI gateway",
+	"headers":               "Extra HTTP headers for Gemini requests; values may reference ${VAR}",
+	"provider_command":      "Command (or .wasm module) that reads the prompt on stdin and prints the response",
+	"dictation_command":     "Speech-to-text command for say --listen that listens on the microphone and prints what it heard",
+	"max_cost":              "Abort AI calls estimated to cost more than this in USD; 0 disables",
+	"daily_calls":           "AI calls per repository per day before falling back to the offline generator; 0 disables",
+	"daily_tokens":          "AI tokens per repository per day before falling back to the offline generator; 0 disables",
+	"deterministic":         "Generate at temperature 0 with a fixed seed, so reruns on the same input give the same text",
+	"seed":                  "Sampling seed of deterministic mode, for providers that take one",
+	"compress":              "Prompt compression: 0 off, 1 trim context, 2 also collapse repeated hunks and moved code, 3 no context",
+	"recent_commits":        "Recent commit subjects sent as style context; 0 disables",
+	"recent_since":          "Only use recent commits since this date, e.g. \"2 weeks ago\"",
+	"recent_same_author":    "Only use your own recent commits as context",
+	"recent_same_paths":     "Only use recent commits touching the staged files as context",
+	"recent_relevant":       "Use the past commits most similar to the staged diff (local index in .git/commitai) instead of the latest",
+	"file_history":          "Past subjects of each modified file sent with its diff; 0 disables",
+	"format":                "Output format of every command",
+	"notify_after":          "Desktop notification when generation or batch takes at least this many seconds; 0 disables",
+	"closing_keyword":       "Keyword added before issues detected in the branch name, e.g. Closes; empty disables",
+	"policy":                "Content rules enforced on messages before committing",
+	"policy_url":            "Organization policy bundle: https URL, git+<repo>#<path> or file",
+	"policy_public_key":     "Base64 ed25519 public key policy bundles must be signed with",
+	"webhooks":              "Release announcement webhook URL per platform",
+	"forges":                "Forge API settings per remote host",
+	"jira_url":              "Jira site commented on for issue keys in the branch name or message, with --comment-issues",
+	"provenance_trailers":   "Add AI-Generated-By / AI-Edited trailers to commits",
+	"privacy_mode":          "No tool-identifying metadata in commits, no stray files, no extra network calls",
+	"squash_merge":          "Keep the squash commit draft of the branch updated after every commit",
+	"anonymize":             "Replace string literals, emails, hostnames and URLs in diffs with placeholders before sending",
+	"local_only":            "Refuse providers and calls that leave this machine",
+	"anonymize_paths":       "Hide file paths from the AI provider: generalize keeps the top-level directory and extension, hash hashes the rest",
+	"data_consent":          "Ask once per repository before sending its data to the AI provider, or granted when you approved it (user config only)",
+	"commitlint":            "Check generated messages with the project's commitlint before committing",
+	"impact_line":           "End generated commit messages and pull request descriptions with an Impact: <area>; <risk> risk line",
+	"confidence_critique":   "Rate generated messages with a second AI call when the provider returns no token probabilities",
+	"lint_threshold":        "Quality score (0-100) below which ci lint-commits fails a commit; 0 means 60",
+	"type_emojis":           "Emoji put on the subject of generated messages per Conventional Commits type, e.g. {\"feat\": \"✨\"}",
+	"type_emoji_position":   "Where the type emoji goes: start (✨ feat: x) or description (feat: ✨ x)",
+	"no_commit_template":    "Ignore the repository's git commit.template",
+	"no_branch_context":     "Keep the branch name out of prompts",
+	"protected_branches":    "Branch patterns (e.g. main, release/*) where commitai won't commit or tag without --force",
+	"protected_branch_mode": "What to do on a protected branch",
+	"banned_paths":          "Path patterns (e.g. *.pem, .env, secrets/) commitai refuses to commit; a repository can add to them but not remove them",
+	"hooks":                 "Shell command per release hook point",
+	"tag_pattern":           "Glob release tags match, e.g. app-v*; new versions are tagged with its start up to the first wildcard",
+	"edit_release_notes":    "Open generated release notes in the editor before the tag is created",
+	"release_stats":         "Append a stats section counted by git (commits, files, lines, contributors) to release notes",
+	"email_from":            "Sender address of release emails",
+	"email_to":              "Recipients of release emails",
+
+	"policy.forbidden_words":    "Case-insensitive whole words messages must not contain, e.g. wip",
+	"policy.required_patterns":  "Regexes that must all match the message",
+	"policy.max_subject_length": "Maximum subject line length",
+	"policy.max_body_length":    "Maximum body length",
+	"policy.allowed_types":      "Allowed Conventional Commits types, e.g. feat, fix",
+	"policy.allowed_scopes":     "Allowed scopes; empty allows any",
+	"policy.ticket_pattern":     "Regex a ticket reference must match, e.g. [A-Z]+-\\d+",
+	"policy.language":           "Forces the message language",
+	"policy.imperative":         "Subjects in the imperative mood (add, not added or adds); fixed automatically",
+	"policy.subject_case":       "How the subject description starts: lower (add x) or sentence (Add x); fixed automatically",
+	"policy.no_trailing_period": "Subjects don't end with a period; fixed automatically",
+	"policy.body_sections":      "Sections every body has, in order, each under a \"Name:\" line, e.g. [\"Summary\", \"Details\", \"Testing Done\"]; laid out automatically",
+	"policy.data_consent":       "granted: the organization approved its provider, so nobody is asked before data is sent (only honoured in a signed policy bundle)",
+
+	"forges.type":                 "Forge type",
+	"forges.api_url":              "API base URL, e.g. https://ghe.example.com/api/v3",
+	"forges.token_env":            "Environment variable holding the token",
+	"forges.ca_file":              "PEM bundle trusted besides the system roots",
+	"forges.insecure_skip_verify": "Don't verify the TLS certificate",
+}
+
+// schemaEnums lists the accepted values of settings that have a fixed set
+var schemaEnums = map[string][]string{
+	"key_rotation":          {RotationFailover, RotationRoundRobin},
+	"commit_style":          {"conventional", "simple", StyleAuto},
+	"provider":              {"", ProviderOffline},
+	"format":                {"rich", "plain", "markdown"},
+	"protected_branch_mode": {ProtectRefuse, ProtectWarn},
+	"anonymize_paths":       {PathsGeneralize, PathsHash},
+	"data_consent":          {ConsentAsk, ConsentGranted},
+	"policy.subject_case":   {"", SubjectLower, SubjectSentence},
+	"policy.data_consent":   {"", ConsentGranted},
+	"type_emoji_position":   {"", "start", "description"},
+	"forges.type":           {"github", "gitlab", "bitbucket", "bitbucket-server", "gitea", "forgejo", "azure"},
+}
+
+// schemaKeys lists the accepted keys of settings that are maps
+var schemaKeys = map[string][]string{
+	"webhooks": {"slack", "discord", "teams"},
+	"hooks":    {"pre_release", "post_tag", "post_push"},
+}
+
+// Schema returns the JSON Schema (draft 2020-12) of the config file, for
+// editors to complete and validate it. Unknown keys are flagged, so typos
+// show up before commitai silently ignores them.
+func Schema() map[string]any {
+	s := objectSchema(reflect.TypeOf(Config{}), "")
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = SchemaID
+	s["title"] = "commitai configuration (~/.config/commitai/config.json or the repository's .commitai.json)"
+	props := s["properties"].(map[string]any)
+	props["$schema"] = map[string]any{"type": "string"}
+	return s
+}
+
+func objectSchema(t reflect.Type, prefix string) map[string]any {
+	props := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := prefix + name
+		prop := typeSchema(f.Type, path)
+		if doc, ok := schemaDocs[path]; ok {
+			prop["description"] = doc
+		}
+		if values, ok := schemaEnums[path]; ok {
+			prop["enum"] = values
+		}
+		props[name] = prop
+	}
+	return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+}
+
+func typeSchema(t reflect.Type, path string) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "minimum": 0}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), path)}
+	case reflect.Struct:
+		return objectSchema(t, path+".")
+	case reflect.Map:
+		s := map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), path)}
+		if keys, ok := schemaKeys[path]; ok {
+			s["propertyNames"] = map[string]any{"enum": keys}
+		}
+		return s
+	}
+	return map[string]any{}
+}
@@ -0,0 +1,239 @@
+package conventions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/commitlint"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/policy"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the cached analysis inside the .git directory
+const FileName = "commitai/conventions.json"
+
+// version is bumped when the analysis changes, so older caches are redone
+const version = 1
+
+// sample is the number of recent non-merge commits analyzed
+const sample = 200
+
+// Thresholds of the style detection
+const (
+	minCommits        = 5   // Fewer commits say nothing about the style
+	conventionalShare = 0.5 // Share of Conventional Commits subjects for that style
+	minScopeUses      = 2   // Uses for a scope to count as a convention
+	maxScopes         = 10
+	minDirShare       = 0.3 // Share of a scope's files for a directory to be listed
+	maxDirsPerScope   = 2
+	dirDepth          = 2 // Path segments kept of a directory, e.g. internal/api
+)
+
+// Scope is a scope the history uses, with the directories it is used for
+type Scope struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Dirs  []string `json:"dirs,omitempty"`
+}
+
+// String returns the scope with its directories, e.g. "api (internal/api)"
+func (s Scope) String() string {
+	if len(s.Dirs) == 0 {
+		return s.Name
+	}
+	return s.Name + " (" + strings.Join(s.Dirs, ", ") + ")"
+}
+
+// Analysis is what the history says about the repository's commit
+// conventions. It is cached per HEAD and conventions files: a new commit or
+// an edited commitlint config or .commitai.json redoes it.
+type Analysis struct {
+	Version      int       `json:"version"`
+	Head         string    `json:"head"`
+	FilesHash    string    `json:"files_hash"`
+	Analyzed     time.Time `json:"analyzed"`
+	Commits      int       `json:"commits"`      // Subjects analyzed
+	Conventional float64   `json:"conventional"` // Share in Conventional Commits form
+	Style        string    `json:"style"`        // conventional, simple, or "" with too few commits
+	Scopes       []Scope   `json:"scopes"`       // Most used first
+
+	Cached bool `json:"-"` // Read from the cache rather than computed
+}
+
+// ScopeList returns the scopes as "name (dirs)" strings
+func (a *Analysis) ScopeList() []string {
+	list := make([]string, len(a.Scopes))
+	for i, s := range a.Scopes {
+		list[i] = s.String()
+	}
+	return list
+}
+
+// Load returns the analysis of the repository at top, from the cache in
+// gitDir when it is still valid. save is false in privacy mode, which
+// computes it in memory every time.
+func Load(gitDir, top string, save bool) (*Analysis, error) {
+	head, err := git.HeadCommit()
+	if err != nil {
+		return &Analysis{Version: version}, nil // No history yet
+	}
+	hash := filesHash(top)
+
+	file := filepath.Join(gitDir, FileName)
+	if data, err := os.ReadFile(file); err == nil {
+		var cached Analysis
+		if json.Unmarshal(data, &cached) == nil && cached.Version == version && cached.Head == head && cached.FilesHash == hash {
+			cached.Cached = true
+			return &cached, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	a, err := analyze(head)
+	if err != nil {
+		return nil, err
+	}
+	a.FilesHash = hash
+	if !save || readonly.Enabled() {
+		return a, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache the conventions analysis: %w", err)
+	}
+	return a, nil
+}
+
+// filesHash fingerprints the files that state the repository's conventions
+func filesHash(top string) string {
+	h := sha256.New()
+	names := []string{config.ConfigFileName}
+	if lint := commitlint.Find(top); lint != "" {
+		names = append(names, lint)
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(top, name))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// analyze reads the style and scopes from the latest commits up to head
+func analyze(head string) (*Analysis, error) {
+	hashes, err := git.RevList(head, sample)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := git.StatsOf(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Analysis{Version: version, Head: head, Analyzed: time.Now(), Commits: len(commits), Scopes: []Scope{}}
+	conventional := 0
+	uses := make(map[string]int)
+	dirs := make(map[string]map[string]int)
+	for _, c := range commits {
+		_, scope, ok := policy.ParseHeader(c.Subject)
+		if !ok {
+			continue
+		}
+		conventional++
+		for _, sc := range strings.Split(scope, ",") {
+			sc = strings.TrimSpace(sc)
+			if sc == "" {
+				continue
+			}
+			uses[sc]++
+			if dirs[sc] == nil {
+				dirs[sc] = make(map[string]int)
+			}
+			for _, f := range c.Files {
+				dirs[sc][topDir(f.Path)]++
+			}
+		}
+	}
+
+	if len(commits) > 0 {
+		a.Conventional = float64(conventional) / float64(len(commits))
+	}
+	if len(commits) >= minCommits {
+		a.Style = "simple"
+		if a.Conventional >= conventionalShare {
+			a.Style = "conventional"
+		}
+	}
+
+	for name, n := range uses {
+		if n >= minScopeUses {
+			a.Scopes = append(a.Scopes, Scope{Name: name, Count: n, Dirs: mainDirs(dirs[name], name)})
+		}
+	}
+	sort.Slice(a.Scopes, func(i, j int) bool {
+		if a.Scopes[i].Count != a.Scopes[j].Count {
+			return a.Scopes[i].Count > a.Scopes[j].Count
+		}
+		return a.Scopes[i].Name < a.Scopes[j].Name
+	})
+	if len(a.Scopes) > maxScopes {
+		a.Scopes = a.Scopes[:maxScopes]
+	}
+	return a, nil
+}
+
+// topDir returns the first dirDepth segments of the directory of p, "." for
+// files at the root
+func topDir(p string) string {
+	segments := strings.Split(path.Dir(p), "/")
+	if len(segments) > dirDepth {
+		segments = segments[:dirDepth]
+	}
+	return strings.Join(segments, "/")
+}
+
+// mainDirs returns the directories holding a good share of a scope's
+// files, most first, leaving out one named like the scope
+func mainDirs(counts map[string]int, scope string) []string {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	var list []string
+	for dir, n := range counts {
+		if dir != "." && dir != scope && float64(n) >= minDirShare*float64(total) {
+			list = append(list, dir)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if counts[list[i]] != counts[list[j]] {
+			return counts[list[i]] > counts[list[j]]
+		}
+		return list[i] < list[j]
+	})
+	if len(list) > maxDirsPerScope {
+		list = list[:maxDirsPerScope]
+	}
+	return list
+}
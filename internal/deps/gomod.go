@@ -0,0 +1,180 @@
+package deps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Module is a required module and its version
+type Module struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// Change describes how one module differs between two go.mod files
+type Change struct {
+	Path       string
+	OldVersion string // Empty when added
+	NewVersion string // Empty when removed
+	Indirect   bool
+}
+
+// Diff groups dependency changes by kind
+type Diff struct {
+	Added    []Change
+	Removed  []Change
+	Upgraded []Change
+	Changed  []Change // Downgrades and non-semver version switches
+}
+
+// Empty reports whether there are no dependency changes
+func (d Diff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Upgraded)+len(d.Changed) == 0
+}
+
+// ParseGoMod extracts the require directives of a go.mod file
+func ParseGoMod(content string) map[string]Module {
+	mods := make(map[string]Module)
+	inBlock := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		indirect := strings.Contains(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mods[fields[0]] = Module{Path: fields[0], Version: fields[1], Indirect: indirect}
+	}
+	return mods
+}
+
+// DiffGoMod compares two go.mod files. oldContent may be empty (no go.mod before).
+func DiffGoMod(oldContent, newContent string) Diff {
+	return diffModules(ParseGoMod(oldContent), ParseGoMod(newContent))
+}
+
+// diffModules classifies the differences between two sets of modules
+func diffModules(oldMods, newMods map[string]Module) Diff {
+	var d Diff
+	for path, n := range newMods {
+		o, ok := oldMods[path]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, Change{Path: path, NewVersion: n.Version, Indirect: n.Indirect})
+		case o.Version == n.Version:
+			continue
+		case compareVersions(o.Version, n.Version) < 0:
+			d.Upgraded = append(d.Upgraded, Change{Path: path, OldVersion: o.Version, NewVersion: n.Version, Indirect: n.Indirect})
+		default:
+			d.Changed = append(d.Changed, Change{Path: path, OldVersion: o.Version, NewVersion: n.Version, Indirect: n.Indirect})
+		}
+	}
+	for path, o := range oldMods {
+		if _, ok := newMods[path]; !ok {
+			d.Removed = append(d.Removed, Change{Path: path, OldVersion: o.Version, Indirect: o.Indirect})
+		}
+	}
+
+	for _, list := range [][]Change{d.Added, d.Removed, d.Upgraded, d.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	return d
+}
+
+// Markdown renders the diff as a release notes section
+func (d Diff) Markdown() string {
+	if d.Empty() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 📦 Dependency changes\n")
+
+	write := func(title string, changes []Change, format func(Change) string) {
+		if len(changes) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n**%s**\n", title))
+		for _, c := range changes {
+			line := "- " + format(c)
+			if c.Indirect {
+				line += " _(indirect)_"
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	write("Added", d.Added, func(c Change) string {
+		return fmt.Sprintf("`%s` %s", c.Path, c.NewVersion)
+	})
+	write("Upgraded", d.Upgraded, func(c Change) string {
+		return fmt.Sprintf("`%s` %s → %s", c.Path, c.OldVersion, c.NewVersion)
+	})
+	write("Changed", d.Changed, func(c Change) string {
+		return fmt.Sprintf("`%s` %s → %s", c.Path, c.OldVersion, c.NewVersion)
+	})
+	write("Removed", d.Removed, func(c Change) string {
+		return fmt.Sprintf("`%s` %s", c.Path, c.OldVersion)
+	})
+	return sb.String()
+}
+
+// compareVersions compares two Go module versions (vMAJOR.MINOR.PATCH[-pre]).
+// Pre-release and pseudo-versions sort before the release they precede.
+func compareVersions(a, b string) int {
+	an, apre := splitVersion(a)
+	bn, bpre := splitVersion(b)
+	for i := 0; i < 3; i++ {
+		if an[i] != bn[i] {
+			if an[i] < bn[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	case apre < bpre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func splitVersion(v string) ([3]int, string) {
+	v = strings.TrimLeft(v, "v^~<>= ") // Ranges of package.json compare by their base version
+	v = strings.TrimSuffix(v, "+incompatible")
+	pre := ""
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	var n [3]int
+	for i, part := range strings.SplitN(v, ".", 3) {
+		fmt.Sscanf(part, "%d", &n[i])
+	}
+	return n, pre
+}
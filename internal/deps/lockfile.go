@@ -0,0 +1,148 @@
+package deps
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// lockfiles maps lockfile names to the manifests they pin, found in the
+// same directory
+var lockfiles = map[string][]string{
+	"go.sum":              {"go.mod"},
+	"go.work.sum":         {"go.work"},
+	"package-lock.json":   {"package.json"},
+	"npm-shrinkwrap.json": {"package.json"},
+	"yarn.lock":           {"package.json"},
+	"pnpm-lock.yaml":      {"package.json"},
+	"bun.lockb":           {"package.json"},
+	"bun.lock":            {"package.json"},
+	"Cargo.lock":          {"Cargo.toml"},
+	"poetry.lock":         {"pyproject.toml"},
+	"uv.lock":             {"pyproject.toml"},
+	"pdm.lock":            {"pyproject.toml"},
+	"Pipfile.lock":        {"Pipfile"},
+	"Gemfile.lock":        {"Gemfile", "gems.rb"},
+	"composer.lock":       {"composer.json"},
+	"mix.lock":            {"mix.exs"},
+	"pubspec.lock":        {"pubspec.yaml"},
+	"Package.resolved":    {"Package.swift"},
+	"flake.lock":          {"flake.nix"},
+	"packages.lock.json":  {"packages.config"},
+}
+
+// PairLockfiles records on each staged manifest the staged lockfiles that
+// pin it (go.sum for go.mod, package-lock.json for package.json...), so
+// they are committed together, and returns the paired lockfile paths
+func PairLockfiles(changes []git.FileChange) map[string]bool {
+	index := make(map[string]int, len(changes))
+	for i, c := range changes {
+		index[c.Path] = i
+	}
+	paired := make(map[string]bool)
+	for _, c := range changes {
+		dir, name := path.Split(c.Path)
+		for _, manifest := range lockfiles[name] {
+			if i, ok := index[dir+manifest]; ok {
+				changes[i].Lockfiles = append(changes[i].Lockfiles, c.Path)
+				paired[c.Path] = true
+				break
+			}
+		}
+	}
+	return paired
+}
+
+var (
+	goModRequireRe   = regexp.MustCompile(`^(?:require\s+)?([^\s/()]+\.[^\s()]*)\s+(v[^\s]+)`)
+	packageJSONDepRe = regexp.MustCompile(`^"(@?[^"\s]+)":\s*"((?:[~^<>=]*\s*\d|npm:|workspace:|file:|git|https?:)[^"]*)",?$`)
+)
+
+// FromDiff returns the dependency changes a manifest's diff makes, read from
+// its changed lines; only go.mod and package.json are understood
+func FromDiff(manifest, diff string) Diff {
+	var match func(line string) (string, string, bool)
+	switch path.Base(manifest) {
+	case "go.mod":
+		match = func(line string) (string, string, bool) {
+			m := goModRequireRe.FindStringSubmatch(line)
+			if m == nil {
+				return "", "", false
+			}
+			return m[1], m[2], true
+		}
+	case "package.json":
+		match = func(line string) (string, string, bool) {
+			m := packageJSONDepRe.FindStringSubmatch(line)
+			if m == nil || m[1] == "version" || m[1] == "node" || m[1] == "npm" {
+				return "", "", false // The package's own version, or engines
+			}
+			return m[1], m[2], true
+		}
+	default:
+		return Diff{}
+	}
+
+	oldMods, newMods := make(map[string]Module), make(map[string]Module)
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || line == "" {
+			continue
+		}
+		var mods map[string]Module
+		switch line[0] {
+		case '+':
+			mods = newMods
+		case '-':
+			mods = oldMods
+		default:
+			continue
+		}
+		text := strings.TrimSpace(line[1:])
+		if name, version, ok := match(text); ok {
+			mods[name] = Module{Path: name, Version: version, Indirect: strings.Contains(text, "// indirect")}
+		}
+	}
+	return diffModules(oldMods, newMods)
+}
+
+// Summary lists the changes on one line, e.g. "upgraded x v1.0.0 -> v1.1.0;
+// added y v2.0.0"
+func (d Diff) Summary() string {
+	var parts []string
+	for _, c := range d.Upgraded {
+		parts = append(parts, fmt.Sprintf("upgraded %s %s -> %s", c.Path, c.OldVersion, c.NewVersion))
+	}
+	for _, c := range d.Changed {
+		parts = append(parts, fmt.Sprintf("changed %s %s -> %s", c.Path, c.OldVersion, c.NewVersion))
+	}
+	for _, c := range d.Added {
+		parts = append(parts, fmt.Sprintf("added %s %s", c.Path, c.NewVersion))
+	}
+	for _, c := range d.Removed {
+		parts = append(parts, "removed "+c.Path)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Headline describes the changes as a commit subject would, e.g. "bump x
+// from v1.0.0 to v1.1.0" or "update 3 dependencies"
+func (d Diff) Headline() string {
+	switch {
+	case len(d.Added)+len(d.Removed)+len(d.Upgraded)+len(d.Changed) > 1:
+		return fmt.Sprintf("update %d dependencies", len(d.Added)+len(d.Removed)+len(d.Upgraded)+len(d.Changed))
+	case len(d.Upgraded) == 1:
+		c := d.Upgraded[0]
+		return fmt.Sprintf("bump %s from %s to %s", c.Path, c.OldVersion, c.NewVersion)
+	case len(d.Changed) == 1:
+		c := d.Changed[0]
+		return fmt.Sprintf("change %s from %s to %s", c.Path, c.OldVersion, c.NewVersion)
+	case len(d.Added) == 1:
+		return "add " + d.Added[0].Path
+	case len(d.Removed) == 1:
+		return "remove " + d.Removed[0].Path
+	}
+	return ""
+}
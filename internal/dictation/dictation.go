@@ -0,0 +1,105 @@
+// Package dictation captures a spoken description of a change, through a
+// speech-to-text command or by recording the microphone for the provider
+// to transcribe.
+package dictation
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Listen runs a speech-to-text command and returns what it printed. The
+// command gets the terminal's stdin and stderr, so it can show its own
+// prompts and be stopped with a key.
+func Listen(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, &stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("dictation command failed: %w", err)
+	}
+	text := strings.Join(strings.Fields(stdout.String()), " ")
+	if text == "" {
+		return "", fmt.Errorf("the dictation command heard nothing")
+	}
+	return text, nil
+}
+
+// Recorder records the default microphone with a command line tool
+type Recorder struct {
+	Name string
+	args func(path string) []string
+}
+
+// recorders are tried in order; each writes 16 kHz mono WAV, which is
+// plenty for speech and keeps recordings small
+var recorders = []Recorder{
+	{"rec", func(path string) []string { // sox, on every platform
+		return []string{"-q", "-c", "1", "-r", "16000", path}
+	}},
+	{"arecord", func(path string) []string { // ALSA, on Linux
+		return []string{"-q", "-f", "S16_LE", "-c", "1", "-r", "16000", path}
+	}},
+	{"ffmpeg", func(path string) []string { // AVFoundation, on macOS
+		return []string{"-loglevel", "error", "-y", "-f", "avfoundation", "-i", ":0", "-ac", "1", "-ar", "16000", path}
+	}},
+}
+
+// FindRecorder returns the first recorder installed
+func FindRecorder() (*Recorder, error) {
+	for _, r := range recorders {
+		if r.Name == "arecord" && runtime.GOOS != "linux" || r.Name == "ffmpeg" && runtime.GOOS != "darwin" {
+			continue
+		}
+		if _, err := exec.LookPath(r.Name); err == nil {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("no audio recorder found: install sox (rec) or alsa-utils (arecord), set dictation_command, or pass a recording with --audio")
+}
+
+// stopTimeout is how long a recorder gets to finish the file once stopped
+const stopTimeout = 5 * time.Second
+
+// Record records to path (a .wav file) until stop is closed
+func (r *Recorder) Record(path string, stop <-chan struct{}) error {
+	cmd := exec.Command(r.Name, r.args(path)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", r.Name, err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		// Recorders only exit by themselves when something is wrong
+		return fmt.Errorf("%s stopped recording: %s", r.Name, strings.TrimSpace(stderr.String()+" "+fmt.Sprint(err)))
+	case <-stop:
+	}
+	// Interrupted, recorders write the WAV header before exiting
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		cmd.Process.Kill()
+	}
+	select {
+	case <-done:
+	case <-time.After(stopTimeout):
+		cmd.Process.Kill()
+		return fmt.Errorf("%s didn't stop recording", r.Name)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		return fmt.Errorf("%s recorded nothing: %s", r.Name, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package embedding
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the index inside the .git directory
+const FileName = "commitai/embeddings.json"
+
+// Dims is the length of the vectors
+const Dims = 256
+
+// MaxCommits bounds the index to the newest commits reachable from HEAD
+const MaxCommits = 5000
+
+// statsBatch bounds the commits read per git log call while indexing
+const statsBatch = 500
+
+// Entry is an indexed commit
+type Entry struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Vector  string    `json:"vector"` // Base64 of Dims int8 components
+}
+
+// Index maps the commits reachable from Head to vectors of their subject
+// and the paths they changed, newest first. The vectors are hashed bags of
+// words: no model is involved, so indexing and lookups stay on this machine.
+type Index struct {
+	path    string
+	Head    string  `json:"head"`
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the index from the given .git directory. A missing file yields
+// an empty index.
+func Load(gitDir string) (*Index, error) {
+	ix := &Index{path: filepath.Join(gitDir, FileName)}
+	data, err := os.ReadFile(ix.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("invalid embedding index %s: %w", ix.path, err)
+	}
+	return ix, nil
+}
+
+// Save writes the index back to disk
+func (ix *Index) Save() error {
+	if readonly.Enabled() {
+		return nil // Caches aren't written in read-only mode
+	}
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ix.path, data, 0644)
+}
+
+// Update indexes the commits reachable from head that aren't indexed yet
+// and drops the ones no longer reachable. It returns how many commits were
+// added; the index only needs saving when Head changed.
+func (ix *Index) Update(head string) (int, error) {
+	if head == ix.Head {
+		return 0, nil
+	}
+	hashes, err := git.RevList(head, MaxCommits)
+	if err != nil {
+		return 0, err
+	}
+	known := make(map[string]Entry, len(ix.Entries))
+	for _, e := range ix.Entries {
+		known[e.Hash] = e
+	}
+	var missing []string
+	for _, h := range hashes {
+		if _, ok := known[h]; !ok {
+			missing = append(missing, h)
+		}
+	}
+	for start := 0; start < len(missing); start += statsBatch {
+		commits, err := git.StatsOf(missing[start:min(start+statsBatch, len(missing))])
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range commits {
+			paths := make([]string, len(c.Files))
+			for i, f := range c.Files {
+				paths[i] = f.Path
+			}
+			known[c.Hash] = Entry{Hash: c.Hash, Subject: c.Subject, Email: c.Email, Date: c.Date, Vector: encode(Embed(c.Subject, paths, nil))}
+		}
+	}
+
+	ix.Entries = ix.Entries[:0]
+	for _, h := range hashes {
+		if e, ok := known[h]; ok {
+			ix.Entries = append(ix.Entries, e)
+		}
+	}
+	ix.Head = head
+	return len(missing), nil
+}
+
+// Nearest returns up to n entries accepted by keep, most similar to query
+// first; equally similar entries keep their history order
+func (ix *Index) Nearest(query []float32, n int, keep func(Entry) bool) []Entry {
+	type scored struct {
+		entry Entry
+		score float32
+	}
+	var candidates []scored
+	for _, e := range ix.Entries {
+		if keep != nil && !keep(e) {
+			continue
+		}
+		candidates = append(candidates, scored{e, dot(query, decode(e.Vector))})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	var nearest []Entry
+	for _, c := range candidates[:min(n, len(candidates))] {
+		nearest = append(nearest, c.entry)
+	}
+	return nearest
+}
+
+var (
+	wordRe       = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_]*`)
+	identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{2,}`)
+	camelRe      = regexp.MustCompile(`[A-Z]?[a-z0-9]+|[A-Z]+(?:$|[^a-z])`)
+	headerRe     = regexp.MustCompile(`^\w+(?:\(([^)]*)\))?!?:`)
+)
+
+// stopWords are too common in messages and code to tell commits apart
+var stopWords = make(map[string]bool)
+
+func init() {
+	for _, w := range strings.Fields(`the and for with from into that this when was are not but all its out now via
+		use add added remove removed update updated change changes fix fixed new make
+		func return err nil var const int string bool true false null none self def end
+		import package type struct class public private static void`) {
+		stopWords[w] = true
+	}
+}
+
+// Embed returns the unit vector of a commit: the words of its subject
+// (the scope of a Conventional Commits header, not its type), the paths it
+// changed and the identifiers on its changed lines
+func Embed(subject string, paths []string, lines []string) []float32 {
+	weights := make(map[string]float64)
+	add := func(word string, weight float64) {
+		for _, w := range append([]string{word}, camelRe.FindAllString(word, -1)...) {
+			for _, part := range strings.Split(strings.ToLower(w), "_") {
+				if len(part) > 2 && !stopWords[part] {
+					weights[part] += weight
+				}
+			}
+		}
+	}
+
+	if m := headerRe.FindStringSubmatch(subject); m != nil {
+		subject = m[1] + " " + subject[len(m[0]):]
+	}
+	for _, w := range wordRe.FindAllString(subject, -1) {
+		add(w, 1)
+	}
+	for _, p := range paths {
+		for _, dir := range strings.Split(path.Dir(p), "/") {
+			if dir != "." {
+				add(dir, 1)
+			}
+		}
+		add(strings.TrimSuffix(path.Base(p), path.Ext(p)), 1)
+	}
+	for _, l := range lines {
+		for _, id := range identifierRe.FindAllString(l, -1) {
+			add(id, 0.5)
+		}
+	}
+
+	v := make([]float32, Dims)
+	for word, weight := range weights {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		sum := h.Sum32()
+		sign := float32(1)
+		if sum&(1<<31) != 0 {
+			sign = -1
+		}
+		v[sum%Dims] += sign * float32(1+math.Log(weight)) // Repeats count less and less
+	}
+	return normalize(v)
+}
+
+// EmbedChanges returns the vector of staged changes, to look up commits like
+// them
+func EmbedChanges(changes []git.FileChange) []float32 {
+	var paths, lines []string
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+		for _, l := range strings.Split(c.Diff, "\n") {
+			if (strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "+++")) || (strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "---")) {
+				lines = append(lines, l[1:])
+			}
+		}
+	}
+	return Embed("", paths, lines)
+}
+
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return v
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range v {
+		v[i] *= scale
+	}
+	return v
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		if i < len(b) {
+			sum += a[i] * b[i]
+		}
+	}
+	return sum
+}
+
+// encode quantizes a unit vector to int8 components
+func encode(v []float32) string {
+	b := make([]byte, len(v))
+	for i, x := range v {
+		b[i] = byte(int8(math.Round(float64(x) * 127)))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func decode(s string) []float32 {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	v := make([]float32, len(b))
+	for i, x := range b {
+		v[i] = float32(int8(x)) / 127
+	}
+	return v
+}
@@ -0,0 +1,80 @@
+package forge
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxAzureDescription is the longest pull request description Azure DevOps accepts
+const maxAzureDescription = 4000
+
+// azureLocate splits an Azure Repos remote path into the organization (or
+// collection) URL, project and repository:
+//
+//	dev.azure.com/org/project/_git/repo
+//	ssh.dev.azure.com:v3/org/project/repo
+//	org.visualstudio.com/project/_git/repo
+//	tfs.example.com/tfs/Collection/project/_git/repo (Azure DevOps Server)
+func (f *Forge) azureLocate(web string) error {
+	parts := strings.Split(f.Repo, "/")
+	var org, project, repo string
+	for i, p := range parts {
+		if p == "_git" && i > 0 && i+1 < len(parts) {
+			org = strings.Join(append([]string{web}, parts[:i-1]...), "/")
+			project, repo = parts[i-1], parts[i+1]
+		}
+	}
+	if repo == "" && len(parts) == 4 && parts[0] == "v3" {
+		org, project, repo = "https://dev.azure.com/"+parts[1], parts[2], parts[3]
+	}
+	if repo == "" {
+		return fmt.Errorf("can't tell the Azure DevOps organization, project and repository from %q", f.Repo)
+	}
+
+	f.Repo = project + "/" + repo
+	f.APIURL = org
+	f.WebURL = org + "/" + url.PathEscape(project) + "/_git/" + url.PathEscape(repo)
+	return nil
+}
+
+// azureHeader authenticates with a personal access token (or a pipeline's
+// System.AccessToken) as the password of an empty user
+func (f *Forge) azureHeader() http.Header {
+	h := http.Header{}
+	h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(":"+f.Token)))
+	return h
+}
+
+func (f *Forge) azurePullRequest(pr PullRequest) (string, error) {
+	project, repo, _ := strings.Cut(f.Repo, "/")
+	body := pr.Body
+	if len(body) > maxAzureDescription {
+		body = strings.ToValidUTF8(body[:maxAzureDescription-1], "") + "…"
+	}
+	in := map[string]any{
+		"sourceRefName": "refs/heads/" + pr.Head,
+		"targetRefName": "refs/heads/" + pr.Base,
+		"title":         pr.Title,
+		"description":   body,
+		"isDraft":       pr.Draft,
+	}
+	var refs []map[string]string
+	for _, id := range pr.WorkItems {
+		refs = append(refs, map[string]string{"id": id})
+	}
+	if len(refs) > 0 {
+		in["workItemRefs"] = refs
+	}
+
+	var out struct {
+		PullRequestID int `json:"pullRequestId"`
+	}
+	path := "/" + url.PathEscape(project) + "/_apis/git/repositories/" + url.PathEscape(repo) + "/pullrequests?api-version=7.0"
+	if err := f.do("POST", path, f.azureHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/pullrequest/%d", f.WebURL, out.PullRequestID), nil
+}
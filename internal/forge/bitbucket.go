@@ -0,0 +1,125 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// bitbucketHeader authenticates with an access token, or with an app
+// password given as user:password
+func (f *Forge) bitbucketHeader() http.Header {
+	h := http.Header{}
+	if strings.Contains(f.Token, ":") {
+		h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(f.Token)))
+	} else {
+		h.Set("Authorization", "Bearer "+f.Token)
+	}
+	return h
+}
+
+func (f *Forge) bitbucketPullRequest(pr PullRequest) (string, error) {
+	in := map[string]any{
+		"title":       pr.Title,
+		"description": pr.Body,
+		"source":      map[string]any{"branch": map[string]string{"name": pr.Head}},
+		"destination": map[string]any{"branch": map[string]string{"name": pr.Base}},
+		"draft":       pr.Draft,
+	}
+	var out struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	if err := f.do("POST", "/repositories/"+f.Repo+"/pullrequests", f.bitbucketHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.Links.HTML.Href, nil
+}
+
+// bitbucketRelease follows the Bitbucket Cloud convention for releases,
+// which it has none of: the tag carries the notes and RELEASE-<tag>.md is
+// uploaded to the repository's Downloads
+func (f *Forge) bitbucketRelease(rel Release) (string, error) {
+	name := bitbucketReleaseFile(rel)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("files", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write([]byte(rel.Notes)); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	if err := f.send("POST", "/repositories/"+f.Repo+"/downloads", form.FormDataContentType(), f.bitbucketHeader(), &body, nil); err != nil {
+		return "", err
+	}
+	return f.WebURL + "/downloads/" + url.PathEscape(name), nil
+}
+
+func bitbucketReleaseFile(rel Release) string {
+	return "RELEASE-" + rel.Tag + ".md"
+}
+
+// bitbucketServerRepo returns the API path of the repository. Remote paths
+// are scm/PROJECT/repo over https and PROJECT/repo over ssh.
+func (f *Forge) bitbucketServerRepo() (string, error) {
+	project, slug, ok := strings.Cut(strings.TrimPrefix(f.Repo, "scm/"), "/")
+	if !ok || strings.Contains(slug, "/") {
+		return "", fmt.Errorf("can't tell the Bitbucket project and repository from %q", f.Repo)
+	}
+	return "/projects/" + project + "/repos/" + slug, nil
+}
+
+func (f *Forge) bitbucketServerPullRequest(pr PullRequest) (string, error) {
+	repo, err := f.bitbucketServerRepo()
+	if err != nil {
+		return "", err
+	}
+	in := map[string]any{
+		"title":       pr.Title,
+		"description": pr.Body,
+		"fromRef":     map[string]string{"id": "refs/heads/" + pr.Head},
+		"toRef":       map[string]string{"id": "refs/heads/" + pr.Base},
+		"draft":       pr.Draft,
+	}
+	var out struct {
+		Links struct {
+			Self []struct {
+				Href string `json:"href"`
+			} `json:"self"`
+		} `json:"links"`
+	}
+	if err := f.do("POST", repo+"/pull-requests", f.bitbucketHeader(), in, &out); err != nil {
+		return "", err
+	}
+	if len(out.Links.Self) == 0 {
+		return "", nil
+	}
+	return out.Links.Self[0].Href, nil
+}
+
+// bitbucketServerRelease checks the pushed tag is there: Bitbucket Server has
+// no release or download storage, the annotated tag carries the notes
+func (f *Forge) bitbucketServerRelease(rel Release) (string, error) {
+	repo, err := f.bitbucketServerRepo()
+	if err != nil {
+		return "", err
+	}
+	if err := f.do("GET", repo+"/tags/"+url.PathEscape(rel.Tag), f.bitbucketHeader(), nil, nil); err != nil {
+		return "", err
+	}
+	web := strings.TrimSuffix(f.WebURL, "/"+f.Repo)
+	return web + repo + "/browse?at=" + url.QueryEscape("refs/tags/"+rel.Tag), nil
+}
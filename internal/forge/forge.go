@@ -0,0 +1,425 @@
+package forge
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+// Supported forge types
+const (
+	GitHub          = "github"
+	GitLab          = "gitlab"
+	Bitbucket       = "bitbucket"        // Bitbucket Cloud (bitbucket.org)
+	BitbucketServer = "bitbucket-server" // Bitbucket Server and Data Center
+	Gitea           = "gitea"
+	Forgejo         = "forgejo" // Gitea's API, e.g. Codeberg
+	Azure           = "azure"   // Azure DevOps Services and Server
+)
+
+// Types lists every supported forge type
+var Types = []string{GitHub, GitLab, Bitbucket, BitbucketServer, Gitea, Forgejo, Azure}
+
+// Supported reports whether kind is a known forge type
+func Supported(kind string) bool {
+	for _, t := range Types {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Forge is the API of the forge hosting a repository
+type Forge struct {
+	Type    string
+	Host    string // Remote host, as written in the git remote
+	Repo    string // owner/name, or group/subgroup/name on GitLab
+	WebURL  string // e.g. https://github.com/owner/name
+	APIURL  string // e.g. https://api.github.com
+	Token   string
+	TokenIn string // Env var the token is read from
+
+	client *http.Client
+}
+
+// Release is a release created on a forge
+type Release struct {
+	Tag   string
+	Name  string
+	Notes string
+}
+
+// Call is an API request, as shown by release previews
+type Call struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// PullRequest is a pull (or merge) request opened on a forge
+type PullRequest struct {
+	Title string
+	Body  string
+	Head  string // Branch with the changes
+	Base  string // Branch to merge into
+	Draft bool
+
+	WorkItems []string // Azure Boards work items to link
+}
+
+// Detect works out the forge of a git remote URL. github.com, gitlab.com,
+// bitbucket.org, codeberg.org, dev.azure.com and visualstudio.com are
+// known; other hosts with github, gitlab,
+// bitbucket, gitea or forgejo in their name are taken for GitHub Enterprise
+// Server, self-hosted GitLab, Bitbucket Server, Gitea and Forgejo. cfg.Forges overrides the type, API URL, token variable
+// and TLS options per host.
+func Detect(cfg *config.Config, remote string) (*Forge, error) {
+	scheme, host, repo, err := ParseRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	settings := cfg.Forges[host]
+	hostname := strings.Split(host, ":")[0] // SSH ports don't serve the API
+
+	kind := settings.Type
+	if kind == "" {
+		kind = guessType(hostname)
+	}
+	if kind == "" {
+		return nil, fmt.Errorf("can't tell which forge %s is. Run: commitai config --forge %s=TYPE (%s)", host, host, strings.Join(Types, ", "))
+	}
+	if !Supported(kind) {
+		return nil, fmt.Errorf("unsupported forge type %q for %s (supported: %s)", kind, host, strings.Join(Types, ", "))
+	}
+
+	web := scheme + "://" + host
+	if scheme != "http" && scheme != "https" {
+		web = "https://" + hostname // ssh:// and git:// remotes
+	}
+
+	f := &Forge{Type: kind, Host: host, Repo: repo, WebURL: web + "/" + repo}
+	if kind == Azure {
+		if err := f.azureLocate(web); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case settings.APIURL != "":
+		f.APIURL = strings.TrimSuffix(settings.APIURL, "/")
+	case f.APIURL == "":
+		f.APIURL = defaultAPIURL(kind, hostname, web)
+	}
+
+	f.TokenIn = settings.TokenEnv
+	if f.TokenIn == "" {
+		f.TokenIn = tokenEnv(kind, hostname)
+	}
+	f.Token = os.Getenv(f.TokenIn)
+
+	if f.client, err = httpClient(settings); err != nil {
+		return nil, fmt.Errorf("forge %s: %w", host, err)
+	}
+	return f, nil
+}
+
+// ParseRemote splits a git remote URL (https, ssh:// or scp-like
+// git@host:path) into its scheme, host and repository path
+func ParseRemote(remote string) (scheme, host, repo string, err error) {
+	r := strings.TrimSpace(remote)
+	if r == "" {
+		return "", "", "", fmt.Errorf("no remote URL")
+	}
+	if !strings.Contains(r, "://") {
+		// scp-like: [user@]host:owner/name
+		hostPart, path, ok := strings.Cut(r, ":")
+		if !ok {
+			return "", "", "", fmt.Errorf("can't parse remote %q", remote)
+		}
+		if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+			hostPart = hostPart[at+1:]
+		}
+		r = "ssh://" + hostPart + "/" + path
+	}
+
+	u, err := url.Parse(r)
+	if err != nil || u.Host == "" {
+		return "", "", "", fmt.Errorf("can't parse remote %q", remote)
+	}
+	scheme = u.Scheme
+	if scheme == "git+ssh" || scheme == "ssh+git" {
+		scheme = "ssh"
+	}
+	repo = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if repo == "" {
+		return "", "", "", fmt.Errorf("remote %q has no repository path", remote)
+	}
+	return scheme, u.Host, repo, nil
+}
+
+func guessType(hostname string) string {
+	h := strings.ToLower(hostname)
+	switch {
+	case strings.Contains(h, "github"):
+		return GitHub
+	case strings.Contains(h, "gitlab"):
+		return GitLab
+	case h == "bitbucket.org":
+		return Bitbucket
+	case strings.Contains(h, "bitbucket"):
+		return BitbucketServer
+	case strings.Contains(h, "gitea"):
+		return Gitea
+	case strings.Contains(h, "forgejo"), h == "codeberg.org":
+		return Forgejo
+	case strings.HasSuffix(h, "dev.azure.com"), strings.HasSuffix(h, ".visualstudio.com"):
+		return Azure
+	}
+	return ""
+}
+
+func defaultAPIURL(kind, hostname, web string) string {
+	switch kind {
+	case GitHub:
+		if strings.EqualFold(hostname, "github.com") {
+			return "https://api.github.com"
+		}
+		return web + "/api/v3" // GitHub Enterprise Server
+	case GitLab:
+		return web + "/api/v4"
+	case Bitbucket:
+		return "https://api.bitbucket.org/2.0"
+	case BitbucketServer:
+		return web + "/rest/api/1.0"
+	case Gitea, Forgejo:
+		return web + "/api/v1"
+	}
+	return ""
+}
+
+// tokenEnv names the conventional token variable of a forge
+func tokenEnv(kind, hostname string) string {
+	switch {
+	case kind == GitHub && strings.EqualFold(hostname, "github.com"):
+		return "GITHUB_TOKEN"
+	case kind == GitHub:
+		return "GH_ENTERPRISE_TOKEN"
+	case kind == GitLab:
+		return "GITLAB_TOKEN"
+	case kind == Bitbucket || kind == BitbucketServer:
+		return "BITBUCKET_TOKEN"
+	case kind == Gitea || kind == Forgejo:
+		return "GITEA_TOKEN"
+	case kind == Azure:
+		return "AZURE_DEVOPS_EXT_PAT"
+	}
+	return ""
+}
+
+// httpClient honors the TLS options of a forge: an extra CA bundle for
+// internal certificate authorities, or no verification at all
+func httpClient(settings config.Forge) (*http.Client, error) {
+	if settings.CAFile == "" && !settings.InsecureSkipVerify {
+		return &http.Client{Timeout: 30 * time.Second}, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", settings.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// CheckAllowed returns an error when cfg forbids talking to the forge:
+// privacy mode allows no network calls besides the AI provider, and
+// local-only mode only a forge on this machine
+func (f *Forge) CheckAllowed(cfg *config.Config) error {
+	return checkAllowed(cfg, "forge", f.APIURL)
+}
+
+func checkAllowed(cfg *config.Config, kind, apiURL string) error {
+	if cfg.PrivacyMode {
+		return fmt.Errorf("privacy mode forbids %s API calls (no network calls besides the AI provider)", kind)
+	}
+	if cfg.LocalOnly {
+		u, err := url.Parse(apiURL)
+		if err != nil || !config.IsLocalHost(u.Hostname()) {
+			return fmt.Errorf("local-only mode forbids calls to %s (nothing leaves this machine)", apiURL)
+		}
+	}
+	return nil
+}
+
+// HasReleases reports whether releases can be published on the forge.
+// Azure DevOps keeps releases in pipelines, not in the repository.
+func (f *Forge) HasReleases() bool {
+	return f.Type != Azure
+}
+
+// CreateRelease publishes a release for an existing, pushed tag and returns
+// its web URL
+func (f *Forge) CreateRelease(rel Release) (string, error) {
+	if f.Token == "" {
+		return "", fmt.Errorf("no %s token: set %s", f.Type, f.TokenIn)
+	}
+	switch f.Type {
+	case GitHub:
+		return f.githubRelease(rel)
+	case GitLab:
+		return f.gitlabRelease(rel)
+	case Bitbucket:
+		return f.bitbucketRelease(rel)
+	case BitbucketServer:
+		return f.bitbucketServerRelease(rel)
+	case Gitea, Forgejo:
+		return f.giteaRelease(rel)
+	}
+	return "", fmt.Errorf("releases are not supported on %s", f.Type)
+}
+
+// ReleaseCall returns the request CreateRelease would send for rel, without
+// sending it. Uploads show the file name instead of the multipart body.
+func (f *Forge) ReleaseCall(rel Release) (Call, error) {
+	switch f.Type {
+	case GitHub, Gitea, Forgejo:
+		return Call{"POST", f.APIURL + "/repos/" + f.Repo + "/releases", githubReleaseBody(rel)}, nil
+	case GitLab:
+		return Call{"POST", f.APIURL + f.gitlabProject() + "/releases", gitlabReleaseBody(rel)}, nil
+	case Bitbucket:
+		body := map[string]any{"files": bitbucketReleaseFile(rel)}
+		return Call{"POST", f.APIURL + "/repositories/" + f.Repo + "/downloads", body}, nil
+	case BitbucketServer:
+		repo, err := f.bitbucketServerRepo()
+		if err != nil {
+			return Call{}, err
+		}
+		return Call{"GET", f.APIURL + repo + "/tags/" + url.PathEscape(rel.Tag), nil}, nil
+	}
+	return Call{}, fmt.Errorf("releases are not supported on %s", f.Type)
+}
+
+// UpdateRelease replaces the notes of the release of a tag and returns its
+// web URL. Where the notes only live in the tag or a download, those are
+// published again.
+func (f *Forge) UpdateRelease(rel Release) (string, error) {
+	if f.Token == "" {
+		return "", fmt.Errorf("no %s token: set %s", f.Type, f.TokenIn)
+	}
+	switch f.Type {
+	case GitHub:
+		return f.githubUpdateRelease(rel)
+	case GitLab:
+		return f.gitlabUpdateRelease(rel)
+	case Bitbucket:
+		return f.bitbucketRelease(rel)
+	case BitbucketServer:
+		return f.bitbucketServerRelease(rel)
+	case Gitea, Forgejo:
+		return f.giteaUpdateRelease(rel)
+	}
+	return "", fmt.Errorf("releases are not supported on %s", f.Type)
+}
+
+// CreatePullRequest opens a pull request for a pushed branch and returns
+// its web URL
+func (f *Forge) CreatePullRequest(pr PullRequest) (string, error) {
+	if f.Token == "" {
+		return "", fmt.Errorf("no %s token: set %s", f.Type, f.TokenIn)
+	}
+	switch f.Type {
+	case GitHub:
+		return f.githubPullRequest(pr)
+	case GitLab:
+		return f.gitlabMergeRequest(pr)
+	case Bitbucket:
+		return f.bitbucketPullRequest(pr)
+	case BitbucketServer:
+		return f.bitbucketServerPullRequest(pr)
+	case Gitea, Forgejo:
+		return f.giteaPullRequest(pr)
+	case Azure:
+		return f.azurePullRequest(pr)
+	}
+	return "", fmt.Errorf("pull requests are not supported on %s", f.Type)
+}
+
+// CommentOnIssue posts a comment on an issue of the repository, by number
+func (f *Forge) CommentOnIssue(issue, body string) error {
+	if f.Token == "" {
+		return fmt.Errorf("no %s token: set %s", f.Type, f.TokenIn)
+	}
+	switch f.Type {
+	case GitHub:
+		return f.do("POST", "/repos/"+f.Repo+"/issues/"+url.PathEscape(issue)+"/comments", f.githubHeader(), map[string]any{"body": body}, nil)
+	case GitLab:
+		return f.do("POST", f.gitlabProject()+"/issues/"+url.PathEscape(issue)+"/notes", f.gitlabHeader(), map[string]any{"body": body}, nil)
+	case Gitea, Forgejo:
+		return f.do("POST", "/repos/"+f.Repo+"/issues/"+url.PathEscape(issue)+"/comments", f.giteaHeader(), map[string]any{"body": body}, nil)
+	}
+	return fmt.Errorf("issue comments are not supported on %s", f.Type)
+}
+
+// do sends a JSON request to the forge API and decodes the answer into out
+func (f *Forge) do(method, path string, header http.Header, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	return f.send(method, path, "application/json", header, body, out)
+}
+
+// send is do for bodies that aren't JSON, e.g. file uploads
+func (f *Forge) send(method, path, contentType string, header http.Header, body io.Reader, out any) error {
+	req, err := http.NewRequest(method, f.APIURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s API request failed: %w", f.Type, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s API returned %s: %s", f.Type, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
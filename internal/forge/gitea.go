@@ -0,0 +1,62 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Gitea and Forgejo share the same API
+
+func (f *Forge) giteaHeader() http.Header {
+	h := http.Header{}
+	h.Set("Authorization", "token "+f.Token)
+	return h
+}
+
+func (f *Forge) giteaRelease(rel Release) (string, error) {
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.do("POST", "/repos/"+f.Repo+"/releases", f.giteaHeader(), githubReleaseBody(rel), &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
+
+func (f *Forge) giteaUpdateRelease(rel Release) (string, error) {
+	var found struct {
+		ID int64 `json:"id"`
+	}
+	if err := f.do("GET", "/repos/"+f.Repo+"/releases/tags/"+url.PathEscape(rel.Tag), f.giteaHeader(), nil, &found); err != nil {
+		return "", err
+	}
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	in := map[string]any{"body": rel.Notes}
+	if err := f.do("PATCH", fmt.Sprintf("/repos/%s/releases/%d", f.Repo, found.ID), f.giteaHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
+
+func (f *Forge) giteaPullRequest(pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = "WIP: " + title // Gitea marks drafts by title prefix
+	}
+	in := map[string]any{
+		"title": title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+	}
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.do("POST", "/repos/"+f.Repo+"/pulls", f.giteaHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
@@ -0,0 +1,68 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+func (f *Forge) githubHeader() http.Header {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+f.Token)
+	h.Set("Accept", "application/vnd.github+json")
+	return h
+}
+
+// githubReleaseBody is the release payload of GitHub, and of Gitea which
+// copied its API
+func githubReleaseBody(rel Release) map[string]any {
+	return map[string]any{
+		"tag_name": rel.Tag,
+		"name":     rel.Name,
+		"body":     rel.Notes,
+	}
+}
+
+func (f *Forge) githubRelease(rel Release) (string, error) {
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.do("POST", "/repos/"+f.Repo+"/releases", f.githubHeader(), githubReleaseBody(rel), &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
+
+func (f *Forge) githubUpdateRelease(rel Release) (string, error) {
+	var found struct {
+		ID int64 `json:"id"`
+	}
+	if err := f.do("GET", "/repos/"+f.Repo+"/releases/tags/"+url.PathEscape(rel.Tag), f.githubHeader(), nil, &found); err != nil {
+		return "", err
+	}
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	in := map[string]any{"body": rel.Notes}
+	if err := f.do("PATCH", fmt.Sprintf("/repos/%s/releases/%d", f.Repo, found.ID), f.githubHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
+
+func (f *Forge) githubPullRequest(pr PullRequest) (string, error) {
+	in := map[string]any{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"draft": pr.Draft,
+	}
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.do("POST", "/repos/"+f.Repo+"/pulls", f.githubHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.HTMLURL, nil
+}
@@ -0,0 +1,61 @@
+package forge
+
+import (
+	"net/http"
+	"net/url"
+)
+
+func (f *Forge) gitlabHeader() http.Header {
+	h := http.Header{}
+	h.Set("PRIVATE-TOKEN", f.Token)
+	return h
+}
+
+// gitlabProject is the project path as the API expects it: URL-encoded,
+// slashes included
+func (f *Forge) gitlabProject() string {
+	return "/projects/" + url.PathEscape(f.Repo)
+}
+
+func gitlabReleaseBody(rel Release) map[string]any {
+	return map[string]any{
+		"tag_name":    rel.Tag,
+		"name":        rel.Name,
+		"description": rel.Notes,
+	}
+}
+
+func (f *Forge) gitlabRelease(rel Release) (string, error) {
+	if err := f.do("POST", f.gitlabProject()+"/releases", f.gitlabHeader(), gitlabReleaseBody(rel), nil); err != nil {
+		return "", err
+	}
+	return f.WebURL + "/-/releases/" + url.PathEscape(rel.Tag), nil
+}
+
+func (f *Forge) gitlabUpdateRelease(rel Release) (string, error) {
+	in := map[string]any{"description": rel.Notes}
+	if err := f.do("PUT", f.gitlabProject()+"/releases/"+url.PathEscape(rel.Tag), f.gitlabHeader(), in, nil); err != nil {
+		return "", err
+	}
+	return f.WebURL + "/-/releases/" + url.PathEscape(rel.Tag), nil
+}
+
+func (f *Forge) gitlabMergeRequest(pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = "Draft: " + title
+	}
+	in := map[string]any{
+		"title":         title,
+		"description":   pr.Body,
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+	}
+	var out struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := f.do("POST", f.gitlabProject()+"/merge_requests", f.gitlabHeader(), in, &out); err != nil {
+		return "", err
+	}
+	return out.WebURL, nil
+}
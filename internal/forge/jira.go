@@ -0,0 +1,85 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/config"
+)
+
+// Env vars Jira is authenticated with: an API token, with the account email
+// on Jira Cloud, or a personal access token alone on Jira Server and Data
+// Center
+const (
+	EnvJiraToken = "JIRA_API_TOKEN"
+	EnvJiraUser  = "JIRA_USER"
+)
+
+// Jira is the REST API of a Jira site, for commenting on issues mentioned
+// by commits
+type Jira struct {
+	URL   string // e.g. https://example.atlassian.net
+	User  string
+	Token string
+
+	client *http.Client
+}
+
+// NewJira returns the Jira site of cfg, or nil when none is configured
+func NewJira(cfg *config.Config) *Jira {
+	if cfg.JiraURL == "" {
+		return nil
+	}
+	return &Jira{
+		URL:    strings.TrimSuffix(cfg.JiraURL, "/"),
+		User:   os.Getenv(EnvJiraUser),
+		Token:  os.Getenv(EnvJiraToken),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CheckAllowed is Forge.CheckAllowed for the Jira site
+func (j *Jira) CheckAllowed(cfg *config.Config) error {
+	return checkAllowed(cfg, "Jira", j.URL)
+}
+
+// Comment posts a comment on an issue, by key (PROJ-123)
+func (j *Jira) Comment(key, body string) error {
+	if j.Token == "" {
+		return fmt.Errorf("no Jira token: set %s (and %s on Jira Cloud)", EnvJiraToken, EnvJiraUser)
+	}
+	data, err := json.Marshal(map[string]any{"body": body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", j.URL+"/rest/api/2/issue/"+url.PathEscape(key)+"/comment", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if j.User != "" {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(j.User+":"+j.Token)))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Jira API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		out, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Jira API returned %s: %s", resp.Status, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -1,9 +1,19 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
 )
 
 // FileChange represents a staged file and its diff
@@ -11,19 +21,250 @@ type FileChange struct {
 	Path   string
 	Status string // A=added, M=modified, D=deleted, R=renamed
 	Diff   string
+
+	OldMode string // e.g. 100644; empty for added files
+	NewMode string // e.g. 100755; empty for deleted files
+
+	// LastSubject is the subject of the last commit that touched a deleted
+	// file, giving the model a hint about what is being removed.
+	LastSubject string
+
+	// History holds the subjects of the last commits touching the file,
+	// newest first, when file history context is enabled
+	History []string
+
+	// Lockfiles are the staged lockfiles pinning this manifest (go.sum for
+	// go.mod...), committed along with it
+	Lockfiles []string
+
+	// DiffLeftOut is set when the user chose not to send the diff, e.g. a
+	// large generated file; the prompt then only names the file
+	DiffLeftOut bool
+}
+
+// ModeOnly reports whether only the file permissions changed
+func (c FileChange) ModeOnly() bool {
+	return c.OldMode != "" && c.NewMode != "" && c.OldMode != c.NewMode &&
+		!strings.Contains(c.Diff, "\n@@") && !strings.Contains(c.Diff, "Binary files")
+}
+
+// CommitInfo is a single commit with its full message
+type CommitInfo struct {
+	Hash    string
+	Subject string
+	Body    string
+	Diff    string   // Only filled when requested, see ShowDiff
+	Parents []string // Only filled by FirstParentHistory
+
+	Author string    // Only filled by SearchCommits
+	Date   time.Time // Only filled by SearchCommits
+}
+
+const (
+	symlinkMode = "120000"
+	lfsSpecLine = "version https://git-lfs.github.com/spec/v1"
+)
+
+// IsSymlink reports whether the change involves a symbolic link
+func (c FileChange) IsSymlink() bool {
+	return c.OldMode == symlinkMode || c.NewMode == symlinkMode
+}
+
+// SymlinkTargets returns the old and new link targets of a symlink change
+func (c FileChange) SymlinkTargets() (oldTarget, newTarget string) {
+	oldLines, newLines := diffBodyLines(c.Diff)
+	if c.OldMode == symlinkMode && len(oldLines) > 0 {
+		oldTarget = oldLines[0]
+	}
+	if c.NewMode == symlinkMode && len(newLines) > 0 {
+		newTarget = newLines[0]
+	}
+	return oldTarget, newTarget
+}
+
+// IsLFSPointer reports whether the diff is a Git LFS pointer file change
+func (c FileChange) IsLFSPointer() bool {
+	return strings.Contains(c.Diff, "\n+"+lfsSpecLine) || strings.Contains(c.Diff, "\n-"+lfsSpecLine) ||
+		strings.Contains(c.Diff, "\n "+lfsSpecLine)
+}
+
+// LFSSizes returns the old and new object sizes (bytes) of an LFS pointer
+// change; -1 means the side doesn't exist.
+func (c FileChange) LFSSizes() (oldSize, newSize int64) {
+	oldSize, newSize = -1, -1
+	oldLines, newLines := diffBodyLines(c.Diff)
+	for _, l := range oldLines {
+		fmt.Sscanf(l, "size %d", &oldSize)
+	}
+	for _, l := range newLines {
+		fmt.Sscanf(l, "size %d", &newSize)
+	}
+	// Context lines (unchanged size) appear on neither side
+	if oldSize == -1 && newSize != -1 && !strings.HasPrefix(c.Status, "A") {
+		oldSize = newSize
+	}
+	if newSize == -1 && oldSize != -1 && !strings.HasPrefix(c.Status, "D") {
+		newSize = oldSize
+	}
+	return oldSize, newSize
+}
+
+// diffBodyLines returns removed and added lines of a diff, without markers
+func diffBodyLines(diff string) (removed, added []string) {
+	inHunk := false
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			inHunk = true
+		case !inHunk:
+			continue
+		case strings.HasPrefix(l, "-"):
+			removed = append(removed, l[1:])
+		case strings.HasPrefix(l, "+"):
+			added = append(added, l[1:])
+		}
+	}
+	return removed, added
 }
 
 // StagedChanges returns all staged changes grouped by file
 func StagedChanges() ([]FileChange, error) {
-	// Get list of staged files with status
-	out, err := run("git", "diff", "--cached", "--name-status")
+	changes, err := diffChanges("--cached")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get staged files: %w", err)
+		return nil, err
 	}
-
-	if strings.TrimSpace(out) == "" {
+	if len(changes) == 0 {
 		return nil, fmt.Errorf("no staged changes found. Use 'git add' to stage files first")
 	}
+	return changes, nil
+}
+
+// StagedPaths returns the paths of staged files; unlike StagedChanges an
+// empty index is not an error
+func StagedPaths() ([]string, error) {
+	return stagedPaths()
+}
+
+// StagedFiles returns the paths of staged files the next commit contains:
+// StagedPaths without the deletions
+func StagedFiles() ([]string, error) {
+	return stagedPaths("--diff-filter=d")
+}
+
+func stagedPaths(filter ...string) ([]string, error) {
+	out, err := run("git", append([]string{"diff", "--cached", "--name-only", "-z"}, filter...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// IndexTree writes the index as a tree object and returns its hash, so the
+// staging can be put back with RestoreStaged
+func IndexTree() (string, error) {
+	out, err := run("git", "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to save the index: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RestoreStaged stages again what tree (see IndexTree) staged over HEAD,
+// except the paths of skip: files still staged when the tree was written
+// end up staged exactly as they were, new files, deletions and partially
+// staged hunks included
+func RestoreStaged(tree string, skip map[string]bool) error {
+	out, err := run("git", "diff", "--name-only", "--no-renames", "-z", "HEAD", tree)
+	if err != nil {
+		// No HEAD yet: everything in the tree was staged
+		if out, err = run("git", "ls-tree", "-r", "--name-only", "-z", tree); err != nil {
+			return fmt.Errorf("failed to list the saved index: %w", err)
+		}
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" && !skip[p] {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	args := append([]string{"restore", "--staged", "--source=" + tree, "--"}, paths...)
+	if _, err := run("git", args...); err != nil {
+		return fmt.Errorf("failed to restage %s: %w", strings.Join(paths, ", "), err)
+	}
+	return nil
+}
+
+// WorktreeHashes returns the blob hash of each path as it is in the working
+// tree, "" for paths that don't exist, to tell later whether they changed
+func WorktreeHashes(paths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(paths))
+	var existing []string
+	for _, p := range paths {
+		hashes[p] = ""
+		if info, err := os.Lstat(p); err == nil && !info.IsDir() {
+			existing = append(existing, p)
+		}
+	}
+	if len(existing) == 0 {
+		return hashes, nil
+	}
+	out, err := run("git", append([]string{"hash-object", "--"}, existing...)...)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(existing) {
+		return nil, fmt.Errorf("git hash-object returned %d hash(es) for %d file(s)", len(lines), len(existing))
+	}
+	for i, p := range existing {
+		hashes[p] = lines[i]
+	}
+	return hashes, nil
+}
+
+// CommitPaths returns the paths a commit changed, the root commit included
+func CommitPaths(rev string) ([]string, error) {
+	out, err := run("git", "diff-tree", "--root", "--no-commit-id", "--name-only", "--no-renames", "-r", "-z", rev)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// StagedChangesSince returns the changes between rev and the index, e.g.
+// HEAD^ to describe the commit being amended
+func StagedChangesSince(rev string) ([]FileChange, error) {
+	return diffChanges("--cached", rev)
+}
+
+// ChangesBetween returns the changes between two commits, like StagedChanges
+func ChangesBetween(from, to string) ([]FileChange, error) {
+	return diffChanges(from, to)
+}
+
+// diffChanges collects per-file changes for a git diff selection
+// ("--cached" or two revisions)
+func diffChanges(selection ...string) ([]FileChange, error) {
+	// Get list of changed files with status
+	out, err := run("git", append(append([]string{"diff"}, selection...), "--name-status")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
 
 	var changes []FileChange
 	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
@@ -42,9 +283,12 @@ func StagedChanges() ([]FileChange, error) {
 			Status: status,
 		})
 	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
 
-	// Get unified diff for all staged changes
-	fullDiff, err := run("git", "diff", "--cached", "--unified=3")
+	// Get unified diff for all changes
+	fullDiff, err := run("git", append(append([]string{"diff"}, selection...), "--unified=3")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff: %w", err)
 	}
@@ -57,9 +301,300 @@ func StagedChanges() ([]FileChange, error) {
 		}
 	}
 
+	// File modes, to tell chmod-only changes apart from content edits
+	modes, _ := diffModes(selection...)
+	for i := range changes {
+		if m, ok := modes[changes[i].Path]; ok {
+			changes[i].OldMode, changes[i].NewMode = m[0], m[1]
+		}
+	}
+
+	// What deleted files were about, from their last commit
+	for i := range changes {
+		if strings.HasPrefix(changes[i].Status, "D") {
+			changes[i].LastSubject, _ = LastSubject(changes[i].Path)
+		}
+	}
+
+	return changes, nil
+}
+
+// diffModes returns path -> [old mode, new mode] for a diff selection
+func diffModes(selection ...string) (map[string][2]string, error) {
+	out, err := run("git", append(append([]string{"diff"}, selection...), "--raw")...)
+	if err != nil {
+		return nil, err
+	}
+	modes := make(map[string][2]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// :100644 100755 abc123 def456 M\tpath (renames: R100\told\tnew)
+		meta, paths, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(meta, ":") {
+			continue
+		}
+		fields := strings.Fields(meta[1:])
+		if len(fields) < 2 {
+			continue
+		}
+		parts := strings.Split(paths, "\t")
+		modes[parts[len(parts)-1]] = [2]string{zeroMode(fields[0]), zeroMode(fields[1])}
+	}
+	return modes, nil
+}
+
+func zeroMode(m string) string {
+	if m == "000000" {
+		return ""
+	}
+	return m
+}
+
+// IndexState fingerprints what committing paths would record: every index
+// entry (mode, blob, path) and the unstaged changes to paths, binary ones
+// included
+func IndexState(paths []string) (string, error) {
+	top, err := TopLevel()
+	if err != nil {
+		return "", err
+	}
+	entries, err := run("git", "-C", top, "ls-files", "-s")
+	if err != nil {
+		return "", fmt.Errorf("failed to list the index: %s", strings.TrimSpace(entries))
+	}
+	unstaged, err := run("git", append([]string{"-C", top, "diff", "--binary", "--"}, paths...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff the working tree: %s", strings.TrimSpace(unstaged))
+	}
+	return entries + "\x00" + unstaged, nil
+}
+
+// WorkingTree returns files with unstaged modifications and untracked files
+func WorkingTree() (unstaged []FileChange, untracked []string, err error) {
+	out, err := run("git", "status", "--porcelain=v1", "--untracked-files=all")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		x, y, path := line[0], line[1], line[3:]
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+4:]
+		}
+		switch {
+		case x == '?' && y == '?':
+			untracked = append(untracked, path)
+		case y != ' ':
+			unstaged = append(unstaged, FileChange{Path: path, Status: string(y)})
+		}
+	}
+	return unstaged, untracked, nil
+}
+
+// UnstagedChanges returns the changes in the working tree that aren't
+// staged, like StagedChanges; untracked files are not included
+func UnstagedChanges() ([]FileChange, error) {
+	return diffChanges()
+}
+
+// untrackedLimit is how much of an untracked file UntrackedChanges reads
+const untrackedLimit = 64 << 10
+
+// UntrackedChanges returns untracked files as added files, with a diff
+// adding their content. Binary files get no diff; large ones are cut
+// short, like the diffs sent to the provider.
+func UntrackedChanges(paths []string) ([]FileChange, error) {
+	var changes []FileChange
+	for _, p := range paths {
+		c := FileChange{Path: p, Status: "A"}
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode().IsRegular() {
+			f, err := os.Open(p)
+			if err != nil {
+				return nil, err
+			}
+			data := make([]byte, untrackedLimit)
+			n, _ := io.ReadFull(f, data)
+			f.Close()
+			if text := data[:n]; !bytes.Contains(text, []byte{0}) && n > 0 {
+				lines := strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+				c.Diff = fmt.Sprintf("--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n+%s", p, len(lines), strings.Join(lines, "\n+"))
+			}
+		}
+		changes = append(changes, c)
+	}
 	return changes, nil
 }
 
+// LastSubject returns the subject of the last commit touching path
+func LastSubject(path string) (string, error) {
+	out, err := run("git", "log", "-1", "--format=%s", "--", path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// FileSubjects returns the subjects of the last n commits touching path,
+// following renames
+func FileSubjects(path string, n int) ([]string, error) {
+	out, err := run("git", "log", "--follow", fmt.Sprintf("-n%d", n), "--format=%s", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if l != "" {
+			subjects = append(subjects, l)
+		}
+	}
+	return subjects, nil
+}
+
+// Author is a commit author with the number of lines attributed to them
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Lines int    `json:"lines"`
+}
+
+// hunkOldRe captures the old-side start of a hunk header, e.g. "@@ -12,4 +12,6 @@"
+var hunkOldRe = regexp.MustCompile(`^@@ -(\d+)`)
+
+// BlameChanged attributes the lines a staged change removes or rewrites (as
+// they are in HEAD) to their authors, most lines first. Pure insertions count
+// the line they were inserted after. Added (and renamed) files have no
+// previous authors under their path.
+func BlameChanged(c FileChange) ([]Author, error) {
+	out, err := blameChanged(c)
+	if out == "" || err != nil {
+		return nil, err
+	}
+
+	byEmail := make(map[string]*Author)
+	var order []*Author
+	var name string
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "author "):
+			name = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			email := strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+			a, ok := byEmail[email]
+			if !ok {
+				a = &Author{Name: name, Email: email}
+				byEmail[email] = a
+				order = append(order, a)
+			}
+			a.Lines++
+		}
+	}
+	authors := make([]Author, len(order))
+	for i, a := range order {
+		authors[i] = *a
+	}
+	sort.SliceStable(authors, func(i, j int) bool { return authors[i].Lines > authors[j].Lines })
+	return authors, nil
+}
+
+// blameLineRe matches the header of a line in blame --line-porcelain output
+var blameLineRe = regexp.MustCompile(`^([0-9a-f]{40}) \d+ \d+`)
+
+// BlameCommits returns the commits the lines BlameChanged looks at come
+// from, with how many lines each
+func BlameCommits(c FileChange) (map[string]int, error) {
+	out, err := blameChanged(c)
+	if out == "" || err != nil {
+		return nil, err
+	}
+	commits := make(map[string]int)
+	for _, line := range strings.Split(out, "\n") {
+		if m := blameLineRe.FindStringSubmatch(line); m != nil {
+			commits[m[1]]++
+		}
+	}
+	return commits, nil
+}
+
+// blameChanged runs blame --line-porcelain on the lines of HEAD a staged
+// change touches, returning "" when there are none
+func blameChanged(c FileChange) (string, error) {
+	if strings.HasPrefix(c.Status, "A") || strings.HasPrefix(c.Status, "R") {
+		return "", nil
+	}
+	args := []string{"blame", "--line-porcelain", "-w"}
+	if !strings.HasPrefix(c.Status, "D") {
+		ranges := changedOldLines(c.Diff)
+		if len(ranges) == 0 {
+			return "", nil
+		}
+		for _, r := range ranges {
+			args = append(args, "-L", fmt.Sprintf("%d,%d", r[0], r[1]))
+		}
+	}
+	args = append(args, "HEAD", "--", c.Path)
+	out, err := run("git", args...)
+	if err != nil {
+		return "", fmt.Errorf("blame %s: %w", c.Path, err)
+	}
+	return out, nil
+}
+
+// changedOldLines returns the old-side line ranges a diff removes, plus the
+// line each insertion follows, merged and in order
+func changedOldLines(diff string) [][2]int {
+	var ranges [][2]int
+	add := func(n int) {
+		if n < 1 {
+			return // Insertion at the top of the file
+		}
+		if last := len(ranges) - 1; last >= 0 && n <= ranges[last][1]+1 {
+			ranges[last][1] = max(ranges[last][1], n)
+			return
+		}
+		ranges = append(ranges, [2]int{n, n})
+	}
+
+	old := 0 // Next old-side line number
+	inHunk := false
+	prevRemoved := false
+	for _, line := range strings.Split(diff, "\n") {
+		if m := hunkOldRe.FindStringSubmatch(line); m != nil {
+			old, _ = strconv.Atoi(m[1])
+			if strings.HasPrefix(line, "@@ -"+m[1]+",0 ") {
+				old++ // Empty old range: the start is the line before the insertion
+			}
+			inHunk, prevRemoved = true, false
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			add(old)
+			old++
+			prevRemoved = true
+		case '+':
+			if !prevRemoved {
+				add(old - 1)
+			}
+		case ' ':
+			old++
+			prevRemoved = false
+		case '\\':
+			// "\ No newline at end of file"
+		default:
+			inHunk = false // Next file header
+		}
+	}
+	return ranges
+}
+
 // AllStagedDiff returns a single combined diff string (for single-request mode)
 func AllStagedDiff() (string, error) {
 	out, err := run("git", "diff", "--cached", "--unified=3", "--stat")
@@ -78,11 +613,37 @@ func AllStagedDiff() (string, error) {
 	return out + "\n---\n" + diff, nil
 }
 
+// CommitOptions overrides authorship of created commits. GIT_AUTHOR_* and
+// GIT_COMMITTER_* environment variables are passed through to git as well.
+type CommitOptions struct {
+	Author string // "Name <email>"
+	Date   string // Any date format git accepts; used for author and committer date
+	Fixup  string // Commit to fix up: git writes the "fixup! <subject>" message
+}
+
 // Commit creates a commit with the given message
-func Commit(message string) error {
-	out, err := run("git", "commit", "-m", message)
-	if err != nil {
-		return fmt.Errorf("commit failed: %s\n%w", out, err)
+func Commit(message string, opts CommitOptions) error {
+	args := []string{"commit", "-m", message}
+	if opts.Fixup != "" {
+		args = []string{"commit", "--fixup=" + opts.Fixup}
+	}
+	if opts.Author != "" {
+		args = append(args, "--author", opts.Author)
+	}
+	if opts.Date != "" {
+		args = append(args, "--date", opts.Date)
+	}
+
+	if err := readonly.CheckGit(args); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = os.Environ()
+	if opts.Date != "" && os.Getenv("GIT_COMMITTER_DATE") == "" {
+		cmd.Env = append(cmd.Env, "GIT_COMMITTER_DATE="+opts.Date)
+	}
+	if _, _, err := runCmd(cmd); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
 	}
 	return nil
 }
@@ -93,9 +654,27 @@ func IsGitRepo() bool {
 	return err == nil
 }
 
+// LogOptions selects the commits returned by RecentCommits
+type LogOptions struct {
+	N      int      // Most recent commits to return
+	Since  string   // Any date git accepts, e.g. "2 weeks ago" or 2024-01-15; empty for no limit
+	Author string   // Fixed string matched against "Name <email>"; empty for any author
+	Paths  []string // Only commits touching these paths; empty for all
+}
+
 // RecentCommits returns recent commit messages for context
-func RecentCommits(n int) ([]string, error) {
-	out, err := run("git", "log", fmt.Sprintf("--oneline"), fmt.Sprintf("-n%d", n))
+func RecentCommits(opts LogOptions) ([]string, error) {
+	args := []string{"log", "--oneline", fmt.Sprintf("-n%d", opts.N)}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Author != "" {
+		args = append(args, "--fixed-strings", "--author="+opts.Author)
+	}
+	if len(opts.Paths) > 0 {
+		args = append(append(args, "--"), opts.Paths...)
+	}
+	out, err := run("git", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -109,15 +688,43 @@ func RecentCommits(n int) ([]string, error) {
 	return msgs, nil
 }
 
+// UserEmail returns the configured user.email, or "" if unset
+func UserEmail() string {
+	out, err := run("git", "config", "user.email")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// GrepCommits returns up to n recent commits whose message matches the regex
+func GrepCommits(pattern string, n int) ([]string, error) {
+	out, err := run("git", "log", "--oneline", "-E", "--grep="+pattern, fmt.Sprintf("-n%d", n))
+	if err != nil {
+		return nil, err
+	}
+	var msgs []string
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if l != "" {
+			msgs = append(msgs, l)
+		}
+	}
+	return msgs, nil
+}
+
 // CommitsSinceTag returns commits since the last tag
 func CommitsSinceTag(tag string) ([]string, error) {
-	var out string
-	var err error
-	if tag == "" {
-		out, err = run("git", "log", "--oneline")
-	} else {
-		out, err = run("git", "log", "--oneline", tag+"..HEAD")
+	return CommitsBetween(tag, "HEAD")
+}
+
+// CommitsBetween returns commits reachable from "to" but not from "from".
+// An empty "from" means the whole history up to "to".
+func CommitsBetween(from, to string) ([]string, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
 	}
+	out, err := run("git", "log", "--oneline", rng)
 	if err != nil {
 		return nil, err
 	}
@@ -131,25 +738,1161 @@ func CommitsSinceTag(tag string) ([]string, error) {
 	return msgs, nil
 }
 
-// LatestTag returns the most recent git tag
-func LatestTag() (string, error) {
-	out, err := run("git", "describe", "--tags", "--abbrev=0")
+// SubjectsBetween returns the hashes and subjects of the non-merge commits
+// reachable from "to" but not from "from", newest first. An empty "from"
+// means the whole history up to "to".
+func SubjectsBetween(from, to string) ([]CommitInfo, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+	out, err := run("git", "log", "--no-merges", "--format=%H%x00%s", rng)
 	if err != nil {
-		return "", nil // No tags yet
+		return nil, err
 	}
-	return strings.TrimSpace(out), nil
+	var commits []CommitInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if hash, subject, ok := strings.Cut(line, "\x00"); ok {
+			commits = append(commits, CommitInfo{Hash: hash, Subject: subject})
+		}
+	}
+	return commits, nil
 }
 
-// CreateTag creates an annotated git tag
-func CreateTag(tag, message string) error {
-	_, err := run("git", "tag", "-a", tag, "-m", message)
-	return err
+// FileStat is the lines one commit added and deleted in a file; binary
+// files have -1 for both
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+}
+
+// StatCommit is a commit with its author, date and per-file line counts
+type StatCommit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Date    time.Time
+	Subject string
+	Files   []FileStat
+}
+
+// RangeStat sums up the changes between two revisions
+type RangeStat struct {
+	Commits      int // Non-merge commits
+	Files        int
+	Insertions   int
+	Deletions    int
+	Contributors []Contributor // Most commits first, then by name
+}
+
+// Contributor is an author of commits in a range, as .mailmap resolves them
+type Contributor struct {
+	Name    string
+	Email   string
+	Commits int
 }
 
-func run(name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+var shortstatRe = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// RangeStats counts the non-merge commits reachable from to but not from
+// from (the whole history when from is empty), the files and lines the
+// range changes, and the people who wrote its commits
+func RangeStats(from, to string) (RangeStat, error) {
+	var st RangeStat
+	rng, base := to, "4b825dc642cb6eb9a060e54bf8d69288fbee4904" // The empty tree
+	if from != "" {
+		rng, base = from+".."+to, from
+	}
+
+	out, err := run("git", "shortlog", "-sne", "--no-merges", rng, "--")
+	if err != nil {
+		return st, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		count, author, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(count)
+		name, email, _ := strings.Cut(author, " <")
+		st.Contributors = append(st.Contributors, Contributor{Name: name, Email: strings.TrimSuffix(email, ">"), Commits: n})
+		st.Commits += n
+	}
+	sort.SliceStable(st.Contributors, func(i, j int) bool {
+		a, b := st.Contributors[i], st.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	out, err = run("git", "diff", "--shortstat", base, to)
+	if err != nil {
+		return st, err
+	}
+	for _, m := range shortstatRe.FindAllStringSubmatch(out, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			st.Files = n
+		case "insertion":
+			st.Insertions = n
+		case "deletion":
+			st.Deletions = n
+		}
+	}
+	return st, nil
+}
+
+// CommitStats returns the non-merge commits made between since and until
+// (any dates git accepts; empty for no bound) with their line counts, newest
+// first. Renames are reported under the new path.
+func CommitStats(since, until string) ([]StatCommit, error) {
+	args := []string{"log", "--no-merges", "--numstat", "--format=%x1e%H%x00%an%x00%ae%x00%aI%x00%s"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if until != "" {
+		args = append(args, "--until="+until)
+	}
+	out, err := run("git", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatLog(out), nil
+}
+
+// StatsOf returns the given commits with their line counts, in the order
+// given
+func StatsOf(hashes []string) ([]StatCommit, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	args := append([]string{"log", "--no-walk=unsorted", "--numstat", "--format=%x1e%H%x00%an%x00%ae%x00%aI%x00%s"}, hashes...)
+	out, err := run("git", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatLog(out), nil
+}
+
+// parseStatLog parses the output of git log --numstat in the format of
+// CommitStats
+func parseStatLog(out string) []StatCommit {
+	var commits []StatCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		c := StatCommit{Hash: fields[0], Author: fields[1], Email: fields[2], Subject: fields[4]}
+		c.Date, _ = time.Parse(time.RFC3339, fields[3])
+		for _, l := range lines[1:] {
+			parts := strings.SplitN(l, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			added, err1 := strconv.Atoi(parts[0])
+			deleted, err2 := strconv.Atoi(parts[1])
+			if err1 != nil || err2 != nil {
+				added, deleted = -1, -1 // "-" for binary files
+			}
+			c.Files = append(c.Files, FileStat{Path: renamedPath(parts[2]), Added: added, Deleted: deleted})
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// RevList returns up to n non-merge commits reachable from rev, newest first
+func RevList(rev string, n int) ([]string, error) {
+	out, err := run("git", "rev-list", "--no-merges", fmt.Sprintf("--max-count=%d", n), rev)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// ParseDate turns any date git accepts, e.g. "2 weeks ago", into a time
+func ParseDate(date string) (time.Time, error) {
+	out, err := run("git", "rev-parse", "--since="+date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+	secs, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(out), "--max-age="), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+	return time.Unix(secs, 0), nil
+}
+
+// renamedPath turns numstat rename notation ("a/{old => new}/f", "old => new")
+// into the new path
+func renamedPath(p string) string {
+	if !strings.Contains(p, " => ") {
+		return p
+	}
+	if open := strings.Index(p, "{"); open >= 0 {
+		if end := strings.Index(p[open:], "}"); end >= 0 {
+			inner := p[open+1 : open+end]
+			_, to, _ := strings.Cut(inner, " => ")
+			return strings.ReplaceAll(p[:open]+to+p[open+end+1:], "//", "/")
+		}
+	}
+	_, to, _ := strings.Cut(p, " => ")
+	return to
+}
+
+// BreakingCommits returns commits in from..to marked as breaking, either with
+// a "!" after the Conventional Commits type or a BREAKING CHANGE footer.
+func BreakingCommits(from, to string) ([]CommitInfo, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+	out, err := run("git", "log", "--format=%H%x00%s%x00%b%x1e", rng)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitInfo
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		c := CommitInfo{Hash: fields[0], Subject: fields[1], Body: strings.TrimSpace(fields[2])}
+		if IsBreaking(c.Subject, c.Body) {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// IsBreaking reports whether a commit message declares a breaking change
+func IsBreaking(subject, body string) bool {
+	if strings.Contains(body, "BREAKING CHANGE") || strings.Contains(body, "BREAKING-CHANGE") {
+		return true
+	}
+	head, _, ok := strings.Cut(subject, ":")
+	return ok && strings.HasSuffix(head, "!")
+}
+
+// ShowDiff returns the patch introduced by a single commit
+func ShowDiff(hash string) (string, error) {
+	out, err := run("git", "show", "--format=", "--unified=3", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to show %s: %w", hash, err)
+	}
+	return out, nil
+}
+
+// DiffStat returns the diffstat of a single commit, as git show --stat
+// prints it
+func DiffStat(hash string) (string, error) {
+	out, err := run("git", "show", "--format=", "--stat", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to show %s: %w", hash, err)
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// SearchOptions bounds a history search
+type SearchOptions struct {
+	N     int      // Most commits to return
+	Since string   // Any date git accepts; empty for the whole history
+	Paths []string // Only commits touching these paths; empty for all
+}
+
+// SearchCommits returns non-merge commits whose message matches pattern
+// (case-insensitive), or with pickaxe, whose diff adds or removes lines
+// matching it (git log -G). pattern is an extended regex.
+func SearchCommits(pattern string, pickaxe bool, opts SearchOptions) ([]CommitInfo, error) {
+	args := []string{"log", "--no-merges", "-E", fmt.Sprintf("-n%d", opts.N), "--format=%H%x00%aI%x00%an%x00%s"}
+	if pickaxe {
+		args = append(args, "-G"+pattern)
+	} else {
+		args = append(args, "-i", "--grep="+pattern)
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if len(opts.Paths) > 0 {
+		args = append(append(args, "--"), opts.Paths...)
+	}
+	out, err := run("git", args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[1])
+		commits = append(commits, CommitInfo{Hash: fields[0], Date: date, Author: fields[2], Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// ShowCommit returns the full hash, subject and body of a commit
+func ShowCommit(rev string) (CommitInfo, error) {
+	out, err := run("git", "show", "-s", "--format=%H%x00%s%x00%b", rev+"^{commit}")
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("unknown commit %s", rev)
+	}
+	fields := strings.SplitN(strings.TrimSpace(out), "\x00", 3)
+	if len(fields) < 2 {
+		return CommitInfo{}, fmt.Errorf("unknown commit %s", rev)
+	}
+	c := CommitInfo{Hash: fields[0], Subject: fields[1]}
+	if len(fields) == 3 {
+		c.Body = strings.TrimSpace(fields[2])
+	}
+	return c, nil
+}
+
+// Note returns the note attached to a commit under the notes ref (e.g.
+// "commits" for refs/notes/commits), or "" if there is none
+func Note(ref, hash string) string {
+	out, err := run("git", "notes", "--ref="+ref, "show", hash)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// AddNote attaches text to a commit under the notes ref, replacing any
+// existing note when overwrite is set
+func AddNote(ref, hash, text string, overwrite bool) error {
+	args := []string{"notes", "--ref=" + ref, "add", "-m", text}
+	if overwrite {
+		args = append(args, "-f")
+	}
+	_, err := run("git", append(args, hash)...)
+	if err != nil {
+		return fmt.Errorf("failed to add note: %w", err)
+	}
+	return nil
+}
+
+// Tags returns all tags ordered from oldest to newest
+func Tags() ([]string, error) {
+	return TagsMatching("")
+}
+
+// TagsMatching returns the tags matching the glob pattern (all if empty)
+// ordered from oldest to newest
+func TagsMatching(pattern string) ([]string, error) {
+	args := []string{"tag", "--sort=creatordate"}
+	if pattern != "" {
+		args = append(args, "--list", pattern)
+	}
+	out, err := run("git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %s\n%w", out, err)
+	}
+	var tags []string
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			tags = append(tags, l)
+		}
+	}
+	return tags, nil
+}
+
+// TagDate returns the date (YYYY-MM-DD) of the commit a tag points to
+func TagDate(tag string) (string, error) {
+	out, err := run("git", "log", "-1", "--format=%as", tag)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CurrentBranch returns the checked out branch name ("HEAD" when detached)
+func CurrentBranch() (string, error) {
+	out, err := run("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitTemplate returns the content of the commit.template file, or ""
+// when none is configured. Relative paths are taken from the repository root.
+func CommitTemplate() (string, error) {
+	out, err := run("git", "config", "--path", "--get", "commit.template")
+	path := strings.TrimSpace(out)
+	if err != nil || path == "" {
+		return "", nil // Not configured
+	}
+	if !filepath.IsAbs(path) {
+		if top, err := TopLevel(); err == nil {
+			path = filepath.Join(top, path)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("commit.template: %w", err)
+	}
+	return string(data), nil
+}
+
+// CommentChar returns the character starting comment lines in commit
+// messages (core.commentChar, "#" by default)
+func CommentChar() string {
+	out, err := run("git", "config", "--get", "core.commentChar")
+	c := strings.TrimSpace(out)
+	if err != nil || c == "" || c == "auto" {
+		return "#"
+	}
+	return c
+}
+
+// LocalConfig returns a value of the repository's own git config
+// (.git/config), or "" when unset
+func LocalConfig(key string) string {
+	out, err := run("git", "config", "--local", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// SetLocalConfig sets a value in the repository's own git config
+func SetLocalConfig(key, value string) error {
+	if _, err := run("git", "config", "--local", key, value); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Editor returns the editor git uses for commit messages: GIT_EDITOR,
+// core.editor, VISUAL, EDITOR, then git's default
+func Editor() string {
+	_, out, err := runCmd(exec.Command("git", "var", "GIT_EDITOR"))
+	if editor := strings.TrimSpace(out); err == nil && editor != "" {
+		return editor
+	}
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// TopLevel returns the absolute path of the repository's working tree root
+func TopLevel() (string, error) {
+	out, err := run("git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate repository root: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ShowFile returns the content of path at the given revision
+func ShowFile(rev, path string) (string, error) {
+	out, err := run("git", "show", rev+":"+path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+	return out, nil
+}
+
+// HeadCommit returns the full hash of HEAD
+func HeadCommit() (string, error) {
+	out, err := run("git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RemoteURL returns the URL of the given remote
+func RemoteURL(remote string) (string, error) {
+	out, err := run("git", "remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", remote, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// PushURL returns the URL git pushes to for a remote, which pushurl can set
+// apart from the fetch URL
+func PushURL(remote string) (string, error) {
+	out, err := run("git", "remote", "get-url", "--push", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get push URL of remote %s: %w", remote, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Worktree is a working tree of the repository, the main one or a linked one
+type Worktree struct {
+	Path     string
+	Head     string
+	Branch   string // Empty when detached
+	Bare     bool
+	Prunable bool // Its directory is gone
+}
+
+// Worktrees lists the working trees of the repository, the main one first
+func Worktrees() ([]Worktree, error) {
+	out, err := run("git", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	var trees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		var wt Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "HEAD":
+				wt.Head = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				wt.Bare = true
+			case "prunable":
+				wt.Prunable = true
+			}
+		}
+		if wt.Path != "" {
+			trees = append(trees, wt)
+		}
+	}
+	return trees, nil
+}
+
+// HooksDir returns the absolute path of the directory git runs hooks from,
+// honoring core.hooksPath
+func HooksDir() (string, error) {
+	out, err := run("git", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	return filepath.Abs(strings.TrimSpace(out))
+}
+
+// Dir returns the path of the repository's .git directory
+func Dir() (string, error) {
+	out, err := run("git", "rev-parse", "--git-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate .git directory: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RebaseInProgress reports whether a rebase is stopped or running in the
+// current worktree
+func RebaseInProgress() bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := run("git", "rev-parse", "--git-path", name)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(strings.TrimSpace(out)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstParentHistory returns up to n commits reachable from HEAD following
+// first parents, newest first
+func FirstParentHistory(n int) ([]CommitInfo, error) {
+	out, err := run("git", "log", "--first-parent", fmt.Sprintf("-%d", n), "--format=%H%x00%P%x00%s%x00%b%x1e")
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Parents: strings.Fields(fields[1]),
+			Subject: fields[2],
+			Body:    strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// UpstreamBase returns the merge base of HEAD and its upstream branch, or ""
+// when the branch has no upstream
+func UpstreamBase() string {
+	out, err := run("git", "merge-base", "HEAD", "@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// Upstream returns the upstream branch of HEAD, e.g. origin/main, or ""
+// when it has none
+func Upstream() string {
+	out, err := run("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// PushBranch pushes the current branch to its upstream, or to a branch of
+// the same name on origin, set as upstream, when it has none
+func PushBranch() error {
+	args := []string{"push"}
+	if Upstream() == "" {
+		args = append(args, "--set-upstream", "origin", "HEAD")
+	}
+	if _, err := run("git", args...); err != nil {
+		return fmt.Errorf("push failed: %w", err)
+	}
+	return nil
+}
+
+// MergeBase returns the best common ancestor of two revisions
+func MergeBase(a, b string) (string, error) {
+	out, err := run("git", "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor of %s and %s%s", a, b, shallowHint())
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// FilesMentioning returns the tracked files (with their staged content)
+// that contain word as a whole word
+func FilesMentioning(word string) []string {
+	out, err := run("git", "grep", "--cached", "-l", "-w", "-F", "-e", word)
+	if err != nil {
+		return nil // Exit status 1: no match
+	}
+	return strings.Split(strings.TrimSpace(out), "\n")
+}
+
+// IsShallow reports whether the repository is a shallow clone, as CI
+// checkouts usually are
+func IsShallow() bool {
+	out, err := run("git", "rev-parse", "--is-shallow-repository")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// shallowHint explains, in a shallow clone, why a commit may be missing
+func shallowHint() string {
+	if !IsShallow() {
+		return ""
+	}
+	return " (this is a shallow clone: fetch the rest with git fetch --unshallow, or fetch-depth: 0 in GitHub Actions)"
+}
+
+// CountCommits returns the number of commits reachable from rev
+func CountCommits(rev string) int {
+	out, err := run("git", "rev-list", "--count", rev)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(out))
+	return n
+}
+
+// IsAncestor reports whether ancestor is reachable from rev
+func IsAncestor(ancestor, rev string) bool {
+	_, err := run("git", "merge-base", "--is-ancestor", ancestor, rev)
+	return err == nil
+}
+
+// DefaultBranch returns the branch pull requests usually target: the
+// remote's HEAD (origin/main), else a local main or master, else ""
+func DefaultBranch() string {
+	if out, err := run("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimSpace(out)
+	}
+	for _, b := range []string{"main", "master"} {
+		if _, err := run("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+b); err == nil {
+			return b
+		}
+	}
+	return ""
+}
+
+// RangeCommits returns the commits reachable from "to" but not from "from",
+// merges included, oldest first with their parents. An empty "from" means
+// the whole history up to "to".
+func RangeCommits(from, to string) ([]CommitInfo, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+	out, err := run("git", "log", "--reverse", "--format=%H%x00%P%x00%s%x00%b%x1e", rng)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Parents: strings.Fields(fields[1]),
+			Subject: fields[2],
+			Body:    strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// ResolveCommit returns the full hash of the commit rev names
+func ResolveCommit(rev string) (string, error) {
+	out, err := run("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s%s", rev, shallowHint())
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// BranchCommits returns the non-merge commits in from..to with their
+// bodies, oldest first
+func BranchCommits(from, to string) ([]CommitInfo, error) {
+	out, err := run("git", "log", "--no-merges", "--reverse", "--format=%H%x00%s%x00%b%x1e", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x00", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		commits = append(commits, CommitInfo{Hash: fields[0], Subject: fields[1], Body: strings.TrimSpace(fields[2])})
+	}
+	return commits, nil
+}
+
+// FormatPatch writes the patches of rangeSpec with a cover letter using git
+// format-patch and returns the files written, cover letter first
+func FormatPatch(rangeSpec, dir string, extra ...string) ([]string, error) {
+	args := append([]string{"format-patch", "--cover-letter", "-o", dir}, extra...)
+	out, err := run("git", append(args, rangeSpec)...)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line = strings.TrimSpace(line); strings.HasSuffix(line, ".patch") {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// CommitTree creates a commit object for the tree of treeish on top of parent
+// without touching the index or working tree. With keepAuthor, the author of
+// the commit named by keepAuthor is preserved.
+func CommitTree(treeish, parent, message, keepAuthor string) (string, error) {
+	args := []string{"commit-tree", treeish + "^{tree}", "-F", "-"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	if err := readonly.CheckGit(args); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(message)
+	cmd.Env = os.Environ()
+	if keepAuthor != "" {
+		out, err := run("git", "log", "-1", "--format=%an%x00%ae%x00%ad", "--date=raw", keepAuthor)
+		if err != nil {
+			return "", err
+		}
+		if f := strings.SplitN(strings.TrimSpace(out), "\x00", 3); len(f) == 3 {
+			cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME="+f[0], "GIT_AUTHOR_EMAIL="+f[1], "GIT_AUTHOR_DATE="+f[2])
+		}
+	}
+	_, out, err := runCmd(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// UpdateRef moves ref to newHash if it still points at oldHash (unchecked
+// when oldHash is empty)
+func UpdateRef(ref, newHash, oldHash, reason string) error {
+	args := []string{"update-ref", "-m", reason, ref, newHash}
+	if oldHash != "" {
+		args = append(args, oldHash)
+	}
+	_, err := run("git", args...)
+	if err != nil {
+		return fmt.Errorf("update-ref failed: %w", err)
+	}
+	return nil
+}
+
+// ResolveRef returns the object a ref points at, without peeling tags
+func ResolveRef(ref string) (string, error) {
+	out, err := run("git", "rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %s", ref)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// DeleteRef removes a ref
+func DeleteRef(ref string) error {
+	_, err := run("git", "update-ref", "-d", ref)
+	if err != nil {
+		return fmt.Errorf("update-ref failed: %w", err)
+	}
+	return nil
+}
+
+// Autosquash runs git rebase -i --autosquash onto base ("" for the whole
+// history) without opening an editor, folding fixup! commits into the
+// commits they fix. Local changes are stashed and restored around it.
+func Autosquash(base string) error {
+	args := []string{"rebase", "-i", "--autosquash", "--autostash"}
+	if base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+	if err := readonly.CheckGit(args); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=:")
+	if _, _, err := runCmd(cmd); err != nil {
+		return fmt.Errorf("rebase failed: %w", err)
+	}
+	return nil
+}
+
+// ResetKeep moves the checked-out branch to rev like git reset --keep: local
+// changes are kept, and it fails rather than overwrite them
+func ResetKeep(rev string) error {
+	_, err := run("git", "reset", "--keep", rev)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Subject returns the subject of the commit rev names
+func Subject(rev string) string {
+	out, err := run("git", "log", "-1", "--format=%s", rev+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// LatestTag returns the most recent git tag, among those matching the glob
+// pattern (e.g. app-v*) unless it is empty
+func LatestTag(pattern string) (string, error) {
+	out, err := run("git", describeArgs(pattern, "HEAD")...)
+	if err != nil {
+		return "", nil // No tags yet
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CreateTag creates an annotated git tag. The message is kept as is, so
+// markdown headings aren't stripped as comments.
+func CreateTag(tag, message string) error {
+	return CreateTagAt(tag, "HEAD", message)
+}
+
+// CreateTagAt is CreateTag for a commit other than HEAD
+func CreateTagAt(tag, commit, message string) error {
+	_, err := run("git", "tag", "-a", "--cleanup=whitespace", tag, commit, "-m", message)
+	return err
+}
+
+// AddWorktree checks out a new branch, started at start, in dir, leaving
+// the current checkout alone
+func AddWorktree(dir, branch, start string) error {
+	_, err := run("git", "worktree", "add", "-q", "-b", branch, dir, start)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree for %s: %w", branch, err)
+	}
+	return nil
+}
+
+// AddDetachedWorktree checks start out in a new worktree at dir, on no
+// branch; remove it with RemoveWorktree
+func AddDetachedWorktree(dir, start string) error {
+	_, err := run("git", "worktree", "add", "-q", "--detach", dir, start)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree at %s: %w", dir, err)
+	}
+	return nil
+}
+
+// ResetWorktree puts the worktree at dir back to its HEAD, index and files
+func ResetWorktree(dir string) error {
+	if _, err := run("git", "-C", dir, "reset", "-q", "--hard"); err != nil {
+		return fmt.Errorf("failed to reset worktree %s: %w", dir, err)
+	}
+	return nil
+}
+
+// CopyStaged gives paths (relative to the repository root) in the worktree
+// at dir the content they have in this repository's index. Paths not in the
+// index, i.e. staged deletions, are removed.
+func CopyStaged(dir string, paths []string) error {
+	top, err := TopLevel()
+	if err != nil {
+		return err
+	}
+	out, err := run("git", append([]string{"-C", top, "ls-files", "-s", "--"}, paths...)...)
+	if err != nil {
+		return fmt.Errorf("failed to list staged files: %w", err)
+	}
+	staged := make(map[string]bool)
+	var entries []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if _, path, ok := strings.Cut(line, "\t"); ok {
+			staged[path] = true
+			entries = append(entries, line)
+		}
+	}
+
+	if len(entries) > 0 {
+		if err := readonly.CheckGit([]string{"-C", dir, "update-index"}); err != nil {
+			return err
+		}
+		cmd := exec.Command("git", "-C", dir, "update-index", "--index-info")
+		cmd.Stdin = strings.NewReader(strings.Join(entries, "\n") + "\n")
+		if _, _, err := runCmd(cmd); err != nil {
+			return fmt.Errorf("failed to update worktree index: %w", err)
+		}
+	}
+	var checkout, removed []string
+	for _, p := range paths {
+		if staged[p] {
+			checkout = append(checkout, p)
+		} else {
+			removed = append(removed, p)
+		}
+	}
+	if len(checkout) > 0 {
+		if _, err := run("git", append([]string{"-C", dir, "checkout-index", "-f", "--"}, checkout...)...); err != nil {
+			return fmt.Errorf("failed to check out staged files: %w", err)
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := run("git", append([]string{"-C", dir, "rm", "-q", "-f", "--ignore-unmatch", "--"}, removed...)...); err != nil {
+			return fmt.Errorf("failed to remove deleted files: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveWorktree deletes a worktree created with AddWorktree; its branch
+// is kept
+func RemoveWorktree(dir string) error {
+	_, err := run("git", "worktree", "remove", "--force", dir)
+	if err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", dir, err)
+	}
+	return nil
+}
+
+// CommitAllIn commits every change in the worktree at dir
+func CommitAllIn(dir, message string) error {
+	if _, err := run("git", "-C", dir, "add", "-A"); err != nil {
+		return err
+	}
+	if _, err := run("git", "-C", dir, "commit", "-q", "-m", message); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
+// TagExists reports whether the tag is in the repository
+func TagExists(tag string) bool {
+	_, err := run("git", "rev-parse", "-q", "--verify", "refs/tags/"+tag)
+	return err == nil
+}
+
+// BranchExists reports whether the local branch is in the repository
+func BranchExists(branch string) bool {
+	_, err := run("git", "rev-parse", "-q", "--verify", "refs/heads/"+branch)
+	return err == nil
+}
+
+// TagMessage returns the message of an annotated tag, without its
+// signature. It fails for lightweight and missing tags.
+func TagMessage(tag string) (string, error) {
+	out, err := run("git", "for-each-ref", "--format=%(objecttype)", "refs/tags/"+tag)
+	switch kind := strings.TrimSpace(out); {
+	case err != nil:
+		return "", fmt.Errorf("failed to read tag %s: %w", tag, err)
+	case kind == "":
+		return "", fmt.Errorf("tag %s not found", tag)
+	case kind != "tag":
+		return "", fmt.Errorf("%s is a lightweight tag; it has no notes", tag)
+	}
+	msg, _, err := tagContents(tag)
+	return msg, err
+}
+
+// TagSigned reports whether an annotated tag carries a signature
+func TagSigned(tag string) (bool, error) {
+	_, signature, err := tagContents(tag)
+	return signature != "", err
+}
+
+// tagContents splits the message of an annotated tag from its signature
+func tagContents(tag string) (msg, signature string, err error) {
+	out, err := run("git", "for-each-ref", "--format=%(contents:signature)%00%(contents)", "refs/tags/"+tag)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read tag %s: %w", tag, err)
+	}
+	signature, contents, _ := strings.Cut(out, "\x00")
+	contents = strings.TrimSuffix(strings.TrimRight(contents, "\n"), strings.TrimRight(signature, "\n"))
+	return strings.TrimSpace(contents), strings.TrimSpace(signature), nil
+}
+
+// PreviousTag returns the tag before the given one matching the glob
+// pattern (any tag if empty), or "" for the first
+func PreviousTag(tag, pattern string) string {
+	out, err := run("git", describeArgs(pattern, tag+"^")...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// describeArgs finds the nearest tag of rev matching pattern
+func describeArgs(pattern, rev string) []string {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if pattern != "" {
+		args = append(args, "--match", pattern)
+	}
+	return append(args, rev)
+}
+
+// ReplaceTag re-creates an annotated tag on the same commit with a new
+// message, signed with the user's key when sign is set
+func ReplaceTag(tag, message string, sign bool) error {
+	out, err := run("git", "rev-parse", "--verify", "refs/tags/"+tag+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	commit := strings.TrimSpace(out)
+	args := []string{"tag", "-a", "-f", "--cleanup=whitespace"}
+	if sign {
+		args = append(args, "-s")
+	}
+	if _, err := run("git", append(args, tag, commit, "-m", message)...); err != nil {
+		return fmt.Errorf("failed to re-create tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// ParseDiff turns a unified diff (as printed by git diff) into per-file
+// changes. Diffs without git headers (diff -u, svn diff, IDE patches) are
+// split on their ---/+++ file headers instead.
+func ParseDiff(diff string) []FileChange {
+	if !strings.HasPrefix(diff, "diff --git ") && !strings.Contains(diff, "\ndiff --git ") {
+		return parsePlainDiff(diff)
+	}
+	var changes []FileChange
+	files := splitDiffByFile(diff)
+	for _, line := range strings.Split(diff, "\n") {
+		if !strings.HasPrefix(line, "diff --git ") {
+			continue
+		}
+		parts := strings.Split(line, " b/")
+		path := parts[len(parts)-1]
+		d := files[path]
+
+		c := FileChange{Path: path, Status: "M", Diff: d}
+		for _, l := range strings.Split(d, "\n") {
+			switch {
+			case strings.HasPrefix(l, "new file mode "):
+				c.Status, c.NewMode = "A", strings.TrimPrefix(l, "new file mode ")
+			case strings.HasPrefix(l, "deleted file mode "):
+				c.Status, c.OldMode = "D", strings.TrimPrefix(l, "deleted file mode ")
+			case strings.HasPrefix(l, "rename from "):
+				c.Status = "R"
+			case strings.HasPrefix(l, "old mode "):
+				c.OldMode = strings.TrimPrefix(l, "old mode ")
+			case strings.HasPrefix(l, "new mode "):
+				c.NewMode = strings.TrimPrefix(l, "new mode ")
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// parsePlainDiff splits a unified diff without git headers on its
+// "--- old" / "+++ new" lines. Text before a file's --- line (Index: lines,
+// ===== separators) belongs to the previous file and is dropped.
+func parsePlainDiff(diff string) []FileChange {
+	var changes []FileChange
+	lines := strings.Split(diff, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") || !strings.HasPrefix(lines[i+1], "+++ ") {
+			continue
+		}
+		oldPath, newPath := plainDiffPath(lines[i][4:]), plainDiffPath(lines[i+1][4:])
+		c := FileChange{Path: newPath, Status: "M"}
+		switch {
+		case oldPath == "/dev/null":
+			c.Status = "A"
+		case newPath == "/dev/null":
+			c.Path, c.Status = oldPath, "D"
+		}
+
+		end := i + 2
+		for end < len(lines) && !(strings.HasPrefix(lines[end], "--- ") && end+1 < len(lines) && strings.HasPrefix(lines[end+1], "+++ ")) {
+			end++
+		}
+		// Drop the next file's Index:/===== preamble
+		body := lines[i:end]
+		for len(body) > 2 {
+			last := body[len(body)-1]
+			if last == "" || strings.HasPrefix(last, " ") || strings.HasPrefix(last, "+") || strings.HasPrefix(last, "-") || strings.HasPrefix(last, "@@") || strings.HasPrefix(last, "\\") {
+				break
+			}
+			body = body[:len(body)-1]
+		}
+		c.Diff = strings.Join(body, "\n")
+		changes = append(changes, c)
+		i = end - 1
+	}
+	return changes
+}
+
+// plainDiffPath reads the path of a ---/+++ header, dropping the timestamp
+// or revision after a tab and the a/ or b/ prefix
+func plainDiffPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return path
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		path = path[2:]
+	}
+	return path
 }
 
 func splitDiffByFile(diff string) map[string]string {
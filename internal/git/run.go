@@ -0,0 +1,142 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// EnvVerbose sets how much of the git commands commitai runs is shown on
+// stderr: 1 the command lines, 2 also what git prints on stderr, 3 its
+// stdout too. It's an environment variable so child commitai runs inherit
+// it.
+const EnvVerbose = "COMMITAI_VERBOSE"
+
+// Trace receives the verbose output
+var Trace io.Writer = os.Stderr
+
+// Verbosity returns the level set in EnvVerbose, 0 by default
+func Verbosity() int {
+	n, _ := strconv.Atoi(os.Getenv(EnvVerbose))
+	return n
+}
+
+// CommandError is a git command that failed: the exact command line and
+// what git said about it
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	detail := strings.TrimSpace(e.Stderr)
+	if detail == "" {
+		detail = e.Err.Error()
+	}
+	return fmt.Sprintf("%s failed: %s", CommandLine(e.Args), detail)
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// CommandLine renders args as a shell command line, quoting arguments
+// that need it
+func CommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Run runs git with args for callers outside this package, with the same
+// read-only check, tracing and errors as every git command commitai runs.
+// It returns everything git printed.
+func Run(args ...string) (string, error) {
+	return run("git", args...)
+}
+
+// run runs a command and returns its combined output. Failures are
+// *CommandError, so they tell what ran and why it failed.
+func run(name string, args ...string) (string, error) {
+	if name == "git" {
+		// The reason doubles as the output, which callers often report
+		if err := readonly.CheckGit(args); err != nil {
+			return err.Error(), err
+		}
+	}
+	out, _, err := runCmd(exec.Command(name, args...))
+	return out, err
+}
+
+// runCmd runs a prepared command (stdin, environment) with tracing and
+// returns its combined output and its stdout alone
+func runCmd(cmd *exec.Cmd) (combined, stdout string, err error) {
+	level := Verbosity()
+	if level >= 1 {
+		fmt.Fprintf(Trace, "$ %s\n", CommandLine(cmd.Args))
+	}
+
+	var all lockedBuffer
+	var out, errOut bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&all, &out)
+	cmd.Stderr = io.MultiWriter(&all, &errOut)
+	err = cmd.Run()
+	combined = all.String()
+
+	if level >= 3 {
+		traceLines(combined)
+	} else if level >= 2 {
+		traceLines(errOut.String())
+	}
+	if err != nil {
+		err = &CommandError{Args: cmd.Args, Stderr: ifEmpty(errOut.String(), combined), Err: err}
+	}
+	return combined, out.String(), err
+}
+
+// traceLines indents what a command printed under its command line
+func traceLines(out string) {
+	out = strings.TrimRight(out, "\n")
+	if out == "" {
+		return
+	}
+	for _, l := range strings.Split(out, "\n") {
+		fmt.Fprintf(Trace, "  │ %s\n", l)
+	}
+}
+
+// lockedBuffer collects stdout and stderr, which exec copies concurrently
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func ifEmpty(s, fallback string) string {
+	if strings.TrimSpace(s) == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package githooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/commitlint"
+)
+
+// Finding is a hook framework set up in the repository that won't do what
+// the project expects when commitai commits
+type Finding struct {
+	Framework string // husky, pre-commit, lefthook, commitlint, commitizen
+	Problem   string
+	Fix       string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Framework, f.Problem)
+}
+
+// Scan looks for hook frameworks in the working tree at top whose hooks are
+// not installed in hooksDir (git rev-parse --git-path hooks), so commits
+// skip checks the project relies on, and for hooks that expect a terminal.
+func Scan(top, hooksDir string) []Finding {
+	var findings []Finding
+	installed := func(hook, marker string) bool {
+		data, err := os.ReadFile(filepath.Join(hooksDir, hook))
+		return err == nil && (marker == "" || strings.Contains(string(data), marker))
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(top, name))
+		return err == nil
+	}
+
+	if exists(".husky") && !strings.Contains(filepath.ToSlash(hooksDir), ".husky") {
+		findings = append(findings, Finding{
+			Framework: "husky",
+			Problem:   "hooks in .husky/ are not installed (core.hooksPath doesn't point there), so they don't run",
+			Fix:       "npm install (or npx husky) to install them",
+		})
+	}
+
+	if exists(".pre-commit-config.yaml") {
+		data, _ := os.ReadFile(filepath.Join(top, ".pre-commit-config.yaml"))
+		switch {
+		case !installed("pre-commit", "pre-commit"):
+			findings = append(findings, Finding{
+				Framework: "pre-commit",
+				Problem:   ".pre-commit-config.yaml exists but its hooks are not installed",
+				Fix:       "pre-commit install",
+			})
+		case strings.Contains(string(data), "commit-msg") && !installed("commit-msg", "pre-commit"):
+			findings = append(findings, Finding{
+				Framework: "pre-commit",
+				Problem:   "commit-msg hooks are configured but the commit-msg hook is not installed, so messages are not checked",
+				Fix:       "pre-commit install --hook-type commit-msg",
+			})
+		}
+	}
+
+	for _, name := range []string{"lefthook.yml", "lefthook.yaml", ".lefthook.yml"} {
+		if exists(name) && !installed("pre-commit", "lefthook") && !installed("commit-msg", "lefthook") {
+			findings = append(findings, Finding{
+				Framework: "lefthook",
+				Problem:   name + " exists but its hooks are not installed",
+				Fix:       "lefthook install",
+			})
+			break
+		}
+	}
+
+	if commitlint.Find(top) != "" && !installed("commit-msg", "") {
+		findings = append(findings, Finding{
+			Framework: "commitlint",
+			Problem:   "commitlint is configured but no commit-msg hook runs it, so messages are only checked later (e.g. in CI)",
+			Fix:       "commitai config --commitlint on to check generated messages before committing",
+		})
+	}
+
+	if data, err := os.ReadFile(filepath.Join(hooksDir, "prepare-commit-msg")); err == nil {
+		hook := string(data)
+		if strings.Contains(hook, "cz --hook") || strings.Contains(hook, "git-cz") || strings.Contains(hook, "commitizen") {
+			findings = append(findings, Finding{
+				Framework: "commitizen",
+				Problem:   "the prepare-commit-msg hook runs commitizen, which asks for the message interactively and may replace commitai's",
+				Fix:       "run commitizen only from npm scripts (npx cz), not as a hook",
+			})
+		}
+	}
+	return findings
+}
+
+// ErrNoCommitlint is returned when commitlint can't be found
+var ErrNoCommitlint = errors.New("commitlint not found (install it with npm install --save-dev @commitlint/cli)")
+
+// Commitlint runs the project's commitlint on msg from top and returns the
+// errors it reports; warnings are ignored, like commitlint's exit status does
+func Commitlint(top, msg string) ([]string, error) {
+	var cmd *exec.Cmd
+	local := filepath.Join(top, "node_modules", ".bin", "commitlint")
+	if _, err := os.Stat(local); err == nil {
+		cmd = exec.Command(local)
+	} else if path, err := exec.LookPath("commitlint"); err == nil {
+		cmd = exec.Command(path)
+	} else if npx, err := exec.LookPath("npx"); err == nil {
+		cmd = exec.Command(npx, "--no-install", "commitlint")
+	} else {
+		return nil, ErrNoCommitlint
+	}
+	cmd.Dir = top
+	cmd.Stdin = strings.NewReader(msg)
+
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("commitlint: %w", err)
+	}
+
+	var problems []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "✖") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "✖"))
+		if strings.HasPrefix(line, "found ") {
+			continue // "found 2 problems, 0 warnings"
+		}
+		problems = append(problems, line)
+	}
+	if len(problems) == 0 {
+		// Not a lint failure (e.g. a broken config or no commitlint for npx)
+		return nil, fmt.Errorf("commitlint failed: %s", strings.TrimSpace(string(out)))
+	}
+	return problems, nil
+}
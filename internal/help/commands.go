@@ -0,0 +1,247 @@
+package help
+
+// commands holds the examples shown in each command's help, keyed by the
+// command path
+var commands = map[string][]Example{
+	"commitai": {
+		{"commitai", "Auto-detect: single message or granular based on file count"},
+		{"commitai --all", "One message for all staged changes"},
+		{"commitai --granular", "Separate message per file"},
+		{"commitai --group-by dir", "One commit per top-level directory"},
+		{"commitai --group-by owner", "One commit per CODEOWNERS owner"},
+		{"commitai --fixups", "Fixup commits for changes to unpushed commits, then autosquash"},
+		{"commitai --granular --bisectable", "Order per-file commits so that each one builds (Go)"},
+		{"commitai --dry-run", "Preview messages without committing"},
+		{"commitai --push", "Commit, then push the branch (upstream set if missing)"},
+		{"commitai --push --comment-issues", "Also post the message and diffstat on the referenced issues"},
+		{"commitai --anonymize-paths generalize", "Send internal/<DIR_1>/<FILE_1>.go instead of real file paths"},
+		{"commitai --read-only", "Guarantee nothing is committed, staged or written"},
+		{"commitai --agent", "JSON plan with an approval token, for AI agents"},
+		{"commitai --approve <token>", "Commit the plan reviewed with --agent"},
+		{"commitai --impact", "End the message with an impact and risk line"},
+		{"commitai say \"...\"", "Polish your own description of the change"},
+		{"commitai status", "Preview what commitai would do, without API calls"},
+		{"commitai --diff-file changes.patch", "Describe a patch file, without git"},
+		{"commitai config", "Configure API key and preferences"},
+		{"commitai release", "Create a tagged release with AI-generated notes"},
+		{"commitai changelog", "Generate CHANGELOG.md from tag history"},
+	},
+	"commitai apply-plan": {
+		{"commitai --granular --plan-out plan.yaml", ""},
+		{"$EDITOR plan.yaml", ""},
+		{"commitai apply-plan plan.yaml --dry-run", ""},
+		{"commitai apply-plan plan.yaml", ""},
+		{"commitai apply-plan --resume", "Continue after a failed commit"},
+		{"commitai apply-plan --abort", "Forget the interrupted plan"},
+	},
+	"commitai ask": {
+		{"commitai ask \"when did we change the retry timeout?\"", ""},
+		{"commitai ask \"why was the cache layer removed?\" --since 2023-01-01", ""},
+		{"commitai ask \"who added rate limiting?\" --path internal/server", ""},
+		{"commitai ask \"max_tokens default\" --search", "Only list matches, no AI"},
+	},
+	"commitai batch": {
+		{"commitai batch --repos repos.txt --yes", ""},
+		{"commitai batch --repos repos.txt --add --all --yes --report batch.json", ""},
+		{"commitai batch ../svc-a ../svc-b --dry-run", ""},
+	},
+	"commitai worktrees": {
+		{"commitai worktrees --list", "Show every worktree and what it has staged"},
+		{"commitai worktrees", "Commit the staged changes of each worktree in turn"},
+		{"commitai worktrees --all --yes", ""},
+	},
+	"commitai changelog": {
+		{"commitai changelog", "Generate the section for the latest tag"},
+		{"commitai changelog --all", "Backfill every tag in history"},
+		{"commitai changelog --all -o docs/CHANGELOG.md", ""},
+		{"commitai changelog --all --no-cache --dry-run", ""},
+	},
+	"commitai checkpoint": {
+		{"commitai checkpoint", ""},
+		{"commitai checkpoint \"before trying the new parser\"", ""},
+		{"commitai checkpoint --staged", ""},
+	},
+	"commitai config": {
+		{"commitai config --key YOUR_GEMINI_API_KEY", ""},
+		{"commitai config --add-key SECOND_KEY --key-rotation round-robin", ""},
+		{"commitai config --lang pt-br", ""},
+		{"commitai config --style conventional", ""},
+		{"commitai config --model gemini-2.5-flash", ""},
+		{"commitai config --provider-command \"llm-gateway --model internal-large\"", ""},
+		{"commitai config --provider offline", ""},
+		{"commitai config --gateway-url https://ai-gateway.corp.example --header 'X-Org-Token=${ORG_TOKEN}'", ""},
+		{"commitai config --closing-keyword Closes", ""},
+		{"commitai config --notify-after 20", ""},
+		{"commitai config --compress 2", ""},
+		{"commitai config --daily-calls 50 --daily-tokens 200000", ""},
+		{"commitai config --provenance on", ""},
+		{"commitai config --commit-template off", ""},
+		{"commitai config --impact-line on", ""},
+		{"commitai config --type-emoji feat=✨ --type-emoji-position description", ""},
+		{"commitai config --deterministic-mode on --default-seed 42", ""},
+		{"commitai config --commitlint on", ""},
+		{"commitai config --data-consent granted", "Don't ask before sending repository data"},
+		{"commitai config --anonymize-paths hash", "Always send hashes instead of file paths"},
+		{"commitai config --privacy on", ""},
+		{"commitai config --protect-branches 'main,release/*'", ""},
+		{"commitai config --policy-url https://example.com/commit-policy.json", ""},
+		{"commitai config --webhook slack=https://hooks.slack.com/services/...", ""},
+		{"commitai config --hook post_tag=\"make dist\"", ""},
+		{"commitai config --forge git.corp.example=gitlab --forge-ca-file git.corp.example=/etc/ssl/corp-ca.pem", ""},
+		{"commitai config --email-from releases@example.com --email-to dev@lists.example.com", ""},
+		{"commitai config --edit-release-notes on", ""},
+		{"commitai config --show", ""},
+		{"commitai config schema > .vscode/commitai.schema.json", "For editor completion and validation"},
+	},
+	"commitai demo": {
+		{"commitai demo", ""},
+		{"commitai demo --keep", "Keep the sandbox to look around afterwards"},
+		{"commitai demo --yes", "Run every step without prompts"},
+	},
+	"commitai doctor": {
+		{"commitai doctor", "Environment and configuration checks"},
+		{"commitai doctor --privacy", "Verify strict privacy mode guarantees"},
+	},
+	"commitai note": {
+		{"commitai note", "Explain HEAD"},
+		{"commitai note a1b2c3d --dry-run", ""},
+		{"commitai note HEAD~2 --append", "Add to an existing note"},
+		{"commitai note --ref review", "Use refs/notes/review"},
+	},
+	"commitai owners": {
+		{"commitai owners", ""},
+		{"commitai owners --authors 5", ""},
+		{"commitai owners --json | jq -r '.reviewers[].name'", ""},
+	},
+	"commitai policy": {
+		{"commitai policy show", ""},
+		{"commitai config --policy-url https://example.com/commit-policy.json", ""},
+		{"commitai config --policy-url git+https://github.com/org/policies.git#commitai.json", ""},
+		{"commitai policy keygen", ""},
+		{"commitai policy sign policy.json --key-file policy.key > bundle.json", ""},
+	},
+	"commitai release": {
+		{"commitai release --auto", "AI suggests version bump"},
+		{"commitai release --major", "Bump major version (1.0.0 -> 2.0.0)"},
+		{"commitai release --minor", "Bump minor version (1.0.0 -> 1.1.0)"},
+		{"commitai release --patch", "Bump patch version (1.0.0 -> 1.0.1)"},
+		{"commitai release --tag v1.2.3", "Use specific tag"},
+		{"commitai release --auto --push", "Auto version + push tags"},
+		{"commitai release --auto --publish --dry-run --json", "Print the release plan as JSON, e.g. for CI"},
+		{"commitai release --auto --pr", "Open a release pull request with the changelog instead of tagging"},
+		{"commitai release --finalize --publish --yes", "Tag and publish the merged release pull request, from CI"},
+		{"commitai release --if-changes --auto --publish", "Nightly release from a scheduled job; no-op without new commits"},
+		{"commitai release --hotfix --base v1.4.2", "Patch release v1.4.3 from the maintenance branch"},
+		{"commitai release --auto --tag-pattern \"app-v*\"", "Version the app-v* tags only, ignoring deploy and infra tags"},
+		{"commitai release --auto --dry-run --deterministic", "Same notes on every rerun, e.g. in CI"},
+		{"commitai release --auto --publish", "Push and create the release on the forge hosting origin"},
+		{"commitai release --auto --provenance", "Also write a signed SLSA provenance attestation of the release"},
+		{"commitai release --auto --stats", "Append commit, file, line and contributor counts from git"},
+		{"commitai release --auto --audience users", "Notes for end users"},
+		{"commitai release --auto --candidates", "Pick sections from terse and detailed notes"},
+		{"commitai release --auto --edit", "Touch up the notes in $EDITOR before tagging"},
+		{"commitai release --major --migration-guide", "Also write MIGRATION.md"},
+		{"commitai release --auto --announce slack", "Post notes to Slack after tagging"},
+		{"commitai release --auto --email notes.eml", "Write an email version of the notes"},
+		{"commitai release --auto --sendmail", "Mail the notes via local sendmail"},
+		{"commitai release --auto --version-files build", "VERSION, OCI labels, build-args for image builds"},
+	},
+	"commitai release edit-notes": {
+		{"commitai release edit-notes v1.2.0", "Fix a typo in the tag's notes"},
+		{"commitai release edit-notes v1.2.0 --publish", "Also force-push the tag and update the forge release"},
+		{"commitai release edit-notes v1.2.0 --regenerate --audience users", "Rewrite the notes from the tag's commits"},
+	},
+	"commitai report": {
+		{"commitai report", "Last month, printed"},
+		{"commitai report --since \"2 weeks ago\" -o REPORT.md", ""},
+		{"commitai report --since 2024-01-01 --until 2024-04-01", ""},
+	},
+	"commitai say": {
+		{"commitai say \"refactored the retry logic to use backoff\"", ""},
+		{"commitai say fixed login redirect loop --dry-run", ""},
+	},
+	"commitai serve": {
+		{"commitai serve", ""},
+		{"commitai serve --addr :8443 --tls-cert server.crt --tls-key server.key", ""},
+		{"commitai serve --token alice=s3cret --token bob=hunter2 --rate-limit 10 --daily-tokens 2000000", ""},
+	},
+	"commitai ci lint-commits": {
+		{"commitai ci lint-commits", "Commits since the default branch, failing below 60"},
+		{"commitai ci lint-commits --range origin/main..HEAD --threshold 70", ""},
+		{"commitai ci lint-commits --sarif commits.sarif", "Annotations for GitHub code scanning"},
+		{"commitai ci lint-commits --json", ""},
+	},
+	"commitai ci": {
+		{"commitai ci lint-commits", "Score the branch's commit messages"},
+	},
+	"commitai stats types": {
+		{"commitai stats types", "Since the latest tag"},
+		{"commitai stats types --since v1.0.0", ""},
+		{"commitai stats types --since v1.0.0 --until v2.0.0 --json", ""},
+	},
+	"commitai watch": {
+		{"commitai watch", ""},
+		{"commitai watch --debounce 30s", ""},
+		{"commitai watch --auto-commit --branch wip/experiments", ""},
+	},
+	"commitai squash": {
+		{"commitai squash", "Draft or update the squash commit of the branch"},
+		{"commitai squash --show", "Print the stored draft"},
+		{"commitai squash --base develop --refresh", "Rewrite it against another target"},
+	},
+	"commitai pr": {
+		{"commitai pr --dry-run", "Show the pull request without opening it"},
+		{"commitai pr", "Push the branch and open the pull request"},
+		{"commitai pr --base develop --draft", "Open a draft against another branch"},
+		{"commitai pr --impact --dry-run", "End the description with an impact and risk line"},
+	},
+	"commitai hook pre-commit-msg": {
+		{"pre-commit install --hook-type prepare-commit-msg --hook-type commit-msg", "After adding the hooks to .pre-commit-config.yaml"},
+		{"commitai hook pre-commit-msg .git/COMMIT_EDITMSG", "Write the message into the file"},
+		{"commitai hook pre-commit-msg --check .git/COMMIT_EDITMSG", "Check the message in the file"},
+	},
+	"commitai series": {
+		{"commitai series --dry-run", "Show the cover letter and patch notes"},
+		{"commitai series -o outgoing/", "Write the patches of main..HEAD"},
+		{"commitai series --range v1.2.0..HEAD -v 2", "Second iteration of a series"},
+		{"git send-email --to=list@example.org outgoing/*.patch", ""},
+	},
+	"commitai tidy": {
+		{"commitai tidy --dry-run", "Show how checkpoints would be squashed"},
+		{"commitai tidy", "Squash unpushed checkpoints into described commits"},
+		{"commitai tidy --depth 500", "Look further back for checkpoints"},
+	},
+	"commitai reword": {
+		{"commitai reword --dry-run", "Show the new messages of the branch's commits"},
+		{"commitai reword", "Reword the commits since the branch left main, one by one"},
+		{"commitai reword --range main..HEAD --yes", "Accept every new message"},
+	},
+	"commitai editor sequence": {
+		{"GIT_SEQUENCE_EDITOR=\"commitai editor sequence --reword-all\" git rebase -i main", "With GIT_EDITOR=\"commitai editor message\""},
+		{"GIT_SEQUENCE_EDITOR=\"commitai editor sequence --squash-checkpoints\" git rebase -i main", ""},
+		{"GIT_SEQUENCE_EDITOR=\"commitai editor sequence --reword HEAD~2 --drop HEAD~4\" git rebase -i main", ""},
+	},
+	"commitai editor message": {
+		{"GIT_EDITOR=\"commitai editor message\" git rebase -i main", "Messages of reworded and squashed commits"},
+		{"GIT_EDITOR=\"commitai editor message --amend\" git commit --amend", "Describe the whole amended commit"},
+	},
+	"commitai restore-backup": {
+		{"commitai restore-backup --list", "Backups made before history rewrites, newest first"},
+		{"commitai restore-backup", "Undo the latest rewrite"},
+		{"commitai restore-backup 20260312-101500 --delete", "Drop a backup you no longer need"},
+	},
+	"commitai status": {
+		{"commitai status", "Staged files with their diff tokens, unstaged and untracked files, no API call"},
+	},
+	"commitai policy sign": {
+		{"commitai policy sign policy.json --key-file policy.key -o bundle.json", ""},
+	},
+	"commitai stats": {
+		{"commitai stats types", "Commit type distribution since the latest tag"},
+	},
+	"commitai examples": {
+		{"commitai examples", "List recipe topics"},
+		{"commitai examples ci", "Print the CI recipe"},
+		{"commitai examples hooks --format markdown", "Paste-ready markdown"},
+	},
+}
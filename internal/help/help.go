@@ -0,0 +1,95 @@
+// Package help is the single source of the examples in each command's help
+// and of the recipes printed by `commitai examples`.
+package help
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Example is one copy-pasteable command line
+type Example struct {
+	Command string
+	Comment string // Optional, printed as a trailing # comment
+}
+
+// Recipe is a multi-step how-to for a topic
+type Recipe struct {
+	Topic    string
+	Title    string
+	Intro    string
+	Steps    []Example
+	Notes    string   // Optional closing paragraph
+	Commands []string // Command paths whose help points to this recipe
+}
+
+// Examples returns the examples of a command, by command path
+// ("commitai stats types")
+func Examples(path string) []Example { return commands[path] }
+
+// Format lays out examples for cobra's Example field, comments aligned
+func Format(examples []Example) string {
+	width := 0
+	for _, e := range examples {
+		if e.Comment != "" {
+			width = max(width, len(e.Command))
+		}
+	}
+	lines := make([]string, len(examples))
+	for i, e := range examples {
+		lines[i] = "  " + e.Command
+		if e.Comment != "" {
+			lines[i] = fmt.Sprintf("  %-*s  # %s", width, e.Command, e.Comment)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Recipes returns every recipe, sorted by topic
+func Recipes() []Recipe {
+	sorted := append([]Recipe(nil), recipes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Topic < sorted[j].Topic })
+	return sorted
+}
+
+// Find returns the recipe of a topic
+func Find(topic string) (Recipe, bool) {
+	for _, r := range recipes {
+		if r.Topic == strings.ToLower(topic) {
+			return r, true
+		}
+	}
+	return Recipe{}, false
+}
+
+// Topics returns the recipe topics that mention a command, sorted
+func Topics(path string) []string {
+	var topics []string
+	for _, r := range Recipes() {
+		for _, c := range r.Commands {
+			if c == path {
+				topics = append(topics, r.Topic)
+				break
+			}
+		}
+	}
+	return topics
+}
+
+// Markdown renders a recipe as a markdown document
+func (r Recipe) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n%s\n\n```bash\n", r.Title, r.Intro)
+	for _, s := range r.Steps {
+		if s.Comment != "" {
+			fmt.Fprintf(&sb, "# %s\n", s.Comment)
+		}
+		sb.WriteString(s.Command + "\n")
+	}
+	sb.WriteString("```\n")
+	if r.Notes != "" {
+		sb.WriteString("\n" + r.Notes + "\n")
+	}
+	return sb.String()
+}
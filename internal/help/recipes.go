@@ -0,0 +1,77 @@
+package help
+
+// recipes are printed by `commitai examples <topic>`
+var recipes = []Recipe{
+	{
+		Topic: "ci",
+		Title: "Releases and reports in CI",
+		Intro: "Run commitai non-interactively in a pipeline. The API key comes from the GEMINI_API_KEY environment variable (a CI secret), so nothing is written to the runner's home.",
+		Steps: []Example{
+			{"export GEMINI_API_KEY=\"$CI_GEMINI_API_KEY\"", "Provided by the CI secret store"},
+			{"commitai doctor", "Fail early on a broken setup"},
+			{"commitai release --auto --yes --push --max-cost 0.05", "Tag with an AI-suggested bump and push"},
+			{"commitai changelog --all", "Refresh CHANGELOG.md (cached sections are reused)"},
+			{"commitai ci lint-commits --range origin/main..HEAD --sarif commits.sarif", "Fail pull requests with vague commit messages"},
+			{"commitai stats types --json > commit-types.json", "Commit type distribution as a build artifact"},
+			{"commitai report --since \"1 week ago\" -o REPORT.md", "Weekly repository report"},
+		},
+		Notes:    "Fetch the full history (in GitHub Actions: `fetch-depth: 0`) so tags and commit ranges resolve. Add `--format plain` to keep logs free of colors and emoji.",
+		Commands: []string{"commitai release", "commitai changelog", "commitai ci lint-commits", "commitai stats types", "commitai report", "commitai doctor"},
+	},
+	{
+		Topic: "hooks",
+		Title: "Release hooks",
+		Intro: "Run builds, publishing or notifications at fixed points of `commitai release`.",
+		Steps: []Example{
+			{"commitai config --hook pre_release=\"make test\"", "Abort the release when tests fail"},
+			{"commitai config --hook post_tag=\"make dist\"", "Build artifacts once the tag exists"},
+			{"commitai config --hook post_push=\"npm publish\"", "Publish after the tag is pushed (--push)"},
+			{"commitai release --auto --push", ""},
+			{"commitai config --hook post_tag=", "Remove a hook"},
+		},
+		Notes:    "Hooks receive COMMITAI_HOOK, COMMITAI_NEW_TAG, COMMITAI_NEW_VERSION, COMMITAI_PREVIOUS_TAG, COMMITAI_PUSH and COMMITAI_NOTES_FILE. Per-repository hooks go under \"hooks\" in the repository's .commitai.json.",
+		Commands: []string{"commitai release", "commitai config"},
+	},
+	{
+		Topic: "monorepo",
+		Title: "Working in a monorepo",
+		Intro: "Keep commits, reviews and releases readable when many packages and teams share one repository.",
+		Steps: []Example{
+			{"commitai --group-by package", "One commit per Go package"},
+			{"commitai --group-by owner", "One commit per CODEOWNERS owner, easier to review"},
+			{"commitai owners", "Reviewers for what is staged"},
+			{"commitai ask \"why was the retry limit lowered?\" --path services/api", "History of one package"},
+			{"commitai stats types --since v1.4.0", "Commit types since the last release"},
+			{"commitai release --auto --dry-run", "Preview the bump and notes"},
+			{"commitai release --auto --push", ""},
+		},
+		Notes:    "Tags are repository-wide: one release covers every package. For many separate repositories, use `commitai batch --repos repos.txt` instead.",
+		Commands: []string{"commitai", "commitai owners", "commitai release", "commitai batch"},
+	},
+	{
+		Topic: "offline",
+		Title: "Without an API key",
+		Intro: "The offline provider writes rule-based messages with no network calls, for air-gapped machines or a first try.",
+		Steps: []Example{
+			{"commitai demo", "Guided tour in a sandbox repository"},
+			{"commitai config --provider offline", ""},
+			{"commitai status", "What commitai would do, no API call"},
+			{"commitai --dry-run", ""},
+			{"commitai config --provider gemini", "Back to AI messages"},
+		},
+		Commands: []string{"commitai config", "commitai demo", "commitai status"},
+	},
+	{
+		Topic: "checkpoints",
+		Title: "Commit early, clean later",
+		Intro: "Save work in progress without stopping to describe it, then turn the checkpoints into proper commits before pushing.",
+		Steps: []Example{
+			{"commitai checkpoint", "WIP commit of everything, no AI call"},
+			{"commitai checkpoint \"before trying the new parser\"", ""},
+			{"commitai tidy --dry-run", "Show how the checkpoints would be squashed"},
+			{"commitai tidy", "Squash them into described commits"},
+			{"git reset --keep refs/commitai/tidy-backup", "Undo the tidy"},
+		},
+		Commands: []string{"commitai checkpoint", "commitai tidy", "commitai watch"},
+	},
+}
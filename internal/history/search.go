@@ -0,0 +1,175 @@
+package history
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// maxTerms bounds the git log calls made for one question
+const maxTerms = 4
+
+// Weights of the ways a commit can match
+const (
+	weightMessage = 2 // A term in the commit message
+	weightCode    = 1 // A term added or removed in the diff
+	weightPhrase  = 3 // Two adjacent terms together in the diff, e.g. retry_timeout
+)
+
+// Candidate is a commit that may answer the question
+type Candidate struct {
+	Commit git.CommitInfo
+	Score  int
+	Lines  []string // Changed lines mentioning the terms, "path: +line"
+}
+
+var stopwords = make(map[string]bool)
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be by can could did do does done for from had has have how
+		i in into is it its me my of on or our should that the their them then there these this those to
+		us was we were what when where which who whom why will with would you your
+		change changed changes changing commit commits add added remove removed update updated
+		introduce introduced last first ever time times code file files repo repository`) {
+		stopwords[w] = true
+	}
+}
+
+var wordRe = regexp.MustCompile(`[A-Za-z0-9_][A-Za-z0-9_.-]*[A-Za-z0-9_]`)
+
+// Terms extracts the words of a question worth searching for, in order,
+// without stopwords. Identifiers (retry_timeout, maxRetries) are kept whole.
+func Terms(question string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range wordRe.FindAllString(question, -1) {
+		lower := strings.ToLower(w)
+		if len(w) < 3 || stopwords[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		terms = append(terms, w)
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// caseless builds an extended regex matching s in any letter case; git's
+// -G has no case-insensitive mode
+func caseless(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		lower, upper := strings.ToLower(string(r)), strings.ToUpper(string(r))
+		switch {
+		case lower != upper:
+			sb.WriteString("[" + lower + upper + "]")
+		case strings.ContainsRune(`.+*?()|[]{}^$\`, r):
+			sb.WriteString(`\` + string(r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Search gathers commits related to the terms from their messages and
+// diffs (pickaxe), scores them and returns the best n, most relevant first.
+// Changed lines mentioning the terms are attached for context.
+func Search(terms []string, n int, opts git.SearchOptions) ([]Candidate, error) {
+	byHash := make(map[string]*Candidate)
+	var order []*Candidate
+	add := func(pattern string, pickaxe bool, weight int) error {
+		commits, err := git.SearchCommits(pattern, pickaxe, opts)
+		if err != nil {
+			return err
+		}
+		for _, c := range commits {
+			cand, ok := byHash[c.Hash]
+			if !ok {
+				cand = &Candidate{Commit: c}
+				byHash[c.Hash] = cand
+				order = append(order, cand)
+			}
+			cand.Score += weight
+		}
+		return nil
+	}
+
+	for i, t := range terms {
+		if err := add(regexp.QuoteMeta(t), false, weightMessage); err != nil {
+			return nil, err
+		}
+		if err := add(caseless(t), true, weightCode); err != nil {
+			return nil, err
+		}
+		// retry_timeout, retryTimeout, "retry timeout", RETRY-TIMEOUT
+		if i > 0 {
+			if err := add(caseless(terms[i-1])+"[ _.-]?"+caseless(t), true, weightPhrase); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].Score != order[j].Score {
+			return order[i].Score > order[j].Score
+		}
+		return order[i].Commit.Date.After(order[j].Commit.Date)
+	})
+	if len(order) > n {
+		order = order[:n]
+	}
+	candidates := make([]Candidate, len(order))
+	for i, c := range order {
+		c.Lines = matchingLines(c.Commit.Hash, terms)
+		candidates[i] = *c
+	}
+	return candidates, nil
+}
+
+// Changed lines attached per candidate, and their maximum length
+const (
+	maxLines     = 8
+	maxLineBytes = 200
+)
+
+// matchingLines returns the added and removed lines of a commit that
+// mention one of the terms
+func matchingLines(hash string, terms []string) []string {
+	diff, err := git.ShowDiff(hash)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	file := ""
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "--- "), strings.HasPrefix(l, "+++ "):
+			// The new path, or the old one for deleted files
+			if name := l[4:]; name != "/dev/null" {
+				file = name[strings.Index(name, "/")+1:]
+			}
+			continue
+		case !strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "-"):
+			continue
+		}
+		lower := strings.ToLower(l)
+		for _, t := range terms {
+			if strings.Contains(lower, strings.ToLower(t)) {
+				if len(l) > maxLineBytes {
+					l = l[:maxLineBytes] + "…"
+				}
+				lines = append(lines, file+": "+l[:1]+strings.TrimSpace(l[1:]))
+				break
+			}
+		}
+		if len(lines) == maxLines {
+			break
+		}
+	}
+	return lines
+}
@@ -0,0 +1,58 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+)
+
+// Release hook points
+const (
+	PreRelease = "pre_release" // After confirmation, before the tag is created; failure aborts
+	PostTag    = "post_tag"    // After the tag and release files are written, before pushing
+	PostPush   = "post_push"   // After the tag was pushed and announced
+)
+
+// Names lists the supported hook points in execution order
+var Names = []string{PreRelease, PostTag, PostPush}
+
+// Supported reports whether name is a known hook point
+func Supported(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run executes a hook command through the shell with the current environment
+// plus env and COMMITAI_HOOK. Output goes straight to the terminal.
+func Run(name, command string, env map[string]string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	cmd.Env = append(os.Environ(), "COMMITAI_HOOK="+name)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package i18n localizes commitai's own prompts and status messages. The
+// generated commit messages and notes follow config.Language through the
+// prompt instead.
+//
+// Messages are looked up by their English text, so untranslated messages
+// fall back to English and call sites read as they always did.
+package i18n
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// Supported UI languages
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+)
+
+// catalogs maps a language to its translations, keyed by the English text
+var catalogs = map[string]map[string]string{
+	Portuguese: ptBR,
+}
+
+var current = English
+
+// Normalize maps a language tag or locale (pt-br, pt_BR.UTF-8, en_US) to a
+// supported UI language, or "" when there is no catalog for it
+func Normalize(tag string) string {
+	tag, _, _ = strings.Cut(tag, ".") // pt_BR.UTF-8
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	switch {
+	case tag == "pt" || strings.HasPrefix(tag, "pt-"):
+		return Portuguese
+	case tag == "en" || strings.HasPrefix(tag, "en-"):
+		return English
+	}
+	return ""
+}
+
+// Detect picks the UI language: the configured message language when it
+// isn't the default English, then the locale (LC_ALL, LC_MESSAGES, LANG),
+// then English
+func Detect(configured string) string {
+	if lang := Normalize(configured); lang != "" && lang != English {
+		return lang
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if lang := Normalize(v); lang != "" {
+				return lang
+			}
+			return English // The first locale set wins, like in C programs
+		}
+	}
+	return English
+}
+
+// Set selects the UI language; unsupported languages select English
+func Set(lang string) {
+	if _, ok := catalogs[lang]; !ok {
+		lang = English
+	}
+	current = lang
+}
+
+// Lang returns the selected UI language
+func Lang() string { return current }
+
+// T translates msg into the selected language, or returns it unchanged
+func T(msg string) string {
+	if tr, ok := catalogs[current][msg]; ok {
+		return tr
+	}
+	return msg
+}
+
+// answers holds the extra yes and no answers of each language
+var answers = map[string]struct{ yes, no []string }{
+	Portuguese: {yes: []string{"s", "sim"}, no: []string{"n", "nao", "não"}},
+}
+
+// Yes reports whether input is an explicit yes, in English or the selected language
+func Yes(input string) bool {
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes" || slices.Contains(answers[current].yes, input)
+}
+
+// No reports whether input is an explicit no, in English or the selected language
+func No(input string) bool {
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "n" || input == "no" || slices.Contains(answers[current].no, input)
+}
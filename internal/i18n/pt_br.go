@@ -0,0 +1,95 @@
+package i18n
+
+// ptBR is the Brazilian Portuguese catalog. Keep the format verbs in the
+// same order as in the English text.
+var ptBR = map[string]string{
+	// Commit flow
+	"🔍 Analyzing staged changes...":                                     "🔍 Analisando as mudanças preparadas...",
+	"No staged changes found. Use 'git add' to stage files.":            "Nenhuma mudança preparada. Use 'git add' para preparar arquivos.",
+	"\n📂 Staged files (%d):":                                            "\n📂 Arquivos preparados (%d):",
+	"\n✨ Generating commit message(s) with %s...":                       "\n✨ Gerando mensagem(ns) de commit com %s...",
+	"⚠️  Using cached policy bundle: %s":                                "⚠️  Usando o pacote de políticas em cache: %s",
+	"🗜️  Compression saved ~%d input tokens (%d%%)":                     "🗜️  A compressão economizou ~%d tokens de entrada (%d%%)",
+	"🧮 ~%d input tokens, up to %d output tokens":                        "🧮 ~%d tokens de entrada, até %d tokens de saída",
+	"🧮 ~%d input tokens, up to %d output tokens, est. max $%.4f (%s)":   "🧮 ~%d tokens de entrada, até %d tokens de saída, máx. estimado $%.4f (%s)",
+	"💬 Suggested commit message:":                                       "💬 Mensagem de commit sugerida:",
+	"\n🔍 Dry run — no commit was made.":                                 "\n🔍 Simulação — nenhum commit foi feito.",
+	"✨ Refining...":                                                     "✨ Refinando...",
+	"Commit cancelled.":                                                 "Commit cancelado.",
+	"\n✅ Committed successfully!":                                       "\n✅ Commit feito com sucesso!",
+	"💬 Suggested commit messages (per file):":                           "💬 Mensagens de commit sugeridas (por arquivo):",
+	"💬 Suggested commit messages (per group):":                          "💬 Mensagens de commit sugeridas (por grupo):",
+	"\n⚠️  No AI message for %d of %d group(s): %s":                     "\n⚠️  Sem mensagem da IA para %d de %d grupo(s): %s",
+	"\n[%d/%d] %s — already committed":                                  "\n[%d/%d] %s — já commitado",
+	"\n🔍 Dry run — no commits were made.":                               "\n🔍 Simulação — nenhum commit foi feito.",
+	"\n🎉 All %d commits created!":                                       "\n🎉 Todos os %d commits criados!",
+	"✖ AI returned a message for %s, which is not staged — ignored":     "✖ A IA retornou uma mensagem para %s, que não está preparado — ignorada",
+	"\n⚠️  No AI message for %d of %d staged file(s):":                  "\n⚠️  Sem mensagem da IA para %d de %d arquivo(s) preparado(s):",
+	"🚫 Policy violations:":                                              "🚫 Violações de política:",
+	"⚠️  Subject is %d columns wide (recommended max %d)":               "⚠️  O assunto tem %d colunas (máximo recomendado %d)",
+	"✖ Refinement failed: %s":                                           "✖ O refinamento falhou: %s",
+	"✨ Polishing your description against %d staged file(s)...":         "✨ Lapidando sua descrição com base em %d arquivo(s) preparado(s)...",
+	"\n⚡ Use this message? [Y/n/e(dit)/r(efine)]: ":                     "\n⚡ Usar esta mensagem? [S/n/e(ditar)/r(efinar)]: ",
+	"\n⚡ Use this message? [Y/n/e(dit)]: ":                              "\n⚡ Usar esta mensagem? [S/n/e(ditar)]: ",
+	"\n⚡ Commit all with these messages? [Y/n/e(dit)]: ":                "\n⚡ Commitar tudo com estas mensagens? [S/n/e(ditar)]: ",
+	"Enter your message: ":                                              "Digite sua mensagem: ",
+	"How should it change? (e.g. shorter, mention the config change): ": "O que mudar? (ex.: mais curta, mencionar a mudança de config): ",
+
+	// Status
+	"📂 No staged changes — commitai has nothing to commit yet.":            "📂 Nenhuma mudança preparada — o commitai ainda não tem o que commitar.",
+	"📂 Staged (%d) — commitai would create %s:":                            "📂 Preparados (%d) — o commitai criaria %s:",
+	"↔️  Moved code (described as a move, not as added and removed code):": "↔️  Código movido (descrito como movimentação, não como código adicionado e removido):",
+	"🧩 Grouping hints (related changes could be committed together):":      "🧩 Sugestões de agrupamento (mudanças relacionadas podem ir no mesmo commit):",
+	"✏️  Not staged (%d) — ignored by commitai:":                           "✏️  Não preparados (%d) — ignorados pelo commitai:",
+	"❔ Untracked (%d) — ignored by commitai:":                              "❔ Não rastreados (%d) — ignorados pelo commitai:",
+	"one commit for all files":                                             "um commit para todos os arquivos",
+	"%d commits, one per file":                                             "%d commits, um por arquivo",
+	"  Use 'git add' to stage files for commitai.":                         "  Use 'git add' para preparar arquivos para o commitai.",
+
+	// API key setup
+	"⚠️  No Gemini API key configured.":                       "⚠️  Nenhuma chave da API Gemini configurada.",
+	"  [k] Enter a key now (checked, then saved to %s)":       "  [k] Informar uma chave agora (verificada e salva em %s)",
+	"  [o] Use the offline rule-based generator for this run": "  [o] Usar o gerador offline baseado em regras nesta execução",
+	"  [q] Quit":       "  [q] Sair",
+	"Choice [k/o/q]: ": "Opção [k/o/q]: ",
+	"🔐 commitai sends data from this repository to %s:":                                      "🔐 O commitai envia dados deste repositório para %s:",
+	"The staged diffs: changed lines with a few lines of context":                            "Os diffs preparados: linhas alteradas com algumas linhas de contexto",
+	"The staged diffs, string literals, emails, hostnames and URLs replaced by placeholders": "Os diffs preparados, com strings, e-mails, hosts e URLs trocados por marcadores",
+	"File names and paths":                         "Nomes e caminhos de arquivos",
+	"Subjects of recent commits":                   "Assuntos de commits recentes",
+	"The branch name":                              "O nome do branch",
+	"  [y] Agree for this repository (asked once)": "  [y] Concordar para este repositório (perguntado uma vez)",
+	"Choice [y/o/q]: ":                             "Opção [y/o/q]: ",
+	"Using offline rules. Get a key at https://aistudio.google.com/app/apikey for AI messages.": "Usando regras offline. Obtenha uma chave em https://aistudio.google.com/app/apikey para mensagens com IA.",
+	"Gemini API key (https://aistudio.google.com/app/apikey): ":                                 "Chave da API Gemini (https://aistudio.google.com/app/apikey): ",
+	"🔑 Checking the key...":             "🔑 Verificando a chave...",
+	"✅ Key works (%d models available)": "✅ A chave funciona (%d modelos disponíveis)",
+	"✅ Key works: %d models available":  "✅ A chave funciona: %d modelos disponíveis",
+	"⚠️  Could not save the key: %s":    "⚠️  Não foi possível salvar a chave: %s",
+	"⚠️  Could not check the key: %s":   "⚠️  Não foi possível verificar a chave: %s",
+	"✅ API key saved":                   "✅ Chave da API salva",
+
+	// Checkpoints
+	"Nothing to checkpoint.":                                    "Nada para salvar em checkpoint.",
+	"No unpushed checkpoint commits to tidy.":                   "Nenhum commit de checkpoint não enviado para organizar.",
+	"✨ Generating messages for %d checkpoint run(s) with %s...": "✨ Gerando mensagens para %d sequência(s) de checkpoints com %s...",
+	"🧹 Tidy plan for %s:":                                       "🧹 Plano de organização para %s:",
+	"\n🔍 Dry run — history was not rewritten.":                  "\n🔍 Simulação — o histórico não foi reescrito.",
+	"\n⚡ Rewrite history as shown? [y/N]: ":                     "\n⚡ Reescrever o histórico como mostrado? [s/N]: ",
+	"Tidy cancelled.":                                           "Organização cancelada.",
+	"\n✅ Tidied %d checkpoint run(s). Undo with: git reset --keep refs/commitai/tidy-backup": "\n✅ %d sequência(s) de checkpoints organizada(s). Desfaça com: git reset --keep refs/commitai/tidy-backup",
+
+	// Pull requests
+	"\n⚡ Open this pull request? [y/N]: ":         "\n⚡ Abrir este pull request? [s/N]: ",
+	"Pull request cancelled.":                     "Pull request cancelado.",
+	"\n🔍 Dry run — nothing was pushed or opened.": "\n🔍 Simulação — nada foi enviado nem aberto.",
+	"✅ Pull request opened: %s":                   "✅ Pull request aberto: %s",
+
+	// Config
+	"💾 Config saved to %s":        "💾 Configuração salva em %s",
+	"⚙️  commitai configuration:": "⚙️  Configuração do commitai:",
+	"✅ Language set to: %s":       "✅ Idioma definido como: %s",
+	"✅ Commit style set to: %s":   "✅ Estilo de commit definido como: %s",
+	"✅ Model set to: %s":          "✅ Modelo definido como: %s",
+	"✅ Output format: %s":         "✅ Formato de saída: %s",
+}
@@ -0,0 +1,69 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Where TypeEmoji puts the emoji
+const (
+	EmojiStart       = "start"       // ✨ feat(api): add x
+	EmojiDescription = "description" // feat(api): ✨ add x
+)
+
+var (
+	typeHeaderRe = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?!?: `)
+	shortcodeRe  = regexp.MustCompile(`^:[a-z0-9_+-]+:(?:\s+|$)`)
+)
+
+// LeadingEmoji returns the emoji (or :shortcode:) s starts with, with the
+// spaces after it, or "" if it doesn't start with one
+func LeadingEmoji(s string) string {
+	if m := shortcodeRe.FindString(s); m != "" {
+		return m
+	}
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end <= 0 {
+		return ""
+	}
+	for _, r := range s[:end] {
+		if r < 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return ""
+		}
+	}
+	return s[:len(s)-len(strings.TrimLeftFunc(s[end:], unicode.IsSpace))]
+}
+
+// TypeEmoji decorates the subject of a Conventional Commits message with
+// the emoji mapped to its type, in front of the subject or of the
+// description. An emoji already in that place is replaced, so the result
+// only depends on the type. Messages whose type isn't mapped are returned
+// as is.
+func TypeEmoji(msg string, emojis map[string]string, position string) string {
+	if len(emojis) == 0 {
+		return msg
+	}
+	subject, rest, multiline := strings.Cut(msg, "\n")
+	subject = strings.TrimPrefix(subject, LeadingEmoji(subject))
+	header := typeHeaderRe.FindStringSubmatch(subject)
+	if header == nil {
+		return msg
+	}
+	emoji := strings.TrimSpace(emojis[strings.ToLower(header[1])])
+	if emoji == "" {
+		return msg
+	}
+	prefix, description := header[0], subject[len(header[0]):]
+	description = strings.TrimPrefix(description, LeadingEmoji(description))
+
+	if position == EmojiDescription {
+		subject = prefix + emoji + " " + description
+	} else {
+		subject = emoji + " " + prefix + description
+	}
+	if !multiline {
+		return subject
+	}
+	return subject + "\n" + rest
+}
@@ -0,0 +1,36 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Provenance appends trailers disclosing that the message was AI-generated
+// and whether the user edited it before committing.
+func Provenance(msg, version, model string, edited bool) string {
+	return AppendFooters(msg, []string{
+		fmt.Sprintf("AI-Generated-By: commitai %s (%s)", version, model),
+		fmt.Sprintf("AI-Edited: %t", edited),
+	})
+}
+
+// AppendFooters adds footer lines, separated from the body by a blank line
+// unless the message already ends in a footer block.
+func AppendFooters(msg string, footers []string) string {
+	msg = strings.TrimRight(msg, "\n ")
+	lines := strings.Split(msg, "\n")
+	last := lines[len(lines)-1]
+	if len(lines) > 1 && IsFooter(last) {
+		return msg + "\n" + strings.Join(footers, "\n")
+	}
+	return msg + "\n\n" + strings.Join(footers, "\n")
+}
+
+var footerRe = regexp.MustCompile(`^([A-Za-z][\w-]*|BREAKING CHANGE)(: | #)`)
+
+// IsFooter reports whether line is a git trailer or footer, e.g.
+// "Closes #12" or "Signed-off-by: A <a@b.c>"
+func IsFooter(line string) bool {
+	return footerRe.MatchString(line)
+}
@@ -0,0 +1,129 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// In the last segment of a branch name: fix/issue-45, gh-12_cleanup,
+	// login-#7, or a leading number as in feature/123-login and 789
+	branchIssueRe   = regexp.MustCompile(`(?i)(?:^|[_-])(?:issue-?|gh-|#)(\d+)(?:[_-]|$)`)
+	branchLeadingRe = regexp.MustCompile(`^(\d+)(?:[_-]|$)`)
+
+	// Azure Boards work items: feature/AB#1234-login, ab-1234, AB1234
+	workItemRe = regexp.MustCompile(`(?i)(?:^|[/_-])AB[#-]?(\d+)(?:[/_-]|$)`)
+
+	// Issue references in messages: #123, and Jira keys such as PROJ-123
+	issueRefRe = regexp.MustCompile(`(?:^|[\s(])#(\d+)\b`)
+	jiraRefRe  = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-\d+\b`)
+)
+
+// IssuesFromBranch extracts issue numbers referenced in a branch name. Only
+// the last segment counts, and a number must be marked as an issue or lead
+// the segment: release/2024-fixes and feat/v2-api reference none. Tracker
+// keys like JIRA-12 and AB#12 aren't forge issue numbers either.
+func IssuesFromBranch(branch string) []string {
+	if branch == "" || branch == "HEAD" {
+		return nil
+	}
+	last := branch[strings.LastIndex(branch, "/")+1:]
+	var issues []string
+	if m := branchLeadingRe.FindStringSubmatch(last); m != nil && !looksLikeYear(m[1]) {
+		issues = append(issues, m[1])
+	}
+	for _, m := range branchIssueRe.FindAllStringSubmatch(last, -1) {
+		issues = appendUnique(issues, m[1])
+	}
+	return issues
+}
+
+// looksLikeYear reports a number such as 2024, which in a branch name is
+// more likely a date than an issue
+func looksLikeYear(n string) bool {
+	return len(n) == 4 && (strings.HasPrefix(n, "19") || strings.HasPrefix(n, "20"))
+}
+
+// IssueRefs extracts the issue numbers a message references as #N
+func IssueRefs(msg string) []string {
+	var issues []string
+	for _, m := range issueRefRe.FindAllStringSubmatch(msg, -1) {
+		issues = appendUnique(issues, m[1])
+	}
+	return issues
+}
+
+// JiraKeys extracts the Jira issue keys (PROJ-123) in a branch name or
+// message
+func JiraKeys(text string) []string {
+	var keys []string
+	for _, k := range jiraRefRe.FindAllString(text, -1) {
+		keys = appendUnique(keys, k)
+	}
+	return keys
+}
+
+// EnsureClosingKeywords appends a "<keyword> #N" footer for every issue that
+// isn't already closed by the message.
+func EnsureClosingKeywords(msg string, issues []string, keyword string) string {
+	if keyword == "" {
+		return msg
+	}
+
+	var footers []string
+	for _, n := range issues {
+		closed := regexp.MustCompile(`(?i)\b(close[sd]?|fix(es|ed)?|resolve[sd]?)\s*:?\s+#` + n + `\b`)
+		if !closed.MatchString(msg) {
+			footers = append(footers, fmt.Sprintf("%s #%s", keyword, n))
+		}
+	}
+	if len(footers) == 0 {
+		return msg
+	}
+	return AppendFooters(msg, footers)
+}
+
+// WorkItemsFromBranch extracts Azure Boards work item IDs (AB#1234)
+// referenced in a branch name
+func WorkItemsFromBranch(branch string) []string {
+	if branch == "" || branch == "HEAD" {
+		return nil
+	}
+	var items []string
+	for _, m := range workItemRe.FindAllStringSubmatch(branch, -1) {
+		items = appendUnique(items, m[1])
+	}
+	return items
+}
+
+// EnsureWorkItems appends an "AB#N" footer, after keyword if one is given,
+// for every work item the message doesn't mention yet. Azure Boards links
+// commits to the work items they mention.
+func EnsureWorkItems(msg string, items []string, keyword string) string {
+	var footers []string
+	for _, id := range items {
+		mentioned := regexp.MustCompile(`(?i)\bAB#` + id + `\b`)
+		if mentioned.MatchString(msg) {
+			continue
+		}
+		footer := "AB#" + id
+		if keyword != "" {
+			footer = keyword + " " + footer
+		}
+		footers = append(footers, footer)
+	}
+	if len(footers) == 0 {
+		return msg
+	}
+	return AppendFooters(msg, footers)
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
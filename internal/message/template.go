@@ -0,0 +1,101 @@
+package message
+
+import "strings"
+
+// Template is a commit.template: static lines (section headings,
+// boilerplate) every message keeps, and comment lines telling the author
+// what to write, which never end up in the commit
+type Template struct {
+	Text    string
+	Comment string // Comment character, usually "#"
+}
+
+// ParseTemplate reads a commit template whose guidance lines start with comment
+func ParseTemplate(text, comment string) Template {
+	if comment == "" {
+		comment = "#"
+	}
+	return Template{Text: strings.TrimSpace(text), Comment: comment}
+}
+
+// Empty reports whether there is no template to follow
+func (t Template) Empty() bool {
+	return t.Text == ""
+}
+
+// Static returns the non-empty lines that are not comments. The first line
+// is the subject, which every message replaces, so it is left out.
+func (t Template) Static() []string {
+	var lines []string
+	for i, line := range strings.Split(t.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if i > 0 && line != "" && !strings.HasPrefix(line, t.Comment) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Fill makes msg follow the template: echoed guidance lines are removed and
+// static lines the message dropped are added back at the end. A static line
+// counts as kept when a message line starts with it, so "Ticket:" is kept by
+// "Ticket: ABC-12".
+func (t Template) Fill(msg string) string {
+	if t.Empty() {
+		return msg
+	}
+
+	guidance := make(map[string]bool)
+	for _, line := range strings.Split(t.Text, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, t.Comment) {
+			guidance[line] = true
+		}
+	}
+	var kept []string
+	for _, line := range strings.Split(msg, "\n") {
+		if !guidance[strings.TrimSpace(line)] {
+			kept = append(kept, line)
+		}
+	}
+	msg = strings.TrimSpace(strings.Join(kept, "\n"))
+
+	var missing []string
+	for _, static := range t.Static() {
+		found := false
+		for _, line := range kept {
+			if strings.HasPrefix(strings.TrimSpace(line), static) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, static)
+		}
+	}
+	if len(missing) == 0 {
+		return msg
+	}
+	return AppendFooters(msg, missing)
+}
+
+// scissors marks the start of the diff `git commit --verbose` appends to
+// the message file; nothing below it is part of the message
+const scissors = " ------------------------ >8 ------------------------"
+
+// StripComments returns the message git would record from a message file:
+// comment lines and everything below the scissors line are dropped
+func StripComments(text, comment string) string {
+	if comment == "" {
+		comment = "#"
+	}
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		if line == comment+scissors {
+			break
+		}
+		if !strings.HasPrefix(line, comment) {
+			kept = append(kept, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SubjectWidth is the conventional maximum subject width in terminal columns
+const SubjectWidth = 72
+
+// DisplayWidth returns the number of terminal columns s occupies.
+// East Asian wide and fullwidth characters count as two columns; combining
+// marks and format characters (zero-width joiners, bidi marks) count as none.
+func DisplayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wideRanges are the East Asian Wide (W) and Fullwidth (F) blocks
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initials
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo Extended-A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x20000, 0x3FFFD}, // CJK Extensions B and beyond
+}
+
+func isWide(r rune) bool {
+	if r < 0x1100 {
+		return false
+	}
+	for _, rg := range wideRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWideLanguage reports whether messages in lang are mostly written with
+// double-width characters (Chinese, Japanese, Korean)
+func IsWideLanguage(lang string) bool {
+	lang = strings.ToLower(lang)
+	for _, prefix := range []string{"zh", "ja", "ko"} {
+		if lang == prefix || strings.HasPrefix(lang, prefix+"-") || strings.HasPrefix(lang, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package message
+
+import "regexp"
+
+// Kinds of word diff spans
+const (
+	SpanSame = iota
+	SpanRemoved
+	SpanAdded
+)
+
+// Span is a run of text a word diff keeps, removes or adds
+type Span struct {
+	Kind int
+	Text string
+}
+
+// wordRe splits text into words, runs of whitespace and single punctuation
+// characters
+var wordRe = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|[^\p{L}\p{N}_\s]`)
+
+// maxDiffWords bounds the quadratic diff; longer texts are compared whole
+const maxDiffWords = 4000
+
+// WordDiff returns the word-level changes that turn old into new, like git
+// diff --word-diff
+func WordDiff(old, new string) []Span {
+	a, b := wordRe.FindAllString(old, -1), wordRe.FindAllString(new, -1)
+	if len(a) > maxDiffWords || len(b) > maxDiffWords {
+		return merge([]Span{{SpanRemoved, old}, {SpanAdded, new}})
+	}
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var spans []Span
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			spans = append(spans, Span{SpanSame, a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			spans = append(spans, Span{SpanRemoved, a[i]})
+			i++
+		default:
+			spans = append(spans, Span{SpanAdded, b[j]})
+			j++
+		}
+	}
+	return merge(spans)
+}
+
+// merge joins adjacent spans of the same kind and drops empty ones
+func merge(spans []Span) []Span {
+	var out []Span
+	for _, s := range spans {
+		switch {
+		case s.Text == "":
+		case len(out) > 0 && out[len(out)-1].Kind == s.Kind:
+			out[len(out)-1].Text += s.Text
+		default:
+			out = append(out, s)
+		}
+	}
+	return out
+}
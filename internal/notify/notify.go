@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Send shows a desktop notification using the platform's native mechanism:
+// notify-send on Linux/BSD, osascript on macOS and a PowerShell balloon tip
+// on Windows.
+func Send(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleString(body), appleString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.BalloonTipTitle = %s
+$n.BalloonTipText = %s
+$n.Visible = $true
+$n.ShowBalloonTip(5000)
+Start-Sleep -Seconds 5
+$n.Dispose()`, psString(title), psString(body))
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=commitai", title, body)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop notification failed: %s", strings.TrimSpace(string(out)+" "+err.Error()))
+	}
+	return nil
+}
+
+// After sends the notification in the background when the operation that
+// started at start took at least threshold. A zero threshold disables it.
+// Errors are ignored: a missing notifier must never fail the operation.
+func After(threshold time.Duration, start time.Time, title, body string) {
+	if threshold <= 0 || time.Since(start) < threshold {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		Send(title, body)
+		close(done)
+	}()
+	// Give the notifier a moment, but don't hold the prompt hostage
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+	}
+}
+
+// appleString quotes s as an AppleScript string literal
+func appleString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// psString quotes s as a single-quoted PowerShell string literal
+func psString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
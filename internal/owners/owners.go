@@ -0,0 +1,111 @@
+package owners
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations GitHub and GitLab look for a CODEOWNERS file, in order
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Rule is one CODEOWNERS line
+type Rule struct {
+	Pattern string
+	Owners  []string // Empty when the pattern explicitly has no owner
+	re      *regexp.Regexp
+}
+
+// Codeowners is a parsed CODEOWNERS file
+type Codeowners struct {
+	Path  string // Relative to the repository root
+	Rules []Rule
+}
+
+// Load reads the first CODEOWNERS file found under root, or returns nil if
+// the repository has none
+func Load(root string) (*Codeowners, error) {
+	for _, loc := range Locations {
+		f, err := os.Open(filepath.Join(root, loc))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parse(loc, f)
+	}
+	return nil, nil
+}
+
+func parse(path string, f *os.File) (*Codeowners, error) {
+	c := &Codeowners{Path: path}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// GitLab [Section] headers and negations are not ownership rules
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		re, err := regexp.Compile(globToRegexp(fields[0]))
+		if err != nil {
+			continue // Unusable pattern; GitHub ignores it too
+		}
+		c.Rules = append(c.Rules, Rule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	return c, scanner.Err()
+}
+
+// Owners returns the owners of path and the pattern that assigned them. The
+// last matching rule wins; ok is false when no rule matches.
+func (c *Codeowners) Owners(path string) (owners []string, pattern string, ok bool) {
+	if c == nil {
+		return nil, "", false
+	}
+	path = strings.TrimPrefix(path, "./")
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		if c.Rules[i].re.MatchString(path) {
+			return c.Rules[i].Owners, c.Rules[i].Pattern, true
+		}
+	}
+	return nil, "", false
+}
+
+// globToRegexp converts a gitignore-style CODEOWNERS pattern. Patterns
+// without a slash match at any depth; a pattern matching a directory
+// matches everything under it.
+func globToRegexp(pattern string) string {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString("(?:/.*)?$")
+	return sb.String()
+}
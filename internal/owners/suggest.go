@@ -0,0 +1,87 @@
+package owners
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Sources of a reviewer suggestion
+const (
+	SourceCodeowners = "codeowners"
+	SourceBlame      = "blame"
+)
+
+// FileOwners is who owns and who last wrote the lines of one staged file
+type FileOwners struct {
+	Path    string       `json:"path"`
+	Owners  []string     `json:"owners,omitempty"`
+	Rule    string       `json:"rule,omitempty"` // CODEOWNERS pattern that assigned the owners
+	Authors []git.Author `json:"authors,omitempty"`
+}
+
+// Reviewer is a suggested reviewer for the staged changes
+type Reviewer struct {
+	Name   string `json:"name"` // CODEOWNERS owner (@team, @user, email) or "Name <email>"
+	Source string `json:"source"`
+	Files  int    `json:"files"`           // Staged files they own or wrote lines of
+	Lines  int    `json:"lines,omitempty"` // Changed lines they wrote (blame only)
+}
+
+// Suggest ranks reviewers: every CODEOWNERS owner (they have to approve
+// anyway) by files owned, then up to maxAuthors blame authors by changed
+// lines written. exclude is the current user's email, who can't review their
+// own change.
+func Suggest(files []FileOwners, exclude string, maxAuthors int) []Reviewer {
+	owned := make(map[string]int)
+	written := make(map[string]*Reviewer)
+	for _, f := range files {
+		for _, o := range f.Owners {
+			if !strings.EqualFold(o, exclude) {
+				owned[o]++
+			}
+		}
+		for _, a := range f.Authors {
+			if strings.EqualFold(a.Email, exclude) {
+				continue
+			}
+			r, ok := written[a.Email]
+			if !ok {
+				r = &Reviewer{Name: a.Name + " <" + a.Email + ">", Source: SourceBlame}
+				written[a.Email] = r
+			}
+			r.Files++
+			r.Lines += a.Lines
+		}
+	}
+
+	var owners []Reviewer
+	for o, n := range owned {
+		owners = append(owners, Reviewer{Name: o, Source: SourceCodeowners, Files: n})
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Files != owners[j].Files {
+			return owners[i].Files > owners[j].Files
+		}
+		return owners[i].Name < owners[j].Name
+	})
+
+	var authors []Reviewer
+	for email, r := range written {
+		if owned[email] > 0 {
+			continue // Already suggested as an owner
+		}
+		authors = append(authors, *r)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Lines != authors[j].Lines {
+			return authors[i].Lines > authors[j].Lines
+		}
+		return authors[i].Name < authors[j].Name
+	})
+	if len(authors) > maxAuthors {
+		authors = authors[:maxAuthors]
+	}
+	return append(owners, authors...)
+}
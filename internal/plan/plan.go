@@ -0,0 +1,306 @@
+package plan
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// Version of the plan file format
+const Version = 1
+
+// Commit is one planned commit
+type Commit struct {
+	Name    string // File path or group name
+	Files   []string
+	Message string
+	Fixup   string // Commit this one fixes up, to be autosquashed into it; Message is then git's
+}
+
+// Plan is a set of commits generated now and applied later, possibly after
+// hand-editing. It is stored as a small, fixed subset of YAML.
+type Plan struct {
+	Base    string // HEAD the plan was generated on
+	Commits []Commit
+}
+
+const header = `# commitai commit plan
+# Edit messages, move files between commits or delete commits, then run:
+#   commitai apply-plan %s
+`
+
+// Save writes the plan to path
+func (p *Plan) Save(path string) error {
+	if err := readonly.Check("writing " + path); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, header, path)
+	for _, c := range p.Commits {
+		if c.Fixup != "" {
+			sb.WriteString("# Commits with a fixup key are committed as fixup! commits of that commit.\n")
+			break
+		}
+	}
+	fmt.Fprintf(&sb, "version: %d\n", Version)
+	if p.Base != "" {
+		fmt.Fprintf(&sb, "base: %s\n", p.Base)
+	}
+	sb.WriteString("commits:\n")
+	for _, c := range p.Commits {
+		fmt.Fprintf(&sb, "  - name: %s\n", scalar(c.Name))
+		if c.Fixup != "" {
+			fmt.Fprintf(&sb, "    fixup: %s\n", scalar(c.Fixup))
+		}
+		sb.WriteString("    files:\n")
+		for _, f := range c.Files {
+			fmt.Fprintf(&sb, "      - %s\n", scalar(f))
+		}
+		sb.WriteString("    message: |\n")
+		for _, line := range strings.Split(strings.TrimRight(c.Message, "\n"), "\n") {
+			if line == "" {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("      " + line + "\n")
+			}
+		}
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// scalar quotes values plain YAML would misread
+func scalar(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#\"'\\") || strings.ContainsAny(s[:1], "-?[]{}&*!|>%@` ") ||
+		strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// unscalar reverses scalar, accepting single quotes too as hand edits may use them
+func unscalar(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2:
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
+
+// Load reads a plan written by Save (and possibly edited since)
+func Load(path string) (*Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &Plan{}
+	var cur *Commit
+	section := "" // "files" or "message" inside a commit
+	var message []string
+	lineNo := 0
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("%s:%d: %s", path, lineNo, fmt.Sprintf(format, args...))
+	}
+	flush := func() {
+		if cur != nil && section == "message" {
+			cur.Message = strings.TrimSpace(strings.Join(message, "\n"))
+		}
+		message = nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+
+		// Message lines keep their text verbatim, '#' included
+		if section == "message" && (trimmed == "" || indent >= 6) {
+			if trimmed == "" {
+				message = append(message, "")
+			} else {
+				message = append(message, line[6:])
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch {
+		case indent == 0:
+			flush()
+			section, cur = "", nil
+			key, value, _ := strings.Cut(trimmed, ":")
+			value = strings.TrimSpace(value)
+			switch key {
+			case "version":
+				if v, err := strconv.Atoi(value); err != nil || v != Version {
+					return nil, fail("unsupported plan version %q", value)
+				}
+			case "base":
+				p.Base = value
+			case "commits":
+			default:
+				return nil, fail("unknown key %q", key)
+			}
+
+		case indent == 2 && strings.HasPrefix(trimmed, "- "):
+			flush()
+			p.Commits = append(p.Commits, Commit{})
+			cur, section = &p.Commits[len(p.Commits)-1], ""
+			if err := setField(cur, strings.TrimPrefix(trimmed, "- "), &section); err != nil {
+				return nil, fail("%s", err)
+			}
+
+		case indent == 4 && cur != nil:
+			flush()
+			section = ""
+			if err := setField(cur, trimmed, &section); err != nil {
+				return nil, fail("%s", err)
+			}
+
+		case indent >= 6 && section == "files" && strings.HasPrefix(trimmed, "- "):
+			file, err := unscalar(strings.TrimPrefix(trimmed, "- "))
+			if err != nil {
+				return nil, fail("bad file name: %s", err)
+			}
+			cur.Files = append(cur.Files, file)
+
+		default:
+			return nil, fail("unexpected line %q", trimmed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return p, p.validate(path)
+}
+
+// setField applies one "key: value" line of a commit; files and block
+// messages continue on the following lines
+func setField(c *Commit, kv string, section *string) error {
+	key, value, _ := strings.Cut(kv, ":")
+	value = strings.TrimSpace(value)
+	switch key {
+	case "name":
+		v, err := unscalar(value)
+		if err != nil {
+			return fmt.Errorf("bad name: %w", err)
+		}
+		c.Name = v
+	case "fixup":
+		v, err := unscalar(value)
+		if err != nil {
+			return fmt.Errorf("bad fixup: %w", err)
+		}
+		c.Fixup = v
+	case "files":
+		*section = "files"
+	case "message":
+		if value == "|" || value == "|-" || value == "" {
+			*section = "message"
+			return nil
+		}
+		v, err := unscalar(value)
+		if err != nil {
+			return fmt.Errorf("bad message: %w", err)
+		}
+		c.Message = v
+	default:
+		return fmt.Errorf("unknown commit key %q", key)
+	}
+	return nil
+}
+
+func (p *Plan) validate(path string) error {
+	if len(p.Commits) == 0 {
+		return fmt.Errorf("%s: plan has no commits", path)
+	}
+	seen := make(map[string]int)
+	for i, c := range p.Commits {
+		if c.Name == "" {
+			p.Commits[i].Name = fmt.Sprintf("commit %d", i+1)
+		}
+		if len(c.Files) == 0 {
+			return fmt.Errorf("%s: commit %q has no files", path, p.Commits[i].Name)
+		}
+		if c.Message == "" && c.Fixup == "" {
+			return fmt.Errorf("%s: commit %q has no message", path, p.Commits[i].Name)
+		}
+		for _, f := range c.Files {
+			if j, ok := seen[f]; ok {
+				return fmt.Errorf("%s: %s is in commit %d and %d; a file can only be committed once", path, f, j+1, i+1)
+			}
+			seen[f] = i
+		}
+	}
+	return nil
+}
+
+// ProgressFile is where a running or failed apply records its progress,
+// relative to the .git directory
+const ProgressFile = "commitai-plan.json"
+
+// Progress records how far applying a plan got, so a failed run can resume
+type Progress struct {
+	Path  string   `json:"path"`  // Absolute path of the plan file
+	Total int      `json:"total"` // Commits in the plan
+	Names []string `json:"names"` // Names of the commits created so far, in order
+	Head  string   `json:"head"`  // HEAD after the last created commit
+}
+
+// LoadProgress returns the recorded progress, or nil if no apply is pending
+func LoadProgress(gitDir string) (*Progress, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, ProgressFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pr Progress
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("corrupt %s: %w", ProgressFile, err)
+	}
+	return &pr, nil
+}
+
+// Save records the progress
+func (pr *Progress) Save(gitDir string) error {
+	if err := readonly.Check("recording plan progress"); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pr, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(gitDir, ProgressFile), append(data, '\n'), 0644)
+}
+
+// ClearProgress removes the recorded progress once a plan is fully applied
+// or abandoned
+func ClearProgress(gitDir string) error {
+	if readonly.Enabled() {
+		return nil // Nothing was recorded
+	}
+	err := os.Remove(filepath.Join(gitDir, ProgressFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
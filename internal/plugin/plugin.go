@@ -0,0 +1,132 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the executable name prefix that marks a commitai plugin
+const Prefix = "commitai-"
+
+// ProtocolVersion is bumped on incompatible changes to Context or Result
+const ProtocolVersion = 1
+
+// Context is written as JSON to the plugin's stdin
+type Context struct {
+	Protocol int      `json:"protocol"`
+	Version  string   `json:"version"` // commitai version
+	Args     []string `json:"args"`
+	RepoRoot string   `json:"repo_root,omitempty"`
+	Branch   string   `json:"branch,omitempty"`
+	Staged   []File   `json:"staged"`
+	Config   Config   `json:"config"`
+}
+
+// File is one staged change
+type File struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// Config is the part of the configuration plugins may see. Secrets such as
+// the API key are never passed.
+type Config struct {
+	Language    string          `json:"language"`
+	CommitStyle string          `json:"commit_style"`
+	Model       string          `json:"model"`
+	Policy      json.RawMessage `json:"policy,omitempty"`
+	PrivacyMode bool            `json:"privacy_mode"`
+	LocalOnly   bool            `json:"local_only"` // Plugins must not send anything off this machine
+}
+
+// Result is what a plugin may print as JSON on stdout. Plugins that print
+// anything else have their output shown as is.
+type Result struct {
+	Output        string `json:"output,omitempty"`         // Text shown to the user
+	CommitMessage string `json:"commit_message,omitempty"` // Proposed message, confirmed and committed by commitai
+	Error         string `json:"error,omitempty"`
+}
+
+// Lookup finds the executable for a plugin name on PATH
+func Lookup(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	path, err := exec.LookPath(Prefix + name)
+	return path, err == nil
+}
+
+// List returns the names of all plugins found on PATH
+func List() []string {
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasPrefix(name, Prefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if info, err := e.Info(); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[strings.TrimPrefix(name, Prefix)] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes the plugin with ctx on stdin. Stdout is captured and parsed
+// as a Result when it is JSON; stderr goes to the terminal.
+func Run(path string, ctx Context) (*Result, error) {
+	input, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path, ctx.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("COMMITAI_PLUGIN_PROTOCOL=%d", ProtocolVersion),
+		"COMMITAI_VERSION="+ctx.Version,
+	)
+	out, err := cmd.Output()
+	res := parseResult(out)
+	if err != nil {
+		if res.Error == "" {
+			res.Error = err.Error()
+		}
+		return res, fmt.Errorf("plugin %s failed: %s", filepath.Base(path), res.Error)
+	}
+	if res.Error != "" {
+		return res, fmt.Errorf("plugin %s: %s", filepath.Base(path), res.Error)
+	}
+	return res, nil
+}
+
+func parseResult(out []byte) *Result {
+	var res Result
+	trimmed := bytes.TrimSpace(out)
+	if len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &res) == nil {
+		return &res
+	}
+	return &Result{Output: string(out)}
+}
@@ -0,0 +1,314 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/commitlint"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// bundleCacheTTL is how long a fetched bundle is reused before refetching
+const bundleCacheTTL = time.Hour
+
+// Bundle is an organization policy distributed from a central location.
+// Signature is the base64 ed25519 signature of the compact Policy JSON.
+type Bundle struct {
+	Policy    json.RawMessage `json:"policy"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// Effective is the policy actually enforced and where it came from
+type Effective struct {
+	Policy   config.Policy
+	Source   string // "local" or the bundle location
+	Signed   bool
+	Cached   bool
+	FetchErr error // Set when a stale cached bundle was used because fetching failed
+
+	Commitlint    *commitlint.Rules // The repository's commitlint rules, merged in
+	CommitlintErr error             // Set when the commitlint config couldn't be read
+}
+
+// Resolve merges the organization bundle (if configured) over the local
+// policy, then the rules of the repository's commitlint config, so messages
+// pass the commitlint CI job
+func Resolve(cfg *config.Config) (*Effective, error) {
+	eff := &Effective{Policy: cfg.Policy, Source: "local"}
+	if cfg.PolicyURL != "" {
+		if err := resolveBundle(cfg, eff); err != nil {
+			return nil, err
+		}
+	}
+
+	top, err := git.TopLevel()
+	if err != nil {
+		return eff, nil
+	}
+	lint, err := commitlint.Load(top)
+	switch {
+	case err != nil:
+		eff.CommitlintErr = err
+	case lint != nil:
+		eff.Policy = Merge(eff.Policy, lint.Policy)
+		eff.Commitlint = lint
+	}
+	return eff, nil
+}
+
+// resolveBundle loads the organization bundle into eff
+func resolveBundle(cfg *config.Config, eff *Effective) error {
+	if cfg.PrivacyMode && IsRemote(cfg.PolicyURL) {
+		return fmt.Errorf("privacy mode forbids fetching the remote policy bundle %s; use a local file", cfg.PolicyURL)
+	}
+	if cfg.LocalOnly && IsRemote(cfg.PolicyURL) {
+		return fmt.Errorf("local-only mode forbids fetching the remote policy bundle %s; use a local file", cfg.PolicyURL)
+	}
+
+	data, cached, fetchErr := fetchBundle(cfg.PolicyURL)
+	if data == nil {
+		return fmt.Errorf("failed to load policy bundle from %s: %w", cfg.PolicyURL, fetchErr)
+	}
+
+	org, signed, err := VerifyBundle(data, cfg.PolicyPublicKey)
+	if err != nil {
+		return fmt.Errorf("policy bundle from %s rejected: %w", cfg.PolicyURL, err)
+	}
+
+	eff.Policy = Merge(cfg.Policy, org)
+	eff.Source = cfg.PolicyURL
+	eff.Signed = signed
+	eff.Cached = cached
+	eff.FetchErr = fetchErr
+	return nil
+}
+
+// ConsentGranted reports whether the organization bundle grants data
+// consent. Only a bundle signed with the configured key counts: the key and
+// the bundle location come from the user config, so a repository can't
+// grant it for itself.
+func ConsentGranted(cfg *config.Config) bool {
+	if cfg.PolicyURL == "" || cfg.PolicyPublicKey == "" {
+		return false
+	}
+	if (cfg.PrivacyMode || cfg.LocalOnly) && IsRemote(cfg.PolicyURL) {
+		return false
+	}
+	data, _, _ := fetchBundle(cfg.PolicyURL)
+	if data == nil {
+		return false
+	}
+	org, signed, err := VerifyBundle(data, cfg.PolicyPublicKey)
+	return err == nil && signed && org.DataConsent == config.ConsentGranted
+}
+
+// VerifyBundle parses a bundle and checks its signature against publicKey.
+// Without a configured key the bundle is accepted unsigned.
+func VerifyBundle(data []byte, publicKey string) (config.Policy, bool, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return config.Policy{}, false, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if len(b.Policy) == 0 {
+		return config.Policy{}, false, errors.New("bundle has no policy")
+	}
+
+	signed := false
+	if publicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return config.Policy{}, false, errors.New("configured policy_public_key is not a base64 ed25519 public key")
+		}
+		sig, err := base64.StdEncoding.DecodeString(b.Signature)
+		if err != nil || b.Signature == "" {
+			return config.Policy{}, false, errors.New("bundle is not signed")
+		}
+		// Sign over the compact form so re-indenting the bundle keeps it valid
+		var canonical bytes.Buffer
+		if err := json.Compact(&canonical, b.Policy); err != nil {
+			return config.Policy{}, false, fmt.Errorf("invalid policy in bundle: %w", err)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(key), canonical.Bytes(), sig) {
+			return config.Policy{}, false, errors.New("signature verification failed")
+		}
+		signed = true
+	}
+
+	var p config.Policy
+	if err := json.Unmarshal(b.Policy, &p); err != nil {
+		return config.Policy{}, false, fmt.Errorf("invalid policy in bundle: %w", err)
+	}
+	return p, signed, nil
+}
+
+// Sign produces a bundle for the given policy JSON using a base64 ed25519 private key
+func Sign(policyJSON []byte, privateKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("private key is not a base64 ed25519 private key")
+	}
+	var p config.Policy
+	if err := json.Unmarshal(policyJSON, &p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	sig := ed25519.Sign(ed25519.PrivateKey(key), raw)
+	return json.MarshalIndent(Bundle{Policy: raw, Signature: base64.StdEncoding.EncodeToString(sig)}, "", "  ")
+}
+
+// Merge layers the organization policy over the local one.
+// Lists are combined, limits take the stricter value and
+// organization settings win for types, scopes, ticket, language, subject
+// case and body sections.
+func Merge(local, org config.Policy) config.Policy {
+	p := local
+	p.ForbiddenWords = union(local.ForbiddenWords, org.ForbiddenWords)
+	p.RequiredPatterns = union(local.RequiredPatterns, org.RequiredPatterns)
+	p.MaxSubjectLength = stricter(local.MaxSubjectLength, org.MaxSubjectLength)
+	p.MaxBodyLength = stricter(local.MaxBodyLength, org.MaxBodyLength)
+	if len(org.AllowedTypes) > 0 {
+		p.AllowedTypes = org.AllowedTypes
+	}
+	if len(org.AllowedScopes) > 0 {
+		p.AllowedScopes = org.AllowedScopes
+	}
+	if org.TicketPattern != "" {
+		p.TicketPattern = org.TicketPattern
+	}
+	if org.Language != "" {
+		p.Language = org.Language
+	}
+	p.Imperative = local.Imperative || org.Imperative
+	p.NoTrailingPeriod = local.NoTrailingPeriod || org.NoTrailingPeriod
+	if org.SubjectCase != "" {
+		p.SubjectCase = org.SubjectCase
+	}
+	if len(org.BodySections) > 0 {
+		p.BodySections = org.BodySections
+	}
+	// Only an organization can grant data consent
+	p.DataConsent = org.DataConsent
+	return p
+}
+
+// fetchBundle loads bundle bytes, falling back to the cached copy when the
+// source is unreachable. Returns the data, whether it came from cache,
+// and the fetch error if any.
+func fetchBundle(location string) ([]byte, bool, error) {
+	if !IsRemote(location) {
+		data, err := readBundle(location)
+		return data, false, err
+	}
+
+	cachePath := bundleCachePath(location)
+	if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < bundleCacheTTL {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			return data, true, nil
+		}
+	}
+
+	data, err := readBundle(location)
+	if err != nil {
+		if cached, cerr := os.ReadFile(cachePath); cerr == nil {
+			return cached, true, err
+		}
+		return nil, false, err
+	}
+
+	if cachePath != "" && !readonly.Enabled() {
+		os.MkdirAll(filepath.Dir(cachePath), 0755)
+		os.WriteFile(cachePath, data, 0644)
+	}
+	return data, false, nil
+}
+
+func readBundle(location string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://"):
+		client := &http.Client{Timeout: 15 * time.Second}
+		resp, err := client.Get(location)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	case strings.HasPrefix(location, "git+"):
+		// git+<repo-url>#<path/in/repo>
+		repo, path, ok := strings.Cut(strings.TrimPrefix(location, "git+"), "#")
+		if !ok || path == "" {
+			return nil, errors.New("git policy location must look like git+<repo-url>#<path>")
+		}
+		tmp, err := os.MkdirTemp("", "commitai-policy-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		if out, err := exec.Command("git", "clone", "--quiet", "--depth", "1", repo, tmp).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git clone %s failed: %s", repo, strings.TrimSpace(string(out)))
+		}
+		return os.ReadFile(filepath.Join(tmp, path))
+
+	default:
+		return os.ReadFile(location)
+	}
+}
+
+// IsRemote reports whether a bundle location requires network access
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://") ||
+		strings.HasPrefix(location, "git+")
+}
+
+func bundleCachePath(location string) string {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(location))
+	return filepath.Join(dir, "policy", hex.EncodeToString(sum[:8])+".json")
+}
+
+func union(a, b []string) []string {
+	out := append([]string{}, a...)
+	for _, v := range b {
+		if !contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func stricter(a, b int) int {
+	switch {
+	case a == 0:
+		return b
+	case b == 0:
+		return a
+	case a < b:
+		return a
+	default:
+		return b
+	}
+}
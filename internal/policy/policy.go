@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+// Violation is a single broken policy rule
+type Violation struct {
+	Rule   string
+	Detail string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Detail)
+}
+
+// wordRe matches word as a whole word, case-insensitively. Word boundaries
+// only go on the sides that end in a word character, so words such as
+// "fixup!" and "WIP:" match too.
+func wordRe(word string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(word)
+	if isWordChar(word[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordChar(word[len(word)-1]) {
+		pattern += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + pattern)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// Check validates a commit message against the policy and returns every violation
+func Check(msg string, p config.Policy) []Violation {
+	var violations []Violation
+	subject, body := Split(msg)
+
+	for _, word := range p.ForbiddenWords {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		if wordRe(word).MatchString(msg) {
+			violations = append(violations, Violation{"forbidden-word", fmt.Sprintf("message contains %q", word)})
+		}
+	}
+
+	for _, pattern := range p.RequiredPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			violations = append(violations, Violation{"required-pattern", fmt.Sprintf("invalid pattern %q: %s", pattern, err)})
+			continue
+		}
+		if !re.MatchString(msg) {
+			violations = append(violations, Violation{"required-pattern", fmt.Sprintf("message does not match %q", pattern)})
+		}
+	}
+
+	if len(p.AllowedTypes) > 0 || len(p.AllowedScopes) > 0 {
+		typ, scope, ok := ParseHeader(subject)
+		switch {
+		case !ok:
+			violations = append(violations, Violation{"type", "subject is not in <type>(<scope>): <description> form"})
+		case len(p.AllowedTypes) > 0 && !contains(p.AllowedTypes, typ):
+			violations = append(violations, Violation{"type", fmt.Sprintf("type %q is not allowed (allowed: %s)", typ, strings.Join(p.AllowedTypes, ", "))})
+		}
+		if ok && scope != "" && len(p.AllowedScopes) > 0 {
+			for _, sc := range strings.Split(scope, ",") {
+				if sc = strings.TrimSpace(sc); !contains(p.AllowedScopes, sc) {
+					violations = append(violations, Violation{"scope", fmt.Sprintf("scope %q is not allowed (allowed: %s)", sc, strings.Join(p.AllowedScopes, ", "))})
+				}
+			}
+		}
+	}
+
+	if p.TicketPattern != "" {
+		re, err := regexp.Compile(p.TicketPattern)
+		switch {
+		case err != nil:
+			violations = append(violations, Violation{"ticket", fmt.Sprintf("invalid ticket pattern %q: %s", p.TicketPattern, err)})
+		case !re.MatchString(msg):
+			violations = append(violations, Violation{"ticket", fmt.Sprintf("no ticket reference matching %q", p.TicketPattern)})
+		}
+	}
+
+	// Measured in terminal columns so CJK subjects aren't undercounted
+	if width := message.DisplayWidth(subject); p.MaxSubjectLength > 0 && width > p.MaxSubjectLength {
+		violations = append(violations, Violation{"max-subject-length",
+			fmt.Sprintf("subject is %d columns wide, max is %d", width, p.MaxSubjectLength)})
+	}
+
+	if p.MaxBodyLength > 0 && len([]rune(body)) > p.MaxBodyLength {
+		violations = append(violations, Violation{"max-body-length",
+			fmt.Sprintf("body is %d chars, max is %d", len([]rune(body)), p.MaxBodyLength)})
+	}
+
+	violations = append(violations, checkStyle(subject, p)...)
+	violations = append(violations, checkSections(subject, body, p.BodySections)...)
+
+	return violations
+}
+
+var headerRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?!?: \S`)
+
+// ParseHeader splits a Conventional Commits subject into type and scope.
+// An emoji in front of the type (✨ feat: ...) is skipped.
+func ParseHeader(subject string) (typ, scope string, ok bool) {
+	m := headerRe.FindStringSubmatch(strings.TrimPrefix(subject, message.LeadingEmoji(subject)))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split separates a commit message into its subject line and body
+func Split(msg string) (subject, body string) {
+	msg = strings.TrimSpace(msg)
+	subject, body, _ = strings.Cut(msg, "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body)
+}
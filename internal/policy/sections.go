@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+// EmptySection is written under a body section with nothing in it
+const EmptySection = "N/A"
+
+// bodySections is a message body cut at the policy's section headings
+type bodySections struct {
+	lead    []string            // Lines before the first heading
+	content map[string][]string // By section name as configured
+	order   []string            // Sections in the order found
+	footers []string            // Trailing trailer block
+}
+
+// parseSections finds the sections of body. Headings are matched by name,
+// case-insensitively, in the forms models and people write them: "Summary:",
+// "## Summary", "**Summary**", "Summary: inline text".
+func parseSections(body string, sections []string) bodySections {
+	s := bodySections{content: make(map[string][]string)}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+
+	// Trailers stay below the sections
+	if start := footerStart(lines, sections); start >= 0 {
+		s.footers = lines[start:]
+		lines = lines[:start]
+	}
+
+	current := ""
+	for _, line := range lines {
+		if name, rest, ok := sectionHeading(line, sections); ok {
+			current = name
+			if _, seen := s.content[name]; !seen {
+				s.order = append(s.order, name)
+				s.content[name] = nil
+			}
+			if rest != "" {
+				s.content[name] = append(s.content[name], rest)
+			}
+			continue
+		}
+		if current == "" {
+			s.lead = append(s.lead, line)
+		} else {
+			s.content[current] = append(s.content[current], line)
+		}
+	}
+	return s
+}
+
+// footerStart returns where the last paragraph of lines starts if it is a
+// trailer block, or -1. A section heading with inline text looks like a
+// trailer ("Summary: ..."), so a paragraph with one isn't.
+func footerStart(lines []string, sections []string) int {
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start == 0 || start == len(lines) {
+		return -1
+	}
+	for _, line := range lines[start:] {
+		if _, _, heading := sectionHeading(line, sections); heading || !message.IsFooter(line) {
+			return -1
+		}
+	}
+	return start
+}
+
+// sectionHeading reports whether line starts one of sections, and the text
+// after the heading on the same line
+func sectionHeading(line string, sections []string) (name, rest string, ok bool) {
+	text := strings.TrimSpace(line)
+	text = strings.TrimSpace(strings.TrimLeft(text, "#"))
+	text = strings.TrimLeft(text, "*_")
+	for _, name := range sections {
+		if len(text) < len(name) || !strings.EqualFold(text[:len(name)], name) {
+			continue
+		}
+		after := strings.TrimLeft(text[len(name):], "*_")
+		switch {
+		case after == "":
+			return name, "", true
+		case strings.HasPrefix(after, ":"):
+			after = strings.TrimLeft(strings.TrimPrefix(after, ":"), "*_")
+			return name, strings.TrimSpace(after), true
+		}
+	}
+	return "", "", false
+}
+
+// FixSections renders the body of msg as the policy's body sections, in
+// order, each under a "Name:" line: text before the first heading goes to
+// the first section and missing sections get EmptySection, so tools can
+// parse every generated message the same way. Trailers stay at the end.
+func FixSections(msg string, p config.Policy) string {
+	if len(p.BodySections) == 0 {
+		return msg
+	}
+	subject, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	if isAutosquash(subject) {
+		return msg
+	}
+	s := parseSections(body, p.BodySections)
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(subject) + "\n")
+	for i, name := range p.BodySections {
+		content := s.content[name]
+		if i == 0 {
+			content = append(s.lead, content...)
+		}
+		text := strings.TrimSpace(strings.Join(content, "\n"))
+		if text == "" {
+			text = EmptySection
+		}
+		sb.WriteString("\n" + name + ":\n" + text + "\n")
+	}
+	if len(s.footers) > 0 {
+		sb.WriteString("\n" + strings.Join(s.footers, "\n") + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// checkSections reports body sections that are missing, empty or out of
+// order
+func checkSections(subject, body string, sections []string) []Violation {
+	if len(sections) == 0 || isAutosquash(subject) {
+		return nil
+	}
+	s := parseSections(body, sections)
+	var violations []Violation
+	for _, name := range sections {
+		content, ok := s.content[name]
+		switch {
+		case !ok:
+			violations = append(violations, Violation{"body-sections", fmt.Sprintf("missing section %q", name)})
+		case strings.TrimSpace(strings.Join(content, "\n")) == "":
+			violations = append(violations, Violation{"body-sections", fmt.Sprintf("section %q is empty (write %s if there's nothing to say)", name, EmptySection)})
+		}
+	}
+	if len(violations) == 0 && strings.Join(s.order, "\n") != strings.Join(sections, "\n") {
+		violations = append(violations, Violation{"body-sections", fmt.Sprintf("sections must be in this order: %s", strings.Join(sections, ", "))})
+	}
+	return violations
+}
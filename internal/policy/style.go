@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/message"
+)
+
+// prefixRe matches what precedes the description of a subject: a
+// Conventional Commits type and scope
+var prefixRe = regexp.MustCompile(`^\w+(?:\([^)]*\))?!?: `)
+
+// imperativeVerbs are the verbs commit subjects usually start with; their
+// past, third person and -ing forms are recognized as not imperative
+var imperativeVerbs = strings.Fields(`add adjust allow avoid bump build cache change check clean
+	convert correct create delete deprecate disable document drop enable ensure expose extract fix
+	guard handle hide implement improve increase introduce log make merge migrate move optimize
+	parse pass prevent reduce refactor release remove rename reorder replace restore return revert
+	rewrite run set show simplify skip sort split stop support switch test tweak update upgrade use
+	validate wrap write`)
+
+// irregularPast are the past forms not made with -ed
+var irregularPast = map[string]string{
+	"build": "built", "hide": "hid", "make": "made", "rewrite": "rewrote",
+	"run": "ran", "set": "set", "split": "split", "write": "wrote",
+}
+
+// doubled are the verbs doubling their last consonant before -ed and -ing
+var doubled = map[string]bool{"drop": true, "log": true, "run": true, "set": true, "skip": true, "split": true, "stop": true, "wrap": true}
+
+// nonImperative maps inflected verbs (added, adds, adding) to their
+// imperative form
+var nonImperative = func() map[string]string {
+	forms := make(map[string]string)
+	for _, verb := range imperativeVerbs {
+		last := verb[len(verb)-1:]
+		stem := verb
+		if doubled[verb] {
+			stem += last
+		}
+		var third, past, gerund string
+		switch {
+		case strings.HasSuffix(verb, "y") && !strings.ContainsAny(verb[len(verb)-2:len(verb)-1], "aeiou"):
+			third, past, gerund = verb[:len(verb)-1]+"ies", verb[:len(verb)-1]+"ied", verb+"ing"
+		case strings.HasSuffix(verb, "e"):
+			third, past, gerund = verb+"s", verb+"d", verb[:len(verb)-1]+"ing"
+		case strings.HasSuffix(verb, "s"), strings.HasSuffix(verb, "x"), strings.HasSuffix(verb, "z"),
+			strings.HasSuffix(verb, "ch"), strings.HasSuffix(verb, "sh"):
+			third, past, gerund = verb+"es", verb+"ed", verb+"ing"
+		default:
+			third, past, gerund = verb+"s", stem+"ed", stem+"ing"
+		}
+		if p, ok := irregularPast[verb]; ok {
+			past = p
+		}
+		for _, form := range []string{third, past, gerund} {
+			if form != verb {
+				forms[form] = verb
+			}
+		}
+	}
+	return forms
+}()
+
+// splitSubject cuts a subject into its type and scope prefix, if any, and
+// the description. Emojis in front of either are part of the prefix.
+func splitSubject(subject string) (prefix, description string) {
+	prefix = message.LeadingEmoji(subject)
+	if m := prefixRe.FindString(subject[len(prefix):]); m != "" {
+		prefix += m
+	}
+	prefix += message.LeadingEmoji(subject[len(prefix):])
+	return prefix, subject[len(prefix):]
+}
+
+// checkStyle reports the subject style rules the subject breaks
+func checkStyle(subject string, p config.Policy) []Violation {
+	if isAutosquash(subject) {
+		return nil
+	}
+	var violations []Violation
+	_, description := splitSubject(subject)
+	word := firstWord(description)
+
+	if p.Imperative {
+		if verb, ok := nonImperative[strings.ToLower(word)]; ok {
+			violations = append(violations, Violation{"imperative", fmt.Sprintf("subject starts with %q; use the imperative %q", word, verb)})
+		}
+	}
+	switch p.SubjectCase {
+	case config.SubjectLower:
+		if startsCapitalized(word) {
+			violations = append(violations, Violation{"subject-case", "subject description must start in lower case"})
+		}
+	case config.SubjectSentence:
+		if r, _ := utf8.DecodeRuneInString(description); unicode.IsLower(r) {
+			violations = append(violations, Violation{"subject-case", "subject description must start with a capital letter"})
+		}
+	}
+	if p.NoTrailingPeriod && hasTrailingPeriod(subject) {
+		violations = append(violations, Violation{"subject-full-stop", "subject must not end with a period"})
+	}
+	return violations
+}
+
+// FixStyle rewrites the subject of msg to follow the policy's subject
+// style rules: the first verb in the imperative, the case of the first
+// letter and no trailing period. The body is left alone.
+func FixStyle(msg string, p config.Policy) string {
+	if !p.Imperative && p.SubjectCase == "" && !p.NoTrailingPeriod {
+		return msg
+	}
+	subject, rest, multiline := strings.Cut(msg, "\n")
+	if isAutosquash(subject) {
+		return msg
+	}
+	indent := subject[:len(subject)-len(strings.TrimLeft(subject, " \t"))]
+	prefix, description := splitSubject(strings.TrimSpace(subject))
+
+	if word := firstWord(description); word != "" {
+		if verb, ok := nonImperative[strings.ToLower(word)]; ok && p.Imperative {
+			if startsCapitalized(word) {
+				verb = capitalize(verb)
+			}
+			description = verb + description[len(word):]
+			word = verb
+		}
+		switch {
+		case p.SubjectCase == config.SubjectLower && startsCapitalized(word):
+			r, size := utf8.DecodeRuneInString(description)
+			description = string(unicode.ToLower(r)) + description[size:]
+		case p.SubjectCase == config.SubjectSentence:
+			description = capitalize(description)
+		}
+	}
+	subject = prefix + description
+	if p.NoTrailingPeriod && hasTrailingPeriod(subject) {
+		subject = strings.TrimSuffix(strings.TrimRight(subject, " \t"), ".")
+	}
+	if !multiline {
+		return indent + subject
+	}
+	return indent + subject + "\n" + rest
+}
+
+// firstWord returns the leading letters of s
+func firstWord(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+// startsCapitalized reports a word like "Added", but not an acronym or
+// name like "API" or "README" that keeps its case
+func startsCapitalized(word string) bool {
+	r, size := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(r) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(word[size:])
+	return next == utf8.RuneError || !unicode.IsUpper(next)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// hasTrailingPeriod reports a subject ending with one period, not an
+// ellipsis
+func hasTrailingPeriod(subject string) bool {
+	subject = strings.TrimRight(subject, " \t")
+	return strings.HasSuffix(subject, ".") && !strings.HasSuffix(subject, "..")
+}
+
+// isAutosquash reports a fixup!/squash!/amend! subject, which git writes
+func isAutosquash(subject string) bool {
+	return strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") || strings.HasPrefix(subject, "amend! ")
+}
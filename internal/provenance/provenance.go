@@ -0,0 +1,161 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// Statement and predicate types of the attestation, and the payload type
+// of its signed envelope
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://slsa.dev/provenance/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+
+	buildType = "https://github.com/kaiqui/commitai/release/v1"
+	builderID = "https://github.com/kaiqui/commitai"
+)
+
+// EnvKey holds the base64 ed25519 private key attestations are signed with
+const EnvKey = "COMMITAI_PROVENANCE_KEY"
+
+// Release is what a release attestation records: how the tag and its notes
+// were produced
+type Release struct {
+	Tag         string
+	PreviousTag string // Empty for the first release
+	Commit      string // Commit the tag points to
+	Commits     int    // Commits since the previous tag
+	Source      string // Repository URL, if known
+	Notes       string
+
+	ToolVersion string
+	Provider    string // gemini, offline, command
+	Model       string // Empty for the offline provider
+
+	Finished time.Time
+}
+
+// Statement returns the in-toto statement with an SLSA provenance
+// predicate for the release. Its subjects are the tag, by commit, and the
+// notes, by SHA-256.
+func Statement(r Release) map[string]any {
+	notesSum := sha256.Sum256([]byte(r.Notes))
+	rng := r.Commit
+	if r.PreviousTag != "" {
+		rng = r.PreviousTag + ".." + r.Commit
+	}
+	dependency := map[string]any{"digest": map[string]string{"gitCommit": r.Commit}}
+	if r.Source != "" {
+		dependency["uri"] = "git+" + r.Source + "@refs/tags/" + r.Tag
+	}
+	generator := map[string]string{"provider": r.Provider}
+	if r.Model != "" {
+		generator["model"] = r.Model
+	}
+
+	return map[string]any{
+		"_type": StatementType,
+		"subject": []map[string]any{
+			{"name": r.Tag, "digest": map[string]string{"gitCommit": r.Commit}},
+			{"name": "RELEASE-" + r.Tag + ".md", "digest": map[string]string{"sha256": hex.EncodeToString(notesSum[:])}},
+		},
+		"predicateType": PredicateType,
+		"predicate": map[string]any{
+			"buildDefinition": map[string]any{
+				"buildType": buildType,
+				"externalParameters": map[string]any{
+					"tag":         r.Tag,
+					"previousTag": r.PreviousTag,
+					"range":       rng,
+					"commits":     r.Commits,
+				},
+				"resolvedDependencies": []map[string]any{dependency},
+			},
+			"runDetails": map[string]any{
+				"builder": map[string]any{
+					"id":      builderID,
+					"version": map[string]string{"commitai": r.ToolVersion},
+				},
+				"metadata": map[string]any{
+					"finishedOn": r.Finished.UTC().Format(time.RFC3339),
+				},
+				"byproducts": []map[string]any{
+					{"name": "release-notes-generator", "annotations": generator},
+				},
+			},
+		},
+	}
+}
+
+// Envelope is a DSSE envelope holding a signed statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"` // Base64 statement JSON
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature; KeyID is the hex SHA-256 of the public key
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Sign wraps a statement in a DSSE envelope signed with a base64 ed25519
+// private key
+func Sign(statement []byte, privateKey string) (*Envelope, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("provenance key is not a base64 ed25519 private key")
+	}
+	priv := ed25519.PrivateKey(key)
+	keyID := sha256.Sum256(priv.Public().(ed25519.PublicKey))
+	sig := ed25519.Sign(priv, pae(PayloadType, statement))
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures:  []Signature{{KeyID: hex.EncodeToString(keyID[:]), Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// pae is DSSE's pre-authentication encoding, what the signature covers
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Write saves the attestation of a release to path: a DSSE envelope when
+// privateKey is set, the bare statement otherwise. It reports whether the
+// attestation was signed.
+func Write(path string, r Release, privateKey string) (bool, error) {
+	if err := readonly.Check("writing the release provenance"); err != nil {
+		return false, err
+	}
+	statement, err := json.Marshal(Statement(r))
+	if err != nil {
+		return false, err
+	}
+	var out any = json.RawMessage(statement)
+	if privateKey != "" {
+		if out, err = Sign(statement, privateKey); err != nil {
+			return false, err
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return privateKey != "", nil
+}
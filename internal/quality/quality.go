@@ -0,0 +1,219 @@
+package quality
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/message"
+	"github.com/kaiqui/commitai/internal/policy"
+)
+
+// DefaultThreshold is the score below which a commit fails when no
+// threshold is configured
+const DefaultThreshold = 60
+
+// Check names, also the SARIF rule ids
+const (
+	CheckFormat      = "format"
+	CheckSpecificity = "specificity"
+	CheckLength      = "length"
+)
+
+// weights of each check in the overall score; they add up to 100
+var weights = map[string]int{CheckFormat: 40, CheckSpecificity: 35, CheckLength: 25}
+
+// Check is one scored aspect of a commit message
+type Check struct {
+	Name   string   `json:"name"`
+	Score  int      `json:"score"` // 0-100
+	Issues []string `json:"issues,omitempty"`
+}
+
+// Rating is the quality of one commit message
+type Rating struct {
+	Score  int     `json:"score"` // 0-100, the weighted checks
+	Checks []Check `json:"checks"`
+}
+
+// Rate scores msg against the changes of its commit. conventional is whether
+// the repo writes Conventional Commits; types are the accepted types then.
+func Rate(msg string, changes []git.FileChange, pol config.Policy, conventional bool, types []string) Rating {
+	subject, body := policy.Split(msg)
+	checks := []Check{
+		formatCheck(msg, subject, pol, conventional, types),
+		specificityCheck(subject, body, changes),
+		lengthCheck(subject),
+	}
+	total := 0
+	for _, c := range checks {
+		total += c.Score * weights[c.Name]
+	}
+	return Rating{Score: (total + 50) / 100, Checks: checks}
+}
+
+func formatCheck(msg, subject string, pol config.Policy, conventional bool, types []string) Check {
+	c := Check{Name: CheckFormat, Score: 100}
+	penalize := func(points int, issue string) {
+		c.Score -= points
+		c.Issues = append(c.Issues, issue)
+	}
+
+	if conventional {
+		typ, _, ok := policy.ParseHeader(subject)
+		switch {
+		case !ok:
+			penalize(50, "subject is not in <type>(<scope>): <description> form")
+		case len(pol.AllowedTypes) == 0 && !contains(types, strings.ToLower(typ)):
+			penalize(20, fmt.Sprintf("type %q is not a Conventional Commits type", typ))
+		}
+	}
+	for _, v := range policy.Check(msg, pol) {
+		penalize(25, v.Detail)
+	}
+	if lines := strings.SplitN(msg, "\n", 3); len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		penalize(20, "no blank line between the subject and the body")
+	}
+	if strings.HasSuffix(subject, ".") {
+		penalize(10, "subject ends with a period")
+	}
+	c.Score = max(c.Score, 0)
+	return c
+}
+
+// genericWords say that something changed without saying what
+var genericWords = set("update", "updates", "updated", "updating", "change", "changes", "changed",
+	"fix", "fixes", "fixed", "stuff", "misc", "minor", "wip", "tweak", "tweaks", "cleanup", "clean",
+	"code", "file", "files", "thing", "things", "work", "improve", "improvements", "improved",
+	"refactor", "refactored", "edit", "edits", "some", "various", "more", "small", "bug", "bugs",
+	"issue", "issues", "temp", "test", "tests", "again", "final", "new", "add", "added", "remove",
+	"removed", "make", "made")
+
+// stopWords carry no meaning of their own
+var stopWords = set("the", "and", "for", "with", "from", "into", "onto", "that", "this", "when",
+	"was", "are", "not", "but", "all", "its", "out", "now", "via", "use", "uses", "using")
+
+var (
+	wordRe       = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_]*`)
+	identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{2,}`)
+	camelRe      = regexp.MustCompile(`[A-Z]?[a-z0-9]+|[A-Z]+(?:$|[^a-z])`)
+)
+
+// largeChange is the number of changed lines past which a body is expected
+const largeChange = 100
+
+func specificityCheck(subject, body string, changes []git.FileChange) Check {
+	c := Check{Name: CheckSpecificity, Score: 100}
+
+	description := subject
+	if _, _, ok := policy.ParseHeader(subject); ok {
+		description = strings.TrimSpace(strings.SplitN(subject, ":", 2)[1])
+	}
+	var specific []string
+	for _, w := range wordRe.FindAllString(description, -1) {
+		lw := strings.ToLower(w)
+		if len(lw) > 2 && !genericWords[lw] && !stopWords[lw] {
+			specific = append(specific, lw)
+		}
+	}
+
+	vocabulary, lines := diffVocabulary(changes)
+	switch {
+	case len(specific) == 0:
+		c.Score = 20
+		c.Issues = append(c.Issues, fmt.Sprintf("%q doesn't say what changed", description))
+	case len(changes) > 0 && !mentions(subject+"\n"+body, vocabulary):
+		c.Score = 60
+		c.Issues = append(c.Issues, "message names nothing the diff touches (files, directories, identifiers)")
+	}
+	if lines > largeChange && strings.TrimSpace(body) == "" {
+		c.Score -= 20
+		c.Issues = append(c.Issues, fmt.Sprintf("%d changed lines without a body explaining them", lines))
+	}
+	c.Score = max(c.Score, 0)
+	return c
+}
+
+// diffVocabulary returns the words of the changed paths and of the
+// identifiers on changed lines, and the number of changed lines
+func diffVocabulary(changes []git.FileChange) (map[string]bool, int) {
+	vocabulary := make(map[string]bool)
+	add := func(word string) {
+		for _, w := range append([]string{word}, camelRe.FindAllString(word, -1)...) {
+			for _, part := range strings.Split(strings.ToLower(w), "_") {
+				if len(part) > 2 && !stopWords[part] {
+					vocabulary[part] = true
+				}
+			}
+		}
+	}
+
+	lines := 0
+	for _, ch := range changes {
+		for _, part := range strings.FieldsFunc(ch.Path, func(r rune) bool { return r == '/' || r == '.' || r == '-' }) {
+			add(part)
+		}
+		add(strings.TrimSuffix(path.Base(ch.Path), path.Ext(ch.Path)))
+		for _, line := range strings.Split(ch.Diff, "\n") {
+			if len(line) == 0 || (line[0] != '+' && line[0] != '-') || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+				continue
+			}
+			lines++
+			for _, id := range identifierRe.FindAllString(line[1:], -1) {
+				add(id)
+			}
+		}
+	}
+	return vocabulary, lines
+}
+
+// mentions reports whether text has a word of vocabulary, or a plural or
+// past tense of one
+func mentions(text string, vocabulary map[string]bool) bool {
+	for _, w := range wordRe.FindAllString(text, -1) {
+		lw := strings.ToLower(w)
+		for _, form := range []string{lw, strings.TrimSuffix(lw, "s"), strings.TrimSuffix(lw, "es"), strings.TrimSuffix(lw, "ed"), strings.TrimSuffix(lw, "d")} {
+			if len(form) > 2 && !genericWords[form] && vocabulary[form] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func lengthCheck(subject string) Check {
+	c := Check{Name: CheckLength, Score: 100}
+	width := message.DisplayWidth(subject)
+	switch {
+	case width < 10:
+		c.Score = 30
+		c.Issues = append(c.Issues, fmt.Sprintf("subject is only %d columns", width))
+	case width > message.SubjectWidth:
+		c.Score = max(60-(width-message.SubjectWidth)*2, 0)
+		c.Issues = append(c.Issues, fmt.Sprintf("subject is %d columns (limit %d)", width, message.SubjectWidth))
+	case width > 50:
+		c.Score = 85
+		c.Issues = append(c.Issues, fmt.Sprintf("subject is %d columns (50 reads best)", width))
+	}
+	return c
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package quality
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit is a scored commit, as reported by ci lint-commits
+type Commit struct {
+	Hash    string   `json:"hash"`
+	Subject string   `json:"subject"`
+	Files   []string `json:"files,omitempty"`
+	Passed  bool     `json:"passed"`
+	Rating
+}
+
+// sarifRules describe the checks to code scanning
+var sarifRules = []map[string]any{
+	{"id": CheckFormat, "shortDescription": map[string]string{"text": "Commit message format"},
+		"fullDescription": map[string]string{"text": "The message follows the commit convention and policy, with a blank line after the subject."}},
+	{"id": CheckSpecificity, "shortDescription": map[string]string{"text": "Commit message specificity"},
+		"fullDescription": map[string]string{"text": "The message says what changed, naming something the diff touches; large changes have a body."}},
+	{"id": CheckLength, "shortDescription": map[string]string{"text": "Commit subject length"},
+		"fullDescription": map[string]string{"text": "The subject fits in 72 columns, ideally 50, and isn't a single word."}},
+}
+
+// SARIF returns a SARIF 2.1.0 log with a result per issue of each commit,
+// located at the first file the commit changes so code scanning can show it.
+// Issues of failing commits are errors, the others warnings.
+func SARIF(commits []Commit, version, infoURI string) map[string]any {
+	results := []map[string]any{}
+	for _, c := range commits {
+		level := "warning"
+		if !c.Passed {
+			level = "error"
+		}
+		for _, check := range c.Checks {
+			for _, issue := range check.Issues {
+				result := map[string]any{
+					"ruleId":  check.Name,
+					"level":   level,
+					"message": map[string]string{"text": fmt.Sprintf("%s %q: %s (score %d/100)", short(c.Hash), c.Subject, issue, c.Score)},
+					"partialFingerprints": map[string]string{
+						"commitCheck/v1": c.Hash + ":" + check.Name + ":" + issue,
+					},
+					"properties": map[string]any{"commit": c.Hash, "score": c.Score},
+				}
+				if len(c.Files) > 0 {
+					result["locations"] = []map[string]any{{
+						"physicalLocation": map[string]any{
+							"artifactLocation": map[string]string{"uri": c.Files[0]},
+							"region":           map[string]int{"startLine": 1},
+						},
+					}}
+				}
+				results = append(results, result)
+			}
+		}
+	}
+
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool": map[string]any{
+				"driver": map[string]any{
+					"name":           "commitai",
+					"version":        strings.TrimPrefix(version, "v"),
+					"informationUri": infoURI,
+					"rules":          sarifRules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,117 @@
+// Package readonly is commitai's read-only mode (--read-only): nothing may
+// change the repository, its .git directory or any other file. Commands
+// preview what they would do; anything that would still write is refused
+// with ErrReadOnly.
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Env set to 1 turns read-only mode on, like --read-only. Child commitai
+// processes (batch) inherit it.
+const Env = "COMMITAI_READ_ONLY"
+
+// ErrReadOnly is returned for anything refused in read-only mode
+var ErrReadOnly = errors.New("not allowed in read-only mode (--read-only)")
+
+// Enabled reports whether read-only mode is on
+func Enabled() bool {
+	return os.Getenv(Env) == "1"
+}
+
+// Check returns an error naming what was refused when read-only mode is on
+func Check(what string) error {
+	if Enabled() {
+		return fmt.Errorf("%s: %w", what, ErrReadOnly)
+	}
+	return nil
+}
+
+// gitWrites are the git commands that change a repository whatever their
+// arguments; the ones that only do with some arguments are told apart in
+// GitWrites
+var gitWrites = map[string]bool{
+	"add": true, "am": true, "apply": true, "checkout": true, "checkout-index": true,
+	"cherry-pick": true, "clean": true, "commit": true, "commit-tree": true, "fetch": true,
+	"gc": true, "merge": true, "mktag": true, "mktree": true, "mv": true, "prune": true,
+	"pull": true, "push": true, "read-tree": true, "rebase": true, "replace": true,
+	"reset": true, "restore": true, "revert": true, "rm": true, "switch": true,
+	"update-index": true, "update-ref": true, "write-tree": true,
+}
+
+// GitWrites reports whether running git with args would change the
+// repository or write files. Global options (-C, -c) are skipped.
+func GitWrites(args []string) bool {
+	for len(args) > 0 && (args[0] == "-C" || args[0] == "-c") {
+		if len(args) < 2 {
+			return false
+		}
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return false
+	}
+	sub, rest := args[0], args[1:]
+	if gitWrites[sub] {
+		return true
+	}
+	switch sub {
+	case "tag":
+		// A tag name without a listing option creates a lightweight tag
+		if hasAny(rest, "-a", "-d", "-f", "-m", "-s", "--delete") {
+			return true
+		}
+		return len(nonFlags(rest)) > 0 && !hasAny(rest, "-l", "--list", "--contains", "--no-contains", "--merged", "--no-merged", "--points-at")
+	case "notes":
+		return hasAny(rest, "add", "append", "copy", "edit", "merge", "prune", "remove")
+	case "worktree":
+		return hasAny(rest, "add", "move", "prune", "remove", "repair")
+	case "branch":
+		return hasAny(rest, "-c", "-C", "-d", "-D", "-f", "-m", "-M", "--delete", "--set-upstream-to", "-u")
+	case "stash":
+		return !hasAny(rest, "list", "show")
+	case "config":
+		return !hasAny(rest, "--get", "--get-all", "--get-regexp", "--list", "-l") && len(nonFlags(rest)) > 1
+	case "symbolic-ref":
+		return len(nonFlags(rest)) > 1
+	case "hash-object":
+		return hasAny(rest, "-w")
+	case "format-patch":
+		return !hasAny(rest, "--stdout")
+	}
+	return false
+}
+
+func hasAny(args []string, names ...string) bool {
+	for _, a := range args {
+		for _, n := range names {
+			if a == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func nonFlags(args []string) []string {
+	var out []string
+	for _, a := range args {
+		if len(a) > 0 && a[0] != '-' {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// CheckGit returns an error when read-only mode is on and running git with
+// args would change the repository
+func CheckGit(args []string) error {
+	if Enabled() && GitWrites(args) {
+		return Check("git " + strings.Join(args, " "))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package rebase
+
+import (
+	"strings"
+)
+
+// commands maps the one-letter forms of todo commands to their full names
+var commands = map[string]string{
+	"p": "pick", "r": "reword", "e": "edit", "s": "squash", "f": "fixup", "d": "drop",
+	"x": "exec", "b": "break", "l": "label", "t": "reset", "m": "merge", "u": "update-ref",
+}
+
+// withCommit are the commands whose argument is a commit
+var withCommit = map[string]bool{"pick": true, "reword": true, "edit": true, "squash": true, "fixup": true, "drop": true}
+
+// Line is one line of a rebase todo list. Lines that aren't a command on a
+// commit (comments, exec, label...) only have Raw and are written back as
+// they were.
+type Line struct {
+	Raw     string
+	Command string // Full name, e.g. pick; empty for other lines
+	Option  string // e.g. -C of fixup -C
+	Hash    string // Abbreviated, as git wrote it
+	Rest    string // The subject, or whatever follows the hash
+}
+
+// HasCommit reports whether the line is a command on a commit
+func (l Line) HasCommit() bool { return l.Command != "" }
+
+// String returns the line as written to the todo file
+func (l Line) String() string {
+	if !l.HasCommit() {
+		return l.Raw
+	}
+	parts := []string{l.Command}
+	if l.Option != "" {
+		parts = append(parts, l.Option)
+	}
+	parts = append(parts, l.Hash)
+	if l.Rest != "" {
+		parts = append(parts, l.Rest)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Todo is the todo list git rebase -i hands to the sequence editor
+type Todo struct {
+	Lines []Line
+}
+
+// Parse reads a todo list; comment lines start with commentChar
+func Parse(text, commentChar string) Todo {
+	var t Todo
+	for _, raw := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		t.Lines = append(t.Lines, parseLine(raw, commentChar))
+	}
+	return t
+}
+
+func parseLine(raw, commentChar string) Line {
+	line := Line{Raw: raw}
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || strings.HasPrefix(trimmed, commentChar) {
+		return line
+	}
+	fields := strings.Fields(trimmed)
+	command := strings.ToLower(fields[0])
+	if full, ok := commands[command]; ok {
+		command = full
+	}
+	if !withCommit[command] {
+		return line
+	}
+
+	rest := fields[1:]
+	var option string
+	if command == "fixup" && len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+		option, rest = rest[0], rest[1:]
+	}
+	if len(rest) == 0 {
+		return line // Malformed; git will complain about it, not us
+	}
+	line.Command, line.Option, line.Hash = command, option, rest[0]
+	line.Rest = strings.Join(rest[1:], " ")
+	return line
+}
+
+// String returns the todo list as written to the todo file
+func (t Todo) String() string {
+	lines := make([]string, len(t.Lines))
+	for i, l := range t.Lines {
+		lines[i] = l.String()
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Matches reports whether the line's abbreviated hash is that of the full
+// hash
+func (l Line) Matches(hash string) bool {
+	return l.HasCommit() && len(l.Hash) >= 4 && strings.HasPrefix(hash, l.Hash)
+}
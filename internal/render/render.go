@@ -0,0 +1,197 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+
+	"github.com/kaiqui/commitai/internal/i18n"
+)
+
+// Output formats selected with --format
+const (
+	Rich     = "rich"     // Colors, emoji and styled markdown (default)
+	Plain    = "plain"    // No colors, emoji or markup, for logs and screen readers
+	Markdown = "markdown" // Markdown, to paste into issues, PRs or files
+)
+
+// Formats lists the valid formats
+var Formats = []string{Rich, Plain, Markdown}
+
+// Renderer prints the messages and documents of every command. Messages
+// take Printf-style arguments and end with a newline; a leading newline
+// (a blank line before the message) is kept as is.
+type Renderer interface {
+	Info(format string, a ...any)    // Progress and section headings
+	Success(format string, a ...any) // Something was done
+	Warn(format string, a ...any)    // Something needs attention
+	Error(format string, a ...any)   // Something failed
+	Muted(format string, a ...any)   // Secondary details
+	// Document prints a markdown document, e.g. release notes or a report
+	Document(markdown string)
+}
+
+// New returns the renderer for format, writing to w. Colors are turned off
+// for every format but rich, so inline color.XString spans render plain too.
+func New(format string, w io.Writer) (Renderer, error) {
+	switch format {
+	case Rich, "":
+		return &rich{w: w}, nil
+	case Plain:
+		color.NoColor = true
+		return &plain{w: w}, nil
+	case Markdown:
+		color.NoColor = true
+		return &markdown{w: w}, nil
+	}
+	return nil, fmt.Errorf("invalid format %q (expected %s)", format, strings.Join(Formats, ", "))
+}
+
+// split translates the message and separates leading newlines from its
+// text. Like fatih/color,
+// a format without arguments is printed as is and a trailing newline isn't
+// doubled.
+func split(format string, a []any) (lead, text string) {
+	msg := i18n.T(format)
+	if len(a) > 0 {
+		msg = fmt.Sprintf(msg, a...)
+	}
+	msg = strings.TrimSuffix(msg, "\n")
+	text = strings.TrimLeft(msg, "\n")
+	return msg[:len(msg)-len(text)], text
+}
+
+// --- rich ---
+
+type rich struct{ w io.Writer }
+
+func (r *rich) print(c *color.Color, format string, a []any) {
+	lead, text := split(format, a)
+	fmt.Fprint(r.w, lead+c.Sprint(text)+"\n")
+}
+
+func (r *rich) Info(format string, a ...any)    { r.print(color.New(color.FgCyan), format, a) }
+func (r *rich) Success(format string, a ...any) { r.print(color.New(color.FgGreen), format, a) }
+func (r *rich) Warn(format string, a ...any)    { r.print(color.New(color.FgYellow), format, a) }
+func (r *rich) Error(format string, a ...any)   { r.print(color.New(color.FgRed), format, a) }
+func (r *rich) Muted(format string, a ...any)   { r.print(color.New(color.FgHiBlack), format, a) }
+
+var (
+	boldRe = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	emRe   = regexp.MustCompile(`\b_([^_]+)_\b`)
+	codeRe = regexp.MustCompile("`([^`]+)`")
+	linkRe = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+// Document styles headings, bullets, emphasis, code and links with ANSI
+// colors; tables and other lines are printed as they are
+func (r *rich) Document(md string) {
+	heading := color.New(color.FgCyan, color.Bold)
+	code := color.New(color.FgYellow)
+	bold := color.New(color.Bold)
+	inline := func(s string) string {
+		s = codeRe.ReplaceAllStringFunc(s, func(m string) string { return code.Sprint(m[1 : len(m)-1]) })
+		s = boldRe.ReplaceAllStringFunc(s, func(m string) string { return bold.Sprint(m[2 : len(m)-2]) })
+		s = emRe.ReplaceAllStringFunc(s, func(m string) string { return color.New(color.Italic).Sprint(m[1 : len(m)-1]) })
+		return linkRe.ReplaceAllString(s, "$1 ("+color.New(color.Underline).Sprint("$2")+")")
+	}
+
+	inFence := false
+	for _, line := range strings.Split(strings.TrimRight(md, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inFence = !inFence
+		case inFence:
+			fmt.Fprintln(r.w, "    "+code.Sprint(line))
+		case strings.HasPrefix(trimmed, "#"):
+			fmt.Fprintln(r.w, heading.Sprint(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+			fmt.Fprintln(r.w, indent+"  • "+inline(trimmed[2:]))
+		case strings.HasPrefix(trimmed, "> "):
+			fmt.Fprintln(r.w, color.HiBlackString("│ ")+inline(trimmed[2:]))
+		default:
+			fmt.Fprintln(r.w, inline(line))
+		}
+	}
+}
+
+// --- plain ---
+
+type plain struct{ w io.Writer }
+
+func (p *plain) print(format string, a []any) {
+	lead, text := split(format, a)
+	fmt.Fprint(p.w, lead+stripEmoji(text)+"\n")
+}
+
+func (p *plain) Info(format string, a ...any)    { p.print(format, a) }
+func (p *plain) Success(format string, a ...any) { p.print(format, a) }
+func (p *plain) Warn(format string, a ...any)    { p.print(format, a) }
+func (p *plain) Error(format string, a ...any)   { p.print(format, a) }
+func (p *plain) Muted(format string, a ...any)   { p.print(format, a) }
+
+// Document drops markdown syntax, keeping the text and structure
+func (p *plain) Document(md string) {
+	inFence := false
+	for _, line := range strings.Split(strings.TrimRight(md, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inFence = !inFence
+			continue
+		case inFence:
+			fmt.Fprintln(p.w, "    "+line)
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			line = strings.ToUpper(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+		}
+		line = boldRe.ReplaceAllString(line, "$1")
+		line = emRe.ReplaceAllString(line, "$1")
+		line = codeRe.ReplaceAllString(line, "$1")
+		line = linkRe.ReplaceAllString(line, "$1 ($2)")
+		fmt.Fprintln(p.w, stripEmoji(line))
+	}
+}
+
+// stripEmoji removes a leading emoji (and the spaces after it) from a message
+func stripEmoji(s string) string {
+	rest := strings.TrimLeftFunc(s, func(r rune) bool {
+		return r > unicode.MaxLatin1 && !unicode.IsLetter(r) && !unicode.IsDigit(r) // Includes variation selectors
+	})
+	if rest == s {
+		return s
+	}
+	return strings.TrimLeft(rest, " ")
+}
+
+// --- markdown ---
+
+type markdown struct{ w io.Writer }
+
+func (m *markdown) print(wrap func(string) string, format string, a []any) {
+	lead, text := split(format, a)
+	fmt.Fprint(m.w, lead+wrap(text)+"\n")
+}
+
+func (m *markdown) Info(format string, a ...any) {
+	m.print(func(s string) string { return "**" + strings.TrimSpace(s) + "**" }, format, a)
+}
+func (m *markdown) Success(format string, a ...any) { m.print(identity, format, a) }
+func (m *markdown) Warn(format string, a ...any) {
+	m.print(func(s string) string { return "> " + s }, format, a)
+}
+func (m *markdown) Error(format string, a ...any) {
+	m.print(func(s string) string { return "> **" + strings.TrimSpace(s) + "**" }, format, a)
+}
+func (m *markdown) Muted(format string, a ...any) {
+	m.print(func(s string) string { return "_" + strings.TrimSpace(s) + "_" }, format, a)
+}
+func (m *markdown) Document(md string) { fmt.Fprintln(m.w, strings.TrimRight(md, "\n")) }
+
+func identity(s string) string { return s }
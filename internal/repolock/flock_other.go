@@ -0,0 +1,27 @@
+//go:build !unix
+
+package repolock
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// tryLock creates path exclusively, where flock isn't available. A run
+// that crashed leaves the file behind; it has to be removed by hand.
+func tryLock(path string) (func(), bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	return func() {
+		f.Close()
+		os.Remove(path)
+	}, true, nil
+}
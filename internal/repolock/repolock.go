@@ -0,0 +1,75 @@
+package repolock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the lock file in the git directory
+const FileName = "commitai.lock"
+
+// pollInterval is how often a busy lock is tried again
+const pollInterval = 200 * time.Millisecond
+
+// Lock is held by one commitai run per repository while it changes the
+// index and commits, so a hook and a manual run can't interleave their
+// unstage and re-stage steps
+type Lock struct {
+	release func()
+}
+
+// Acquire takes the lock of the repository whose git directory is gitDir.
+// When another run holds it, waiting is called once and the lock is tried
+// again until timeout.
+func Acquire(gitDir string, timeout time.Duration, waiting func(pid int)) (*Lock, error) {
+	path := filepath.Join(gitDir, FileName)
+	deadline := time.Now().Add(timeout)
+	notified := false
+	for {
+		release, ok, err := tryLock(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if ok {
+			return &Lock{release: release}, nil
+		}
+		pid := holder(path)
+		if time.Now().After(deadline) {
+			return nil, busyError(path, pid)
+		}
+		if !notified && waiting != nil {
+			waiting(pid)
+			notified = true
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release lets the next run in
+func (l *Lock) Release() {
+	if l != nil && l.release != nil {
+		l.release()
+		l.release = nil
+	}
+}
+
+// holder returns the PID written in the lock file, or 0
+func holder(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
+func busyError(path string, pid int) error {
+	if pid > 0 {
+		return fmt.Errorf("another commitai run (PID %d) is committing in this repository; try again once it is done (lock: %s)", pid, path)
+	}
+	return fmt.Errorf("another commitai run is committing in this repository; try again once it is done (lock: %s)", path)
+}
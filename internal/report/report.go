@@ -0,0 +1,190 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// Author is one contributor's activity in the period
+type Author struct {
+	Name    string
+	Email   string
+	Commits int
+	Added   int
+	Deleted int
+}
+
+// File is one file's activity in the period
+type File struct {
+	Path    string
+	Commits int
+	Added   int
+	Deleted int
+	Authors int
+}
+
+// Churn is the number of lines added and deleted
+func (f File) Churn() int { return f.Added + f.Deleted }
+
+// Activity summarizes the commits of a period
+type Activity struct {
+	Since    string
+	Until    string
+	Commits  int
+	Subjects []string // "hash subject", newest first
+	Authors  []Author // Most commits first
+	Files    []File   // Most commits first, then most churn
+	Dirs     []File   // Same, per top-level directory (Authors counts distinct authors)
+}
+
+// Analyze aggregates commits into per-author, per-file and per-directory activity
+func Analyze(since, until string, commits []git.StatCommit) *Activity {
+	a := &Activity{Since: since, Until: until, Commits: len(commits)}
+	authors := make(map[string]*Author)
+	files := make(map[string]*File)
+	dirs := make(map[string]*File)
+	fileAuthors := make(map[string]map[string]bool)
+	dirAuthors := make(map[string]map[string]bool)
+
+	touch := func(m map[string]*File, who map[string]map[string]bool, key, email string, added, deleted int, seen map[string]bool) {
+		f, ok := m[key]
+		if !ok {
+			f = &File{Path: key}
+			m[key] = f
+			who[key] = make(map[string]bool)
+		}
+		if !seen[key] {
+			f.Commits++
+			seen[key] = true
+		}
+		f.Added += added
+		f.Deleted += deleted
+		who[key][email] = true
+	}
+
+	for _, c := range commits {
+		short := c.Hash
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		a.Subjects = append(a.Subjects, short+" "+c.Subject)
+
+		au, ok := authors[c.Email]
+		if !ok {
+			au = &Author{Name: c.Author, Email: c.Email}
+			authors[c.Email] = au
+		}
+		au.Commits++
+
+		seenFiles := make(map[string]bool)
+		seenDirs := make(map[string]bool)
+		for _, fs := range c.Files {
+			added, deleted := max(fs.Added, 0), max(fs.Deleted, 0) // Binary files count as touched only
+			au.Added += added
+			au.Deleted += deleted
+			touch(files, fileAuthors, fs.Path, c.Email, added, deleted, seenFiles)
+			touch(dirs, dirAuthors, topDir(fs.Path), c.Email, added, deleted, seenDirs)
+		}
+	}
+
+	for _, au := range authors {
+		a.Authors = append(a.Authors, *au)
+	}
+	sort.Slice(a.Authors, func(i, j int) bool {
+		if a.Authors[i].Commits != a.Authors[j].Commits {
+			return a.Authors[i].Commits > a.Authors[j].Commits
+		}
+		return a.Authors[i].Name < a.Authors[j].Name
+	})
+	a.Files = sorted(files, fileAuthors)
+	a.Dirs = sorted(dirs, dirAuthors)
+	return a
+}
+
+func topDir(p string) string {
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i] + "/"
+	}
+	return "(root)"
+}
+
+func sorted(m map[string]*File, who map[string]map[string]bool) []File {
+	out := make([]File, 0, len(m))
+	for key, f := range m {
+		f.Authors = len(who[key])
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Commits != out[j].Commits {
+			return out[i].Commits > out[j].Commits
+		}
+		if out[i].Churn() != out[j].Churn() {
+			return out[i].Churn() > out[j].Churn()
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// Risky returns up to n files that changed repeatedly, ranked by churn times
+// the number of authors: code rewritten often by many hands is where
+// regressions and merge conflicts tend to come from
+func (a *Activity) Risky(n int) []File {
+	var risky []File
+	for _, f := range a.Files {
+		if f.Commits >= 2 && f.Churn() > 0 {
+			risky = append(risky, f)
+		}
+	}
+	score := func(f File) int { return f.Churn() * f.Authors }
+	sort.SliceStable(risky, func(i, j int) bool { return score(risky[i]) > score(risky[j]) })
+	if len(risky) > n {
+		risky = risky[:n]
+	}
+	return risky
+}
+
+// Period describes the analyzed period, e.g. "since 1 month ago"
+func (a *Activity) Period() string {
+	switch {
+	case a.Since != "" && a.Until != "":
+		return fmt.Sprintf("from %s until %s", a.Since, a.Until)
+	case a.Since != "":
+		return "since " + a.Since
+	case a.Until != "":
+		return "until " + a.Until
+	}
+	return "over the whole history"
+}
+
+// Tables renders the figures as markdown: contributors, hotspots, directories
+// and risky files, each limited to the top n rows
+func (a *Activity) Tables(n int) string {
+	var sb strings.Builder
+	sb.WriteString("### Contributors\n\n| Author | Commits | Lines added | Lines deleted |\n|---|---:|---:|---:|\n")
+	for _, au := range a.Authors[:min(n, len(a.Authors))] {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d |\n", au.Name, au.Commits, au.Added, au.Deleted)
+	}
+
+	sb.WriteString("\n### Hotspots\n\n")
+	writeFiles(&sb, "File", a.Files[:min(n, len(a.Files))])
+
+	sb.WriteString("\n### Directories\n\n")
+	writeFiles(&sb, "Directory", a.Dirs[:min(n, len(a.Dirs))])
+
+	if risky := a.Risky(n); len(risky) > 0 {
+		sb.WriteString("\n### Risky files (churn × authors)\n\n")
+		writeFiles(&sb, "File", risky)
+	}
+	return sb.String()
+}
+
+func writeFiles(sb *strings.Builder, title string, files []File) {
+	fmt.Fprintf(sb, "| %s | Commits | Churn | Authors |\n|---|---:|---:|---:|\n", title)
+	for _, f := range files {
+		fmt.Fprintf(sb, "| `%s` | %d | %d | %d |\n", f.Path, f.Commits, f.Churn(), f.Authors)
+	}
+}
@@ -0,0 +1,192 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Handler serves one method. params is the raw "params" member.
+type Handler func(params json.RawMessage) (any, error)
+
+// Error is a JSON-RPC error object; handlers may return it to pick the code
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// InvalidParams wraps a parameter decoding problem
+func InvalidParams(err error) error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server dispatches JSON-RPC 2.0 requests read from a stream. Messages may
+// be framed LSP-style (Content-Length headers) or one JSON object per line;
+// responses use the framing of the request.
+type Server struct {
+	// Lenient accepts requests without the "jsonrpc": "2.0" member, for
+	// clients speaking plain line-delimited JSON
+	Lenient bool
+
+	handlers map[string]Handler
+	stopped  bool
+}
+
+// NewServer creates a server without methods
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]Handler)}
+}
+
+// Handle registers a method
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+// Stop makes Serve return after the current message. Call it from a handler.
+func (s *Server) Stop() {
+	s.stopped = true
+}
+
+// Methods lists the registered method names
+func (s *Server) Methods() []string {
+	names := make([]string, 0, len(s.handlers))
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Serve processes requests one at a time until r is exhausted or Stop is called
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	for !s.stopped {
+
+		body, framed, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(body)) == 0 {
+			continue
+		}
+
+		resp := s.dispatch(body)
+		if resp == nil {
+			continue // Notification
+		}
+		if err := writeMessage(w, resp, framed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) dispatch(body []byte) *response {
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{CodeParseError, err.Error()}}
+	}
+	notification := len(req.ID) == 0
+	reply := func(result any, err error) *response {
+		if notification {
+			return nil
+		}
+		resp := &response{JSONRPC: "2.0", ID: req.ID}
+		if err != nil {
+			rerr, ok := err.(*Error)
+			if !ok {
+				rerr = &Error{Code: CodeInternalError, Message: err.Error()}
+			}
+			resp.Error = rerr
+		} else {
+			if result == nil {
+				result = struct{}{}
+			}
+			resp.Result = result
+		}
+		return resp
+	}
+
+	if (req.JSONRPC != "2.0" && !s.Lenient) || req.Method == "" {
+		return reply(nil, &Error{CodeInvalidRequest, "not a JSON-RPC 2.0 request"})
+	}
+	h, ok := s.handlers[req.Method]
+	if !ok {
+		return reply(nil, &Error{CodeMethodNotFound, "method not found: " + req.Method})
+	}
+	return reply(h(req.Params))
+}
+
+func readMessage(br *bufio.Reader) ([]byte, bool, error) {
+	line, err := br.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, false, err
+	}
+	if !strings.HasPrefix(strings.ToLower(line), "content-length:") {
+		return []byte(line), false, nil
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(line[len("content-length:"):]))
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid Content-Length header: %q", line)
+	}
+	// Skip remaining headers up to the blank line
+	for {
+		h, err := br.ReadString('\n')
+		if err != nil {
+			return nil, true, err
+		}
+		if strings.TrimSpace(h) == "" {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(br, body); err != nil {
+		return nil, true, err
+	}
+	return body, true, nil
+}
+
+func writeMessage(w io.Writer, resp *response, framed bool) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if framed {
+		_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	} else {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Limits bound what each client may use. Zero values mean unlimited
+// (MaxRequestBytes falls back to maxRequestSize).
+type Limits struct {
+	RequestsPerMinute int   `json:"requests_per_minute"`
+	MaxRequestBytes   int64 `json:"max_request_bytes"`
+	DailyTokens       int   `json:"daily_tokens"` // Estimated input + max output tokens per UTC day
+}
+
+// Usage is one client's consumption for the current day
+type Usage struct {
+	Client          string `json:"client"`
+	Requests        int    `json:"requests"`
+	Tokens          int    `json:"tokens"`
+	Rejected        int    `json:"rejected"`
+	RemainingTokens *int   `json:"remaining_tokens,omitempty"` // Only with a daily quota
+}
+
+// errExhausted marks rate limit and quota errors (HTTP 429, gRPC RESOURCE_EXHAUSTED)
+type errExhausted struct {
+	msg        string
+	retryAfter time.Duration
+}
+
+func (e errExhausted) Error() string { return e.msg }
+
+// bucket is a token bucket refilled at RequestsPerMinute
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter tracks per-client request rates and daily token usage in memory,
+// so counters start over when the server restarts.
+type limiter struct {
+	limits Limits
+	now    func() time.Time
+
+	mu      sync.Mutex
+	day     string
+	buckets map[string]*bucket
+	usage   map[string]*Usage
+}
+
+func newLimiter(limits Limits) *limiter {
+	if limits.MaxRequestBytes <= 0 {
+		limits.MaxRequestBytes = maxRequestSize
+	}
+	return &limiter{
+		limits:  limits,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+		usage:   make(map[string]*Usage),
+	}
+}
+
+// allow takes one request from the client's bucket
+func (l *limiter) allow(client string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.usageFor(client)
+
+	if rate := l.limits.RequestsPerMinute; rate > 0 {
+		now := l.now()
+		b, ok := l.buckets[client]
+		if !ok {
+			b = &bucket{tokens: float64(rate), last: now}
+			l.buckets[client] = b
+		}
+		b.tokens += now.Sub(b.last).Minutes() * float64(rate)
+		if b.tokens > float64(rate) {
+			b.tokens = float64(rate)
+		}
+		b.last = now
+		if b.tokens < 1 {
+			u.Rejected++
+			wait := time.Duration((1 - b.tokens) / float64(rate) * float64(time.Minute))
+			return errExhausted{fmt.Sprintf("rate limit of %d requests per minute exceeded", rate), wait}
+		}
+		b.tokens--
+	}
+
+	u.Requests++
+	return nil
+}
+
+// charge records an AI call's estimated tokens, refusing it when the
+// client's daily quota would be exceeded
+func (l *limiter) charge(client string, tokens int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	u := l.usageFor(client)
+
+	if quota := l.limits.DailyTokens; quota > 0 && u.Tokens+tokens > quota {
+		u.Rejected++
+		return errExhausted{fmt.Sprintf("daily quota of %d tokens exhausted (%d used, this call needs ~%d); send a smaller diff or try again tomorrow",
+			quota, u.Tokens, tokens), l.untilTomorrow()}
+	}
+	u.Tokens += tokens
+	return nil
+}
+
+// snapshot returns today's usage for every client, sorted by name
+func (l *limiter) snapshot() (string, []Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+
+	list := make([]Usage, 0, len(l.usage))
+	for _, u := range l.usage {
+		c := *u
+		if l.limits.DailyTokens > 0 {
+			remaining := max(l.limits.DailyTokens-c.Tokens, 0)
+			c.RemainingTokens = &remaining
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Client < list[j].Client })
+	return l.day, list
+}
+
+// reset clears the usage of one client, or of all clients when client is empty
+func (l *limiter) reset(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if client == "" {
+		l.usage = make(map[string]*Usage)
+		l.buckets = make(map[string]*bucket)
+		return
+	}
+	delete(l.usage, client)
+	delete(l.buckets, client)
+}
+
+func (l *limiter) usageFor(client string) *Usage {
+	l.rollover()
+	u, ok := l.usage[client]
+	if !ok {
+		u = &Usage{Client: client}
+		l.usage[client] = u
+	}
+	return u
+}
+
+// rollover starts a fresh usage table at UTC midnight
+func (l *limiter) rollover() {
+	if day := l.now().UTC().Format("2006-01-02"); day != l.day {
+		l.day = day
+		l.usage = make(map[string]*Usage)
+	}
+}
+
+func (l *limiter) untilTomorrow() time.Duration {
+	now := l.now().UTC()
+	return now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
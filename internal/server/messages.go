@@ -0,0 +1,106 @@
+package server
+
+// Request and response types shared by the HTTP JSON and gRPC transports.
+// Field numbers match api/proto/commitai/v1/commitai.proto.
+
+type GenerateCommitRequest struct {
+	Diff          string   `json:"diff"`
+	Granular      bool     `json:"granular,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	Style         string   `json:"style,omitempty"`
+	RecentCommits []string `json:"recent_commits,omitempty"`
+}
+
+type GenerateCommitResponse struct {
+	Messages map[string]string `json:"messages"`
+}
+
+type GenerateReleaseRequest struct {
+	Commits    []string `json:"commits"`
+	CurrentTag string   `json:"current_tag,omitempty"`
+	NewTag     string   `json:"new_tag"`
+	Audience   string   `json:"audience,omitempty"`
+}
+
+type GenerateReleaseResponse struct {
+	Notes string `json:"notes"`
+}
+
+type SuggestVersionRequest struct {
+	Commits    []string `json:"commits"`
+	CurrentTag string   `json:"current_tag,omitempty"`
+}
+
+type SuggestVersionResponse struct {
+	Version string `json:"version"`
+}
+
+func (r *GenerateCommitRequest) unmarshal(b []byte) error {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			r.Diff = string(f.data)
+		case 2:
+			r.Granular = f.varint != 0
+		case 3:
+			r.Language = string(f.data)
+		case 4:
+			r.Style = string(f.data)
+		case 5:
+			r.RecentCommits = append(r.RecentCommits, string(f.data))
+		}
+	}
+	return nil
+}
+
+func (r *GenerateCommitResponse) marshal() []byte {
+	return appendStringMap(nil, 1, r.Messages)
+}
+
+func (r *GenerateReleaseRequest) unmarshal(b []byte) error {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			r.Commits = append(r.Commits, string(f.data))
+		case 2:
+			r.CurrentTag = string(f.data)
+		case 3:
+			r.NewTag = string(f.data)
+		case 4:
+			r.Audience = string(f.data)
+		}
+	}
+	return nil
+}
+
+func (r *GenerateReleaseResponse) marshal() []byte {
+	return appendString(nil, 1, r.Notes)
+}
+
+func (r *SuggestVersionRequest) unmarshal(b []byte) error {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			r.Commits = append(r.Commits, string(f.data))
+		case 2:
+			r.CurrentTag = string(f.data)
+		}
+	}
+	return nil
+}
+
+func (r *SuggestVersionResponse) marshal() []byte {
+	return appendString(nil, 1, r.Version)
+}
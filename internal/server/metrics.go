@@ -0,0 +1,200 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the request duration histogram bounds in seconds;
+// generation calls take from under a second to about a minute
+var latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metrics collects counters for /metrics in the Prometheus text format
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[[3]string]uint64 // method, transport, code
+	durations map[string]*histogram
+	tokens    map[string]uint64 // input, max_output
+	calls     uint64
+	failures  map[string]uint64 // method
+	limited   map[string]uint64 // reason
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, cumulative at render time
+	sum    float64
+	count  uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:  make(map[[3]string]uint64),
+		durations: make(map[string]*histogram),
+		tokens:    make(map[string]uint64),
+		failures:  make(map[string]uint64),
+		limited:   make(map[string]uint64),
+	}
+}
+
+func (m *metrics) observeRequest(method, transport, code string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[[3]string{method, transport, code}]++
+	h, ok := m.durations[method]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.durations[method] = h
+	}
+	for i, le := range latencyBuckets {
+		if d.Seconds() <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += d.Seconds()
+	h.count++
+}
+
+func (m *metrics) observeCall(inputTokens, maxOutputTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	m.tokens["input"] += uint64(inputTokens)
+	m.tokens["max_output"] += uint64(maxOutputTokens)
+}
+
+func (m *metrics) observeFailure(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[method]++
+}
+
+func (m *metrics) observeLimited(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limited[reason]++
+}
+
+// write renders every metric in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP commitai_requests_total Requests handled, by method, transport and status code.")
+	fmt.Fprintln(w, "# TYPE commitai_requests_total counter")
+	keys := make([][3]string, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.Join(keys[i][:], " ") < strings.Join(keys[j][:], " ") })
+	for _, k := range keys {
+		fmt.Fprintf(w, "commitai_requests_total{method=%q,transport=%q,code=%q} %d\n", k[0], k[1], k[2], m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP commitai_request_duration_seconds Request latency, by method.")
+	fmt.Fprintln(w, "# TYPE commitai_request_duration_seconds histogram")
+	for _, method := range sortedKeys(m.durations) {
+		h := m.durations[method]
+		var cumulative uint64
+		for i, le := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "commitai_request_duration_seconds_bucket{method=%q,le=\"%g\"} %d\n", method, le, cumulative)
+		}
+		fmt.Fprintf(w, "commitai_request_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", method, h.count)
+		fmt.Fprintf(w, "commitai_request_duration_seconds_sum{method=%q} %g\n", method, h.sum)
+		fmt.Fprintf(w, "commitai_request_duration_seconds_count{method=%q} %d\n", method, h.count)
+	}
+
+	fmt.Fprintln(w, "# HELP commitai_provider_calls_total AI provider calls made.")
+	fmt.Fprintln(w, "# TYPE commitai_provider_calls_total counter")
+	fmt.Fprintf(w, "commitai_provider_calls_total %d\n", m.calls)
+
+	fmt.Fprintln(w, "# HELP commitai_estimated_tokens_total Estimated tokens sent to the provider (input) and requested back (max_output).")
+	fmt.Fprintln(w, "# TYPE commitai_estimated_tokens_total counter")
+	for _, kind := range []string{"input", "max_output"} {
+		fmt.Fprintf(w, "commitai_estimated_tokens_total{type=%q} %d\n", kind, m.tokens[kind])
+	}
+
+	fmt.Fprintln(w, "# HELP commitai_provider_errors_total Requests that failed because the AI provider returned an error.")
+	fmt.Fprintln(w, "# TYPE commitai_provider_errors_total counter")
+	for _, method := range sortedKeys(m.failures) {
+		fmt.Fprintf(w, "commitai_provider_errors_total{method=%q} %d\n", method, m.failures[method])
+	}
+
+	fmt.Fprintln(w, "# HELP commitai_limited_total Requests rejected by rate limits, size caps or quotas.")
+	fmt.Fprintln(w, "# TYPE commitai_limited_total counter")
+	for _, reason := range sortedKeys(m.limited) {
+		fmt.Fprintf(w, "commitai_limited_total{reason=%q} %d\n", reason, m.limited[reason])
+	}
+}
+
+func (m *metrics) handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.write(w)
+}
+
+// instrument records count and latency of every API request
+func (m *metrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := methodLabel(r.URL.Path)
+		if method == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		transport, code := "http", fmt.Sprint(rec.status)
+		if isGRPC(r) {
+			transport, code = "grpc", w.Header().Get("Grpc-Status")
+		}
+		m.observeRequest(method, transport, code, time.Since(start))
+	})
+}
+
+// methodLabel maps a request path to a bounded method label
+// ("" for paths that are not part of the API)
+func methodLabel(path string) string {
+	switch path {
+	case "/v1/commit", "/commitai.v1.GenerationService/GenerateCommit":
+		return "GenerateCommit"
+	case "/v1/release", "/commitai.v1.GenerationService/GenerateRelease":
+		return "GenerateRelease"
+	case "/v1/version", "/commitai.v1.GenerationService/SuggestVersion":
+		return "SuggestVersion"
+	}
+	return ""
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps gRPC trailers working through the wrapper
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,448 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/ai"
+	"github.com/kaiqui/commitai/internal/app"
+	"github.com/kaiqui/commitai/internal/config"
+	"github.com/kaiqui/commitai/internal/git"
+)
+
+// maxRequestSize bounds request bodies (diffs can be large, but not unbounded)
+const maxRequestSize = 8 << 20
+
+// gRPC status codes used by the server
+const (
+	grpcOK              = 0
+	grpcInvalidArgument = 3
+	grpcExhausted       = 8
+	grpcUnimplemented   = 12
+	grpcInternal        = 13
+	grpcUnauthenticated = 16
+)
+
+// Options configure access control and limits
+type Options struct {
+	// Tokens maps accepted bearer tokens to client names. When empty the
+	// server is open and clients are told apart by IP address.
+	Tokens map[string]string
+	// AdminToken enables /admin/usage for callers presenting it
+	AdminToken string
+	Limits     Limits
+}
+
+// Server is the generation service, served over HTTP JSON and gRPC
+type Server struct {
+	cfg       *config.Config
+	newClient func(*config.Config) *ai.GeminiClient
+	opts      Options
+	limiter   *limiter
+	metrics   *metrics
+}
+
+// New creates a server using cfg for every request (language and style may
+// be overridden per request). newClient builds the AI client for a config.
+func New(cfg *config.Config, newClient func(*config.Config) *ai.GeminiClient, opts Options) *Server {
+	return &Server{cfg: cfg, newClient: newClient, opts: opts, limiter: newLimiter(opts.Limits), metrics: newMetrics()}
+}
+
+// ClientName is the name shown for a token configured without one
+func ClientName(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token-" + hex.EncodeToString(sum[:4])
+}
+
+type clientKey struct{}
+
+// client builds the AI client for a request, charging its calls
+// against the caller's daily quota
+func (s *Server) client(ctx context.Context, cfg *config.Config) *ai.GeminiClient {
+	c := s.newClient(cfg)
+	name, _ := ctx.Value(clientKey{}).(string)
+	before := c.BeforeCall
+	c.BeforeCall = func(est ai.Estimate) error {
+		if before != nil {
+			if err := before(est); err != nil {
+				return err
+			}
+		}
+		if err := s.limiter.charge(name, est.InputTokens+est.MaxOutputTokens); err != nil {
+			s.metrics.observeLimited("daily_quota")
+			return err
+		}
+		s.metrics.observeCall(est.InputTokens, est.MaxOutputTokens)
+		return nil
+	}
+	return c
+}
+
+// providerFailed counts errors coming from the AI provider (quota
+// rejections are counted separately)
+func (s *Server) providerFailed(method string, err error) error {
+	var exhausted errExhausted
+	if !errors.As(err, &exhausted) {
+		s.metrics.observeFailure(method)
+	}
+	return err
+}
+
+// errInvalid marks request errors (HTTP 400, gRPC INVALID_ARGUMENT)
+type errInvalid struct{ msg string }
+
+func (e errInvalid) Error() string { return e.msg }
+
+// GenerateCommit generates message(s) for a unified diff
+func (s *Server) GenerateCommit(ctx context.Context, req *GenerateCommitRequest) (*GenerateCommitResponse, error) {
+	changes := git.ParseDiff(req.Diff)
+	if len(changes) == 0 {
+		return nil, errInvalid{"diff contains no file changes"}
+	}
+	cfg := *s.cfg
+	if req.Language != "" {
+		cfg.Language = req.Language
+	}
+	if req.Style != "" {
+		cfg.CommitStyle = req.Style
+	}
+	commits := &app.CommitService{Config: &cfg, AI: s.client(ctx, &cfg)}
+	messages, err := commits.Generate(changes, req.Granular, req.RecentCommits)
+	if err != nil {
+		return nil, s.providerFailed("GenerateCommit", err)
+	}
+	return &GenerateCommitResponse{Messages: messages}, nil
+}
+
+// GenerateRelease generates release notes for commit subjects
+func (s *Server) GenerateRelease(ctx context.Context, req *GenerateReleaseRequest) (*GenerateReleaseResponse, error) {
+	if len(req.Commits) == 0 || req.NewTag == "" {
+		return nil, errInvalid{"commits and new_tag are required"}
+	}
+	if !ai.ValidAudience(req.Audience) {
+		return nil, errInvalid{fmt.Sprintf("invalid audience %q", req.Audience)}
+	}
+	releases := &app.ReleaseService{Config: s.cfg, AI: s.client(ctx, s.cfg)}
+	notes, err := releases.Notes(req.Commits, req.CurrentTag, req.NewTag, ai.ReleaseOptions{Audience: req.Audience})
+	if err != nil {
+		return nil, s.providerFailed("GenerateRelease", err)
+	}
+	return &GenerateReleaseResponse{Notes: notes}, nil
+}
+
+// SuggestVersion suggests the next semantic version
+func (s *Server) SuggestVersion(ctx context.Context, req *SuggestVersionRequest) (*SuggestVersionResponse, error) {
+	if len(req.Commits) == 0 {
+		return nil, errInvalid{"commits are required"}
+	}
+	releases := &app.ReleaseService{Config: s.cfg, AI: s.client(ctx, s.cfg)}
+	version, err := releases.NextVersion(req.Commits, req.CurrentTag, "", app.BumpAuto)
+	if err != nil {
+		return nil, s.providerFailed("SuggestVersion", err)
+	}
+	return &SuggestVersionResponse{Version: version}, nil
+}
+
+// Handler serves the HTTP JSON API under /v1/ and gRPC under
+// /commitai.v1.GenerationService/ (gRPC requires HTTP/2, i.e. TLS),
+// plus Prometheus metrics on /metrics.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+
+	mux.HandleFunc("/v1/commit", jsonHandler(s.GenerateCommit))
+	mux.HandleFunc("/v1/release", jsonHandler(s.GenerateRelease))
+	mux.HandleFunc("/v1/version", jsonHandler(s.SuggestVersion))
+
+	mux.HandleFunc("/commitai.v1.GenerationService/GenerateCommit", grpcHandler(s.GenerateCommit))
+	mux.HandleFunc("/commitai.v1.GenerationService/GenerateRelease", grpcHandler(s.GenerateRelease))
+	mux.HandleFunc("/commitai.v1.GenerationService/SuggestVersion", grpcHandler(s.SuggestVersion))
+
+	mux.HandleFunc("/admin/usage", s.handleUsage)
+	mux.HandleFunc("/admin/usage/reset", s.handleUsageReset)
+	mux.HandleFunc("/metrics", s.metrics.handler)
+
+	return s.metrics.instrument(s.authenticate(s.limit(mux)))
+}
+
+// authenticate resolves the caller's client name from the bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/metrics" || strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r) // Admin endpoints check the admin token themselves
+			return
+		}
+
+		if len(s.opts.Tokens) == 0 {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, "ip:"+host)))
+			return
+		}
+
+		got := bearer(r)
+		for token, name := range s.opts.Tokens {
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, name)))
+				return
+			}
+		}
+		if isGRPC(r) {
+			writeGRPCStatus(w, grpcUnauthenticated, "missing or invalid bearer token")
+			return
+		}
+		http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+	})
+}
+
+// limit applies the per-client rate limit and request size cap
+func (s *Server) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := r.Context().Value(clientKey{}).(string)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		maxBytes := s.limiter.limits.MaxRequestBytes
+		if r.ContentLength > maxBytes {
+			s.metrics.observeLimited("request_size")
+			writeError(w, r, errTooLarge{maxBytes})
+			return
+		}
+		if err := s.limiter.allow(name); err != nil {
+			s.metrics.observeLimited("rate_limit")
+			writeError(w, r, err)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// errTooLarge rejects bodies over the size cap (HTTP 413, gRPC RESOURCE_EXHAUSTED)
+type errTooLarge struct{ limit int64 }
+
+func (e errTooLarge) Error() string {
+	return fmt.Sprintf("request exceeds the %d byte limit; split the diff or commit in smaller pieces", e.limit)
+}
+
+// writeError reports an error in the caller's protocol
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if isGRPC(r) {
+		writeGRPCStatus(w, grpcCode(err), err.Error())
+		return
+	}
+	writeJSONError(w, httpStatus(err), err)
+}
+
+func httpStatus(err error) int {
+	var invalid errInvalid
+	var exhausted errExhausted
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge), errors.As(err, &errTooLarge{}):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &exhausted):
+		return http.StatusTooManyRequests
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway // The AI provider failed
+	}
+}
+
+func grpcCode(err error) int {
+	var invalid errInvalid
+	var exhausted errExhausted
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &exhausted), errors.As(err, &tooLarge), errors.As(err, &errTooLarge{}):
+		return grpcExhausted
+	case errors.As(err, &invalid):
+		return grpcInvalidArgument
+	default:
+		return grpcInternal
+	}
+}
+
+// handleUsage reports today's per-client usage
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(w, r) {
+		return
+	}
+	day, clients := s.limiter.snapshot()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"date":    day,
+		"limits":  s.limiter.limits,
+		"clients": clients,
+	})
+}
+
+// handleUsageReset clears one client's usage ({"client": "name"}) or everyone's ({})
+func (s *Server) handleUsageReset(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Client string `json:"client"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&req); err != nil && err != io.EOF {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.limiter.reset(req.Client)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) isAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.opts.AdminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(bearer(r)), []byte(s.opts.AdminToken)) != 1 {
+		http.Error(w, "missing or invalid admin token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// jsonHandler adapts a service method to a POST JSON endpoint
+func jsonHandler[Req any, Resp any](method func(context.Context, *Req) (*Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req Req
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			status := http.StatusBadRequest
+			if httpStatus(err) == http.StatusRequestEntityTooLarge {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeJSONError(w, status, err)
+			return
+		}
+		resp, err := method(r.Context(), &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	var exhausted errExhausted
+	if errors.As(err, &exhausted) && exhausted.retryAfter > 0 {
+		w.Header().Set("Retry-After", fmt.Sprint(int(exhausted.retryAfter.Round(time.Second).Seconds())+1))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// protoRequest and protoResponse are implemented by the message types
+type protoRequest interface{ unmarshal([]byte) error }
+type protoResponse interface{ marshal() []byte }
+
+// grpcHandler adapts a service method to a unary gRPC endpoint
+func grpcHandler[Req any, Resp any, PReq interface {
+	*Req
+	protoRequest
+}, PResp interface {
+	*Resp
+	protoResponse
+}](method func(context.Context, PReq) (PResp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isGRPC(r) {
+			http.Error(w, "gRPC endpoint: use HTTP/2 with content-type application/grpc", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeGRPCStatus(w, grpcCode(err), err.Error())
+			return
+		}
+		if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+			writeGRPCStatus(w, grpcInvalidArgument, "malformed gRPC frame")
+			return
+		}
+		if body[0] != 0 {
+			writeGRPCStatus(w, grpcUnimplemented, "compressed messages are not supported")
+			return
+		}
+
+		req := PReq(new(Req))
+		if err := req.unmarshal(body[5:]); err != nil {
+			writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+			return
+		}
+		resp, err := method(r.Context(), req)
+		if err != nil {
+			writeGRPCStatus(w, grpcCode(err), err.Error())
+			return
+		}
+
+		msg := resp.marshal()
+		frame := make([]byte, 5, 5+len(msg))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.Write(append(frame, msg...))
+		w.Header().Set("Grpc-Status", fmt.Sprint(grpcOK))
+	}
+}
+
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// writeGRPCStatus sends a trailers-only error response
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", fmt.Sprint(code))
+	w.Header().Set("Grpc-Message", percentEncode(msg))
+	w.WriteHeader(http.StatusOK)
+}
+
+// percentEncode escapes a grpc-message value as the gRPC spec requires
+func percentEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7E || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Minimal protocol buffers wire format codec for the messages in
+// api/proto/commitai/v1/commitai.proto (strings, bools, repeated strings and
+// string maps).
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("protobuf: truncated message")
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendStringMap(b []byte, field int, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = appendString(entry, 1, k)
+		entry = appendString(entry, 2, m[k])
+		b = appendTag(b, field, wireBytes)
+		b = binary.AppendUvarint(b, uint64(len(entry)))
+		b = append(b, entry...)
+	}
+	return b
+}
+
+// field is one decoded field; value is the varint for wireVarint and the
+// payload for wireBytes
+type field struct {
+	num    int
+	varint uint64
+	data   []byte
+}
+
+// decodeFields splits a message into its fields, skipping fixed-size ones
+func decodeFields(b []byte) ([]field, error) {
+	var fields []field
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		b = b[n:]
+		f := field{num: int(tag >> 3)}
+		switch tag & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			f.varint, b = v, b[n:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, errTruncated
+			}
+			f.data, b = b[n:n+int(l)], b[n+int(l):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, errTruncated
+			}
+			b = b[8:]
+			continue
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, errTruncated
+			}
+			b = b[4:]
+			continue
+		default:
+			return nil, errors.New("protobuf: unsupported wire type")
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
@@ -0,0 +1,101 @@
+package squash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// FileName is the draft store inside the .git directory
+const FileName = "commitai/squash.json"
+
+// Draft is the message of the commit a branch will become once
+// squash-merged, kept up to date as commits land on the branch
+type Draft struct {
+	Branch  string    `json:"branch"`
+	Base    string    `json:"base"` // Merge base the draft covers from
+	Head    string    `json:"head"` // Last commit the draft covers
+	Commits int       `json:"commits"`
+	Message string    `json:"message"`
+	Updated time.Time `json:"updated"`
+}
+
+// Title returns the subject line of the draft, e.g. for a pull request title
+func (d Draft) Title() string {
+	title, _, _ := strings.Cut(d.Message, "\n")
+	return strings.TrimSpace(title)
+}
+
+// Body returns the draft without its subject line, e.g. for a pull request body
+func (d Draft) Body() string {
+	_, body, _ := strings.Cut(d.Message, "\n")
+	return strings.TrimSpace(body)
+}
+
+// Store holds the drafts of every branch of a repository
+type Store struct {
+	path   string
+	Drafts map[string]Draft `json:"drafts"`
+}
+
+// Load reads the store from the given .git directory. A missing file yields
+// an empty store.
+func Load(gitDir string) (*Store, error) {
+	s := &Store{
+		path:   filepath.Join(gitDir, FileName),
+		Drafts: make(map[string]Draft),
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid squash drafts %s: %w", s.path, err)
+	}
+	if s.Drafts == nil {
+		s.Drafts = make(map[string]Draft)
+	}
+	return s, nil
+}
+
+// Get returns the draft of a branch
+func (s *Store) Get(branch string) (Draft, bool) {
+	d, ok := s.Drafts[branch]
+	return d, ok
+}
+
+// Put stores a draft and persists the store immediately
+func (s *Store) Put(d Draft) error {
+	s.Drafts[d.Branch] = d
+	return s.Save()
+}
+
+// Delete forgets the draft of a branch
+func (s *Store) Delete(branch string) error {
+	delete(s.Drafts, branch)
+	return s.Save()
+}
+
+// Save writes the store to disk
+func (s *Store) Save() error {
+	if err := readonly.Check("saving the squash draft"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
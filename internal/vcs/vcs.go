@@ -0,0 +1,240 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaiqui/commitai/internal/git"
+	"github.com/kaiqui/commitai/internal/readonly"
+)
+
+// VCS is the version control client that collects the changes a commit
+// message describes and records the commit
+type VCS interface {
+	Name() string
+	// Changes returns what the next commit will contain: the staged changes
+	// for git, the working-copy change for jj and Sapling
+	Changes() ([]git.FileChange, error)
+	Commit(message string, opts git.CommitOptions) error
+	// RecentCommits returns the last n commits, one line each, newest first
+	RecentCommits(n int) ([]string, error)
+	// Tags returns the repository's tags, oldest first
+	Tags() ([]string, error)
+}
+
+// Detect returns the client of the repository the current directory is in:
+// the nearest .jj, .sl, .hg or .git directory going up, jj first as it may
+// share its directory with git. It returns nil outside any repository.
+func Detect() VCS {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	for {
+		switch {
+		case isDir(filepath.Join(dir, ".jj")):
+			return Jujutsu{}
+		case isDir(filepath.Join(dir, ".sl")):
+			return Sapling{}
+		case isDir(filepath.Join(dir, ".hg")):
+			return Mercurial{}
+		case exists(filepath.Join(dir, ".git")): // A file in worktrees and submodules
+			return Git{}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if git.IsGitRepo() {
+		return Git{} // GIT_DIR and friends
+	}
+	return nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// run runs a client command and returns its standard output
+func run(name string, args ...string) (string, error) {
+	if args[0] == "commit" {
+		if err := readonly.Check(name + " commit"); err != nil {
+			return "", err
+		}
+	}
+	cmd := exec.Command(name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if _, lookErr := exec.LookPath(name); lookErr != nil {
+			return "", fmt.Errorf("%s not found on PATH", name)
+		}
+		return "", fmt.Errorf("%s %s: %s", name, args[0], strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// lines splits command output into its non-empty lines
+func lines(out string) []string {
+	var result []string
+	for _, l := range strings.Split(out, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// Git commits the staged changes
+type Git struct{}
+
+func (Git) Name() string { return "git" }
+
+func (Git) Changes() ([]git.FileChange, error) { return git.StagedChanges() }
+
+func (Git) Commit(message string, opts git.CommitOptions) error { return git.Commit(message, opts) }
+
+func (Git) RecentCommits(n int) ([]string, error) {
+	return git.RecentCommits(git.LogOptions{N: n})
+}
+
+func (Git) Tags() ([]string, error) { return git.Tags() }
+
+// Jujutsu describes the working-copy change (@) and starts a new one on
+// top of it, like jj commit
+type Jujutsu struct{}
+
+func (Jujutsu) Name() string { return "jj" }
+
+func (Jujutsu) Changes() ([]git.FileChange, error) {
+	out, err := run("jj", "diff", "--git", "-r", "@")
+	if err != nil {
+		return nil, err
+	}
+	return git.ParseDiff(out), nil
+}
+
+func (Jujutsu) Commit(message string, opts git.CommitOptions) error {
+	if opts.Author != "" || opts.Date != "" {
+		return fmt.Errorf("--author and --date are not supported with jj; use jj describe --author")
+	}
+	_, err := run("jj", "commit", "-m", message)
+	return err
+}
+
+func (Jujutsu) RecentCommits(n int) ([]string, error) {
+	out, err := run("jj", "log", "--no-graph", "-r", "::@- & ~root()", "-n", strconv.Itoa(n),
+		"-T", `description.first_line() ++ "\n"`)
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+func (Jujutsu) Tags() ([]string, error) {
+	out, err := run("jj", "tag", "list", "-T", `name ++ "\n"`)
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+// Sapling commits the pending changes of tracked files, like sl commit
+type Sapling struct{}
+
+func (Sapling) Name() string { return "Sapling" }
+
+func (Sapling) Changes() ([]git.FileChange, error) {
+	out, err := run("sl", "diff", "--git")
+	if err != nil {
+		return nil, err
+	}
+	return git.ParseDiff(out), nil
+}
+
+func (Sapling) Commit(message string, opts git.CommitOptions) error {
+	args := []string{"commit", "-m", message}
+	if opts.Author != "" {
+		args = append(args, "--user", opts.Author)
+	}
+	if opts.Date != "" {
+		args = append(args, "--date", opts.Date)
+	}
+	_, err := run("sl", args...)
+	return err
+}
+
+func (Sapling) RecentCommits(n int) ([]string, error) {
+	out, err := run("sl", "log", "-l", strconv.Itoa(n), "-T", "{desc|firstline}\n")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+func (Sapling) Tags() ([]string, error) { return hgTags("sl") }
+
+// Mercurial commits the pending changes of tracked files, like hg commit
+type Mercurial struct{}
+
+func (Mercurial) Name() string { return "Mercurial" }
+
+func (Mercurial) Changes() ([]git.FileChange, error) {
+	out, err := run("hg", "diff", "--git")
+	if err != nil {
+		return nil, err
+	}
+	return git.ParseDiff(out), nil
+}
+
+func (Mercurial) Commit(message string, opts git.CommitOptions) error {
+	args := []string{"commit", "-m", message}
+	if opts.Author != "" {
+		args = append(args, "--user", opts.Author)
+	}
+	if opts.Date != "" {
+		args = append(args, "--date", opts.Date)
+	}
+	_, err := run("hg", args...)
+	return err
+}
+
+func (Mercurial) RecentCommits(n int) ([]string, error) {
+	out, err := run("hg", "log", "-l", strconv.Itoa(n), "-T", "{desc|firstline}\n")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+func (Mercurial) Tags() ([]string, error) { return hgTags("hg") }
+
+// hgTags lists the tags of a Mercurial or Sapling repository, which print
+// them newest first, without the tip pseudo-tag
+func hgTags(client string) ([]string, error) {
+	out, err := run(client, "tags", "-q")
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	all := lines(out)
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i] != "tip" {
+			tags = append(tags, all[i])
+		}
+	}
+	return tags, nil
+}
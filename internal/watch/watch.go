@@ -0,0 +1,98 @@
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Watcher polls a fingerprint of the working tree and reports when changes
+// have settled, i.e. the tree stopped changing for the debounce period.
+type Watcher struct {
+	Interval time.Duration // How often to poll
+	Debounce time.Duration // Quiet period before changes count as settled
+
+	// Fingerprint summarizes the watched state; a change in its value is a
+	// change in the tree. Defaults to GitFingerprint.
+	Fingerprint func() (string, error)
+}
+
+// Run blocks until ctx is done, calling onSettled each time a batch of
+// changes settles. The initial state does not count as a change.
+func (w *Watcher) Run(ctx context.Context, onSettled func()) error {
+	fingerprint := w.Fingerprint
+	if fingerprint == nil {
+		fingerprint = GitFingerprint
+	}
+
+	handled, err := fingerprint()
+	if err != nil {
+		return err
+	}
+	current := handled
+	var lastChange time.Time
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		fp, err := fingerprint()
+		if err != nil {
+			return err
+		}
+		if fp != current {
+			current = fp
+			lastChange = time.Now()
+			continue
+		}
+		if current != handled && time.Since(lastChange) >= w.Debounce {
+			onSettled()
+			// The callback usually changes the tree (staging, committing)
+			if handled, err = fingerprint(); err != nil {
+				return err
+			}
+			current = handled
+		}
+	}
+}
+
+// GitFingerprint hashes the git status of the working tree together with the
+// size and modification time of every changed file. Ignored files don't count.
+func GitFingerprint() (string, error) {
+	out, err := exec.Command("git", "status", "--porcelain=v1", "--untracked-files=all").Output()
+	if err != nil {
+		return "", fmt.Errorf("git status failed: %w", err)
+	}
+	top, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	root := strings.TrimSpace(string(top))
+
+	h := sha256.New()
+	h.Write(out)
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+4:]
+		}
+		if info, err := os.Lstat(filepath.Join(root, path)); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}